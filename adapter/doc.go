@@ -7,6 +7,8 @@
 // This package includes adapters for:
 //   - GoSkills: Custom Go-based skills and tools
 //   - MCP (Model Context Protocol): Standardized tool communication
+//   - OpenTelemetry: Export of graph traces and callbacks as OpenTelemetry spans
+//   - Prometheus: Export of node, retry, circuit breaker, rate limit and checkpoint metrics
 //
 // # Core Concepts
 //
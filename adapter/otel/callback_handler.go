@@ -0,0 +1,212 @@
+package otel
+
+import (
+	"context"
+	"sync"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// CallbackHandler is a graph.CallbackHandler that records chain, LLM, tool and retriever
+// callbacks as OpenTelemetry spans.
+//
+// A span is a child of the span of its parent run when the parent run ID is known,
+// otherwise of the span current in the context of the callback. LLM spans carry the
+// token usage reported by the model as gen_ai.usage.* attributes.
+type CallbackHandler struct {
+	tracer trace.Tracer
+
+	mu    sync.Mutex
+	spans map[string]trace.Span
+}
+
+var _ graph.CallbackHandler = (*CallbackHandler)(nil)
+
+// NewCallbackHandler creates a CallbackHandler that starts spans with tracer.
+// If tracer is nil, the tracer of the global TracerProvider is used.
+//
+// Example:
+//
+//	config := &graph.Config{
+//	    Callbacks: []graph.CallbackHandler{otel.NewCallbackHandler(nil)},
+//	}
+//	result, err := runnable.InvokeWithConfig(ctx, input, config)
+func NewCallbackHandler(tracer trace.Tracer) *CallbackHandler {
+	if tracer == nil {
+		tracer = globalTracer()
+	}
+	return &CallbackHandler{
+		tracer: tracer,
+		spans:  make(map[string]trace.Span),
+	}
+}
+
+// OnChainStart implements graph.CallbackHandler.
+func (h *CallbackHandler) OnChainStart(ctx context.Context, serialized map[string]any, inputs map[string]any, runID string, parentRunID *string, tags []string, metadata map[string]any) {
+	h.start(ctx, "chain", serialized, runID, parentRunID, tags)
+}
+
+// OnChainEnd implements graph.CallbackHandler.
+func (h *CallbackHandler) OnChainEnd(ctx context.Context, outputs map[string]any, runID string) {
+	h.end(runID, nil)
+}
+
+// OnChainError implements graph.CallbackHandler.
+func (h *CallbackHandler) OnChainError(ctx context.Context, err error, runID string) {
+	h.end(runID, err)
+}
+
+// OnLLMStart implements graph.CallbackHandler.
+func (h *CallbackHandler) OnLLMStart(ctx context.Context, serialized map[string]any, prompts []string, runID string, parentRunID *string, tags []string, metadata map[string]any) {
+	span := h.start(ctx, "llm", serialized, runID, parentRunID, tags)
+	span.SetAttributes(attribute.Int("gen_ai.prompt.count", len(prompts)))
+	if model, ok := metadata["model"].(string); ok {
+		span.SetAttributes(attribute.String("gen_ai.request.model", model))
+	}
+}
+
+// OnLLMEnd implements graph.CallbackHandler.
+// Token usage is read from the generation info of an *llms.ContentResponse,
+// or from a map response holding the same keys.
+func (h *CallbackHandler) OnLLMEnd(ctx context.Context, response any, runID string) {
+	h.mu.Lock()
+	span, ok := h.spans[runID]
+	h.mu.Unlock()
+	if ok {
+		span.SetAttributes(tokenUsage(response)...)
+	}
+	h.end(runID, nil)
+}
+
+// OnLLMError implements graph.CallbackHandler.
+func (h *CallbackHandler) OnLLMError(ctx context.Context, err error, runID string) {
+	h.end(runID, err)
+}
+
+// OnToolStart implements graph.CallbackHandler.
+func (h *CallbackHandler) OnToolStart(ctx context.Context, serialized map[string]any, inputStr string, runID string, parentRunID *string, tags []string, metadata map[string]any) {
+	h.start(ctx, "tool", serialized, runID, parentRunID, tags)
+}
+
+// OnToolEnd implements graph.CallbackHandler.
+func (h *CallbackHandler) OnToolEnd(ctx context.Context, output string, runID string) {
+	h.end(runID, nil)
+}
+
+// OnToolError implements graph.CallbackHandler.
+func (h *CallbackHandler) OnToolError(ctx context.Context, err error, runID string) {
+	h.end(runID, err)
+}
+
+// OnRetrieverStart implements graph.CallbackHandler.
+func (h *CallbackHandler) OnRetrieverStart(ctx context.Context, serialized map[string]any, query string, runID string, parentRunID *string, tags []string, metadata map[string]any) {
+	h.start(ctx, "retriever", serialized, runID, parentRunID, tags)
+}
+
+// OnRetrieverEnd implements graph.CallbackHandler.
+func (h *CallbackHandler) OnRetrieverEnd(ctx context.Context, documents []any, runID string) {
+	h.mu.Lock()
+	span, ok := h.spans[runID]
+	h.mu.Unlock()
+	if ok {
+		span.SetAttributes(attribute.Int("langgraph.retriever.documents", len(documents)))
+	}
+	h.end(runID, nil)
+}
+
+// OnRetrieverError implements graph.CallbackHandler.
+func (h *CallbackHandler) OnRetrieverError(ctx context.Context, err error, runID string) {
+	h.end(runID, err)
+}
+
+func (h *CallbackHandler) start(ctx context.Context, kind string, serialized map[string]any, runID string, parentRunID *string, tags []string) trace.Span {
+	h.mu.Lock()
+	if parentRunID != nil {
+		if parent, ok := h.spans[*parentRunID]; ok {
+			ctx = trace.ContextWithSpan(ctx, parent)
+		}
+	}
+	h.mu.Unlock()
+
+	name := kind
+	if n, ok := serialized["name"].(string); ok && n != "" {
+		name = kind + " " + n
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("langgraph.span.kind", kind),
+		attribute.String("langgraph.run_id", runID),
+	}
+	if len(tags) > 0 {
+		attrs = append(attrs, attribute.StringSlice("langgraph.tags", tags))
+	}
+
+	_, span := h.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+
+	h.mu.Lock()
+	h.spans[runID] = span
+	h.mu.Unlock()
+	return span
+}
+
+func (h *CallbackHandler) end(runID string, err error) {
+	h.mu.Lock()
+	span, ok := h.spans[runID]
+	delete(h.spans, runID)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+	recordError(span, err)
+	span.End()
+}
+
+// tokenUsage returns the token usage attributes of an LLM response.
+func tokenUsage(response any) []attribute.KeyValue {
+	var info map[string]any
+	switch r := response.(type) {
+	case *llms.ContentResponse:
+		if r != nil && len(r.Choices) > 0 {
+			info = r.Choices[0].GenerationInfo
+		}
+	case map[string]any:
+		info = r
+	}
+
+	var attrs []attribute.KeyValue
+	add := func(key string, names ...string) {
+		for _, name := range names {
+			if n, ok := toInt(info[name]); ok {
+				attrs = append(attrs, attribute.Int(key, n))
+				return
+			}
+		}
+	}
+	add("gen_ai.usage.input_tokens", "PromptTokens", "InputTokens", "input_tokens", "prompt_tokens")
+	add("gen_ai.usage.output_tokens", "CompletionTokens", "OutputTokens", "output_tokens", "completion_tokens")
+	add("gen_ai.usage.total_tokens", "TotalTokens", "total_tokens")
+	return attrs
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// globalTracer returns the tracer of the global TracerProvider.
+func globalTracer() trace.Tracer {
+	return otelapi.GetTracerProvider().Tracer(InstrumentationName)
+}
@@ -0,0 +1,60 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestCallbackHandlerSpans(t *testing.T) {
+	otelTracer, exporter := newTestTracer(t)
+	handler := NewCallbackHandler(otelTracer)
+
+	ctx, parent := otelTracer.Start(context.Background(), "request")
+
+	chainID := "chain-1"
+	handler.OnChainStart(ctx, map[string]any{"name": "agent"}, nil, chainID, nil, []string{"test"}, nil)
+	handler.OnLLMStart(ctx, map[string]any{"name": "gpt"}, []string{"hi"}, "llm-1", &chainID, nil, map[string]any{"model": "gpt-test"})
+	handler.OnLLMEnd(ctx, &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		Content:        "hello",
+		GenerationInfo: map[string]any{"PromptTokens": 12, "CompletionTokens": 5, "TotalTokens": 17},
+	}}}, "llm-1")
+	handler.OnToolStart(ctx, map[string]any{"name": "search"}, "query", "tool-1", &chainID, nil, nil)
+	handler.OnToolError(ctx, errors.New("tool failed"), "tool-1")
+	handler.OnChainEnd(ctx, nil, chainID)
+	parent.End()
+
+	byName := spansByName(exporter.GetSpans())
+	chain := byName["chain agent"]
+	llm := byName["llm gpt"]
+	tool := byName["tool search"]
+
+	assert.Equal(t, parent.SpanContext().SpanID(), chain.Parent.SpanID())
+	assert.Equal(t, chain.SpanContext.SpanID(), llm.Parent.SpanID())
+	assert.Equal(t, chain.SpanContext.SpanID(), tool.Parent.SpanID())
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range llm.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, int64(12), attrs["gen_ai.usage.input_tokens"].AsInt64())
+	assert.Equal(t, int64(5), attrs["gen_ai.usage.output_tokens"].AsInt64())
+	assert.Equal(t, int64(17), attrs["gen_ai.usage.total_tokens"].AsInt64())
+	assert.Equal(t, "gpt-test", attrs["gen_ai.request.model"].AsString())
+
+	assert.Equal(t, "Error", tool.Status.Code.String())
+	assert.Equal(t, "Unset", chain.Status.Code.String())
+}
+
+func TestTokenUsageFromMap(t *testing.T) {
+	attrs := tokenUsage(map[string]any{"input_tokens": float64(3), "output_tokens": float64(4)})
+	require.Len(t, attrs, 2)
+	assert.Equal(t, attribute.Int("gen_ai.usage.input_tokens", 3), attrs[0])
+	assert.Equal(t, attribute.Int("gen_ai.usage.output_tokens", 4), attrs[1])
+	assert.Empty(t, tokenUsage("no usage"))
+}
@@ -0,0 +1,31 @@
+// Package otel exports the execution of LangGraph Go graphs to OpenTelemetry.
+//
+// The package bridges the two observability surfaces of the graph package:
+//
+//   - TraceHook turns the spans of a graph.Tracer into OpenTelemetry spans. Graph, node
+//     and edge spans keep their nesting, including subgraphs and the branches of a
+//     ParallelNode, and nodes run with the OpenTelemetry span of the node in their context.
+//   - CallbackHandler records chain, LLM, tool and retriever callbacks as spans, with the
+//     token usage of LLM calls as gen_ai.usage.* attributes.
+//
+// Both use the tracer given to their constructor, or the tracer of the global TracerProvider.
+//
+// # Tracing a graph
+//
+//	tracer := graph.NewTracer()
+//	tracer.AddHook(otel.NewTraceHook(nil))
+//	runnable.SetTracer(tracer)
+//
+//	config := &graph.Config{
+//		Callbacks: []graph.CallbackHandler{otel.NewCallbackHandler(nil)},
+//	}
+//	result, err := runnable.InvokeWithConfig(ctx, input, config)
+//
+// # Propagation
+//
+// The ptc package propagates the trace context of a node to the tools it calls through
+// the ToolServer HTTP API, using the global TextMapPropagator. Configure one to link the
+// tool spans to the graph:
+//
+//	otelapi.SetTextMapPropagator(propagation.TraceContext{})
+package otel
@@ -0,0 +1,163 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer used by this package.
+const InstrumentationName = "github.com/smallnest/langgraphgo"
+
+// TraceHook is a graph.TraceHook that exports the spans of a graph.Tracer as OpenTelemetry spans.
+//
+// Graph and node spans become OpenTelemetry spans nested like the graph spans: nodes under
+// their graph, subgraphs under the node running them, and the branches of a ParallelNode
+// under the parallel node. Edge traversals become zero-length spans under the graph, and
+// node retries become events on the node span.
+//
+// TraceHook implements graph.TraceContextHook, so the context passed to a node carries the
+// OpenTelemetry span of the node. Spans started by the node code, e.g. by CallbackHandler or
+// an instrumented HTTP client, are nested under it.
+type TraceHook struct {
+	tracer trace.Tracer
+
+	mu    sync.Mutex
+	spans map[string]trace.Span
+}
+
+// NewTraceHook creates a TraceHook that starts spans with tracer.
+// If tracer is nil, the tracer of the global TracerProvider is used.
+//
+// Example:
+//
+//	tracer := graph.NewTracer()
+//	tracer.AddHook(otel.NewTraceHook(nil))
+//	runnable.SetTracer(tracer)
+func NewTraceHook(tracer trace.Tracer) *TraceHook {
+	if tracer == nil {
+		tracer = globalTracer()
+	}
+	return &TraceHook{
+		tracer: tracer,
+		spans:  make(map[string]trace.Span),
+	}
+}
+
+// OnEvent implements graph.TraceHook.
+func (h *TraceHook) OnEvent(ctx context.Context, span *graph.TraceSpan) {
+	switch span.Event {
+	case graph.TraceEventEdgeTraversal:
+		_, edge := h.tracer.Start(ctx, "edge "+span.FromNode+" -> "+span.ToNode,
+			trace.WithTimestamp(span.StartTime),
+			trace.WithAttributes(
+				attribute.String("langgraph.edge.from", span.FromNode),
+				attribute.String("langgraph.edge.to", span.ToNode),
+			))
+		edge.End(trace.WithTimestamp(span.EndTime))
+
+	case graph.TraceEventNodeRetry:
+		// Retries are reported once when they start and once when they end
+		if span.EndTime.IsZero() {
+			return
+		}
+		attrs := []attribute.KeyValue{attribute.String("langgraph.node", span.NodeName)}
+		if attempt, ok := span.Metadata["attempt"].(int); ok {
+			attrs = append(attrs, attribute.Int("langgraph.retry.attempt", attempt))
+		}
+		if delay, ok := span.Metadata["delay"]; ok {
+			attrs = append(attrs, attribute.String("langgraph.retry.delay", fmt.Sprint(delay)))
+		}
+		if span.Error != nil {
+			attrs = append(attrs, attribute.String("exception.message", span.Error.Error()))
+		}
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithTimestamp(span.StartTime), trace.WithAttributes(attrs...))
+
+	default:
+		if span.EndTime.IsZero() {
+			h.start(ctx, span)
+		} else {
+			h.end(span)
+		}
+	}
+}
+
+// ContextWithSpan implements graph.TraceContextHook. It makes the OpenTelemetry span
+// exported for span the current span of ctx.
+func (h *TraceHook) ContextWithSpan(ctx context.Context, span *graph.TraceSpan) context.Context {
+	h.mu.Lock()
+	otelSpan, ok := h.spans[span.ID]
+	h.mu.Unlock()
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, otelSpan)
+}
+
+func (h *TraceHook) start(ctx context.Context, span *graph.TraceSpan) {
+	var name string
+	var attrs []attribute.KeyValue
+	switch span.Event {
+	case graph.TraceEventGraphStart:
+		name = "graph"
+		attrs = append(attrs, attribute.String("langgraph.span.kind", "graph"))
+	case graph.TraceEventNodeStart:
+		name = "node " + span.NodeName
+		attrs = append(attrs,
+			attribute.String("langgraph.span.kind", "node"),
+			attribute.String("langgraph.node", span.NodeName))
+		if group, ok := span.Metadata["parallel_group"].(string); ok {
+			attrs = append(attrs, attribute.String("langgraph.parallel_group", group))
+		}
+	default:
+		// A node error is also reported on the span of the node
+		return
+	}
+
+	_, otelSpan := h.tracer.Start(ctx, name, trace.WithTimestamp(span.StartTime), trace.WithAttributes(attrs...))
+
+	h.mu.Lock()
+	h.spans[span.ID] = otelSpan
+	h.mu.Unlock()
+}
+
+func (h *TraceHook) end(span *graph.TraceSpan) {
+	h.mu.Lock()
+	otelSpan, ok := h.spans[span.ID]
+	delete(h.spans, span.ID)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if hit, ok := span.Metadata["cache_hit"].(bool); ok {
+		otelSpan.SetAttributes(attribute.Bool("langgraph.cache_hit", hit))
+	}
+	recordError(otelSpan, span.Error)
+	otelSpan.End(trace.WithTimestamp(span.EndTime))
+}
+
+// recordError marks span as failed with err. Interrupts are recorded as attributes,
+// as they pause the graph rather than fail it.
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	var graphInterrupt *graph.GraphInterrupt
+	var nodeInterrupt *graph.NodeInterrupt
+	if errors.As(err, &graphInterrupt) || errors.As(err, &nodeInterrupt) {
+		span.SetAttributes(attribute.Bool("langgraph.interrupted", true))
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
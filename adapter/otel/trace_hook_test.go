@@ -0,0 +1,156 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+func newTestTracer(t *testing.T) (trace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider.Tracer(InstrumentationName), exporter
+}
+
+func spansByName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	return byName
+}
+
+func TestTraceHookNesting(t *testing.T) {
+	otelTracer, exporter := newTestTracer(t)
+
+	inner := graph.NewStateGraph[map[string]any]()
+	inner.AddNode("child", "child", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"child": true}, nil
+	})
+	inner.SetEntryPoint("child")
+	inner.AddEdge("child", graph.END)
+
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("first", "first", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		// Spans started by node code are nested under the node
+		_, span := otelTracer.Start(ctx, "work")
+		span.End()
+		return map[string]any{"first": true}, nil
+	})
+	require.NoError(t, graph.AddSubgraph(g, "sub", inner,
+		func(s map[string]any) map[string]any { return s },
+		func(s map[string]any) map[string]any { return s }))
+	g.AddParallelNodes("fan", map[string]func(context.Context, map[string]any) (map[string]any, error){
+		"left": func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"left": true}, nil
+		},
+		"right": func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"right": true}, nil
+		},
+	}, func(results []map[string]any) map[string]any { return map[string]any{} })
+	g.SetEntryPoint("first")
+	g.AddEdge("first", "sub")
+	g.AddEdge("sub", "fan")
+	g.AddEdge("fan", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	tracer := graph.NewTracer()
+	tracer.AddHook(NewTraceHook(otelTracer))
+	runnable.SetTracer(tracer)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	byName := spansByName(spans)
+
+	var graphSpans []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == "graph" {
+			graphSpans = append(graphSpans, span)
+		}
+	}
+	require.Len(t, graphSpans, 2)
+
+	var root, subRoot tracetest.SpanStub
+	for _, span := range graphSpans {
+		if span.Parent.IsValid() {
+			subRoot = span
+		} else {
+			root = span
+		}
+	}
+
+	parentOf := func(name string) trace.SpanID {
+		span, ok := byName[name]
+		require.True(t, ok, "missing span %s", name)
+		return span.Parent.SpanID()
+	}
+
+	assert.Equal(t, root.SpanContext.SpanID(), parentOf("node first"))
+	assert.Equal(t, byName["node first"].SpanContext.SpanID(), parentOf("work"))
+	assert.Equal(t, byName["node sub"].SpanContext.SpanID(), subRoot.Parent.SpanID())
+	assert.Equal(t, subRoot.SpanContext.SpanID(), parentOf("node child"))
+	assert.Equal(t, byName["node fan"].SpanContext.SpanID(), parentOf("node left"))
+	assert.Equal(t, byName["node fan"].SpanContext.SpanID(), parentOf("node right"))
+	assert.Equal(t, root.SpanContext.SpanID(), parentOf("edge first -> sub"))
+
+	for _, span := range spans {
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID())
+	}
+}
+
+func TestTraceHookRecordsErrorsAndRetries(t *testing.T) {
+	otelTracer, exporter := newTestTracer(t)
+
+	attempts := 0
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("flaky", "flaky", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("transient")
+		}
+		return nil, errors.New("permanent")
+	}, graph.WithRetryPolicy(&graph.RetryPolicy{
+		MaxRetries:   1,
+		InitialDelay: time.Millisecond,
+		Retryable:    func(err error) bool { return err.Error() == "transient" },
+	}))
+	g.SetEntryPoint("flaky")
+	g.AddEdge("flaky", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	tracer := graph.NewTracer()
+	tracer.AddHook(NewTraceHook(otelTracer))
+	runnable.SetTracer(tracer)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.Error(t, err)
+
+	byName := spansByName(exporter.GetSpans())
+	node := byName["node flaky"]
+	assert.Equal(t, "Error", node.Status.Code.String())
+	require.NotEmpty(t, node.Events)
+
+	var retries int
+	for _, event := range node.Events {
+		if event.Name == "retry" {
+			retries++
+		}
+	}
+	assert.Equal(t, 1, retries)
+	assert.Equal(t, "Error", byName["graph"].Status.Code.String())
+}
@@ -0,0 +1,34 @@
+// Package prometheus exports metrics of LangGraph Go graphs to a Prometheus registry.
+//
+// Listener is a node listener, like graph.MetricsListener, and a graph.CallbackHandler.
+// It records:
+//
+//   - langgraph_node_executions_total, langgraph_node_errors_total and
+//     langgraph_node_duration_seconds, labelled by graph and node
+//   - langgraph_active_runs, the invocations of the graph in progress
+//   - langgraph_node_retries_total, the retried attempts of nodes with a retry policy
+//   - langgraph_circuit_breaker_transitions_total and langgraph_circuit_breaker_state
+//     for nodes added with AddNodeWithCircuitBreaker
+//   - langgraph_rate_limited_total and langgraph_rate_limit_wait_seconds for nodes
+//     added with AddNodeWithRateLimit
+//   - langgraph_checkpoint_save_duration_seconds, labelled by checkpoint store
+//
+// Node metrics are collected by adding the listener to the nodes of a ListenableStateGraph,
+// the others by passing it as a callback of the invocation:
+//
+//	listener, err := prometheus.NewListener(prom.DefaultRegisterer, prometheus.WithGraphName("agent"))
+//	if err != nil {
+//		return err
+//	}
+//
+//	g := graph.NewListenableStateGraph[map[string]any]()
+//	// ... add nodes and edges
+//	g.AddGlobalListener(listener)
+//	runnable, _ := g.CompileListenable()
+//
+//	config := &graph.Config{Callbacks: []graph.CallbackHandler{listener}}
+//	result, err := runnable.InvokeWithConfig(ctx, input, config)
+//
+// Listeners of several graphs can share a registry: they use the same collectors with
+// a different graph label.
+package prometheus
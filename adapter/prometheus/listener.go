@@ -0,0 +1,275 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// Listener records the execution of a graph as Prometheus metrics.
+// It implements graph.NodeListener[map[string]any], graph.CallbackHandler,
+// graph.RetryCallbackHandler, graph.CircuitBreakerCallbackHandler,
+// graph.RateLimitCallbackHandler and graph.CheckpointCallbackHandler.
+type Listener struct {
+	graph.NoOpCallbackHandler
+
+	graphName string
+
+	nodeExecutions     *prom.CounterVec
+	nodeErrors         *prom.CounterVec
+	nodeDuration       *prom.HistogramVec
+	activeRuns         *prom.GaugeVec
+	retries            *prom.CounterVec
+	circuitTransitions *prom.CounterVec
+	circuitState       *prom.GaugeVec
+	rateLimited        *prom.CounterVec
+	rateLimitWait      *prom.HistogramVec
+	checkpointSave     *prom.HistogramVec
+
+	mu         sync.Mutex
+	startTimes map[execution]time.Time
+	runs       map[string]struct{}
+}
+
+// execution identifies a running node. The listeners of a node are notified of its
+// start and end with the same context, which tells apart concurrent runs of the node.
+type execution struct {
+	ctx  context.Context
+	node string
+}
+
+// Option configures a Listener.
+type Option func(*options)
+
+type options struct {
+	graphName string
+	namespace string
+	buckets   []float64
+}
+
+// WithGraphName sets the value of the graph label, "graph" by default.
+func WithGraphName(name string) Option {
+	return func(o *options) {
+		o.graphName = name
+	}
+}
+
+// WithNamespace prefixes the metric names with namespace, e.g. "myapp_langgraph_active_runs".
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets of the duration histograms, prometheus.DefBuckets by default.
+func WithBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// NewListener creates a Listener and registers its collectors with reg.
+// Collectors already registered by the listener of another graph are reused.
+func NewListener(reg prom.Registerer, opts ...Option) (*Listener, error) {
+	o := options{
+		graphName: "graph",
+		buckets:   prom.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l := &Listener{
+		graphName:  o.graphName,
+		startTimes: make(map[execution]time.Time),
+		runs:       make(map[string]struct{}),
+	}
+
+	var err error
+	if l.nodeExecutions, err = register(reg, prom.NewCounterVec(prom.CounterOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_node_executions_total",
+		Help:      "Number of finished node executions.",
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.nodeErrors, err = register(reg, prom.NewCounterVec(prom.CounterOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_node_errors_total",
+		Help:      "Number of node executions that failed.",
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.nodeDuration, err = register(reg, prom.NewHistogramVec(prom.HistogramOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_node_duration_seconds",
+		Help:      "Duration of node executions.",
+		Buckets:   o.buckets,
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.activeRuns, err = register(reg, prom.NewGaugeVec(prom.GaugeOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_active_runs",
+		Help:      "Number of graph invocations in progress.",
+	}, []string{"graph"})); err != nil {
+		return nil, err
+	}
+	if l.retries, err = register(reg, prom.NewCounterVec(prom.CounterOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_node_retries_total",
+		Help:      "Number of failed node attempts that were retried.",
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.circuitTransitions, err = register(reg, prom.NewCounterVec(prom.CounterOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_circuit_breaker_transitions_total",
+		Help:      "Number of circuit breaker state transitions.",
+	}, []string{"graph", "node", "from", "to"})); err != nil {
+		return nil, err
+	}
+	if l.circuitState, err = register(reg, prom.NewGaugeVec(prom.GaugeOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_circuit_breaker_state",
+		Help:      "State of circuit breakers: 0 closed, 1 open, 2 half-open.",
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.rateLimited, err = register(reg, prom.NewCounterVec(prom.CounterOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_rate_limited_total",
+		Help:      "Number of node calls rejected by a rate limiter.",
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.rateLimitWait, err = register(reg, prom.NewHistogramVec(prom.HistogramOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_rate_limit_wait_seconds",
+		Help:      "Time until a rate limiter accepts calls again, when a call was rejected.",
+		Buckets:   o.buckets,
+	}, []string{"graph", "node"})); err != nil {
+		return nil, err
+	}
+	if l.checkpointSave, err = register(reg, prom.NewHistogramVec(prom.HistogramOpts{
+		Namespace: o.namespace,
+		Name:      "langgraph_checkpoint_save_duration_seconds",
+		Help:      "Duration of checkpoint saves.",
+		Buckets:   o.buckets,
+	}, []string{"graph", "store", "status"})); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// register registers c with reg, returning the collector registered before if there is one.
+func register[C prom.Collector](reg prom.Registerer, c C) (C, error) {
+	if err := reg.Register(c); err != nil {
+		var are prom.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		var zero C
+		return zero, err
+	}
+	return c, nil
+}
+
+// OnNodeEvent implements the NodeListener[map[string]any] interface
+func (l *Listener) OnNodeEvent(ctx context.Context, event graph.NodeEvent, nodeName string, _ map[string]any, err error) {
+	key := execution{ctx: ctx, node: nodeName}
+
+	switch event {
+	case graph.NodeEventStart:
+		l.mu.Lock()
+		l.startTimes[key] = time.Now()
+		l.mu.Unlock()
+
+	case graph.NodeEventComplete, graph.NodeEventError:
+		l.mu.Lock()
+		start, ok := l.startTimes[key]
+		delete(l.startTimes, key)
+		l.mu.Unlock()
+
+		l.nodeExecutions.WithLabelValues(l.graphName, nodeName).Inc()
+		if event == graph.NodeEventError {
+			l.nodeErrors.WithLabelValues(l.graphName, nodeName).Inc()
+		}
+		if ok {
+			l.nodeDuration.WithLabelValues(l.graphName, nodeName).Observe(time.Since(start).Seconds())
+		}
+	}
+}
+
+// NodeListener returns a listener recording the node metrics of a graph with state S into l.
+func NodeListener[S any](l *Listener) graph.NodeListener[S] {
+	return graph.NodeListenerFunc[S](func(ctx context.Context, event graph.NodeEvent, nodeName string, _ S, err error) {
+		l.OnNodeEvent(ctx, event, nodeName, nil, err)
+	})
+}
+
+// OnChainStart implements graph.CallbackHandler.
+func (l *Listener) OnChainStart(_ context.Context, _ map[string]any, _ map[string]any, runID string, _ *string, _ []string, _ map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.runs[runID]; ok {
+		return
+	}
+	l.runs[runID] = struct{}{}
+	l.activeRuns.WithLabelValues(l.graphName).Inc()
+}
+
+// OnChainEnd implements graph.CallbackHandler.
+func (l *Listener) OnChainEnd(_ context.Context, _ map[string]any, runID string) {
+	l.endRun(runID)
+}
+
+// OnChainError implements graph.CallbackHandler.
+func (l *Listener) OnChainError(_ context.Context, _ error, runID string) {
+	l.endRun(runID)
+}
+
+// endRun decrements the active runs once per run, as a run may both fail and end.
+func (l *Listener) endRun(runID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.runs[runID]; !ok {
+		return
+	}
+	delete(l.runs, runID)
+	l.activeRuns.WithLabelValues(l.graphName).Dec()
+}
+
+// OnNodeRetry implements graph.RetryCallbackHandler.
+func (l *Listener) OnNodeRetry(_ context.Context, nodeName string, _ int, _ error, _ time.Duration, _ string) {
+	l.retries.WithLabelValues(l.graphName, nodeName).Inc()
+}
+
+// OnCircuitBreakerStateChange implements graph.CircuitBreakerCallbackHandler.
+func (l *Listener) OnCircuitBreakerStateChange(_ context.Context, nodeName string, from, to graph.CircuitBreakerState) {
+	l.circuitTransitions.WithLabelValues(l.graphName, nodeName, from.String(), to.String()).Inc()
+	l.circuitState.WithLabelValues(l.graphName, nodeName).Set(float64(to))
+}
+
+// OnRateLimited implements graph.RateLimitCallbackHandler.
+func (l *Listener) OnRateLimited(_ context.Context, nodeName string, wait time.Duration) {
+	l.rateLimited.WithLabelValues(l.graphName, nodeName).Inc()
+	l.rateLimitWait.WithLabelValues(l.graphName, nodeName).Observe(wait.Seconds())
+}
+
+// OnCheckpointSave implements graph.CheckpointCallbackHandler.
+func (l *Listener) OnCheckpointSave(_ context.Context, store string, duration time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	l.checkpointSave.WithLabelValues(l.graphName, store, status).Observe(duration.Seconds())
+}
@@ -0,0 +1,164 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+func TestListenerNodeMetrics(t *testing.T) {
+	reg := prom.NewRegistry()
+	listener, err := NewListener(reg, WithGraphName("test"))
+	require.NoError(t, err)
+
+	g := graph.NewListenableStateGraph[map[string]any]()
+	g.AddNode("ok", "ok", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"ok": true}, nil
+	})
+	g.AddNode("fail", "fail", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("boom")
+	})
+	g.SetEntryPoint("ok")
+	g.AddEdge("ok", "fail")
+	g.AddEdge("fail", graph.END)
+	g.AddGlobalListener(listener)
+
+	runnable, err := g.CompileListenable()
+	require.NoError(t, err)
+
+	config := &graph.Config{Callbacks: []graph.CallbackHandler{listener}}
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.nodeExecutions.WithLabelValues("test", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.nodeExecutions.WithLabelValues("test", "fail")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(listener.nodeErrors.WithLabelValues("test", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.nodeErrors.WithLabelValues("test", "fail")))
+	assert.Equal(t, 2, testutil.CollectAndCount(listener.nodeDuration))
+	assert.Equal(t, 0.0, testutil.ToFloat64(listener.activeRuns.WithLabelValues("test")))
+}
+
+func TestListenerActiveRuns(t *testing.T) {
+	listener, err := NewListener(prom.NewRegistry())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	listener.OnChainStart(ctx, nil, nil, "run-1", nil, nil, nil)
+	listener.OnChainStart(ctx, nil, nil, "run-2", nil, nil, nil)
+	assert.Equal(t, 2.0, testutil.ToFloat64(listener.activeRuns.WithLabelValues("graph")))
+
+	listener.OnChainError(ctx, errors.New("boom"), "run-1")
+	listener.OnChainEnd(ctx, nil, "run-1")
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.activeRuns.WithLabelValues("graph")))
+}
+
+func TestListenerActiveRuns_RoutingError(t *testing.T) {
+	listener, err := NewListener(prom.NewRegistry())
+	require.NoError(t, err)
+
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("route", "route", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.AddNode("done", "done", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("route")
+	g.AddConditionalEdge("route", func(ctx context.Context, state map[string]any) string {
+		return "unknown"
+	}, map[string]string{"done": "done"})
+	g.AddEdge("done", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	// Runs failing outside of a node end their chain too
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, &graph.Config{Callbacks: []graph.CallbackHandler{listener}})
+	require.ErrorContains(t, err, "not in its path map")
+	assert.Equal(t, 0.0, testutil.ToFloat64(listener.activeRuns.WithLabelValues("graph")))
+	assert.Empty(t, listener.runs)
+}
+
+func TestListenerResilienceMetrics(t *testing.T) {
+	listener, err := NewListener(prom.NewRegistry())
+	require.NoError(t, err)
+
+	attempts := 0
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("flaky", "flaky", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("transient")
+		}
+		return map[string]any{}, nil
+	}, graph.WithRetryPolicy(&graph.RetryPolicy{
+		MaxRetries:   1,
+		InitialDelay: time.Millisecond,
+		Retryable:    func(err error) bool { return true },
+	}))
+	g.AddNodeWithCircuitBreaker("breaker", "breaker", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("unavailable")
+	}, graph.CircuitBreakerConfig{FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Minute, HalfOpenMaxCalls: 1})
+	g.SetEntryPoint("flaky")
+	g.AddEdge("flaky", "breaker")
+	g.AddEdge("breaker", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	config := &graph.Config{Callbacks: []graph.CallbackHandler{listener}}
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.retries.WithLabelValues("graph", "flaky")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.circuitTransitions.WithLabelValues("graph", "breaker", "closed", "open")))
+	assert.Equal(t, float64(graph.CircuitOpen), testutil.ToFloat64(listener.circuitState.WithLabelValues("graph", "breaker")))
+
+	listener.OnRateLimited(context.Background(), "limited", time.Second)
+	assert.Equal(t, 1.0, testutil.ToFloat64(listener.rateLimited.WithLabelValues("graph", "limited")))
+	assert.Equal(t, 1, testutil.CollectAndCount(listener.rateLimitWait))
+}
+
+func TestListenerCheckpointMetrics(t *testing.T) {
+	listener, err := NewListener(prom.NewRegistry())
+	require.NoError(t, err)
+
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("step", "step", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"done": true}, nil
+	})
+	g.SetEntryPoint("step")
+	g.AddEdge("step", graph.END)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	config := graph.WithThreadID("metrics")
+	config.Callbacks = []graph.CallbackHandler{listener}
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(listener.checkpointSave, "langgraph_checkpoint_save_duration_seconds"))
+}
+
+func TestListenersShareRegistry(t *testing.T) {
+	reg := prom.NewRegistry()
+	first, err := NewListener(reg, WithGraphName("first"))
+	require.NoError(t, err)
+	second, err := NewListener(reg, WithGraphName("second"))
+	require.NoError(t, err)
+
+	first.OnNodeEvent(context.Background(), graph.NodeEventComplete, "node", nil, nil)
+	NodeListener[int](second).OnNodeEvent(context.Background(), graph.NodeEventComplete, "node", 0, nil)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "langgraph_node_executions_total"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(first.nodeExecutions.WithLabelValues("second", "node")))
+}
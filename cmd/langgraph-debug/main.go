@@ -0,0 +1,65 @@
+// Command langgraph-debug runs a demo graph under the interactive step debugger.
+//
+// The graph counts up to a limit, which shows stepping, breakpoints and state edits:
+//
+//	go run ./cmd/langgraph-debug -input '{"count": 0, "limit": 3}'
+//
+// Type help at the prompt for the list of commands. To debug your own graph, build a command of
+// the same shape: compile the graph and pass the runnable to debugger.Main.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/smallnest/langgraphgo/debugger"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+func main() {
+	runnable, err := demoGraph()
+	if err != nil {
+		log.Fatal(err)
+	}
+	debugger.Main(runnable)
+}
+
+// demoGraph builds a graph incrementing "count" until it reaches "limit", then reporting it.
+func demoGraph() (*graph.StateRunnable[map[string]any], error) {
+	g := graph.NewStateGraph[map[string]any]()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("log", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("increment", "increment the counter", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		count := number(state["count"]) + 1
+		return map[string]any{"count": count, "log": []any{fmt.Sprintf("count=%v", count)}}, nil
+	})
+	g.AddNode("report", "report the final count", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"report": fmt.Sprintf("counted to %v", state["count"])}, nil
+	})
+
+	g.SetEntryPoint("increment")
+	g.AddConditionalEdge("increment", func(ctx context.Context, state map[string]any) string {
+		if number(state["count"]) < number(state["limit"]) {
+			return "increment"
+		}
+		return "report"
+	})
+	g.AddEdge("report", graph.END)
+
+	return g.Compile()
+}
+
+// number converts a JSON or Go number to a float64.
+func number(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
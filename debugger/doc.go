@@ -0,0 +1,34 @@
+// Package debugger provides a terminal front-end for graph.DebugSession.
+//
+// A debug session runs a graph and pauses it before each superstep, where its pending nodes and
+// state can be inspected and edited. The Terminal drives a session with line commands:
+//
+//	runnable, _ := g.Compile()
+//	session := runnable.Debug(ctx, initialState, nil)
+//
+//	term := debugger.NewTerminal(session, os.Stdin, os.Stdout)
+//	final, err := term.Run(ctx)
+//
+// A typical session sets breakpoints, continues to them and edits the state before stepping on:
+//
+//	paused before step 0: increment
+//	(debug) break if count=2
+//	breakpoint 1: if count=2
+//	(debug) continue
+//	breakpoint 1 (if count=2) hit
+//	paused before step 2: increment
+//	(debug) set count 10
+//	(debug) step
+//	paused before step 3: report
+//
+// Main turns a compiled graph into a debugging command, reading the initial state from the
+// -input flag as JSON; cmd/langgraph-debug runs a demo graph with it:
+//
+//	func main() {
+//		runnable, _ := g.Compile()
+//		debugger.Main(runnable)
+//	}
+//
+// Conditions and edits use the JSON form of the state, so states must encode as JSON objects.
+// The same API can be used directly to drive sessions from tests or other front-ends.
+package debugger
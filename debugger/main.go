@@ -0,0 +1,64 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// Run starts a debug session of runnable from initialState and drives it with a Terminal reading
+// commands from in and printing to out. It returns the outcome of the run, which is
+// graph.ErrDebugStopped when it was stopped before finishing.
+func Run[S any](ctx context.Context, runnable *graph.StateRunnable[S], initialState S, config *graph.Config, in io.Reader, out io.Writer) (S, error) {
+	session := runnable.Debug(ctx, initialState, config)
+	return NewTerminal(session, in, out).Run(ctx)
+}
+
+// Main is the main function of a debugging command for runnable. It reads the initial state of
+// the run as JSON from the -input flag, runs it under a Terminal on the standard input and
+// output, and exits with status 1 if the run fails:
+//
+//	func main() {
+//		runnable, err := buildGraph().Compile()
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		debugger.Main(runnable)
+//	}
+//
+// Without -input, the run starts from the zero value of S.
+func Main[S any](runnable *graph.StateRunnable[S]) {
+	input := flag.String("input", "", "initial state of the run, as JSON")
+	flag.Parse()
+
+	if err := runMain(runnable, *input, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runMain runs runnable from the JSON input under a Terminal until it finishes, is stopped or
+// is interrupted.
+func runMain[S any](runnable *graph.StateRunnable[S], input string, in io.Reader, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var state S
+	if input != "" {
+		if err := json.Unmarshal([]byte(input), &state); err != nil {
+			return fmt.Errorf("invalid input: %w", err)
+		}
+	}
+
+	if _, err := Run(ctx, runnable, state, nil, in, out); err != nil && !errors.Is(err, graph.ErrDebugStopped) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package debugger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+const help = `Commands:
+  step, s                      run the paused superstep and pause before the next one
+  continue, c                  run until a breakpoint or the end of the run
+  state, p [key]               print the state, or one key of it
+  nodes, n                     print the nodes of the paused superstep
+  history                      print the supersteps of the run
+  break, b <node>              pause before the supersteps running node
+  break, b [node] if key=json  pause when the state holds a value, e.g. "b if count=3"
+  delete, d <id>               delete a breakpoint
+  breakpoints, bl              list the breakpoints
+  set <key> <json>             set a key of the state, e.g. "set count 10"
+  goto <node>...               replace the nodes of the paused superstep
+  replay, r <step>             rewind the run to a superstep of its history
+  quit, q                      stop the run and exit
+  help, h                      print this help`
+
+// Terminal drives a graph.DebugSession with line commands.
+type Terminal[S any] struct {
+	session *graph.DebugSession[S]
+	in      *bufio.Scanner
+	out     io.Writer
+}
+
+// NewTerminal creates a terminal reading commands from in and printing to out.
+func NewTerminal[S any](session *graph.DebugSession[S], in io.Reader, out io.Writer) *Terminal[S] {
+	return &Terminal[S]{
+		session: session,
+		in:      bufio.NewScanner(in),
+		out:     out,
+	}
+}
+
+// Run reads commands until the input ends or the quit command, then stops the run if it is not
+// finished and returns its outcome.
+func (t *Terminal[S]) Run(ctx context.Context) (S, error) {
+	t.wait(ctx)
+
+	for {
+		fmt.Fprint(t.out, "(debug) ")
+		if !t.in.Scan() {
+			fmt.Fprintln(t.out)
+			break
+		}
+		fields := strings.Fields(t.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "q" {
+			break
+		}
+		if err := t.execute(ctx, fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(t.out, "error: %v\n", err)
+		}
+	}
+
+	if !t.session.Done() {
+		if err := t.session.Stop(); err != nil {
+			var zero S
+			return zero, err
+		}
+		if _, err := t.session.Wait(ctx); err != nil {
+			var zero S
+			return zero, err
+		}
+	}
+	return t.session.Result()
+}
+
+// execute runs a command.
+func (t *Terminal[S]) execute(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "help", "h":
+		fmt.Fprintln(t.out, help)
+		return nil
+	case "step", "s":
+		return t.resume(ctx, t.session.Step)
+	case "continue", "c":
+		return t.resume(ctx, t.session.Continue)
+	case "state", "p":
+		return t.printState(args)
+	case "nodes", "n":
+		pause := t.session.Paused()
+		if pause == nil {
+			return graph.ErrNotPaused
+		}
+		fmt.Fprintf(t.out, "step %d: %s\n", pause.Step, strings.Join(pause.Nodes, ", "))
+		return nil
+	case "history":
+		for _, step := range t.session.History() {
+			fmt.Fprintf(t.out, "step %d: %s\n", step.Step, strings.Join(step.Nodes, ", "))
+		}
+		return nil
+	case "break", "b":
+		return t.addBreakpoint(args)
+	case "delete", "d":
+		if len(args) != 1 {
+			return errors.New("usage: delete <id>")
+		}
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid breakpoint ID %q", args[0])
+		}
+		if !t.session.RemoveBreakpoint(id) {
+			return fmt.Errorf("no breakpoint %d", id)
+		}
+		return nil
+	case "breakpoints", "bl":
+		for _, bp := range t.session.Breakpoints() {
+			fmt.Fprintf(t.out, "%d: %s\n", bp.ID, bp.Description)
+		}
+		return nil
+	case "set":
+		return t.setKey(args)
+	case "goto":
+		if len(args) == 0 {
+			return errors.New("usage: goto <node>...")
+		}
+		if err := t.session.SetNodes(args); err != nil {
+			return err
+		}
+		return t.execute(ctx, "nodes", nil)
+	case "replay", "r":
+		if len(args) != 1 {
+			return errors.New("usage: replay <step>")
+		}
+		step, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid step %q", args[0])
+		}
+		return t.resume(ctx, func() error { return t.session.Replay(step) })
+	default:
+		return fmt.Errorf("unknown command %q, type help for the list of commands", command)
+	}
+}
+
+// resume runs a command resuming the run and waits for it to pause or finish.
+func (t *Terminal[S]) resume(ctx context.Context, command func() error) error {
+	if err := command(); err != nil {
+		if errors.Is(err, graph.ErrNotPaused) && t.session.Done() {
+			return errors.New("the run is finished, replay a step to run it again")
+		}
+		return err
+	}
+	t.wait(ctx)
+	return nil
+}
+
+// wait waits for the run to pause or finish and prints where it stopped.
+func (t *Terminal[S]) wait(ctx context.Context) {
+	pause, err := t.session.Wait(ctx)
+	if err != nil {
+		fmt.Fprintf(t.out, "error: %v\n", err)
+		return
+	}
+	if pause == nil {
+		state, err := t.session.Result()
+		if err != nil {
+			fmt.Fprintf(t.out, "run failed: %v\n", err)
+			return
+		}
+		fmt.Fprintf(t.out, "run finished: %s\n", encode(state))
+		return
+	}
+
+	if pause.Breakpoint != nil {
+		fmt.Fprintf(t.out, "breakpoint %d (%s) hit\n", pause.Breakpoint.ID, pause.Breakpoint.Description)
+	}
+	fmt.Fprintf(t.out, "paused before step %d: %s\n", pause.Step, strings.Join(pause.Nodes, ", "))
+}
+
+// printState prints the state of the paused superstep, or one of its keys.
+func (t *Terminal[S]) printState(args []string) error {
+	pause := t.session.Paused()
+	if pause == nil {
+		return graph.ErrNotPaused
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(t.out, encodeIndent(pause.State))
+		return nil
+	}
+
+	values, err := toMap(pause.State)
+	if err != nil {
+		return err
+	}
+	value, ok := values[args[0]]
+	if !ok {
+		return fmt.Errorf("no key %q in the state", args[0])
+	}
+	fmt.Fprintln(t.out, encodeIndent(value))
+	return nil
+}
+
+// addBreakpoint parses the arguments of the break command: a node, a condition or both.
+func (t *Terminal[S]) addBreakpoint(args []string) error {
+	var node, condition string
+	switch {
+	case len(args) == 1 && args[0] != "if":
+		node = args[0]
+	case len(args) == 2 && args[0] == "if":
+		condition = args[1]
+	case len(args) == 3 && args[1] == "if":
+		node, condition = args[0], args[2]
+	default:
+		return errors.New("usage: break <node> | break [node] if key=json")
+	}
+
+	var when func(S) bool
+	description := "node " + node
+	if condition != "" {
+		key, raw, ok := strings.Cut(condition, "=")
+		if !ok {
+			return fmt.Errorf("invalid condition %q, expected key=json", condition)
+		}
+		var want any
+		if err := json.Unmarshal([]byte(raw), &want); err != nil {
+			// Bare words are compared as strings
+			want = raw
+		}
+		when = func(state S) bool {
+			values, err := toMap(state)
+			return err == nil && reflect.DeepEqual(values[key], want)
+		}
+
+		description = "if " + condition
+		if node != "" {
+			description = "node " + node + " " + description
+		}
+	}
+
+	id := t.session.AddBreakpoint(node, when, description)
+	fmt.Fprintf(t.out, "breakpoint %d: %s\n", id, description)
+	return nil
+}
+
+// setKey sets a key of the state of the paused superstep to a JSON value.
+func (t *Terminal[S]) setKey(args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: set <key> <json>")
+	}
+	pause := t.session.Paused()
+	if pause == nil {
+		return graph.ErrNotPaused
+	}
+
+	value := decodeValue(strings.Join(args[1:], " "))
+
+	state, err := withKey(pause.State, args[0], value)
+	if err != nil {
+		return err
+	}
+	return t.session.SetState(state)
+}
+
+// withKey returns a copy of a state with a key set. Map states keep the types of their other
+// values; other states are converted through their JSON form.
+func withKey[S any](state S, key string, value any) (S, error) {
+	if m, ok := any(state).(map[string]any); ok {
+		m = maps.Clone(m)
+		if m == nil {
+			m = make(map[string]any)
+		}
+		m[key] = value
+		return any(m).(S), nil
+	}
+
+	values, err := toMap(state)
+	if err != nil {
+		return state, err
+	}
+	if values == nil {
+		values = make(map[string]any)
+	}
+	values[key] = value
+	return fromMap[S](values)
+}
+
+// decodeValue decodes a JSON value typed in a command. Integers are decoded as int, like the
+// values Go nodes usually store; text that is not JSON is kept as a string.
+func decodeValue(raw string) any {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return raw
+	}
+	return convertNumbers(value)
+}
+
+func convertNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = convertNumbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = convertNumbers(v[k])
+		}
+	}
+	return value
+}
+
+// toMap converts a state to its JSON object form.
+func toMap(state any) (map[string]any, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode state: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("state is not a JSON object: %w", err)
+	}
+	return values, nil
+}
+
+// fromMap converts the JSON object form of a state back to the state type.
+func fromMap[S any](values map[string]any) (S, error) {
+	var state S
+	data, err := json.Marshal(values)
+	if err != nil {
+		return state, fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to decode state as %T: %w", state, err)
+	}
+	return state, nil
+}
+
+func encode(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func encodeIndent(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package debugger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+func counterGraph(t *testing.T) *graph.StateRunnable[map[string]any] {
+	t.Helper()
+	g := graph.NewStateGraph[map[string]any]()
+	g.SetSchema(graph.NewMapSchema())
+	g.AddNode("inc", "inc", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"count": state["count"].(int) + 1}, nil
+	})
+	g.AddNode("done", "done", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"done": true}, nil
+	})
+	g.SetEntryPoint("inc")
+	g.AddConditionalEdge("inc", func(ctx context.Context, state map[string]any) string {
+		if state["count"].(int) < 5 {
+			return "inc"
+		}
+		return "done"
+	})
+	g.AddEdge("done", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func runTerminal(t *testing.T, commands ...string) (map[string]any, error, string) {
+	t.Helper()
+	ctx := context.Background()
+	session := counterGraph(t).Debug(ctx, map[string]any{"count": 0}, nil)
+
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(commands, "\n") + "\n")
+	final, err := NewTerminal(session, in, &out).Run(ctx)
+	return final, err, out.String()
+}
+
+func TestTerminal(t *testing.T) {
+	final, err, out := runTerminal(t,
+		"nodes",
+		"break if count=2",
+		"break done",
+		"breakpoints",
+		"continue",
+		"state count",
+		"set count 4",
+		"step",
+		"delete 2",
+		"c",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 5, final["count"])
+	assert.Equal(t, true, final["done"])
+
+	assert.Contains(t, out, "paused before step 0: inc\n")
+	assert.Contains(t, out, "step 0: inc\n")
+	assert.Contains(t, out, "1: if count=2\n2: node done\n")
+	assert.Contains(t, out, "breakpoint 1 (if count=2) hit\npaused before step 2: inc\n")
+	assert.Contains(t, out, "breakpoint 2 (node done) hit\npaused before step 3: done\n")
+	assert.Contains(t, out, `run finished: {"count":5,"done":true}`)
+}
+
+func TestTerminal_Replay(t *testing.T) {
+	final, err, out := runTerminal(t,
+		"c",
+		"history",
+		"replay 4",
+		"p",
+		"set count 0",
+		"goto done",
+		"c",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 0, final["count"])
+	assert.Contains(t, out, "step 5: done\n")
+	assert.Contains(t, out, "paused before step 4: inc\n")
+	assert.Contains(t, out, `"count": 4`)
+	assert.Contains(t, out, `run finished: {"count":0,"done":true}`)
+}
+
+func TestTerminal_Errors(t *testing.T) {
+	_, err, out := runTerminal(t,
+		"jump",
+		"delete 7",
+		"break",
+		"set count",
+		"goto missing",
+		"replay 9",
+		"quit",
+	)
+	assert.ErrorIs(t, err, graph.ErrDebugStopped)
+	assert.Contains(t, out, `unknown command "jump"`)
+	assert.Contains(t, out, "no breakpoint 7")
+	assert.Contains(t, out, "usage: break")
+	assert.Contains(t, out, "usage: set")
+	assert.Contains(t, out, "node not found: missing")
+	assert.Contains(t, out, "no superstep 9")
+}
+
+type countState struct {
+	Count int `json:"count"`
+}
+
+func TestRunMain(t *testing.T) {
+	g := graph.NewStateGraph[countState]()
+	g.AddNode("inc", "inc", func(ctx context.Context, state countState) (countState, error) {
+		state.Count++
+		return state, nil
+	})
+	g.SetEntryPoint("inc")
+	g.AddEdge("inc", graph.END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, runMain(runnable, `{"count": 41}`, strings.NewReader("p\nc\n"), &out))
+	assert.Contains(t, out.String(), `"count": 41`)
+	assert.Contains(t, out.String(), `run finished: {"count":42}`)
+
+	// Quitting before the run finished is not an error
+	require.NoError(t, runMain(runnable, "", strings.NewReader("quit\n"), &out))
+
+	err = runMain(runnable, `{"count":`, strings.NewReader(""), &out)
+	assert.ErrorContains(t, err, "invalid input")
+}
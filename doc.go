@@ -175,6 +175,13 @@
 //
 //	g.WithCheckpointing(graph.CheckpointConfig{Store: store})
 //
+// store/retention/
+// Retention policies and a background sweeper deleting old checkpoints of any store
+//
+// store/migrate/
+// Copy checkpoints between stores, and export and import them as verified archives (see also
+// cmd/langgraph-checkpoints)
+//
 // # Adapter Packages
 //
 // adapter/
@@ -201,6 +208,29 @@
 //		Language: ptc.LanguagePython,
 //	})
 //
+// runqueue/
+// Background execution of checkpointable graphs on a worker pool, with durable run records
+//
+//	queue := runqueue.New(runnable, runStore, runqueue.WithWorkers(8))
+//	_ = queue.Start(ctx)
+//	run, _ := queue.Submit(ctx, runqueue.Request[map[string]any]{ThreadID: "thread-1", Input: input})
+//
+// debugger/
+// Terminal front-end for the step debugger of graphs, and Main to build debugging commands
+// (see cmd/langgraph-debug)
+//
+//	session := runnable.Debug(ctx, initialState, nil)
+//	session.AddBreakpoint("agent", nil, "before agent")
+//	_, _ = debugger.NewTerminal(session, os.Stdin, os.Stdout).Run(ctx)
+//
+// graphspec/
+// Declarative YAML/JSON graph definitions built from registered node implementations
+//
+//	registry := graphspec.NewRegistry()
+//	registry.RegisterNode("reply", newReply)
+//	spec, _ := graphspec.LoadFile("graph.yaml")
+//	g, _ := registry.Build(spec)
+//
 // log/
 // Simple logging utilities
 //
@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/file"
+	"github.com/smallnest/langgraphgo/store/memory"
+)
+
+// NodeCache is an alias for store.NodeCache
+type NodeCache = store.NodeCache
+
+// NewMemoryNodeCache creates a new in-memory node cache
+func NewMemoryNodeCache() NodeCache {
+	return memory.NewMemoryNodeCache()
+}
+
+// NewFileNodeCache creates a new file-based node cache
+func NewFileNodeCache(path string) (NodeCache, error) {
+	return file.NewFileNodeCache(path)
+}
+
+// CachePolicy caches the results of a node, keyed by its input state.
+// On a cache hit the node is not executed and the cached result is merged instead.
+//
+// Caches implementing store.ValueCache, such as the memory cache, keep copies of the results
+// with their Go types. Other caches store them with encoding/gob, which keeps the Go types of the values of map states,
+// or as JSON decoded into the state type when gob cannot encode them, e.g. for struct states
+// not registered with gob.Register. Results that are a *Command are not cached.
+// Cache failures never fail the node: it simply runs without the cache.
+type CachePolicy struct {
+	// Key derives the cache key from the node input. The node name is always part of the key.
+	// When nil, the JSON encoding of the whole input is used.
+	Key func(state any) (string, error)
+
+	// TTL is how long a result stays cached. Zero means no expiration.
+	TTL time.Duration
+
+	// Cache stores the results. When nil, the graph's cache is used (see SetNodeCache).
+	Cache NodeCache
+}
+
+// cacheKey returns the key of the node result for state.
+func (p *CachePolicy) cacheKey(nodeName string, state any) (string, error) {
+	var raw []byte
+	if p.Key != nil {
+		key, err := p.Key(state)
+		if err != nil {
+			return "", err
+		}
+		raw = []byte(key)
+	} else {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return "", err
+		}
+		raw = data
+	}
+	sum := sha256.Sum256(raw)
+	return nodeName + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// executeNodeCached runs the node through its cache policy, if any.
+// It reports whether the result came from the cache.
+func (r *StateRunnable[S]) executeNodeCached(ctx context.Context, node TypedNode[S], state S, config *Config, runID string) (S, bool, error) {
+	policy := node.Options.CachePolicy
+	cache := r.graph.nodeCache
+	if policy != nil && policy.Cache != nil {
+		cache = policy.Cache
+	}
+	if policy == nil || cache == nil {
+		res, err := r.executeNodeWithRetry(ctx, node, state, config, runID)
+		return res, false, err
+	}
+
+	key, err := policy.cacheKey(node.Name, state)
+	if err != nil {
+		res, err := r.executeNodeWithRetry(ctx, node, state, config, runID)
+		return res, false, err
+	}
+
+	if cached, ok := getCachedResult[S](ctx, cache, key); ok {
+		return cached, true, nil
+	}
+
+	res, err := r.executeNodeWithRetry(ctx, node, state, config, runID)
+	if err != nil {
+		return res, false, err
+	}
+
+	if _, isCommand := any(res).(*Command); !isCommand {
+		_ = setCachedResult(ctx, cache, key, res, policy.TTL)
+	}
+
+	return res, false, nil
+}
+
+// getCachedResult returns the node result cached under key, if there is one of type S.
+func getCachedResult[S any](ctx context.Context, cache NodeCache, key string) (S, bool) {
+	var zero S
+	if vc, ok := cache.(store.ValueCache); ok {
+		value, found, err := vc.GetValue(ctx, key)
+		if err != nil || !found {
+			return zero, false
+		}
+		res, ok := value.(S)
+		return res, ok
+	}
+
+	data, found, err := cache.Get(ctx, key)
+	if err != nil || !found {
+		return zero, false
+	}
+	if format, _, ok := store.ParseFormatHeader(data); ok && format == store.FormatGob {
+		value, err := store.GobSerializer{}.Unmarshal(data)
+		if err != nil {
+			return zero, false
+		}
+		res, ok := value.(S)
+		return res, ok
+	}
+	var res S
+	if err := json.Unmarshal(data, &res); err != nil {
+		return zero, false
+	}
+	return res, true
+}
+
+// setCachedResult caches a node result under key.
+func setCachedResult(ctx context.Context, cache NodeCache, key string, res any, ttl time.Duration) error {
+	if vc, ok := cache.(store.ValueCache); ok {
+		return vc.SetValue(ctx, key, res, ttl)
+	}
+
+	data, err := store.GobSerializer{}.Marshal(res)
+	if err != nil {
+		if data, err = json.Marshal(res); err != nil {
+			return err
+		}
+	}
+	return cache.Set(ctx, key, data, ttl)
+}
@@ -36,6 +36,41 @@ type GraphCallbackHandler interface {
 	OnGraphStep(ctx context.Context, stepNode string, state any)
 }
 
+// RetryCallbackHandler can be implemented by callback handlers to observe node retries.
+type RetryCallbackHandler interface {
+	// OnNodeRetry is called when attempt of nodeName failed with err and will be retried after delay
+	OnNodeRetry(ctx context.Context, nodeName string, attempt int, err error, delay time.Duration, runID string)
+}
+
+// CircuitBreakerCallbackHandler can be implemented by callback handlers to observe circuit breakers.
+type CircuitBreakerCallbackHandler interface {
+	// OnCircuitBreakerStateChange is called when the circuit breaker of nodeName moves from one state to another
+	OnCircuitBreakerStateChange(ctx context.Context, nodeName string, from, to CircuitBreakerState)
+}
+
+// RateLimitCallbackHandler can be implemented by callback handlers to observe rate-limited nodes.
+type RateLimitCallbackHandler interface {
+	// OnRateLimited is called when a call of nodeName is rejected by its rate limiter,
+	// wait is the time until the limiter accepts calls again
+	OnRateLimited(ctx context.Context, nodeName string, wait time.Duration)
+}
+
+// CheckpointCallbackHandler can be implemented by callback handlers to observe checkpoint saves.
+type CheckpointCallbackHandler interface {
+	// OnCheckpointSave is called after a checkpoint was saved to store (e.g. "memory", "sqlite"),
+	// with the time the save took and its error, if any
+	OnCheckpointSave(ctx context.Context, store string, duration time.Duration, err error)
+}
+
+// StepFailureHandler can be implemented by callback handlers to record partially failed supersteps.
+type StepFailureHandler interface {
+	// OnStepFailure is called when some tasks of a superstep failed with a regular error, or
+	// did not finish before the invocation deadline. nodes are the nodes scheduled for the
+	// superstep, state is the state before it ran, and writes hold the outputs of the tasks
+	// that succeeded; they are empty on timeouts, whose partial supersteps are discarded.
+	OnStepFailure(ctx context.Context, nodes []string, state any, writes []PendingWrite)
+}
+
 // Config represents configuration for graph invocation
 // This matches Python's config dict pattern
 type Config struct {
@@ -68,6 +103,10 @@ type Config struct {
 
 	// ResumeValue provides the value to return from an Interrupt() call when resuming
 	ResumeValue any `json:"resume_value"`
+
+	// RecursionLimit is the maximum number of supersteps the graph may execute.
+	// Zero means DefaultRecursionLimit. Nested graphs share the budget of the outermost invocation.
+	RecursionLimit int `json:"recursion_limit"`
 }
 
 // NoOpCallbackHandler provides a no-op implementation of CallbackHandler
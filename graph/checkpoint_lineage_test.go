@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLineageGraph(t *testing.T) *CheckpointableRunnable[map[string]any] {
+	t.Helper()
+
+	g := NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("draft", "draft", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"draft": "v1"}, nil
+	})
+	g.AddNode("review", "review", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"review": state["tone"]}, nil
+	})
+	g.SetEntryPoint("draft")
+	g.AddEdge("draft", "review")
+	g.AddEdge("review", END)
+	g.SetSchema(NewMapSchema())
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestCheckpointParentPointers(t *testing.T) {
+	runnable := newLineageGraph(t)
+	ctx := context.Background()
+
+	_, err := runnable.InvokeWithConfig(ctx, map[string]any{"tone": "formal"}, WithThreadID("lineage"))
+	require.NoError(t, err)
+
+	history, err := runnable.ListHistory(ctx, "lineage")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	root := history[0]
+	assert.Equal(t, "draft", root.Checkpoint.NodeName)
+	assert.Empty(t, root.Checkpoint.ParentID)
+	require.Len(t, root.Children, 1)
+	assert.Equal(t, "review", root.Children[0].Checkpoint.NodeName)
+	assert.Equal(t, root.Checkpoint.ID, root.Children[0].Checkpoint.ParentID)
+
+	snapshot, err := runnable.GetState(ctx, WithThreadID("lineage"))
+	require.NoError(t, err)
+	assert.Equal(t, root.Checkpoint.ID, snapshot.ParentID)
+}
+
+func TestResumeFromCheckpointForksThread(t *testing.T) {
+	runnable := newLineageGraph(t)
+	ctx := context.Background()
+
+	_, err := runnable.InvokeWithConfig(ctx, map[string]any{"tone": "formal"}, WithThreadID("fork"))
+	require.NoError(t, err)
+
+	history, err := runnable.ListHistory(ctx, "fork")
+	require.NoError(t, err)
+	draftCP := history[0].Checkpoint
+	originalReview := history[0].Children[0].Checkpoint
+
+	// Time travel: resume from the draft checkpoint with a different tone
+	res, err := runnable.InvokeWithConfig(ctx, map[string]any{"tone": "casual"}, WithCheckpointID("fork", draftCP.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "casual", res["review"])
+
+	// The original branch is kept and the fork descends from the draft checkpoint
+	loaded, err := runnable.LoadCheckpoint(ctx, originalReview.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "formal", loaded.State.(map[string]any)["review"])
+
+	history, err = runnable.ListHistory(ctx, "fork")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.True(t, history[0].IsFork())
+	require.Len(t, history[0].Children, 2)
+	assert.Equal(t, originalReview.ID, history[0].Children[0].Checkpoint.ID)
+
+	var forkLeaf *CheckpointNode
+	history[0].Children[1].Walk(func(node *CheckpointNode, depth int) {
+		forkLeaf = node
+	})
+	assert.Equal(t, "casual", forkLeaf.Checkpoint.State.(map[string]any)["review"])
+
+	// The latest checkpoint of the thread is now the tip of the fork
+	snapshot, err := runnable.GetState(ctx, WithThreadID("fork"))
+	require.NoError(t, err)
+	assert.Equal(t, forkLeaf.Checkpoint.ID, snapshot.Config.Configurable["checkpoint_id"])
+}
+
+func TestResumeFromUnknownCheckpoint(t *testing.T) {
+	runnable := newLineageGraph(t)
+
+	_, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, WithCheckpointID("missing", "no-such-checkpoint"))
+	assert.ErrorContains(t, err, "failed to load checkpoint no-such-checkpoint")
+}
+
+func TestUpdateStateRecordsParent(t *testing.T) {
+	runnable := newLineageGraph(t)
+	ctx := context.Background()
+
+	_, err := runnable.InvokeWithConfig(ctx, map[string]any{"tone": "formal"}, WithThreadID("edit"))
+	require.NoError(t, err)
+
+	history, err := runnable.ListHistory(ctx, "edit")
+	require.NoError(t, err)
+	draftCP := history[0].Checkpoint
+
+	updated, err := runnable.UpdateState(ctx, WithCheckpointID("edit", draftCP.ID), "draft", map[string]any{"draft": "v2"})
+	require.NoError(t, err)
+
+	cp, err := runnable.LoadCheckpoint(ctx, updated.Configurable["checkpoint_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, draftCP.ID, cp.ParentID)
+	assert.Equal(t, "v2", cp.State.(map[string]any)["draft"])
+}
@@ -3,6 +3,9 @@ package graph
 import (
 	"context"
 	"fmt"
+	"maps"
+	"path"
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +20,9 @@ type Checkpoint = store.Checkpoint
 // CheckpointStore is an alias for store.CheckpointStore
 type CheckpointStore = store.CheckpointStore
 
+// CheckpointNode is an alias for store.CheckpointNode
+type CheckpointNode = store.CheckpointNode
+
 // NewMemoryCheckpointStore creates a new in-memory checkpoint store
 func NewMemoryCheckpointStore() store.CheckpointStore {
 	return memory.NewMemoryCheckpointStore()
@@ -59,17 +65,51 @@ type CheckpointListener[S any] struct {
 	threadID       string
 	autoSave       bool
 	maxCheckpoints int
+	// parentID is the ID of the last checkpoint of the run, the parent of the next one
+	parentID string
 }
 
 // OnGraphStep is called after a step in the graph has completed and the state has been merged.
+// The nodes scheduled next are recorded under the "next" metadata key, for Resume.
 func (cl *CheckpointListener[S]) OnGraphStep(ctx context.Context, nodeName string, state any) {
 	if cl.autoSave {
 		if s, ok := state.(S); ok {
-			cl.saveCheckpoint(ctx, nodeName, s)
+			var extra map[string]any
+			if next, ok := stepNext(ctx); ok {
+				extra = map[string]any{"next": next}
+			}
+			cl.saveCheckpoint(ctx, nodeName, s, extra)
 		}
 	}
 }
 
+// OnStepFailure saves a checkpoint of the state a failed superstep started from and records
+// the outputs of its successful tasks as pending writes, when the store supports them.
+// The nodes of the superstep are recorded under the "next_nodes" metadata key, which resuming
+// and GetState read, and the checkpoint is named after the first of them. Resuming the thread
+// then replays the writes and reruns only the failed tasks.
+func (cl *CheckpointListener[S]) OnStepFailure(ctx context.Context, nodes []string, state any, writes []PendingWrite) {
+	if !cl.autoSave || len(nodes) == 0 {
+		return
+	}
+	s, ok := state.(S)
+	if !ok {
+		return
+	}
+
+	checkpoint := cl.saveCheckpoint(ctx, nodes[0], s, map[string]any{
+		"event":      "step_failed",
+		"next_nodes": nodes,
+	})
+	if checkpoint == nil || len(writes) == 0 {
+		return
+	}
+
+	if pws, ok := cl.store.(store.PendingWriteStore); ok {
+		_ = pws.PutWrites(ctx, checkpoint.ID, writes)
+	}
+}
+
 // Implement other methods of CallbackHandler as no-ops
 func (cl *CheckpointListener[S]) OnChainStart(context.Context, map[string]any, map[string]any, string, *string, []string, map[string]any) {
 }
@@ -88,7 +128,9 @@ func (cl *CheckpointListener[S]) OnRetrieverStart(context.Context, map[string]an
 func (cl *CheckpointListener[S]) OnRetrieverEnd(context.Context, []any, string)   {}
 func (cl *CheckpointListener[S]) OnRetrieverError(context.Context, error, string) {}
 
-func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName string, state S) {
+// saveCheckpoint saves a checkpoint of state, adding extra to its metadata.
+// It returns nil if the checkpoint could not be saved.
+func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName string, state S, extra map[string]any) *store.Checkpoint {
 	// Get current version from existing checkpoints
 	checkpoints, err := cl.store.List(ctx, cl.executionID)
 	version := 1
@@ -97,6 +139,12 @@ func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName st
 		latest := checkpoints[len(checkpoints)-1]
 		version = latest.Version + 1
 	}
+	// Threads outlive executions: a thread resumed by another runnable keeps counting up
+	if cl.threadID != "" {
+		if latest, err := cl.store.GetLatestByThread(ctx, cl.threadID); err == nil && latest != nil && latest.Version >= version {
+			version = latest.Version + 1
+		}
+	}
 
 	metadata := map[string]any{
 		"execution_id": cl.executionID,
@@ -105,6 +153,7 @@ func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName st
 	if cl.threadID != "" {
 		metadata["thread_id"] = cl.threadID
 	}
+	maps.Copy(metadata, extra)
 
 	checkpoint := &store.Checkpoint{
 		ID:        generateCheckpointID(),
@@ -113,15 +162,48 @@ func (cl *CheckpointListener[S]) saveCheckpoint(ctx context.Context, nodeName st
 		Timestamp: time.Now(),
 		Version:   version,
 		Metadata:  metadata,
+		ParentID:  cl.parentID,
 	}
 
 	// Save checkpoint synchronously
-	_ = cl.store.Save(ctx, checkpoint)
+	if err := saveToStore(ctx, cl.store, checkpoint); err != nil {
+		return nil
+	}
+	cl.parentID = checkpoint.ID
 
 	// Cleanup old checkpoints if MaxCheckpoints is set
 	if cl.maxCheckpoints > 0 {
 		cl.cleanupOldCheckpoints(ctx)
 	}
+	return checkpoint
+}
+
+// saveToStore saves checkpoint to s and reports the save to the CheckpointCallbackHandlers
+// of the config in ctx.
+func saveToStore(ctx context.Context, s store.CheckpointStore, checkpoint *store.Checkpoint) error {
+	start := time.Now()
+	err := s.Save(ctx, checkpoint)
+	if config := GetConfig(ctx); config != nil {
+		duration := time.Since(start)
+		for _, handler := range config.Callbacks {
+			if ch, ok := handler.(CheckpointCallbackHandler); ok {
+				ch.OnCheckpointSave(ctx, storeName(s), duration, err)
+			}
+		}
+	}
+	return err
+}
+
+// storeName returns the name of the package implementing s, e.g. "memory" or "sqlite".
+func storeName(s store.CheckpointStore) string {
+	t := reflect.TypeOf(s)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return path.Base(t.PkgPath())
 }
 
 // cleanupOldCheckpoints removes oldest checkpoints exceeding the max limit
@@ -232,58 +314,201 @@ func (cr *CheckpointableRunnable[S]) Invoke(ctx context.Context, initialState S)
 
 // InvokeWithConfig executes the graph with checkpointing support and config
 func (cr *CheckpointableRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S, config *Config) (S, error) {
-	// Extract thread_id from config if present
-	var threadID string
+	return cr.invokeWithConfig(ctx, initialState, config, false)
+}
+
+// Resume continues the thread of config from its latest checkpoint (or from its checkpoint_id)
+// without new input: the state of the checkpoint is used as is, and the run continues with the
+// nodes scheduled after the checkpoint. It picks up runs that were cut off, e.g. by a restart of
+// the process executing them. A thread whose run completed returns the state of its checkpoint.
+func (cr *CheckpointableRunnable[S]) Resume(ctx context.Context, config *Config) (S, error) {
+	var zero S
+	resumeConfig := Config{}
+	if config != nil {
+		resumeConfig = *config
+	}
+	resumeConfig.ResumeFrom = nil
+	return cr.invokeWithConfig(ctx, zero, &resumeConfig, true)
+}
+
+// invokeWithConfig executes the graph. When resume is true, the run continues from the state of
+// the checkpoint of the thread and fails if there is none.
+func (cr *CheckpointableRunnable[S]) invokeWithConfig(ctx context.Context, initialState S, config *Config, resume bool) (S, error) {
+	// Extract thread_id and checkpoint_id from config if present
+	var threadID, checkpointID string
 	if config != nil && config.Configurable != nil {
 		if tid, ok := config.Configurable["thread_id"].(string); ok {
 			threadID = tid
 		}
+		if cid, ok := config.Configurable["checkpoint_id"].(string); ok {
+			checkpointID = cid
+		}
 	}
 
+	var replay *pendingWritesReplay
+
+	// New checkpoints of this run descend from the checkpoint it resumes from
+	var parentID string
+
 	// Auto-resume: if thread_id is provided, try to load the latest checkpoint
-	// and merge its state with the provided initialState (which may be just new input)
-	if threadID != "" {
-		// Only auto-resume if ResumeFrom is not explicitly set (manual control takes precedence)
-		if config == nil || config.ResumeFrom == nil {
+	// and merge its state with the provided initialState (which may be just new input).
+	// If checkpoint_id is provided, resume from that checkpoint instead; when it is not the
+	// latest checkpoint of the thread, the run forks the thread rather than overwriting it.
+	// Only auto-resume if ResumeFrom is not explicitly set (manual control takes precedence)
+	if config == nil || config.ResumeFrom == nil {
+		var base *store.Checkpoint
+		if checkpointID != "" {
+			cp, err := cr.config.Store.Load(ctx, checkpointID)
+			if err != nil {
+				var zero S
+				return zero, fmt.Errorf("failed to load checkpoint %s: %w", checkpointID, err)
+			}
+			base = cp
+			if tid, ok := cp.Metadata["thread_id"].(string); ok && threadID == "" {
+				threadID = tid
+			}
+		} else if threadID != "" {
 			if latestCP, err := cr.getLatestCheckpoint(ctx, threadID); err == nil && latestCP != nil {
-				// Found existing checkpoint - this is a resume
-				checkpointState, ok := latestCP.State.(S)
-				if ok {
-					// Merge checkpoint state with new input using Schema
+				base = latestCP
+			}
+		}
+
+		if resume && base == nil {
+			var zero S
+			return zero, fmt.Errorf("no checkpoint to resume thread %q from", threadID)
+		}
+
+		if base != nil {
+			parentID = base.ID
+
+			// Found existing checkpoint - this is a resume
+			checkpointState, err := storedValue[S](base.State)
+			if err != nil && resume {
+				var zero S
+				return zero, fmt.Errorf("checkpoint %s holds a %T state, not %T: %w", base.ID, base.State, zero, err)
+			}
+			if err == nil {
+				// Merge checkpoint state with new input using Schema
+				if resume {
+					initialState = checkpointState
+				} else {
 					initialState = cr.mergeStates(ctx, checkpointState, initialState)
+				}
 
-					// Check if the checkpoint is at END (completed execution)
-					// Note: NodeName is empty when checkpoint is created at END or via other means
-					if latestCP.NodeName == "" || latestCP.NodeName == END {
-						// Graph has completed - just return the merged state
-						// No need to re-execute anything
-						return initialState, nil
-					}
+				// Check if the checkpoint is at END (completed execution)
+				// Note: NodeName is empty when checkpoint is created at END or via other means
+				if base.NodeName == "" || base.NodeName == END {
+					// Graph has completed - just return the merged state
+					// No need to re-execute anything
+					return initialState, nil
+				}
 
-					// For incomplete checkpoints (interrupted), set ResumeFrom to continue
-					// The graph will continue execution from the checkpoint node
-					if config == nil {
-						config = &Config{}
+				// For incomplete checkpoints (interrupted), set ResumeFrom to continue
+				// The graph will continue execution from the checkpoint node
+				if config == nil {
+					config = &Config{}
+				}
+				config.ResumeFrom = resumeNodes(base)
+
+				// Resumed runs continue with the nodes scheduled after the checkpoint
+				if resume {
+					if next, ok := scheduledNodes(base); ok {
+						if len(next) == 0 {
+							return checkpointState, nil
+						}
+						config.ResumeFrom = next
 					}
-					config.ResumeFrom = []string{latestCP.NodeName}
+				}
+
+				// Replay the outputs of tasks that succeeded before a failure
+				replay = cr.loadPendingWrites(ctx, base.ID)
+				if replay != nil {
+					ctx = withPendingWrites(ctx, replay)
 				}
 			}
 		}
+	} else if threadID != "" {
+		if latestCP, err := cr.getLatestCheckpoint(ctx, threadID); err == nil && latestCP != nil {
+			parentID = latestCP.ID
+		}
 	}
 
-	// Update checkpoint listener with thread_id
-	if cr.listener != nil {
-		cr.listener.threadID = threadID
-		cr.listener.autoSave = cr.config.AutoSave
-	}
+	// Each run saves its checkpoints through its own copy of the checkpoint listener,
+	// so that runs of different threads can execute concurrently
+	listener := *cr.listener
+	listener.threadID = threadID
+	listener.autoSave = cr.config.AutoSave
+	listener.parentID = parentID
 
 	// Add the listener to config callbacks
 	if config == nil {
 		config = &Config{}
 	}
-	config.Callbacks = append(config.Callbacks, cr.listener)
+	config.Callbacks = append(config.Callbacks, &listener)
+
+	result, err := cr.runnable.InvokeWithConfig(ctx, initialState, config)
+
+	// Replayed writes are either applied or recorded again on a newer checkpoint
+	if replay != nil && replay.used {
+		if pws, ok := cr.config.Store.(store.PendingWriteStore); ok {
+			_ = pws.DeleteWrites(context.WithoutCancel(ctx), replay.checkpointID)
+		}
+	}
 
-	return cr.runnable.InvokeWithConfig(ctx, initialState, config)
+	return result, err
+}
+
+// loadPendingWrites returns the pending writes recorded on top of a checkpoint, or nil if there are none.
+func (cr *CheckpointableRunnable[S]) loadPendingWrites(ctx context.Context, checkpointID string) *pendingWritesReplay {
+	pws, ok := cr.config.Store.(store.PendingWriteStore)
+	if !ok {
+		return nil
+	}
+	writes, err := pws.GetWrites(ctx, checkpointID)
+	if err != nil || len(writes) == 0 {
+		return nil
+	}
+	return &pendingWritesReplay{checkpointID: checkpointID, writes: writes}
+}
+
+// scheduledNodes returns the nodes scheduled after the superstep of a checkpoint, as recorded
+// in its "next" metadata. An empty list means the run completed.
+func scheduledNodes(cp *store.Checkpoint) ([]string, bool) {
+	switch next := cp.Metadata["next"].(type) {
+	case []string:
+		return next, true
+	case []any:
+		nodes := make([]string, 0, len(next))
+		for _, n := range next {
+			if name, ok := n.(string); ok {
+				nodes = append(nodes, name)
+			}
+		}
+		return nodes, true
+	}
+	return nil, false
+}
+
+// resumeNodes returns the nodes to run when resuming from a checkpoint.
+// Checkpoints of failed supersteps record the scheduled nodes in their metadata.
+func resumeNodes(cp *store.Checkpoint) []string {
+	switch next := cp.Metadata["next_nodes"].(type) {
+	case []string:
+		if len(next) > 0 {
+			return next
+		}
+	case []any:
+		nodes := make([]string, 0, len(next))
+		for _, n := range next {
+			if name, ok := n.(string); ok {
+				nodes = append(nodes, name)
+			}
+		}
+		if len(nodes) > 0 {
+			return nodes
+		}
+	}
+	return []string{cp.NodeName}
 }
 
 // Stream executes the graph with checkpointing and streaming support
@@ -291,6 +516,81 @@ func (cr *CheckpointableRunnable[S]) Stream(ctx context.Context, initialState S)
 	return cr.runnable.Stream(ctx, initialState)
 }
 
+// StreamWithConfig executes the graph with checkpointing support and config, streaming the events
+// of this run only: node events of the graph and its subgraphs, tokens of models, custom payloads
+// and an EventStep event with the full state after each superstep. Unlike Stream, it does not
+// register graph listeners, so concurrent runs of the runnable can be streamed separately.
+func (cr *CheckpointableRunnable[S]) StreamWithConfig(ctx context.Context, initialState S, config *Config, streamConfig StreamConfig) *StreamResult[S] {
+	if streamConfig.BufferSize <= 0 {
+		streamConfig.BufferSize = DefaultStreamConfig().BufferSize
+	}
+	eventChan := make(chan StreamEvent[S], streamConfig.BufferSize)
+	resultChan := make(chan S, 1)
+	errorChan := make(chan error, 1)
+	doneChan := make(chan struct{})
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	streamingListener := NewStreamingListener(eventChan, streamConfig)
+	streamCtx = WithMessageHandler(streamCtx, streamingListener.OnMessageChunk)
+	streamCtx = withRunStream(withStreamSink(streamCtx, streamingListener))
+
+	// Report the state after each superstep, before the checkpoint listener saves it
+	if config == nil {
+		config = &Config{}
+	}
+	config.Callbacks = append(config.Callbacks, &stepStreamer[S]{listener: streamingListener})
+
+	go func() {
+		defer func() {
+			streamingListener.Close()
+			close(eventChan)
+			close(resultChan)
+			close(errorChan)
+			close(doneChan)
+		}()
+
+		streamingListener.emitEvent(StreamEvent[S]{
+			Timestamp: time.Now(),
+			Event:     EventChainStart,
+			State:     initialState,
+		})
+
+		result, err := cr.InvokeWithConfig(streamCtx, initialState, config)
+
+		streamingListener.emitEvent(StreamEvent[S]{
+			Timestamp: time.Now(),
+			Event:     EventChainEnd,
+			State:     result,
+			Error:     err,
+		})
+
+		if err != nil {
+			errorChan <- err
+		} else {
+			resultChan <- result
+		}
+	}()
+
+	return &StreamResult[S]{
+		Events: eventChan,
+		Result: resultChan,
+		Errors: errorChan,
+		Done:   doneChan,
+		Cancel: cancel,
+	}
+}
+
+// stepStreamer emits the state after each superstep of a streamed run as an EventStep event.
+type stepStreamer[S any] struct {
+	NoOpCallbackHandler
+	listener *StreamingListener[S]
+}
+
+// OnGraphStep implements GraphCallbackHandler.
+func (s *stepStreamer[S]) OnGraphStep(ctx context.Context, stepNode string, state any) {
+	s.listener.emitNamespaced(ctx, "", stepNode, EventStep, state, nil, nil)
+}
+
 // StateSnapshot represents a snapshot of the graph state
 type StateSnapshot struct {
 	Values    any
@@ -415,7 +715,7 @@ func (cr *CheckpointableRunnable[S]) GetState(ctx context.Context, config *Confi
 	}
 
 	// Return state snapshot
-	next := []string{checkpoint.NodeName}
+	next := resumeNodes(checkpoint)
 	if checkpoint.NodeName == "" {
 		next = []string{}
 	}
@@ -430,6 +730,7 @@ func (cr *CheckpointableRunnable[S]) GetState(ctx context.Context, config *Confi
 		},
 		Metadata:  checkpoint.Metadata,
 		CreatedAt: checkpoint.Timestamp,
+		ParentID:  checkpoint.ParentID,
 	}, nil
 }
 
@@ -459,7 +760,7 @@ func (cr *CheckpointableRunnable[S]) SaveCheckpoint(ctx context.Context, nodeNam
 		},
 	}
 
-	return cr.config.Store.Save(ctx, checkpoint)
+	return saveToStore(ctx, cr.config.Store, checkpoint)
 }
 
 // ListCheckpoints lists all checkpoints for the current execution
@@ -467,6 +768,30 @@ func (cr *CheckpointableRunnable[S]) ListCheckpoints(ctx context.Context) ([]*st
 	return cr.config.Store.List(ctx, cr.executionID)
 }
 
+// ListHistory returns the checkpoint tree of a thread, following the parent of each checkpoint.
+// Forks made by resuming from older checkpoints appear as nodes with several children.
+// If threadID is empty, the checkpoints of the current execution are returned.
+func (cr *CheckpointableRunnable[S]) ListHistory(ctx context.Context, threadID string) ([]*CheckpointNode, error) {
+	if threadID == "" {
+		checkpoints, err := cr.config.Store.List(ctx, cr.executionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+		}
+		return store.BuildHistory(checkpoints), nil
+	}
+	return store.ListHistory(ctx, cr.config.Store, threadID)
+}
+
+// GetExecutionTrace builds the execution trace of a thread from its checkpoint history,
+// to render what its runs executed.
+func (cr *CheckpointableRunnable[S]) GetExecutionTrace(ctx context.Context, threadID string) (*ExecutionTrace, error) {
+	checkpoints, err := cr.config.Store.ListByThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	return NewExecutionTraceFromCheckpoints(checkpoints), nil
+}
+
 // LoadCheckpoint loads a specific checkpoint
 func (cr *CheckpointableRunnable[S]) LoadCheckpoint(ctx context.Context, checkpointID string) (*store.Checkpoint, error) {
 	return cr.config.Store.Load(ctx, checkpointID)
@@ -491,8 +816,10 @@ func (cr *CheckpointableRunnable[S]) UpdateState(ctx context.Context, config *Co
 		threadID = cr.executionID
 	}
 
-	// Get current state from config if available
+	// Get current state from config if available.
+	// The new checkpoint descends from the checkpoint it updates.
 	var currentState S
+	var parentID string
 
 	if config != nil {
 		snapshot, err := cr.GetState(ctx, config)
@@ -500,6 +827,7 @@ func (cr *CheckpointableRunnable[S]) UpdateState(ctx context.Context, config *Co
 			if s, ok := snapshot.Values.(S); ok {
 				currentState = s
 			}
+			parentID, _ = snapshot.Config.Configurable["checkpoint_id"].(string)
 		}
 	}
 
@@ -539,12 +867,14 @@ func (cr *CheckpointableRunnable[S]) UpdateState(ctx context.Context, config *Co
 		Version:   version,
 		Metadata: map[string]any{
 			"execution_id": threadID,
+			"thread_id":    threadID,
 			"source":       "update_state",
 			"updated_by":   asNode,
 		},
+		ParentID: parentID,
 	}
 
-	if err := cr.config.Store.Save(ctx, checkpoint); err != nil {
+	if err := saveToStore(ctx, cr.config.Store, checkpoint); err != nil {
 		return nil, err
 	}
 
@@ -590,6 +920,11 @@ func (cr *CheckpointableRunnable[S]) WithTracer(tracer *Tracer) *CheckpointableR
 	}
 }
 
+// GetCheckpointStore returns the store the checkpoints are saved to
+func (cr *CheckpointableRunnable[S]) GetCheckpointStore() store.CheckpointStore {
+	return cr.config.Store
+}
+
 // GetGraph returns the underlying graph
 func (cr *CheckpointableRunnable[S]) GetGraph() *ListenableStateGraph[S] {
 	return cr.runnable.GetListenableGraph()
@@ -618,6 +953,22 @@ func WithThreadID(threadID string) *Config {
 	}
 }
 
+// WithCheckpointID creates a Config that resumes a thread from a specific checkpoint.
+// Resuming from an older checkpoint forks the thread; the checkpoints after it are kept.
+//
+// Example:
+//
+//	history, _ := runnable.ListHistory(ctx, "conversation-1")
+//	result, err := runnable.InvokeWithConfig(ctx, state, graph.WithCheckpointID("conversation-1", checkpointID))
+func WithCheckpointID(threadID, checkpointID string) *Config {
+	return &Config{
+		Configurable: map[string]any{
+			"thread_id":     threadID,
+			"checkpoint_id": checkpointID,
+		},
+	}
+}
+
 // WithInterruptBefore creates a Config with interrupt points set before specified nodes.
 //
 // Example:
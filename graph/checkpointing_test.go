@@ -1378,3 +1378,225 @@ func TestMaxCheckpoints_AutoCleanup_RedisStore(t *testing.T) {
 		t.Errorf("Expected latest checkpoint by thread to be step5")
 	}
 }
+
+func TestCheckpointSaveCallback(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("step1", "step1", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"step1": "done"}, nil
+	})
+	g.AddNode("step2", "step2", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"step2": "done"}, nil
+	})
+	g.AddEdge("step1", "step2")
+	g.AddEdge("step2", graph.END)
+	g.SetEntryPoint("step1")
+
+	runnable, err := g.CompileCheckpointable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	recorder := &resilienceRecorder{}
+	config := graph.WithThreadID("checkpoint-save-callback")
+	config.Callbacks = []graph.CallbackHandler{recorder}
+	config.InterruptAfter = []string{"step1"}
+
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+	if _, ok := err.(*graph.GraphInterrupt); !ok {
+		t.Fatalf("Expected GraphInterrupt, got %v", err)
+	}
+
+	if !slices.Equal(recorder.saves, []string{"memory"}) {
+		t.Errorf("Expected one save to the memory store, got %v", recorder.saves)
+	}
+	// Interrupted runs end their chain
+	if recorder.ended != 1 {
+		t.Errorf("Expected OnChainEnd to be called once, got %d", recorder.ended)
+	}
+}
+
+func TestCheckpointableRunnable_StreamWithConfig(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("step1", "step1", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"step1": "done"}, nil
+	})
+	g.AddNode("step2", "step2", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"step1": state["step1"], "step2": "done"}, nil
+	})
+	g.AddEdge("step1", "step2")
+	g.AddEdge("step2", graph.END)
+	g.SetEntryPoint("step1")
+
+	runnable, err := g.CompileCheckpointable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	// Runs of different threads stream their own events only
+	streams := []*graph.StreamResult[map[string]any]{
+		runnable.StreamWithConfig(context.Background(), map[string]any{}, graph.WithThreadID("stream-a"), graph.StreamConfig{Mode: graph.StreamModeDebug}),
+		runnable.StreamWithConfig(context.Background(), map[string]any{}, graph.WithThreadID("stream-b"), graph.StreamConfig{Mode: graph.StreamModeDebug}),
+	}
+	for _, stream := range streams {
+		var steps, completed int
+		for event := range stream.Events {
+			switch event.Event {
+			case graph.EventStep:
+				steps++
+			case graph.NodeEventComplete:
+				completed++
+			}
+		}
+		if steps != 2 || completed != 2 {
+			t.Errorf("Expected 2 steps and 2 completed nodes, got %d and %d", steps, completed)
+		}
+
+		result, ok := <-stream.Result
+		if !ok {
+			t.Fatalf("Stream failed: %v", <-stream.Errors)
+		}
+		if result["step2"] != "done" {
+			t.Errorf("Unexpected result %v", result)
+		}
+	}
+
+	checkpoints, err := runnable.GetCheckpointStore().ListByThread(context.Background(), "stream-a")
+	if err != nil {
+		t.Fatalf("Failed to list checkpoints: %v", err)
+	}
+	if len(checkpoints) != 2 {
+		t.Errorf("Expected 2 checkpoints for the streamed thread, got %d", len(checkpoints))
+	}
+}
+
+func TestCheckpointableRunnable_Resume(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	for _, name := range []string{"step1", "step2", "step3"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			calls = append(calls, name)
+			return map[string]any{"last": name}, nil
+		})
+	}
+	g.AddEdge("step1", "step2")
+	g.AddEdge("step2", "step3")
+	g.AddEdge("step3", graph.END)
+	g.SetEntryPoint("step1")
+
+	runnable, err := g.CompileCheckpointable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := runnable.Resume(ctx, graph.WithThreadID("resume")); err == nil {
+		t.Error("Expected an error when resuming a thread without checkpoints")
+	}
+
+	config := graph.WithThreadID("resume")
+	config.InterruptAfter = []string{"step1"}
+	if _, err := runnable.InvokeWithConfig(ctx, map[string]any{}, config); err == nil {
+		t.Fatal("Expected the run to be interrupted")
+	}
+
+	// The run continues with the node scheduled after the checkpoint
+	result, err := runnable.Resume(ctx, graph.WithThreadID("resume"))
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if result["last"] != "step3" {
+		t.Errorf("Expected the run to complete, got %v", result)
+	}
+	if !slices.Equal(calls, []string{"step1", "step2", "step3"}) {
+		t.Errorf("Expected each node to run once, got %v", calls)
+	}
+
+	// A completed thread has nothing left to run
+	result, err = runnable.Resume(ctx, graph.WithThreadID("resume"))
+	if err != nil || result["last"] != "step3" || len(calls) != 3 {
+		t.Errorf("Expected the completed state without running nodes, got %v, %v, %v", result, err, calls)
+	}
+}
+
+func TestDeltaCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	inner := graph.NewMemoryCheckpointStore()
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("messages", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	for i := 1; i <= 6; i++ {
+		nodeName := fmt.Sprintf("turn%d", i)
+		g.AddNode(nodeName, nodeName, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"messages": []any{nodeName}}, nil
+		})
+		if i > 1 {
+			g.AddEdge(fmt.Sprintf("turn%d", i-1), nodeName)
+		}
+	}
+	g.AddEdge("turn6", graph.END)
+	g.SetEntryPoint("turn1")
+	g.SetCheckpointConfig(graph.CheckpointConfig{
+		Store:    st.NewDeltaStore(inner, st.DeltaOptions{SnapshotInterval: 3}),
+		AutoSave: true,
+	})
+
+	runnable, err := g.CompileCheckpointable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	ctx := context.Background()
+	config := graph.WithThreadID("delta-thread")
+	if _, err := runnable.InvokeWithConfig(ctx, map[string]any{"messages": []any{"hello"}}, config); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	// States are rebuilt from the deltas
+	snapshot, err := runnable.GetState(ctx, config)
+	if err != nil {
+		t.Fatalf("Failed to get state: %v", err)
+	}
+	messages := snapshot.Values.(map[string]any)["messages"].([]any)
+	if len(messages) != 7 || messages[6] != "turn6" {
+		t.Errorf("Unexpected messages: %v", messages)
+	}
+
+	checkpoints, err := runnable.GetCheckpointStore().ListByThread(ctx, "delta-thread")
+	if err != nil {
+		t.Fatalf("Failed to list checkpoints: %v", err)
+	}
+	if len(checkpoints) != 6 {
+		t.Fatalf("Expected 6 checkpoints, got %d", len(checkpoints))
+	}
+	deltas := 0
+	for i, cp := range checkpoints {
+		loaded, err := runnable.LoadCheckpoint(ctx, cp.ID)
+		if err != nil {
+			t.Fatalf("Failed to load checkpoint: %v", err)
+		}
+		if n := len(loaded.State.(map[string]any)["messages"].([]any)); n != i+2 {
+			t.Errorf("Checkpoint %d: expected %d messages, got %d", i, i+2, n)
+		}
+
+		raw, err := inner.Load(ctx, cp.ID)
+		if err != nil {
+			t.Fatalf("Failed to load stored checkpoint: %v", err)
+		}
+		if _, ok := raw.State.(map[string]any)["$delta"]; ok {
+			deltas++
+		}
+	}
+	// A snapshot every 3 checkpoints
+	if deltas != 4 {
+		t.Errorf("Expected 4 checkpoints stored as deltas, got %d", deltas)
+	}
+}
@@ -9,6 +9,38 @@ type Command struct {
 
 	// Goto specifies the next node(s) to execute.
 	// If set, it overrides the graph's edges.
-	// Can be a single string (node name) or []string.
+	// Can be a single string (node name), []string, Send, *Send or []Send.
 	Goto any
 }
+
+// Send schedules a single invocation of a node with its own input.
+// Unlike a plain node name, which runs the target with the full graph state,
+// each Send becomes a separate parallel task that receives Arg as its state.
+// The outputs of all tasks are merged back through the graph's Schema.
+//
+// Send values can be returned from a send edge (see AddSendEdge) or set as
+// Command.Goto, which makes map-reduce over runtime-sized inputs possible:
+//
+//	g.AddSendEdge("split", func(ctx context.Context, state map[string]any) []graph.Send {
+//	    var sends []graph.Send
+//	    for _, doc := range state["docs"].([]string) {
+//	        sends = append(sends, graph.NewSend("summarize", map[string]any{"doc": doc}))
+//	    }
+//	    return sends
+//	})
+type Send struct {
+	// Node is the name of the node to execute.
+	Node string
+
+	// Arg is the input passed to the node. It must be assignable to the
+	// graph's state type.
+	Arg any
+}
+
+// NewSend creates a Send that runs node with arg as its input.
+func NewSend(node string, arg any) Send {
+	return Send{
+		Node: node,
+		Arg:  arg,
+	}
+}
@@ -0,0 +1,125 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordNode(name string) func(ctx context.Context, state map[string]any) (map[string]any, error) {
+	return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{name: true}, nil
+	}
+}
+
+func TestConditionalEdgesMultipleTargets(t *testing.T) {
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("router", "router", recordNode("router"))
+	g.AddNode("search", "search", recordNode("search"))
+	g.AddNode("lookup", "lookup", recordNode("lookup"))
+	g.AddNode("skip", "skip", recordNode("skip"))
+	g.SetEntryPoint("router")
+	g.AddConditionalEdges("router", func(ctx context.Context, state map[string]any) []string {
+		return []string{"search", "lookup"}
+	})
+	g.AddEdge("search", graph.END)
+	g.AddEdge("lookup", graph.END)
+	g.AddEdge("skip", graph.END)
+	g.SetSchema(graph.NewMapSchema())
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, true, res["search"])
+	assert.Equal(t, true, res["lookup"])
+	assert.Nil(t, res["skip"])
+}
+
+func TestConditionalEdgePathMap(t *testing.T) {
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("check", "check", recordNode("check"))
+	g.AddNode("alert", "alert", recordNode("alert"))
+	g.SetEntryPoint("check")
+	g.AddConditionalEdge("check", func(ctx context.Context, state map[string]any) string {
+		if state["high"] == true {
+			return "high"
+		}
+		return "low"
+	}, map[string]string{"high": "alert", "low": graph.END})
+	g.AddEdge("alert", graph.END)
+	g.SetSchema(graph.NewMapSchema())
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]any{"high": true})
+	require.NoError(t, err)
+	assert.Equal(t, true, res["alert"])
+
+	res, err = runnable.Invoke(context.Background(), map[string]any{"high": false})
+	require.NoError(t, err)
+	assert.Nil(t, res["alert"])
+}
+
+func TestConditionalEdgePathMapUnknownLabel(t *testing.T) {
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("check", "check", recordNode("check"))
+	g.SetEntryPoint("check")
+	g.AddConditionalEdge("check", func(ctx context.Context, state map[string]any) string {
+		return "other"
+	}, map[string]string{"done": graph.END})
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `returned "other", which is not in its path map`)
+}
+
+func TestCompileValidatesPathMapTargets(t *testing.T) {
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("check", "check", recordNode("check"))
+	g.SetEntryPoint("check")
+	g.AddConditionalEdges("check", func(ctx context.Context, state map[string]any) []string {
+		return []string{"a"}
+	}, map[string]string{"a": "missing"})
+
+	_, err := g.Compile()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, graph.ErrNodeNotFound))
+	assert.Contains(t, err.Error(), "missing")
+}
+
+func TestConditionalEdgePathMapVisualization(t *testing.T) {
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNode("check", "check", recordNode("check"))
+	g.AddNode("alert", "alert", recordNode("alert"))
+	g.SetEntryPoint("check")
+	g.AddConditionalEdge("check", func(ctx context.Context, state map[string]any) string {
+		return "low"
+	}, map[string]string{"high": "alert", "low": graph.END})
+	g.AddEdge("alert", graph.END)
+
+	exporter := graph.NewExporter(g)
+
+	mermaid := exporter.DrawMermaid()
+	assert.Contains(t, mermaid, "check -.->|high| alert")
+	assert.Contains(t, mermaid, "check -.->|low| END")
+	assert.NotContains(t, mermaid, "check_condition")
+
+	dot := exporter.DrawDOT()
+	assert.Contains(t, dot, `check -> alert [style=dashed, label="high"];`)
+	assert.Contains(t, dot, `check -> END [style=dashed, label="low"];`)
+
+	ascii := exporter.DrawASCII()
+	assert.Contains(t, ascii, "alert")
+	assert.Contains(t, ascii, "END")
+	assert.NotContains(t, ascii, "(?)")
+}
@@ -1,6 +1,9 @@
 package graph
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+)
 
 type resumeValueKey struct{}
 
@@ -14,3 +17,32 @@ func WithResumeValue(ctx context.Context, value any) context.Context {
 func GetResumeValue(ctx context.Context) any {
 	return ctx.Value(resumeValueKey{})
 }
+
+type recursionBudgetKey struct{}
+
+// recursionBudget counts supersteps across a graph invocation and all nested graphs.
+type recursionBudget struct {
+	limit int
+	steps atomic.Int64
+}
+
+// next records a new superstep and returns the number of steps taken so far.
+func (b *recursionBudget) next() int {
+	return int(b.steps.Add(1))
+}
+
+// withRecursionBudget returns a context carrying the recursion budget of the invocation.
+// If ctx already carries a budget (e.g. inside a subgraph), it is reused so nested graphs
+// count against the parent budget.
+func withRecursionBudget(ctx context.Context, config *Config) (context.Context, *recursionBudget) {
+	if budget, ok := ctx.Value(recursionBudgetKey{}).(*recursionBudget); ok {
+		return ctx, budget
+	}
+
+	limit := DefaultRecursionLimit
+	if config != nil && config.RecursionLimit > 0 {
+		limit = config.RecursionLimit
+	}
+	budget := &recursionBudget{limit: limit}
+	return context.WithValue(ctx, recursionBudgetKey{}, budget), budget
+}
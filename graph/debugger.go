@@ -0,0 +1,490 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrDebugStopped is returned by a run stopped from its debug session.
+	ErrDebugStopped = errors.New("debug session stopped")
+
+	// ErrNotPaused is returned by the debug session commands that need a paused run.
+	ErrNotPaused = errors.New("run is not paused")
+)
+
+// Breakpoint pauses a debugged run before a superstep.
+type Breakpoint[S any] struct {
+	ID int
+
+	// Node pauses the run before the supersteps running this node. Empty matches every superstep.
+	Node string
+
+	// Condition pauses the run when it returns true for the state before the superstep.
+	// Nil matches every state.
+	Condition func(state S) bool
+
+	// Description is shown by debugger front-ends
+	Description string
+}
+
+// matches reports whether the breakpoint pauses before a superstep of nodes on state.
+func (b *Breakpoint[S]) matches(nodes []string, state S) bool {
+	if b.Node != "" && !slices.Contains(nodes, b.Node) {
+		return false
+	}
+	return b.Condition == nil || b.Condition(state)
+}
+
+// DebugPause describes a debugged run paused before a superstep.
+type DebugPause[S any] struct {
+	// Step is the index of the superstep, starting at 0
+	Step int
+
+	// Nodes are the nodes the superstep runs, including the targets of Send tasks
+	Nodes []string
+
+	// State is the state the superstep starts from
+	State S
+
+	// Breakpoint is the breakpoint that paused the run, nil when stepping
+	Breakpoint *Breakpoint[S]
+}
+
+// debugSnapshot is the input of a superstep, recorded to replay it.
+type debugSnapshot[S any] struct {
+	step  int
+	nodes []string
+	sends []Send
+	state S
+}
+
+// debugCommand is a command sent to a paused run.
+type debugCommand[S any] struct {
+	apply func(p *debugFrame[S]) (resume bool, err error)
+	reply chan error
+}
+
+// debugFrame is the superstep a run is paused before.
+type debugFrame[S any] struct {
+	snapshot debugSnapshot[S]
+	pause    *DebugPause[S]
+}
+
+// newPause describes the paused superstep.
+func (f *debugFrame[S]) newPause(hit *Breakpoint[S]) *DebugPause[S] {
+	return &DebugPause[S]{
+		Step:       f.snapshot.step,
+		Nodes:      snapshotNodes(f.snapshot),
+		State:      f.snapshot.state,
+		Breakpoint: hit,
+	}
+}
+
+// DebugSession drives a run of a StateRunnable step by step. The run pauses before each
+// superstep while stepping, or at breakpoints after Continue. While it is paused, its state
+// can be inspected and edited, and it can be rewound to an earlier superstep with Replay.
+//
+// Unlike InterruptBefore, pausing does not end the run, so no checkpoint is needed to go on:
+// the run waits in its goroutine until a command resumes it. State edits merge like
+// UpdateState does. Subgraphs invoked by the nodes of the run are not paused.
+type DebugSession[S any] struct {
+	runnable *StateRunnable[S]
+	ctx      context.Context
+	config   *Config
+	commands chan debugCommand[S]
+	cancel   context.CancelFunc
+
+	mu          sync.Mutex
+	stepping    bool
+	breakpoints []*Breakpoint[S]
+	nextID      int
+	history     []debugSnapshot[S]
+	paused      *DebugPause[S]
+	running     bool
+	// replay is the snapshot the next superstep is replaced with
+	replay *debugSnapshot[S]
+	// changed is closed when the run pauses or finishes
+	changed chan struct{}
+	result  S
+	err     error
+}
+
+// Debug starts a run of the graph under a debug session. The run pauses before its first superstep.
+func (r *StateRunnable[S]) Debug(ctx context.Context, initialState S, config *Config) *DebugSession[S] {
+	ctx, cancel := context.WithCancel(ctx)
+	d := &DebugSession[S]{
+		runnable: r,
+		ctx:      ctx,
+		config:   config,
+		commands: make(chan debugCommand[S]),
+		cancel:   cancel,
+		stepping: true,
+		changed:  make(chan struct{}),
+	}
+	d.start(initialState)
+	return d
+}
+
+// start invokes the graph in the background. d.mu must not be held.
+func (d *DebugSession[S]) start(initialState S) {
+	d.mu.Lock()
+	d.running = true
+	d.mu.Unlock()
+
+	go func() {
+		result, err := d.runnable.InvokeWithConfig(withDebugSession(d.ctx, d), initialState, d.config)
+
+		d.mu.Lock()
+		d.result, d.err = result, err
+		d.running = false
+		d.notify()
+		d.mu.Unlock()
+	}()
+}
+
+// notify wakes up the callers of Wait. d.mu must be held.
+func (d *DebugSession[S]) notify() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// Wait waits until the run pauses or finishes. It returns the pause, or nil once the run
+// finished; Result then returns its outcome.
+func (d *DebugSession[S]) Wait(ctx context.Context) (*DebugPause[S], error) {
+	for {
+		d.mu.Lock()
+		paused, running, changed := d.paused, d.running, d.changed
+		d.mu.Unlock()
+
+		if paused != nil {
+			return paused, nil
+		}
+		if !running {
+			return nil, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Paused returns the current pause, or nil if the run is executing or finished.
+func (d *DebugSession[S]) Paused() *DebugPause[S] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.paused
+}
+
+// Done reports whether the run finished.
+func (d *DebugSession[S]) Done() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.running
+}
+
+// Result returns the final state and error of the run once it finished.
+func (d *DebugSession[S]) Result() (S, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		var zero S
+		return zero, errors.New("run is not finished")
+	}
+	return d.result, d.err
+}
+
+// History returns the supersteps the run started so far, oldest first.
+// Rewinding with Replay drops the supersteps after the replayed one.
+func (d *DebugSession[S]) History() []DebugPause[S] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	history := make([]DebugPause[S], len(d.history))
+	for i, snap := range d.history {
+		history[i] = DebugPause[S]{Step: snap.step, Nodes: snapshotNodes(snap), State: snap.state}
+	}
+	return history
+}
+
+// AddBreakpoint adds a breakpoint and returns its ID. node and condition may be empty or nil.
+func (d *DebugSession[S]) AddBreakpoint(node string, condition func(state S) bool, description string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nextID++
+	d.breakpoints = append(d.breakpoints, &Breakpoint[S]{
+		ID:          d.nextID,
+		Node:        node,
+		Condition:   condition,
+		Description: description,
+	})
+	return d.nextID
+}
+
+// RemoveBreakpoint removes a breakpoint. It returns false if there is no breakpoint with this ID.
+func (d *DebugSession[S]) RemoveBreakpoint(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, bp := range d.breakpoints {
+		if bp.ID == id {
+			d.breakpoints = slices.Delete(d.breakpoints, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// Breakpoints returns the breakpoints of the session.
+func (d *DebugSession[S]) Breakpoints() []Breakpoint[S] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	breakpoints := make([]Breakpoint[S], len(d.breakpoints))
+	for i, bp := range d.breakpoints {
+		breakpoints[i] = *bp
+	}
+	return breakpoints
+}
+
+// Step resumes the paused run until it pauses before the next superstep.
+func (d *DebugSession[S]) Step() error {
+	return d.resume(true)
+}
+
+// Continue resumes the paused run until it reaches a breakpoint or finishes.
+func (d *DebugSession[S]) Continue() error {
+	return d.resume(false)
+}
+
+func (d *DebugSession[S]) resume(stepping bool) error {
+	return d.send(func(f *debugFrame[S]) (bool, error) {
+		d.mu.Lock()
+		d.stepping = stepping
+		d.mu.Unlock()
+		return true, nil
+	})
+}
+
+// SetState replaces the state the paused superstep starts from.
+func (d *DebugSession[S]) SetState(state S) error {
+	return d.send(func(f *debugFrame[S]) (bool, error) {
+		f.snapshot.state = state
+		return false, nil
+	})
+}
+
+// UpdateState merges update into the state the paused superstep starts from, with the schema
+// or state merger of the graph like a node result. Without either, update replaces the state.
+func (d *DebugSession[S]) UpdateState(ctx context.Context, update S) error {
+	return d.send(func(f *debugFrame[S]) (bool, error) {
+		state, err := d.runnable.mergeState(ctx, f.snapshot.state, []S{update})
+		if err != nil {
+			return false, fmt.Errorf("failed to update state: %w", err)
+		}
+		f.snapshot.state = state
+		return false, nil
+	})
+}
+
+// SetNodes replaces the nodes the paused superstep runs. Pending Send tasks are dropped.
+func (d *DebugSession[S]) SetNodes(nodes []string) error {
+	for _, node := range nodes {
+		if _, ok := d.runnable.graph.nodes[node]; !ok {
+			return fmt.Errorf("%w: %s", ErrNodeNotFound, node)
+		}
+	}
+	return d.send(func(f *debugFrame[S]) (bool, error) {
+		f.snapshot.nodes = slices.Clone(nodes)
+		f.snapshot.sends = nil
+		return false, nil
+	})
+}
+
+// Replay rewinds the run to the superstep step of its history and pauses before it again.
+// The superstep runs from its recorded state; later supersteps are dropped from the history.
+// A finished run is started again.
+func (d *DebugSession[S]) Replay(step int) error {
+	d.mu.Lock()
+	var snap *debugSnapshot[S]
+	for i := range d.history {
+		if d.history[i].step == step {
+			s := d.history[i]
+			snap = &s
+		}
+	}
+	running := d.running
+	d.mu.Unlock()
+
+	if snap == nil {
+		return fmt.Errorf("no superstep %d in the history of the run", step)
+	}
+
+	if !running {
+		d.mu.Lock()
+		d.replay = snap
+		d.stepping = true
+		d.mu.Unlock()
+		d.start(snap.state)
+		return nil
+	}
+
+	return d.send(func(f *debugFrame[S]) (bool, error) {
+		d.mu.Lock()
+		d.replay = snap
+		d.stepping = true
+		d.mu.Unlock()
+		return true, nil
+	})
+}
+
+// Stop aborts the run, which returns ErrDebugStopped.
+func (d *DebugSession[S]) Stop() error {
+	err := d.send(func(f *debugFrame[S]) (bool, error) {
+		return true, ErrDebugStopped
+	})
+	if errors.Is(err, ErrNotPaused) {
+		// Stop a running superstep through its context
+		d.cancel()
+		return nil
+	}
+	return err
+}
+
+// send applies a command to the paused run.
+func (d *DebugSession[S]) send(apply func(f *debugFrame[S]) (bool, error)) error {
+	d.mu.Lock()
+	paused := d.paused != nil
+	d.mu.Unlock()
+	if !paused {
+		return ErrNotPaused
+	}
+
+	cmd := debugCommand[S]{apply: apply, reply: make(chan error, 1)}
+	select {
+	case d.commands <- cmd:
+		return <-cmd.reply
+	case <-d.ctx.Done():
+		return d.ctx.Err()
+	}
+}
+
+// beforeStep is called by the run before each superstep. It pauses the run when stepping or
+// at a breakpoint, and returns the superstep to run, which commands may have changed.
+func (d *DebugSession[S]) beforeStep(ctx context.Context, snap debugSnapshot[S]) (debugSnapshot[S], error) {
+	for {
+		d.mu.Lock()
+		if d.replay != nil {
+			snap = *d.replay
+			d.replay = nil
+		}
+		// Record the superstep, forgetting the supersteps a replay rewound
+		d.history = slices.DeleteFunc(d.history, func(h debugSnapshot[S]) bool { return h.step >= snap.step })
+		d.history = append(d.history, snap)
+
+		nodes := snapshotNodes(snap)
+		var hit *Breakpoint[S]
+		for _, bp := range d.breakpoints {
+			if bp.matches(nodes, snap.state) {
+				copied := *bp
+				hit = &copied
+				break
+			}
+		}
+		if !d.stepping && hit == nil {
+			d.mu.Unlock()
+			return snap, nil
+		}
+
+		frame := &debugFrame[S]{snapshot: snap}
+		frame.pause = frame.newPause(hit)
+		d.paused = frame.pause
+		d.notify()
+		d.mu.Unlock()
+
+		resume, err := d.pause(ctx, frame)
+		if err != nil {
+			return snap, err
+		}
+		snap = frame.snapshot
+
+		d.mu.Lock()
+		replaying := d.replay != nil
+		d.mu.Unlock()
+		if resume && !replaying {
+			return snap, nil
+		}
+	}
+}
+
+// pause applies the commands sent to the paused run until one resumes or stops it.
+func (d *DebugSession[S]) pause(ctx context.Context, frame *debugFrame[S]) (bool, error) {
+	for {
+		select {
+		case cmd := <-d.commands:
+			resume, err := cmd.apply(frame)
+			stopped := errors.Is(err, ErrDebugStopped)
+
+			d.mu.Lock()
+			// Edits are recorded for replays
+			d.history[len(d.history)-1] = frame.snapshot
+			if resume {
+				// Callers of Wait must not see this pause once the command returned
+				d.paused = nil
+			} else {
+				// Pauses are not modified once returned, edits replace them
+				frame.pause = frame.newPause(frame.pause.Breakpoint)
+				d.paused = frame.pause
+			}
+			d.mu.Unlock()
+
+			if stopped {
+				cmd.reply <- nil
+				return false, err
+			}
+			cmd.reply <- err
+			if resume {
+				return true, nil
+			}
+		case <-ctx.Done():
+			d.mu.Lock()
+			d.paused = nil
+			d.mu.Unlock()
+			return false, ctx.Err()
+		}
+	}
+}
+
+// snapshotNodes returns the nodes a superstep runs, including the targets of its Send tasks.
+func snapshotNodes[S any](snap debugSnapshot[S]) []string {
+	nodes := slices.Clone(snap.nodes)
+	for _, send := range snap.sends {
+		nodes = append(nodes, send.Node)
+	}
+	return nodes
+}
+
+// debugSessionKey is the context key of the debug session of a run.
+type debugSessionKey struct{}
+
+// withDebugSession returns a context running the graph under a debug session.
+func withDebugSession[S any](ctx context.Context, d *DebugSession[S]) context.Context {
+	return context.WithValue(ctx, debugSessionKey{}, d)
+}
+
+// takeDebugSession returns the debug session of the run and a context without it,
+// so that subgraphs invoked by the nodes of the run are not paused.
+func takeDebugSession[S any](ctx context.Context) (*DebugSession[S], context.Context) {
+	d, ok := ctx.Value(debugSessionKey{}).(*DebugSession[S])
+	if !ok || d == nil {
+		return nil, ctx
+	}
+	return d, context.WithValue(ctx, debugSessionKey{}, nil)
+}
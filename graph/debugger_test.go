@@ -0,0 +1,212 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counterGraph increments "count" until it reaches "limit", then runs "done".
+func counterGraph(t *testing.T) *StateRunnable[map[string]any] {
+	t.Helper()
+	g := NewStateGraph[map[string]any]()
+	schema := NewMapSchema()
+	schema.RegisterReducer("visited", AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("inc", "inc", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"count": state["count"].(int) + 1, "visited": []any{"inc"}}, nil
+	})
+	g.AddNode("done", "done", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"visited": []any{"done"}}, nil
+	})
+	g.SetEntryPoint("inc")
+	g.AddConditionalEdge("inc", func(ctx context.Context, state map[string]any) string {
+		if state["count"].(int) < state["limit"].(int) {
+			return "inc"
+		}
+		return "done"
+	})
+	g.AddEdge("done", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func waitPause(t *testing.T, session *DebugSession[map[string]any]) *DebugPause[map[string]any] {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pause, err := session.Wait(ctx)
+	require.NoError(t, err)
+	return pause
+}
+
+func TestDebugSession_Step(t *testing.T) {
+	session := counterGraph(t).Debug(context.Background(), map[string]any{"count": 0, "limit": 2}, nil)
+
+	pause := waitPause(t, session)
+	require.NotNil(t, pause)
+	assert.Equal(t, 0, pause.Step)
+	assert.Equal(t, []string{"inc"}, pause.Nodes)
+	assert.Equal(t, 0, pause.State["count"])
+	assert.Nil(t, pause.Breakpoint)
+
+	var steps [][]string
+	for pause != nil {
+		steps = append(steps, pause.Nodes)
+		require.NoError(t, session.Step())
+		pause = waitPause(t, session)
+	}
+	assert.Equal(t, [][]string{{"inc"}, {"inc"}, {"done"}}, steps)
+
+	final, err := session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"inc", "inc", "done"}, final["visited"])
+	assert.Len(t, session.History(), 3)
+
+	assert.ErrorIs(t, session.Step(), ErrNotPaused)
+}
+
+func TestDebugSession_Breakpoints(t *testing.T) {
+	session := counterGraph(t).Debug(context.Background(), map[string]any{"count": 0, "limit": 5}, nil)
+	waitPause(t, session)
+
+	session.AddBreakpoint("", func(state map[string]any) bool { return state["count"] == 3 }, "count is 3")
+	doneID := session.AddBreakpoint("done", nil, "done")
+	require.NoError(t, session.Continue())
+
+	pause := waitPause(t, session)
+	require.NotNil(t, pause.Breakpoint)
+	assert.Equal(t, "count is 3", pause.Breakpoint.Description)
+	assert.Equal(t, 3, pause.Step)
+	assert.Equal(t, 3, pause.State["count"])
+
+	require.NoError(t, session.Continue())
+	pause = waitPause(t, session)
+	require.NotNil(t, pause.Breakpoint)
+	assert.Equal(t, doneID, pause.Breakpoint.ID)
+	assert.Equal(t, []string{"done"}, pause.Nodes)
+
+	assert.True(t, session.RemoveBreakpoint(doneID))
+	assert.False(t, session.RemoveBreakpoint(doneID))
+	assert.Len(t, session.Breakpoints(), 1)
+
+	require.NoError(t, session.Continue())
+	assert.Nil(t, waitPause(t, session))
+	final, err := session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 5, final["count"])
+}
+
+func TestDebugSession_EditState(t *testing.T) {
+	session := counterGraph(t).Debug(context.Background(), map[string]any{"count": 0, "limit": 10}, nil)
+	session.AddBreakpoint("inc", func(state map[string]any) bool { return state["count"] == 1 }, "")
+	waitPause(t, session)
+	require.NoError(t, session.Continue())
+	waitPause(t, session)
+
+	// Jump close to the limit: the merge goes through the schema like a node result
+	require.NoError(t, session.UpdateState(context.Background(), map[string]any{"count": 9, "visited": []any{"edit"}}))
+	pause := session.Paused()
+	require.NotNil(t, pause)
+	assert.Equal(t, 9, pause.State["count"])
+	assert.Equal(t, []any{"inc", "edit"}, pause.State["visited"])
+
+	require.NoError(t, session.Continue())
+	assert.Nil(t, waitPause(t, session))
+	final, err := session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 10, final["count"])
+	assert.Equal(t, []any{"inc", "edit", "inc", "done"}, final["visited"])
+}
+
+func TestDebugSession_SetNodes(t *testing.T) {
+	session := counterGraph(t).Debug(context.Background(), map[string]any{"count": 0, "limit": 10}, nil)
+	waitPause(t, session)
+
+	assert.ErrorIs(t, session.SetNodes([]string{"missing"}), ErrNodeNotFound)
+	require.NoError(t, session.SetNodes([]string{"done"}))
+	assert.Equal(t, []string{"done"}, session.Paused().Nodes)
+
+	require.NoError(t, session.Continue())
+	assert.Nil(t, waitPause(t, session))
+	final, err := session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"done"}, final["visited"])
+}
+
+func TestDebugSession_Replay(t *testing.T) {
+	session := counterGraph(t).Debug(context.Background(), map[string]any{"count": 0, "limit": 3}, nil)
+	waitPause(t, session)
+	require.NoError(t, session.Step())
+	waitPause(t, session)
+	require.NoError(t, session.Step())
+	pause := waitPause(t, session)
+	assert.Equal(t, 2, pause.Step)
+
+	// Rewind a paused run
+	require.NoError(t, session.Replay(1))
+	pause = waitPause(t, session)
+	assert.Equal(t, 1, pause.Step)
+	assert.Equal(t, 1, pause.State["count"])
+	assert.Len(t, session.History(), 2)
+
+	require.NoError(t, session.Continue())
+	assert.Nil(t, waitPause(t, session))
+	final, err := session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"inc", "inc", "inc", "done"}, final["visited"])
+
+	// Rewind a finished run, with an edited state
+	require.NoError(t, session.Replay(3))
+	pause = waitPause(t, session)
+	assert.Equal(t, 3, pause.Step)
+	assert.Equal(t, []string{"done"}, pause.Nodes)
+	require.NoError(t, session.SetState(map[string]any{"count": 3, "limit": 3, "visited": []any{"replayed"}}))
+
+	require.NoError(t, session.Continue())
+	assert.Nil(t, waitPause(t, session))
+	final, err = session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"replayed", "done"}, final["visited"])
+
+	assert.Error(t, session.Replay(10))
+}
+
+func TestDebugSession_Stop(t *testing.T) {
+	session := counterGraph(t).Debug(context.Background(), map[string]any{"count": 0, "limit": 3}, nil)
+	waitPause(t, session)
+
+	require.NoError(t, session.Stop())
+	assert.Nil(t, waitPause(t, session))
+	_, err := session.Result()
+	assert.ErrorIs(t, err, ErrDebugStopped)
+}
+
+func TestDebugSession_SubgraphsAreNotPaused(t *testing.T) {
+	inner := counterGraph(t)
+
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("outer", "outer", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return inner.Invoke(ctx, map[string]any{"count": 0, "limit": 2})
+	})
+	g.SetEntryPoint("outer")
+	g.AddEdge("outer", END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	session := runnable.Debug(context.Background(), map[string]any{}, nil)
+	waitPause(t, session)
+	require.NoError(t, session.Step())
+	assert.Nil(t, waitPause(t, session))
+
+	final, err := session.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 2, final["count"])
+	assert.Len(t, session.History(), 1)
+}
@@ -178,6 +178,23 @@
 //		Direction: "LR", // Left to right
 //	})
 //
+//	// Topology as JSON, with the internals of subgraph, parallel and map-reduce nodes
+//	topology, _ := exporter.DrawJSON()
+//
+// Subgraphs are drawn as nested blocks (clusters in DOT), parallel and map-reduce nodes as a
+// fan-out to their branches and a fan-in, and node descriptions become tooltips.
+//
+// Render what a run actually executed, from the spans of a Tracer or the checkpoints of a thread:
+//
+//	trace := tracer.ExecutionTrace()
+//	// or: trace, _ := runnable.GetExecutionTrace(ctx, "thread-1")
+//
+//	// Visit counts, durations and failures over the declared graph
+//	mermaid := exporter.DrawMermaidTrace(trace, graph.TraceDiagramOptions{})
+//
+//	// One node per visit, loops unrolled with iteration numbers
+//	dot := trace.DrawDOT(graph.TraceDiagramOptions{Unroll: true})
+//
 // # Thread Safety
 //
 // All graph structures are thread-safe for read operations. Write operations (adding nodes,
@@ -108,8 +108,11 @@ func TestLargeGraph(t *testing.T) {
 		t.Fatalf("Failed to compile large graph: %v", err)
 	}
 
+	// A 1000-node chain needs one superstep per node, well above the default recursion limit
+	config := &graph.Config{RecursionLimit: nodeCount}
+
 	start := time.Now()
-	result, err := runnable.Invoke(context.Background(), 0)
+	result, err := runnable.InvokeWithConfig(context.Background(), 0, config)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -0,0 +1,616 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// TraceVisit is an execution of a node recorded in an ExecutionTrace.
+type TraceVisit struct {
+	// Node is the name of the node
+	Node string
+
+	// Iteration numbers the executions of the node in the trace, starting at 1
+	Iteration int
+
+	// Step is the index of the superstep of the visit; the visits of parallel nodes share it
+	Step int
+
+	// Start is when the node started
+	Start time.Time
+
+	// Duration is the time the node took, zero if unknown
+	Duration time.Duration
+
+	// Retries is the number of failed attempts that were retried
+	Retries int
+
+	// Failed reports whether the node failed, with Error describing the failure
+	Failed bool
+	Error  string
+}
+
+// TraceTransition links two visits of an ExecutionTrace by their index in Visits.
+// From is -1 for the start of the run and To is -1 for its end.
+type TraceTransition struct {
+	From int
+	To   int
+}
+
+// ExecutionTrace records what a run actually executed, to render it as a diagram.
+// It is built from the spans of a Tracer or from the checkpoint history of a thread.
+type ExecutionTrace struct {
+	// Visits are the executions of nodes, in start order
+	Visits []TraceVisit
+
+	// Transitions are the edges traversed between visits
+	Transitions []TraceTransition
+}
+
+// ExecutionTrace builds the execution trace of the runs recorded by the tracer.
+func (t *Tracer) ExecutionTrace() *ExecutionTrace {
+	return NewExecutionTraceFromSpans(t.GetSpans())
+}
+
+// NewExecutionTraceFromSpans builds an execution trace from the spans of a Tracer.
+// Only the nodes of the outermost graph are included: the nodes of subgraphs and parallel
+// groups are part of the node that runs them.
+func NewExecutionTraceFromSpans(spans map[string]*TraceSpan) *ExecutionTrace {
+	var nodeSpans, edgeSpans []*TraceSpan
+	retries := make(map[string]int)
+	for _, span := range spans {
+		switch {
+		case span.Event == TraceEventNodeRetry:
+			retries[span.ParentID]++
+		case nestedSpan(spans, span):
+		case isNodeSpan(span):
+			nodeSpans = append(nodeSpans, span)
+		case span.Event == TraceEventEdgeTraversal:
+			edgeSpans = append(edgeSpans, span)
+		}
+	}
+	sortSpans(nodeSpans)
+	sortSpans(edgeSpans)
+	nodeSpans = dropErrorEchoes(nodeSpans)
+
+	trace := &ExecutionTrace{}
+	iterations := make(map[string]int)
+	step := -1
+	var stepEnd time.Time
+	nextEdge := 0
+	for _, span := range nodeSpans {
+		// A superstep starts after the edges of the previous one were traversed,
+		// or once all of its nodes ended
+		traversed := false
+		for nextEdge < len(edgeSpans) && !edgeSpans[nextEdge].StartTime.After(span.StartTime) {
+			traversed = true
+			nextEdge++
+		}
+		end := span.EndTime
+		if end.IsZero() {
+			end = span.StartTime
+		}
+		if step < 0 || traversed || !span.StartTime.Before(stepEnd) {
+			step++
+			stepEnd = end
+		} else if end.After(stepEnd) {
+			stepEnd = end
+		}
+
+		iterations[span.NodeName]++
+		visit := TraceVisit{
+			Node:      span.NodeName,
+			Iteration: iterations[span.NodeName],
+			Step:      step,
+			Start:     span.StartTime,
+			Duration:  span.Duration,
+			Retries:   retries[span.ID],
+			Failed:    span.Event == TraceEventNodeError,
+		}
+		if span.Error != nil {
+			visit.Error = span.Error.Error()
+		}
+		trace.Visits = append(trace.Visits, visit)
+	}
+
+	for _, edge := range edgeSpans {
+		from := trace.lastVisit(edge.FromNode, edge.StartTime)
+		if from < 0 {
+			continue
+		}
+		to := -1
+		if edge.ToNode != END {
+			if to = trace.firstVisit(edge.ToNode, edge.StartTime); to < 0 {
+				// The run stopped before the target ran
+				continue
+			}
+		}
+		trace.addTransition(from, to)
+	}
+	trace.inferTransitions()
+	return trace
+}
+
+// NewExecutionTraceFromCheckpoints builds an execution trace from the checkpoint history of a
+// thread, as saved by AutoSave. Checkpoints saved by UpdateState or SaveCheckpoint are skipped.
+//
+// Checkpoints only record supersteps: the duration of a superstep is the time since the
+// previous checkpoint, the first superstep has no duration, and all the nodes of a failed
+// superstep are marked failed.
+func NewExecutionTraceFromCheckpoints(checkpoints []*store.Checkpoint) *ExecutionTrace {
+	sorted := slices.Clone(checkpoints)
+	slices.SortStableFunc(sorted, func(a, b *store.Checkpoint) int { return cmp.Compare(a.Version, b.Version) })
+
+	trace := &ExecutionTrace{}
+	iterations := make(map[string]int)
+	var previous *store.Checkpoint
+	var last *store.Checkpoint
+	step := 0
+	for _, cp := range sorted {
+		event, _ := cp.Metadata["event"].(string)
+		if (event != "step" && event != "step_failed") || cp.NodeName == "" {
+			continue
+		}
+
+		var duration time.Duration
+		if previous != nil {
+			duration = cp.Timestamp.Sub(previous.Timestamp)
+		}
+		previous = cp
+
+		nodes := stepNodes(cp.NodeName)
+		if failed, ok := metadataNodes(cp.Metadata["next_nodes"]); ok && event == "step_failed" {
+			nodes = failed
+		}
+		for _, node := range nodes {
+			iterations[node]++
+			visit := TraceVisit{
+				Node:      node,
+				Iteration: iterations[node],
+				Step:      step,
+				Start:     cp.Timestamp.Add(-duration),
+				Duration:  duration,
+			}
+			if event == "step_failed" {
+				visit.Failed = true
+				visit.Error = "superstep failed"
+			}
+			trace.Visits = append(trace.Visits, visit)
+		}
+		step++
+		last = cp
+	}
+
+	// A run that completed scheduled END after its last superstep
+	if last != nil && last.Metadata["event"] == "step" {
+		if next, ok := metadataNodes(last.Metadata["next"]); ok && !slices.ContainsFunc(next, func(n string) bool { return n != END }) {
+			for i, visit := range trace.Visits {
+				if visit.Step == step-1 {
+					trace.addTransition(i, -1)
+				}
+			}
+		}
+	}
+
+	trace.inferTransitions()
+	return trace
+}
+
+// lastVisit returns the index of the last visit of node started at or before t, or -1.
+func (t *ExecutionTrace) lastVisit(node string, at time.Time) int {
+	for i := len(t.Visits) - 1; i >= 0; i-- {
+		if t.Visits[i].Node == node && !t.Visits[i].Start.After(at) {
+			return i
+		}
+	}
+	return -1
+}
+
+// firstVisit returns the index of the first visit of node started at or after t, or -1.
+func (t *ExecutionTrace) firstVisit(node string, at time.Time) int {
+	for i, visit := range t.Visits {
+		if visit.Node == node && !visit.Start.Before(at) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *ExecutionTrace) addTransition(from, to int) {
+	transition := TraceTransition{From: from, To: to}
+	if !slices.Contains(t.Transitions, transition) {
+		t.Transitions = append(t.Transitions, transition)
+	}
+}
+
+// inferTransitions links the visits no recorded transition leads to: the visits of the first
+// superstep start the run, the others follow all the visits of the previous superstep.
+func (t *ExecutionTrace) inferTransitions() {
+	for i, visit := range t.Visits {
+		if slices.ContainsFunc(t.Transitions, func(tr TraceTransition) bool { return tr.To == i }) {
+			continue
+		}
+		if visit.Step == 0 {
+			t.addTransition(-1, i)
+			continue
+		}
+		for j, previous := range t.Visits {
+			if previous.Step == visit.Step-1 {
+				t.addTransition(j, i)
+			}
+		}
+	}
+	slices.SortStableFunc(t.Transitions, func(a, b TraceTransition) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(endLast(a.To), endLast(b.To)))
+	})
+}
+
+// endLast orders the end of the run after the visits.
+func endLast(visit int) int {
+	if visit < 0 {
+		return math.MaxInt
+	}
+	return visit
+}
+
+func isNodeSpan(span *TraceSpan) bool {
+	return span.Event == TraceEventNodeStart || span.Event == TraceEventNodeEnd || span.Event == TraceEventNodeError
+}
+
+// nestedSpan reports whether a span runs inside a node, e.g. in a subgraph or a parallel group.
+func nestedSpan(spans map[string]*TraceSpan, span *TraceSpan) bool {
+	id := span.ParentID
+	for range len(spans) {
+		parent, ok := spans[id]
+		if !ok {
+			return false
+		}
+		if isNodeSpan(parent) {
+			return true
+		}
+		id = parent.ParentID
+	}
+	return false
+}
+
+func sortSpans(spans []*TraceSpan) {
+	slices.SortStableFunc(spans, func(a, b *TraceSpan) int {
+		return cmp.Or(a.StartTime.Compare(b.StartTime), cmp.Compare(a.ID, b.ID))
+	})
+}
+
+// dropErrorEchoes drops the error spans a failed node emits after its own span, which ends
+// with the same error.
+func dropErrorEchoes(spans []*TraceSpan) []*TraceSpan {
+	kept := spans[:0:0]
+	echoed := make(map[*TraceSpan]bool)
+	for _, span := range spans {
+		if span.Event == TraceEventNodeError && span.Error != nil {
+			i := slices.IndexFunc(kept, func(k *TraceSpan) bool {
+				return !echoed[k] && k.Event == TraceEventNodeError && k.Error != nil &&
+					k.NodeName == span.NodeName && k.ParentID == span.ParentID &&
+					!k.EndTime.After(span.StartTime) && k.Error.Error() == span.Error.Error()
+			})
+			if i >= 0 {
+				echoed[kept[i]] = true
+				continue
+			}
+		}
+		kept = append(kept, span)
+	}
+	return kept
+}
+
+// stepNodes returns the nodes of a superstep from the node name of its checkpoint.
+func stepNodes(nodeName string) []string {
+	if inner, ok := strings.CutPrefix(nodeName, "step:["); ok {
+		return strings.Fields(strings.TrimSuffix(inner, "]"))
+	}
+	return []string{nodeName}
+}
+
+// metadataNodes decodes a list of nodes from checkpoint metadata, which stores decoded from
+// JSON hold as []any.
+func metadataNodes(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case []any:
+		nodes := make([]string, 0, len(v))
+		for _, n := range v {
+			nodes = append(nodes, fmt.Sprint(n))
+		}
+		return nodes, true
+	default:
+		return nil, false
+	}
+}
+
+// TraceDiagramOptions configures the rendering of execution traces.
+type TraceDiagramOptions struct {
+	// Direction of the flowchart (e.g., "TD", "LR")
+	Direction string
+
+	// Unroll draws each visit as its own node numbered by iteration, so that loops read as a
+	// sequence, instead of one node per graph node with traversal counts on the edges
+	Unroll bool
+}
+
+// DrawMermaid renders the trace as a Mermaid flowchart: visited nodes are highlighted with
+// their durations, edges are labeled with traversal counts and failed nodes are red.
+func (t *ExecutionTrace) DrawMermaid(opts TraceDiagramOptions) string {
+	return t.diagram(opts, nil).mermaid(opts.Direction)
+}
+
+// DrawDOT renders the trace as a DOT (Graphviz) digraph, like DrawMermaid.
+func (t *ExecutionTrace) DrawDOT(opts TraceDiagramOptions) string {
+	return t.diagram(opts, nil).dot(opts.Direction)
+}
+
+// traceTopology is the declared topology a trace is drawn over.
+type traceTopology struct {
+	nodes []string
+	// edges are pairs of node names, START and END included
+	edges [][2]string
+}
+
+// traceDiagram is the drawing of an execution trace, shared by the Mermaid and DOT renderers.
+type traceDiagram struct {
+	nodes []traceDiagramNode
+	edges []traceDiagramEdge
+}
+
+type traceDiagramNode struct {
+	id    string
+	lines []string
+	// class is "visited", "failed" or "unvisited"; START and END have none
+	class string
+}
+
+type traceDiagramEdge struct {
+	from, to string
+	label    string
+	// traversed is false for declared edges the run did not take
+	traversed bool
+}
+
+// diagram lays out the trace, over the declared topology when there is one.
+func (t *ExecutionTrace) diagram(opts TraceDiagramOptions, topology *traceTopology) *traceDiagram {
+	d := &traceDiagram{nodes: []traceDiagramNode{{id: "START", lines: []string{"START"}}}}
+	hasEnd := false
+
+	if opts.Unroll {
+		counts := make(map[string]int)
+		for _, visit := range t.Visits {
+			counts[visit.Node]++
+		}
+		ids := make([]string, len(t.Visits))
+		for i, visit := range t.Visits {
+			title := visit.Node
+			ids[i] = traceNodeID(i)
+			if counts[visit.Node] > 1 {
+				title = fmt.Sprintf("%s #%d", visit.Node, visit.Iteration)
+			}
+			d.nodes = append(d.nodes, visitNode(ids[i], title, []TraceVisit{visit}))
+		}
+		for _, tr := range t.Transitions {
+			from, to := "START", "END"
+			if tr.From >= 0 {
+				from = ids[tr.From]
+			}
+			if tr.To >= 0 {
+				to = ids[tr.To]
+			} else {
+				hasEnd = true
+			}
+			d.edges = append(d.edges, traceDiagramEdge{from: from, to: to, traversed: true})
+		}
+	} else {
+		visits := make(map[string][]TraceVisit)
+		var names []string
+		if topology != nil {
+			names = slices.Clone(topology.nodes)
+		}
+		for _, visit := range t.Visits {
+			if !slices.Contains(names, visit.Node) {
+				names = append(names, visit.Node)
+			}
+			visits[visit.Node] = append(visits[visit.Node], visit)
+		}
+		ids := map[string]string{"START": "START", END: "END"}
+		for i, name := range names {
+			ids[name] = traceNodeID(i)
+			if vs, ok := visits[name]; ok {
+				title := name
+				if len(vs) > 1 {
+					title = fmt.Sprintf("%s ×%d", name, len(vs))
+				}
+				d.nodes = append(d.nodes, visitNode(ids[name], title, vs))
+			} else {
+				d.nodes = append(d.nodes, traceDiagramNode{id: ids[name], lines: []string{name}, class: "unvisited"})
+			}
+		}
+
+		// Count the traversals between graph nodes, in order of first traversal
+		var pairs [][2]string
+		counts := make(map[[2]string]int)
+		for _, tr := range t.Transitions {
+			pair := [2]string{"START", END}
+			if tr.From >= 0 {
+				pair[0] = t.Visits[tr.From].Node
+			}
+			if tr.To >= 0 {
+				pair[1] = t.Visits[tr.To].Node
+			}
+			if counts[pair] == 0 {
+				pairs = append(pairs, pair)
+			}
+			counts[pair]++
+		}
+		for _, pair := range pairs {
+			hasEnd = hasEnd || pair[1] == END
+			d.edges = append(d.edges, traceDiagramEdge{
+				from:      ids[pair[0]],
+				to:        ids[pair[1]],
+				label:     fmt.Sprint(counts[pair]),
+				traversed: true,
+			})
+		}
+		if topology != nil {
+			for _, edge := range topology.edges {
+				if counts[edge] > 0 {
+					continue
+				}
+				hasEnd = hasEnd || edge[1] == END
+				d.edges = append(d.edges, traceDiagramEdge{from: ids[edge[0]], to: ids[edge[1]]})
+			}
+		}
+	}
+
+	if hasEnd {
+		d.nodes = append(d.nodes, traceDiagramNode{id: "END", lines: []string{"END"}})
+	}
+	return d
+}
+
+// visitNode draws the visits of a node: their total duration, retries and first error.
+func visitNode(id, title string, visits []TraceVisit) traceDiagramNode {
+	node := traceDiagramNode{id: id, lines: []string{title}, class: "visited"}
+
+	var total time.Duration
+	retries := 0
+	for _, visit := range visits {
+		total += visit.Duration
+		retries += visit.Retries
+	}
+	if total > 0 {
+		node.lines = append(node.lines, formatTraceDuration(total))
+	}
+	if retries > 0 {
+		node.lines = append(node.lines, fmt.Sprintf("%d retries", retries))
+	}
+	if i := slices.IndexFunc(visits, func(v TraceVisit) bool { return v.Failed }); i >= 0 {
+		node.class = "failed"
+		if msg := visits[i].Error; msg != "" {
+			if len(msg) > 60 {
+				msg = msg[:57] + "..."
+			}
+			node.lines = append(node.lines, msg)
+		}
+	}
+	return node
+}
+
+func (d *traceDiagram) mermaid(direction string) string {
+	if direction == "" {
+		direction = "TD"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("flowchart %s\n", direction))
+
+	classes := make(map[string][]string)
+	for _, node := range d.nodes {
+		label := strings.ReplaceAll(strings.Join(node.lines, "<br/>"), `"`, "#quot;")
+		if node.class == "" {
+			sb.WriteString(fmt.Sprintf("    %s([\"%s\"])\n", node.id, label))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", node.id, label))
+		classes[node.class] = append(classes[node.class], node.id)
+	}
+
+	for _, edge := range d.edges {
+		switch {
+		case !edge.traversed:
+			sb.WriteString(fmt.Sprintf("    %s -.-> %s\n", edge.from, edge.to))
+		case edge.label != "":
+			sb.WriteString(fmt.Sprintf("    %s -->|%s| %s\n", edge.from, edge.label, edge.to))
+		default:
+			sb.WriteString(fmt.Sprintf("    %s --> %s\n", edge.from, edge.to))
+		}
+	}
+
+	sb.WriteString("    style START fill:#90EE90\n")
+	if slices.ContainsFunc(d.nodes, func(n traceDiagramNode) bool { return n.id == "END" }) {
+		sb.WriteString("    style END fill:#FFB6C1\n")
+	}
+	sb.WriteString("    classDef visited fill:#87CEEB,stroke:#333\n")
+	sb.WriteString("    classDef failed fill:#FF6B6B,stroke:#B22222,color:#FFFFFF\n")
+	sb.WriteString("    classDef unvisited fill:#F5F5F5,stroke:#BBBBBB,color:#999999\n")
+	for _, class := range []string{"visited", "failed", "unvisited"} {
+		if ids := classes[class]; len(ids) > 0 {
+			sb.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(ids, ","), class))
+		}
+	}
+	return sb.String()
+}
+
+func (d *traceDiagram) dot(direction string) string {
+	// Graphviz spells top-down TB
+	if direction == "" || direction == "TD" {
+		direction = "TB"
+	}
+	var sb strings.Builder
+	sb.WriteString("digraph G {\n")
+	sb.WriteString(fmt.Sprintf("    rankdir=%s;\n", direction))
+	sb.WriteString("    node [shape=box];\n")
+
+	for _, node := range d.nodes {
+		lines := make([]string, len(node.lines))
+		for i, line := range node.lines {
+			lines[i] = dotEscape(line)
+		}
+		label := strings.Join(lines, `\n`)
+		switch {
+		case node.id == "START":
+			sb.WriteString("    START [label=\"START\", shape=ellipse, style=filled, fillcolor=lightgreen];\n")
+		case node.id == "END":
+			sb.WriteString("    END [label=\"END\", shape=ellipse, style=filled, fillcolor=lightpink];\n")
+		case node.class == "failed":
+			sb.WriteString(fmt.Sprintf("    %s [label=\"%s\", style=filled, fillcolor=red, fontcolor=white];\n", node.id, label))
+		case node.class == "unvisited":
+			sb.WriteString(fmt.Sprintf("    %s [label=\"%s\", color=gray, fontcolor=gray];\n", node.id, label))
+		default:
+			sb.WriteString(fmt.Sprintf("    %s [label=\"%s\", style=filled, fillcolor=lightblue];\n", node.id, label))
+		}
+	}
+
+	for _, edge := range d.edges {
+		switch {
+		case !edge.traversed:
+			sb.WriteString(fmt.Sprintf("    %s -> %s [style=dashed, color=gray];\n", edge.from, edge.to))
+		case edge.label != "":
+			sb.WriteString(fmt.Sprintf("    %s -> %s [label=\"%s\"];\n", edge.from, edge.to, edge.label))
+		default:
+			sb.WriteString(fmt.Sprintf("    %s -> %s;\n", edge.from, edge.to))
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// traceNodeID returns the identifier of the i-th node of a diagram. Identifiers are numbered
+// rather than derived from node names, which Mermaid and DOT would need quoted and which could
+// collide once sanitized; the names are shown in the quoted labels.
+func traceNodeID(i int) string {
+	return fmt.Sprintf("n%d", i)
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatTraceDuration rounds a duration for display.
+func formatTraceDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store/memory"
+)
+
+// loopGraph runs "work" until "count" reaches 2, then "check", which fails if "fail" is set.
+func loopGraph() *StateGraph[map[string]any] {
+	g := NewStateGraph[map[string]any]()
+	buildLoopGraph(g, g.AddNode)
+	return g
+}
+
+func buildLoopGraph(g *StateGraph[map[string]any], addNode func(string, string, func(context.Context, map[string]any) (map[string]any, error), ...NodeOption)) {
+	g.SetSchema(NewMapSchema())
+	addNode("work", "work", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		count, _ := state["count"].(int)
+		return map[string]any{"count": count + 1}, nil
+	})
+	addNode("check", "check", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if state["fail"] == true {
+			return nil, errors.New("check failed")
+		}
+		return map[string]any{"checked": true}, nil
+	})
+	addNode("unused", "unused", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("work")
+	g.AddConditionalEdge("work", func(ctx context.Context, state map[string]any) string {
+		if state["count"].(int) < 2 {
+			return "again"
+		}
+		return "done"
+	}, map[string]string{"again": "work", "done": "check", "skip": "unused"})
+	g.AddEdge("check", END)
+	g.AddEdge("unused", END)
+}
+
+func TestExecutionTraceFromSpans(t *testing.T) {
+	runnable, err := loopGraph().Compile()
+	require.NoError(t, err)
+	tracer := NewTracer()
+	_, err = runnable.WithTracer(tracer).Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+
+	trace := tracer.ExecutionTrace()
+	require.Len(t, trace.Visits, 3)
+	assert.Equal(t, "work", trace.Visits[0].Node)
+	assert.Equal(t, 2, trace.Visits[1].Iteration)
+	assert.Equal(t, "check", trace.Visits[2].Node)
+	assert.Equal(t, 2, trace.Visits[2].Step)
+	assert.Equal(t, []TraceTransition{{-1, 0}, {0, 1}, {1, 2}, {2, -1}}, trace.Transitions)
+
+	mermaid := trace.DrawMermaid(TraceDiagramOptions{})
+	assert.Contains(t, mermaid, "START -->|1| n0\n")
+	assert.Contains(t, mermaid, "n0 -->|1| n0\n")
+	assert.Contains(t, mermaid, "n1 -->|1| END\n")
+	assert.Contains(t, mermaid, `n0["work ×2<br/>`)
+	assert.Contains(t, mermaid, "class n0,n1 visited\n")
+
+	unrolled := trace.DrawMermaid(TraceDiagramOptions{Unroll: true, Direction: "LR"})
+	assert.Contains(t, unrolled, "flowchart LR\n")
+	assert.Contains(t, unrolled, `n1["work #2<br/>`)
+	assert.Contains(t, unrolled, "n0 --> n1\n")
+	assert.Contains(t, unrolled, "n1 --> n2\n")
+
+	// The declared graph shows what did not run
+	overlay := NewExporter(loopGraph()).DrawMermaidTrace(trace, TraceDiagramOptions{})
+	assert.Contains(t, overlay, `n2["unused"]`)
+	assert.Contains(t, overlay, "n0 -.-> n2\n")
+	assert.Contains(t, overlay, "n2 -.-> END\n")
+	assert.Contains(t, overlay, "class n2 unvisited\n")
+}
+
+func TestExecutionTraceFromSpans_Failure(t *testing.T) {
+	runnable, err := loopGraph().Compile()
+	require.NoError(t, err)
+	tracer := NewTracer()
+	_, err = runnable.WithTracer(tracer).Invoke(context.Background(), map[string]any{"fail": true})
+	require.Error(t, err)
+
+	trace := tracer.ExecutionTrace()
+	require.Len(t, trace.Visits, 3)
+	assert.True(t, trace.Visits[2].Failed)
+	assert.Equal(t, "check failed", trace.Visits[2].Error)
+
+	dot := trace.DrawDOT(TraceDiagramOptions{})
+	assert.Contains(t, dot, "rankdir=TB;")
+	assert.Contains(t, dot, `n1 [label="check\n`)
+	assert.Contains(t, dot, `check failed", style=filled, fillcolor=red, fontcolor=white];`)
+	assert.Contains(t, dot, `n0 -> n0 [label="1"];`)
+	assert.NotContains(t, dot, "END")
+}
+
+func TestExecutionTraceFromSpans_Subgraph(t *testing.T) {
+	inner, err := loopGraph().Compile()
+	require.NoError(t, err)
+
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("outer", "outer", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return inner.Invoke(ctx, state)
+	})
+	g.SetEntryPoint("outer")
+	g.AddEdge("outer", END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	tracer := NewTracer()
+	_, err = runnable.WithTracer(tracer).Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+
+	trace := tracer.ExecutionTrace()
+	require.Len(t, trace.Visits, 1)
+	assert.Equal(t, "outer", trace.Visits[0].Node)
+}
+
+func TestExecutionTraceFromCheckpoints(t *testing.T) {
+	g := NewCheckpointableStateGraph[map[string]any]()
+	buildLoopGraph(g.StateGraph, func(name, description string, fn func(context.Context, map[string]any) (map[string]any, error), opts ...NodeOption) {
+		g.AddNode(name, description, fn, opts...)
+	})
+	g.SetCheckpointConfig(CheckpointConfig{Store: memory.NewMemoryCheckpointStore(), AutoSave: true})
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = runnable.InvokeWithConfig(ctx, map[string]any{}, WithThreadID("loop"))
+	require.NoError(t, err)
+
+	trace, err := runnable.GetExecutionTrace(ctx, "loop")
+	require.NoError(t, err)
+	require.Len(t, trace.Visits, 3)
+	assert.Equal(t, []string{"work", "work", "check"}, []string{trace.Visits[0].Node, trace.Visits[1].Node, trace.Visits[2].Node})
+	assert.Equal(t, []TraceTransition{{-1, 0}, {0, 1}, {1, 2}, {2, -1}}, trace.Transitions)
+
+	unrolled := trace.DrawDOT(TraceDiagramOptions{Unroll: true})
+	assert.Contains(t, unrolled, "START -> n0;")
+	assert.Contains(t, unrolled, "n0 -> n1;")
+	assert.Contains(t, unrolled, "n2 -> END;")
+}
+
+func TestExecutionTraceFromCheckpoints_ParallelStep(t *testing.T) {
+	trace := NewExecutionTraceFromCheckpoints(nil)
+	assert.Empty(t, trace.Visits)
+
+	assert.Equal(t, []string{"a", "b"}, stepNodes(stepNodeName([]string{"a", "b"})))
+	assert.Equal(t, []string{"a"}, stepNodes(stepNodeName([]string{"a"})))
+}
+
+func TestExecutionTraceDiagram_NodeIDs(t *testing.T) {
+	// Names that collide once sanitized, or with the numbered iterations of another node
+	trace := &ExecutionTrace{
+		Visits: []TraceVisit{
+			{Node: "a", Iteration: 1},
+			{Node: "a", Iteration: 2},
+			{Node: "a_1", Iteration: 1},
+			{Node: "fetch-data", Iteration: 1},
+			{Node: "fetch_data", Iteration: 1},
+		},
+		Transitions: []TraceTransition{{-1, 0}, {0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, -1}},
+	}
+
+	unrolled := trace.DrawMermaid(TraceDiagramOptions{Unroll: true})
+	for i, label := range []string{"a #1", "a #2", "a_1", "fetch-data", "fetch_data"} {
+		assert.Contains(t, unrolled, fmt.Sprintf("    n%d[\"%s\"]\n", i, label))
+	}
+	assert.Contains(t, unrolled, "n3 --> n4\n")
+
+	dot := trace.DrawDOT(TraceDiagramOptions{})
+	for i, label := range []string{"a ×2", "a_1", "fetch-data", "fetch_data"} {
+		assert.Contains(t, dot, fmt.Sprintf("    n%d [label=\"%s\"", i, label))
+	}
+	assert.Contains(t, dot, `n0 -> n0 [label="1"];`)
+	assert.Contains(t, dot, `n2 -> n3 [label="1"];`)
+}
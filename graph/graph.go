@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // END is a special constant used to represent the end node in the graph.
 const END = "END"
 
+// DefaultRecursionLimit is the maximum number of supersteps used when Config.RecursionLimit is not set.
+// It leaves room for the prebuilt agents' own iteration caps (two steps per iteration).
+const DefaultRecursionLimit = 100
+
 var (
 	// ErrEntryPointNotSet is returned when the entry point of the graph is not set.
 	ErrEntryPointNotSet = errors.New("entry point not set")
@@ -18,8 +23,65 @@ var (
 
 	// ErrNoOutgoingEdge is returned when no outgoing edge is found for a node.
 	ErrNoOutgoingEdge = errors.New("no outgoing edge found for node")
+
+	// ErrGraphRecursion is returned when the graph exceeds its recursion limit.
+	// Use errors.As with *GraphRecursionError to get the details.
+	ErrGraphRecursion = errors.New("graph recursion limit reached")
+
+	// ErrGraphTimeout is returned when an invocation exceeds Config.Timeout or the context deadline.
+	// Use errors.As with *GraphTimeoutError to get the details.
+	ErrGraphTimeout = errors.New("graph execution timed out")
 )
 
+// GraphRecursionError is returned when execution exceeds Config.RecursionLimit supersteps.
+// It matches ErrGraphRecursion with errors.Is.
+type GraphRecursionError struct {
+	// Limit is the recursion limit that was exceeded
+	Limit int
+	// Steps is the number of supersteps executed before stopping
+	Steps int
+	// Nodes are the nodes that would have run in the next superstep
+	Nodes []string
+}
+
+func (e *GraphRecursionError) Error() string {
+	return fmt.Sprintf("%s: %d steps executed without reaching END (limit %d), next nodes: %v",
+		ErrGraphRecursion, e.Steps, e.Limit, e.Nodes)
+}
+
+// Is reports whether target is ErrGraphRecursion.
+func (e *GraphRecursionError) Is(target error) bool {
+	return target == ErrGraphRecursion
+}
+
+// GraphTimeoutError is returned when the invocation deadline passes before the graph reaches END.
+// It matches ErrGraphTimeout and context.DeadlineExceeded with errors.Is.
+type GraphTimeoutError struct {
+	// Timeout is the configured invocation timeout (zero if the deadline came from the caller's context)
+	Timeout time.Duration
+	// Nodes are the nodes that were still running or cancelled when the deadline passed
+	Nodes []string
+	// State is the state after the last completed superstep
+	State any
+}
+
+func (e *GraphTimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("%s after %v, nodes still running: %v", ErrGraphTimeout, e.Timeout, e.Nodes)
+	}
+	return fmt.Sprintf("%s, nodes still running: %v", ErrGraphTimeout, e.Nodes)
+}
+
+// Is reports whether target is ErrGraphTimeout.
+func (e *GraphTimeoutError) Is(target error) bool {
+	return target == ErrGraphTimeout
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *GraphTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
 // GraphInterrupt is returned when execution is interrupted by configuration or dynamic interrupt
 type GraphInterrupt struct {
 	// Node that caused the interruption
@@ -57,11 +119,37 @@ type Edge struct {
 	To string
 }
 
-// RetryPolicy defines how to handle node failures
+// JoinEdge represents a barrier edge: To runs once after every node in From has completed.
+type JoinEdge struct {
+	// From lists the upstream nodes that must all complete before To is scheduled.
+	From []string
+
+	// To is the name of the node that waits for the upstream nodes.
+	To string
+}
+
+// RetryPolicy defines how to handle node failures.
+// It can be set for the whole graph with SetRetryPolicy or per node with WithRetryPolicy.
 type RetryPolicy struct {
 	MaxRetries      int
 	BackoffStrategy BackoffStrategy
+
+	// RetryableErrors lists substrings of retryable error messages.
+	// It is only used when Retryable is nil.
 	RetryableErrors []string
+
+	// Retryable classifies errors; return true to retry.
+	// Unlike RetryableErrors it can use errors.Is and errors.As.
+	Retryable func(err error) bool
+
+	// InitialDelay is the base delay of the backoff strategy. Zero means one second.
+	InitialDelay time.Duration
+
+	// MaxDelay caps every delay, including Retry-After hints. Zero means no cap.
+	MaxDelay time.Duration
+
+	// Jitter randomizes each delay by up to ± this fraction (for example 0.2 for ±20%).
+	Jitter float64
 }
 
 // BackoffStrategy defines different backoff strategies
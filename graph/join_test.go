@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnevenJoinGraph builds A→B→B2 and A→C, joined into D.
+func newUnevenJoinGraph(dRuns *atomic.Int32, seen *map[string]any) *StateGraph[map[string]any] {
+	g := NewStateGraph[map[string]any]()
+	for _, name := range []string{"A", "B", "B2", "C"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{name: true}, nil
+		})
+	}
+	g.AddNode("D", "D", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		dRuns.Add(1)
+		*seen = map[string]any{"B2": state["B2"], "C": state["C"]}
+		return map[string]any{"D": true}, nil
+	})
+	g.SetEntryPoint("A")
+	g.AddEdge("A", "B")
+	g.AddEdge("A", "C")
+	g.AddEdge("B", "B2")
+	g.AddJoinEdge([]string{"B2", "C"}, "D")
+	g.AddEdge("D", END)
+	g.SetSchema(NewMapSchema())
+	return g
+}
+
+func TestJoinEdgeWaitsForAllUpstreams(t *testing.T) {
+	var dRuns atomic.Int32
+	var seen map[string]any
+	runnable, err := newUnevenJoinGraph(&dRuns, &seen).Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), dRuns.Load())
+	assert.Equal(t, map[string]any{"B2": true, "C": true}, seen)
+	assert.Equal(t, true, res["D"])
+}
+
+func TestJoinEdgeFiresAgainInLoop(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("start", "start", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	g.AddNode("left", "left", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	g.AddNode("right", "right", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	g.AddNode("join", "join", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		count, _ := state["count"].(int)
+		return map[string]any{"count": count + 1}, nil
+	})
+	g.SetEntryPoint("start")
+	g.AddEdge("start", "left")
+	g.AddEdge("start", "right")
+	g.AddJoinEdge([]string{"left", "right"}, "join")
+	g.AddConditionalEdge("join", func(ctx context.Context, state map[string]any) string {
+		if state["count"].(int) >= 3 {
+			return END
+		}
+		return "start"
+	})
+	g.SetSchema(NewMapSchema())
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res["count"])
+}
+
+func TestJoinEdgeWithCommandGoto(t *testing.T) {
+	g := NewStateGraph[any]()
+	g.AddNode("router", "router", func(ctx context.Context, state any) (any, error) {
+		return &Command{Goto: []string{"a", "b"}}, nil
+	})
+	g.AddNode("a", "a", func(ctx context.Context, state any) (any, error) {
+		return &Command{Update: state, Goto: "a2"}, nil
+	})
+	g.AddNode("a2", "a2", func(ctx context.Context, state any) (any, error) {
+		return state, nil
+	})
+	g.AddNode("b", "b", func(ctx context.Context, state any) (any, error) {
+		return state, nil
+	})
+	var joined atomic.Int32
+	g.AddNode("done", "done", func(ctx context.Context, state any) (any, error) {
+		joined.Add(1)
+		return state, nil
+	})
+	g.SetEntryPoint("router")
+	g.AddJoinEdge([]string{"a2", "b"}, "done")
+	g.AddEdge("done", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), joined.Load())
+}
+
+func TestJoinEdgeVisualization(t *testing.T) {
+	var dRuns atomic.Int32
+	var seen map[string]any
+	exporter := NewExporter(newUnevenJoinGraph(&dRuns, &seen))
+
+	assert.Contains(t, exporter.DrawMermaid(), "B2 & C ==>|join| D")
+	dot := exporter.DrawDOT()
+	assert.Contains(t, dot, "B2 -> D [style=bold, label=\"join\"];")
+	assert.Contains(t, dot, "C -> D [style=bold, label=\"join\"];")
+}
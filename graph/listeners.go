@@ -29,6 +29,9 @@ const (
 	// EventChainEnd indicates the graph execution has completed
 	EventChainEnd NodeEvent = "chain_end"
 
+	// EventStep carries the full state after a superstep of a run streamed with StreamWithConfig
+	EventStep NodeEvent = "step"
+
 	// EventToolStart indicates a tool execution has started
 	EventToolStart NodeEvent = "tool_start"
 
@@ -84,6 +87,21 @@ type StreamEvent[S any] struct {
 
 	// Duration is how long the node took (only for Complete events)
 	Duration time.Duration
+
+	// Mode is the stream mode the event was emitted for
+	Mode StreamMode
+
+	// Namespace is the path of subgraph nodes the event comes from, e.g. "parent" or "outer:inner".
+	// It is empty for events of the streamed graph itself.
+	Namespace string
+}
+
+// Path returns the node name prefixed with the namespace, e.g. "parent:child_node".
+func (e StreamEvent[S]) Path() string {
+	if e.Namespace == "" {
+		return e.NodeName
+	}
+	return e.Namespace + ":" + e.NodeName
 }
 
 // listenerWrapper wraps a listener with a unique ID for comparison
@@ -256,17 +274,18 @@ func NewListenableStateGraph[S any]() *ListenableStateGraph[S] {
 }
 
 // AddNode adds a node with listener capabilities
-func (g *ListenableStateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error)) *ListenableNode[S] {
+func (g *ListenableStateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error), opts ...NodeOption) *ListenableNode[S] {
 	node := TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
+		Options:     newNodeOptions(opts),
 	}
 
 	listenableNode := NewListenableNode(node)
 
 	// Add to both the base graph and our listenable nodes map
-	g.StateGraph.AddNode(name, description, fn)
+	g.StateGraph.AddNode(name, description, fn, opts...)
 	g.listenableNodes[name] = listenableNode
 
 	return listenableNode
@@ -377,8 +396,10 @@ func (lr *ListenableRunnable[S]) Stream(ctx context.Context, initialState S) <-c
 			State:     initialState,
 		}
 
-		// Execute the graph
-		_, err := lr.runnable.Invoke(ctx, initialState)
+		// Execute the graph, forwarding tokens streamed by models inside nodes
+		// as well as custom and subgraph events
+		streamCtx := withStreamSink(WithMessageHandler(ctx, streamListener.OnMessageChunk), streamListener)
+		_, err := lr.runnable.Invoke(streamCtx, initialState)
 
 		// Send chain end event
 		eventChan <- StreamEvent[S]{
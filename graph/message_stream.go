@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// MessageChunk is a chunk of an LLM message streamed by a model called inside a graph node.
+type MessageChunk struct {
+	// NodeName is the node that called the model
+	NodeName string
+
+	// Namespace is the path of subgraph nodes the node runs in, e.g. "parent"; empty outside of subgraphs
+	Namespace string
+
+	// RunID identifies the graph invocation that ran the node
+	RunID string
+
+	// MessageID identifies the message; all chunks of one model call share it
+	MessageID string
+
+	// Content is the text of the chunk
+	Content string
+}
+
+// MessageHandler receives the message chunks streamed by models inside graph nodes.
+type MessageHandler func(ctx context.Context, chunk MessageChunk)
+
+type messageHandlerKey struct{}
+
+type nodeNameKey struct{}
+
+type runIDKey struct{}
+
+// WithMessageHandler returns a context that delivers the chunks streamed by models inside
+// graph nodes to handler. Handlers already attached to ctx keep receiving chunks.
+//
+// Example:
+//
+//	ctx = graph.WithMessageHandler(ctx, func(ctx context.Context, chunk graph.MessageChunk) {
+//	    fmt.Print(chunk.Content)
+//	})
+//	result, err := agent.Invoke(ctx, input)
+func WithMessageHandler(ctx context.Context, handler MessageHandler) context.Context {
+	if parent, ok := ctx.Value(messageHandlerKey{}).(MessageHandler); ok {
+		next := handler
+		handler = func(ctx context.Context, chunk MessageChunk) {
+			parent(ctx, chunk)
+			next(ctx, chunk)
+		}
+	}
+	return context.WithValue(ctx, messageHandlerKey{}, handler)
+}
+
+// StreamingFunc returns a function for llms.WithStreamingFunc that forwards the chunks of one
+// model call to the message handlers of ctx, tagged with the current node and run.
+// It returns nil if ctx has no message handler.
+func StreamingFunc(ctx context.Context) func(ctx context.Context, chunk []byte) error {
+	handler, ok := ctx.Value(messageHandlerKey{}).(MessageHandler)
+	if !ok {
+		return nil
+	}
+
+	nodeName, _ := ctx.Value(nodeNameKey{}).(string)
+	runID, _ := ctx.Value(runIDKey{}).(string)
+	namespace := streamNamespace(ctx)
+	messageID := uuid.New().String()
+
+	return func(_ context.Context, chunk []byte) error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		handler(ctx, MessageChunk{
+			NodeName:  nodeName,
+			Namespace: namespace,
+			RunID:     runID,
+			MessageID: messageID,
+			Content:   string(chunk),
+		})
+		return nil
+	}
+}
+
+// MessageStreamingOptions appends llms.WithStreamingFunc to opts when ctx has a message handler,
+// so that nodes stream the tokens of their model calls only when someone is listening.
+//
+// Example:
+//
+//	resp, err := model.GenerateContent(ctx, messages, graph.MessageStreamingOptions(ctx, llms.WithTools(tools))...)
+func MessageStreamingOptions(ctx context.Context, opts ...llms.CallOption) []llms.CallOption {
+	if fn := StreamingFunc(ctx); fn != nil {
+		return append(opts, llms.WithStreamingFunc(fn))
+	}
+	return opts
+}
+
+// withNodeName records the node being executed in the context.
+func withNodeName(ctx context.Context, nodeName string) context.Context {
+	return context.WithValue(ctx, nodeNameKey{}, nodeName)
+}
+
+// withRunID records the run ID of the graph invocation in the context.
+func withRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// streamingModel is a model that streams its response word by word.
+type streamingModel struct {
+	llms.Model
+	response string
+}
+
+func (m *streamingModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		for _, word := range strings.SplitAfter(m.response, " ") {
+			if err := opts.StreamingFunc(ctx, []byte(word)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: m.response}}}, nil
+}
+
+func newMessageStreamGraph(model llms.Model) *StreamingStateGraph[map[string]any] {
+	g := NewStreamingStateGraph[map[string]any]()
+	g.AddNode("chat", "chat", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		resp, err := model.GenerateContent(ctx, nil, MessageStreamingOptions(ctx)...)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"answer": resp.Choices[0].Content}, nil
+	})
+	g.SetEntryPoint("chat")
+	g.AddEdge("chat", END)
+	return g
+}
+
+func TestStreamModeMessagesEmitsTokens(t *testing.T) {
+	g := newMessageStreamGraph(&streamingModel{response: "tokens from the model"})
+	g.SetStreamConfig(StreamConfig{
+		BufferSize: 100,
+		Mode:       StreamModeMessages,
+	})
+
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+
+	res := runnable.Stream(context.Background(), map[string]any{})
+
+	var tokens []StreamEvent[map[string]any]
+	for event := range res.Events {
+		if event.Event == EventToken {
+			tokens = append(tokens, event)
+		}
+	}
+	require.NoError(t, <-res.Errors)
+
+	require.Len(t, tokens, 4)
+	var content string
+	for _, event := range tokens {
+		assert.Equal(t, "chat", event.NodeName)
+		assert.NotEmpty(t, event.Metadata["run_id"])
+		assert.Equal(t, tokens[0].Metadata["message_id"], event.Metadata["message_id"])
+		content += event.Metadata["chunk"].(string)
+	}
+	assert.Equal(t, "tokens from the model", content)
+}
+
+func TestMessageHandlerOnStateRunnable(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	model := &streamingModel{response: "one two"}
+	g.AddNode("first", "first", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		_, err := model.GenerateContent(ctx, nil, MessageStreamingOptions(ctx)...)
+		return state, err
+	})
+	g.AddNode("second", "second", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		_, err := model.GenerateContent(ctx, nil, MessageStreamingOptions(ctx)...)
+		return state, err
+	})
+	g.SetEntryPoint("first")
+	g.AddEdge("first", "second")
+	g.AddEdge("second", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	var chunks []MessageChunk
+	ctx := WithMessageHandler(context.Background(), func(ctx context.Context, chunk MessageChunk) {
+		chunks = append(chunks, chunk)
+	})
+	_, err = runnable.Invoke(ctx, map[string]any{})
+	require.NoError(t, err)
+
+	require.Len(t, chunks, 4)
+	assert.Equal(t, "first", chunks[0].NodeName)
+	assert.Equal(t, "second", chunks[2].NodeName)
+	assert.Equal(t, chunks[0].RunID, chunks[2].RunID)
+	assert.Equal(t, chunks[0].MessageID, chunks[1].MessageID)
+	assert.NotEqual(t, chunks[0].MessageID, chunks[2].MessageID)
+}
+
+func TestMessageStreamingOptionsWithoutHandler(t *testing.T) {
+	assert.Nil(t, StreamingFunc(context.Background()))
+	assert.Len(t, MessageStreamingOptions(context.Background(), llms.WithMaxTokens(10)), 1)
+}
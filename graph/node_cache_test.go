@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cachedState struct {
+	Query     string
+	Embedding []float64
+}
+
+func newCachedGraph(calls *atomic.Int32, policy *CachePolicy) *StateGraph[cachedState] {
+	g := NewStateGraph[cachedState]()
+	g.AddNode("embed", "embed", func(ctx context.Context, state cachedState) (cachedState, error) {
+		calls.Add(1)
+		state.Embedding = []float64{float64(len(state.Query)), 0.5}
+		return state, nil
+	}, WithCachePolicy(policy))
+	g.SetEntryPoint("embed")
+	g.AddEdge("embed", END)
+	return g
+}
+
+func TestNodeCacheHit(t *testing.T) {
+	var calls atomic.Int32
+	runnable, err := newCachedGraph(&calls, &CachePolicy{
+		Key: func(state any) (string, error) { return state.(cachedState).Query, nil },
+	}).Compile()
+	require.NoError(t, err)
+
+	tracer := NewTracer()
+	var hits []any
+	tracer.AddHook(TraceHookFunc(func(ctx context.Context, span *TraceSpan) {
+		if span.Event == TraceEventNodeEnd {
+			hits = append(hits, span.Metadata["cache_hit"])
+		}
+	}))
+	runnable.SetTracer(tracer)
+
+	ctx := context.Background()
+	first, err := runnable.Invoke(ctx, cachedState{Query: "hello"})
+	require.NoError(t, err)
+	second, err := runnable.Invoke(ctx, cachedState{Query: "hello"})
+	require.NoError(t, err)
+	_, err = runnable.Invoke(ctx, cachedState{Query: "other"})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, first, second)
+	assert.Equal(t, []any{false, true, false}, hits)
+}
+
+func TestNodeCacheTTL(t *testing.T) {
+	var calls atomic.Int32
+	runnable, err := newCachedGraph(&calls, &CachePolicy{TTL: 20 * time.Millisecond}).Compile()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for range 2 {
+		_, err := runnable.Invoke(ctx, cachedState{Query: "q"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = runnable.Invoke(ctx, cachedState{Query: "q"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestNodeCacheCustomStore(t *testing.T) {
+	cache, err := NewFileNodeCache(t.TempDir())
+	require.NoError(t, err)
+
+	var calls atomic.Int32
+	policy := &CachePolicy{Cache: cache}
+
+	// Separate graphs share results through the same cache
+	for range 2 {
+		runnable, err := newCachedGraph(&calls, policy).Compile()
+		require.NoError(t, err)
+		res, err := runnable.Invoke(context.Background(), cachedState{Query: "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{3, 0.5}, res.Embedding)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestNodeCacheHit_MapStateTypes(t *testing.T) {
+	fileCache, err := NewFileNodeCache(t.TempDir())
+	require.NoError(t, err)
+
+	for name, cache := range map[string]NodeCache{"memory": NewMemoryNodeCache(), "file": fileCache} {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			g := NewStateGraph[map[string]any]()
+			g.AddNode("count", "count", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+				calls.Add(1)
+				return map[string]any{"count": 42, "ratio": float32(0.5), "tags": []string{"a"}, "meta": map[string]any{"n": int64(7)}}, nil
+			}, WithCachePolicy(&CachePolicy{Cache: cache}))
+			g.SetEntryPoint("count")
+			g.AddEdge("count", END)
+			g.SetSchema(NewMapSchema())
+
+			runnable, err := g.Compile()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			first, err := runnable.Invoke(ctx, map[string]any{"query": "q"})
+			require.NoError(t, err)
+			second, err := runnable.Invoke(ctx, map[string]any{"query": "q"})
+			require.NoError(t, err)
+
+			assert.Equal(t, int32(1), calls.Load())
+			assert.Equal(t, first, second)
+			assert.IsType(t, 0, second["count"])
+			assert.IsType(t, float32(0), second["ratio"])
+			assert.IsType(t, []string{}, second["tags"])
+			assert.IsType(t, int64(0), second["meta"].(map[string]any)["n"])
+		})
+	}
+}
+
+func TestNodeCacheKeyError(t *testing.T) {
+	var calls atomic.Int32
+	runnable, err := newCachedGraph(&calls, &CachePolicy{
+		Key: func(state any) (string, error) { return "", errors.New("no key") },
+	}).Compile()
+	require.NoError(t, err)
+
+	// Without a key the node runs uncached
+	ctx := context.Background()
+	for range 2 {
+		res, err := runnable.Invoke(ctx, cachedState{Query: "q"})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1, 0.5}, res.Embedding)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestNodeCacheKeyMarshalError(t *testing.T) {
+	var calls atomic.Int32
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("count", "count", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		calls.Add(1)
+		return map[string]any{"count": calls.Load()}, nil
+	}, WithCachePolicy(&CachePolicy{}))
+	g.SetEntryPoint("count")
+	g.AddEdge("count", END)
+	g.SetSchema(NewMapSchema())
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	// Channels cannot be encoded as JSON, the default key
+	ctx := context.Background()
+	for range 2 {
+		_, err := runnable.Invoke(ctx, map[string]any{"done": make(chan struct{})})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+}
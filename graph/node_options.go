@@ -0,0 +1,88 @@
+package graph
+
+import "time"
+
+// NodeOptions holds optional per-node settings declared with AddNode.
+type NodeOptions struct {
+	// Timeout bounds a single execution of the node. Zero means no timeout.
+	Timeout time.Duration
+
+	// RetryPolicy overrides the graph-wide retry policy for the node.
+	RetryPolicy *RetryPolicy
+
+	// CachePolicy enables result caching for the node.
+	CachePolicy *CachePolicy
+
+	// Metadata holds information attached to the node by the code that declared it, such as the
+	// implementation a graph loader built it from. It does not affect execution.
+	Metadata map[string]any
+}
+
+// NodeOption configures a node when it is added to a graph.
+//
+// Example:
+//
+//	g.AddNode("search", "Web search", searchFn, graph.WithNodeTimeout(10*time.Second))
+type NodeOption func(*NodeOptions)
+
+// WithNodeTimeout sets a deadline for each execution of the node.
+// The node's context is cancelled when the deadline passes and the node fails with a *NodeTimeoutError.
+func WithNodeTimeout(timeout time.Duration) NodeOption {
+	return func(o *NodeOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithRetryPolicy sets a retry policy for the node, overriding the one set with SetRetryPolicy.
+// With a per-node timeout, each attempt gets its own deadline.
+//
+// Example:
+//
+//	g.AddNode("llm", "Call the model", callModel, graph.WithRetryPolicy(&graph.RetryPolicy{
+//	    MaxRetries:      3,
+//	    BackoffStrategy: graph.ExponentialBackoff,
+//	    Retryable:       llms.IsRateLimitError,
+//	    MaxDelay:        30 * time.Second,
+//	    Jitter:          0.2,
+//	}))
+func WithRetryPolicy(policy *RetryPolicy) NodeOption {
+	return func(o *NodeOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithCachePolicy caches the results of the node, keyed by its input state.
+// Use it for nodes that are pure functions of (part of) the state, such as embeddings or classification.
+//
+// Example:
+//
+//	g.AddNode("embed", "Embed the query", embedFn, graph.WithCachePolicy(&graph.CachePolicy{
+//	    Key: func(state any) (string, error) { return state.(MyState).Query, nil },
+//	    TTL: time.Hour,
+//	}))
+func WithCachePolicy(policy *CachePolicy) NodeOption {
+	return func(o *NodeOptions) {
+		o.CachePolicy = policy
+	}
+}
+
+// WithNodeMetadata attaches a metadata value to the node, readable from its Options.
+func WithNodeMetadata(key string, value any) NodeOption {
+	return func(o *NodeOptions) {
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]any)
+		}
+		o.Metadata[key] = value
+	}
+}
+
+// newNodeOptions applies opts to a zero NodeOptions.
+func newNodeOptions(opts []NodeOption) NodeOptions {
+	var options NodeOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
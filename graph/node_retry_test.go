@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+var errTransient = errors.New("transient")
+
+type rateLimitError struct {
+	after time.Duration
+}
+
+func (e *rateLimitError) Error() string             { return "rate limited" }
+func (e *rateLimitError) RetryAfter() time.Duration { return e.after }
+
+type retryRecorder struct {
+	NoOpCallbackHandler
+	mu       sync.Mutex
+	attempts []int
+	delays   []time.Duration
+}
+
+func (r *retryRecorder) OnNodeRetry(ctx context.Context, nodeName string, attempt int, err error, delay time.Duration, runID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	r.delays = append(r.delays, delay)
+}
+
+func TestNodeRetryPolicy(t *testing.T) {
+	calls := 0
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("flaky", "flaky", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		calls++
+		if calls < 3 {
+			return nil, fmt.Errorf("call %d: %w", calls, errTransient)
+		}
+		return map[string]any{"calls": calls}, nil
+	}, WithRetryPolicy(&RetryPolicy{
+		MaxRetries:   3,
+		Retryable:    func(err error) bool { return errors.Is(err, errTransient) },
+		InitialDelay: time.Millisecond,
+	}))
+	g.SetEntryPoint("flaky")
+	g.AddEdge("flaky", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	tracer := NewTracer()
+	var retrySpans []*TraceSpan
+	tracer.AddHook(TraceHookFunc(func(ctx context.Context, span *TraceSpan) {
+		if span.Event == TraceEventNodeRetry && !span.EndTime.IsZero() {
+			retrySpans = append(retrySpans, span)
+		}
+	}))
+	runnable.SetTracer(tracer)
+
+	recorder := &retryRecorder{}
+	res, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{
+		Callbacks: []CallbackHandler{recorder},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res["calls"])
+
+	assert.Equal(t, []int{1, 2}, recorder.attempts)
+	require.Len(t, retrySpans, 2)
+	assert.Equal(t, "flaky", retrySpans[0].NodeName)
+	assert.Equal(t, 1, retrySpans[0].Metadata["attempt"])
+	assert.ErrorIs(t, retrySpans[0].Error, errTransient)
+}
+
+func TestNodeRetryPolicyOverridesGraphPolicy(t *testing.T) {
+	calls := 0
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("strict", "strict", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		calls++
+		return nil, errors.New("permanent failure")
+	}, WithRetryPolicy(&RetryPolicy{
+		MaxRetries: 5,
+		Retryable:  func(err error) bool { return errors.Is(err, errTransient) },
+	}))
+	g.SetEntryPoint("strict")
+	g.AddEdge("strict", END)
+	g.SetRetryPolicy(&RetryPolicy{MaxRetries: 5, RetryableErrors: []string{"permanent"}})
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{
+		BackoffStrategy: ExponentialBackoff,
+		InitialDelay:    100 * time.Millisecond,
+		MaxDelay:        time.Second,
+	}
+	assert.Equal(t, 100*time.Millisecond, policy.delay(0, nil))
+	assert.Equal(t, 400*time.Millisecond, policy.delay(2, nil))
+	assert.Equal(t, time.Second, policy.delay(10, nil))
+
+	// Retry-After hints win over a shorter backoff but stay under the cap
+	assert.Equal(t, 700*time.Millisecond, policy.delay(0, &rateLimitError{after: 700 * time.Millisecond}))
+	assert.Equal(t, time.Second, policy.delay(0, fmt.Errorf("wrapped: %w", &rateLimitError{after: time.Minute})))
+	assert.Equal(t, 400*time.Millisecond, policy.delay(2, &rateLimitError{after: 50 * time.Millisecond}))
+
+	jittered := &RetryPolicy{InitialDelay: 100 * time.Millisecond, Jitter: 0.5}
+	for range 20 {
+		d := jittered.delay(0, nil)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 150*time.Millisecond)
+	}
+
+	var nilPolicy *RetryPolicy
+	assert.Zero(t, nilPolicy.delay(3, nil))
+	assert.False(t, nilPolicy.shouldRetry(errTransient))
+}
+
+func TestRetryAfterHint(t *testing.T) {
+	_, ok := RetryAfterHint(errors.New("plain"))
+	assert.False(t, ok)
+
+	d, ok := RetryAfterHint(&rateLimitError{after: 2 * time.Second})
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	llmErr := llms.NewError(llms.ErrCodeRateLimit, "openai", "too many requests").WithDetail("retry_after", 3)
+	d, ok = RetryAfterHint(fmt.Errorf("call model: %w", llmErr))
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	llmErr = llms.NewError(llms.ErrCodeRateLimit, "openai", "too many requests").WithDetail("retry_after", "1.5")
+	d, ok = RetryAfterHint(llmErr)
+	assert.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, d)
+}
+
+func TestValidateNodeRetryPolicy(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode, WithRetryPolicy(&RetryPolicy{MaxRetries: 2}))
+	g.SetEntryPoint("a")
+	g.AddEdge("a", END)
+
+	report := g.Validate()
+	require.Len(t, report.Warnings, 1)
+	assert.Equal(t, CodeInvalidRetryPolicy, report.Warnings[0].Code)
+	assert.Equal(t, []string{"a"}, report.Warnings[0].Nodes)
+}
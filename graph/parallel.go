@@ -31,6 +31,7 @@ func (pn *ParallelNode[S]) Execute(ctx context.Context, state S) ([]S, error) {
 
 	results := make(chan result, len(pn.nodes))
 	var wg sync.WaitGroup
+	tracer := tracerFromContext(ctx)
 
 	// Execute all nodes in parallel
 	for i, node := range pn.nodes {
@@ -48,7 +49,19 @@ func (pn *ParallelNode[S]) Execute(ctx context.Context, state S) ([]S, error) {
 				}
 			}()
 
-			value, err := n.Function(ctx, state)
+			// Trace each branch as a child of the span of the parallel node
+			nodeCtx := ctx
+			var span *TraceSpan
+			if tracer != nil {
+				span = tracer.StartSpan(ctx, TraceEventNodeStart, n.Name)
+				span.Metadata["parallel_group"] = pn.name
+				nodeCtx = tracer.ContextWithSpan(ctx, span)
+			}
+
+			value, err := n.Function(nodeCtx, state)
+			if span != nil {
+				tracer.EndSpan(nodeCtx, span, value, err)
+			}
 			results <- result{
 				index: idx,
 				value: value,
@@ -110,9 +123,12 @@ func (g *StateGraph[S]) AddParallelNodes(
 		}
 		return merger(results), nil
 	})
+	g.setNodeStructure(groupName, nodeStructure{kind: NodeKindParallel, branches: sortedKeys(nodes)})
 }
 
-// MapReduceNode executes nodes in parallel and reduces results
+// MapReduceNode executes nodes in parallel and reduces results.
+// The set of map nodes is fixed when the graph is built; use Send with
+// AddSendEdge to fan out over inputs whose size is only known at runtime.
 type MapReduceNode[S any] struct {
 	name     string
 	mapNodes []TypedNode[S]
@@ -170,6 +186,7 @@ func (g *StateGraph[S]) AddMapReduceNode(
 	// Create and add map-reduce node
 	mrNode := NewMapReduceNode(name, reducer, mapNodes...)
 	g.AddNode(name, "Map-reduce node: "+name, mrNode.Execute)
+	g.setNodeStructure(name, nodeStructure{kind: NodeKindMapReduce, branches: sortedKeys(mapFunctions)})
 }
 
 // FanOutFanIn creates a fan-out/fan-in pattern.
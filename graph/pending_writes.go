@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// PendingWrite is an alias for store.PendingWrite
+type PendingWrite = store.PendingWrite
+
+// pendingWritesKey is the context key of the pending writes to replay on resume
+type pendingWritesKey struct{}
+
+// pendingWritesReplay carries the pending writes of a checkpoint into the first superstep of a resumed run.
+type pendingWritesReplay struct {
+	checkpointID string
+	writes       []store.PendingWrite
+	// used is set once the first superstep has replayed the writes
+	used bool
+}
+
+// withPendingWrites attaches the pending writes of a checkpoint to the context.
+func withPendingWrites(ctx context.Context, replay *pendingWritesReplay) context.Context {
+	return context.WithValue(ctx, pendingWritesKey{}, replay)
+}
+
+// takePendingWrites returns the pending writes attached to the context and a context without them,
+// so that nested graphs do not replay the writes of their parent.
+func takePendingWrites(ctx context.Context) (*pendingWritesReplay, context.Context) {
+	replay, _ := ctx.Value(pendingWritesKey{}).(*pendingWritesReplay)
+	if replay == nil {
+		return nil, ctx
+	}
+	return replay, context.WithValue(ctx, pendingWritesKey{}, (*pendingWritesReplay)(nil))
+}
+
+// taskID identifies a regular task of a superstep by its position and node name.
+func taskID(index int, node string) string {
+	return fmt.Sprintf("%d:%s", index, node)
+}
+
+// storedValue converts a checkpoint state or a recorded write loaded from a store into the state type.
+// Stores that serialize state return generic JSON values, which are decoded through JSON.
+func storedValue[S any](value any) (S, error) {
+	if v, ok := value.(S); ok {
+		return v, nil
+	}
+	var result S
+	data, err := json.Marshal(value)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// executeTasks runs the tasks of a superstep. Regular tasks (the first regularCount tasks)
+// with a recorded pending write are not executed again; their recorded output is used instead.
+func (r *StateRunnable[S]) executeTasks(ctx context.Context, nodes []string, states []S, regularCount int, replay *pendingWritesReplay, config *Config, runID string) ([]S, []error, []string) {
+	if replay == nil || len(replay.writes) == 0 {
+		return r.executeNodesParallel(ctx, nodes, states, config, runID)
+	}
+
+	recorded := make(map[string]any, len(replay.writes))
+	for _, w := range replay.writes {
+		recorded[w.TaskID] = w.Value
+	}
+
+	results := make([]S, len(nodes))
+	errorsList := make([]error, len(nodes))
+	var runNodes []string
+	var runStates []S
+	var runIndexes []int
+	for i, node := range nodes {
+		if i < regularCount {
+			if value, ok := recorded[taskID(i, node)]; ok {
+				if res, err := storedValue[S](value); err == nil {
+					results[i] = res
+					continue
+				}
+			}
+		}
+		runNodes = append(runNodes, node)
+		runStates = append(runStates, states[i])
+		runIndexes = append(runIndexes, i)
+	}
+	replay.used = true
+
+	runResults, runErrors, running := r.executeNodesParallel(ctx, runNodes, runStates, config, runID)
+	for j, i := range runIndexes {
+		results[i] = runResults[j]
+		errorsList[i] = runErrors[j]
+	}
+	return results, errorsList, running
+}
+
+// stepFailureWrites returns the outputs of the regular tasks that succeeded in a failed superstep.
+func stepFailureWrites[S any](nodes []string, results []S, errorsList []error, regularCount int) []store.PendingWrite {
+	var writes []store.PendingWrite
+	now := time.Now()
+	for i := 0; i < regularCount && i < len(nodes); i++ {
+		if errorsList[i] != nil {
+			continue
+		}
+		// Commands carry routing rather than state and cannot be replayed
+		if _, isCmd := any(results[i]).(*Command); isCmd {
+			continue
+		}
+		writes = append(writes, store.PendingWrite{
+			TaskID:    taskID(i, nodes[i]),
+			Node:      nodes[i],
+			Value:     results[i],
+			Timestamp: now,
+		})
+	}
+	return writes
+}
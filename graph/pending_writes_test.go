@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestPendingWritesResumeReplaysSuccessfulTasks(t *testing.T) {
+	fileStore, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]store.CheckpointStore{
+		"memory": NewMemoryCheckpointStore(),
+		"file":   fileStore,
+	}
+
+	for name, cpStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			var aCalls, bCalls, cCalls atomic.Int32
+			var failC atomic.Bool
+			failC.Store(true)
+
+			g := NewCheckpointableStateGraphWithConfig[map[string]any](CheckpointConfig{
+				Store:    cpStore,
+				AutoSave: true,
+			})
+			g.AddNode("start", "start", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+				return map[string]any{"started": true}, nil
+			})
+			g.AddNode("a", "a", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+				aCalls.Add(1)
+				return map[string]any{"a": "done"}, nil
+			})
+			g.AddNode("b", "b", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+				bCalls.Add(1)
+				return map[string]any{"b": "done"}, nil
+			})
+			g.AddNode("c", "c", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+				cCalls.Add(1)
+				if failC.Load() {
+					return nil, errors.New("upstream unavailable")
+				}
+				return map[string]any{"c": "done"}, nil
+			})
+			g.SetEntryPoint("start")
+			g.AddEdge("start", "a")
+			g.AddEdge("start", "b")
+			g.AddEdge("start", "c")
+			g.AddEdge("a", END)
+			g.AddEdge("b", END)
+			g.AddEdge("c", END)
+			g.SetSchema(NewMapSchema())
+
+			runnable, err := g.CompileCheckpointable()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			_, err = runnable.InvokeWithConfig(ctx, map[string]any{}, WithThreadID("writes-"+name))
+			require.ErrorContains(t, err, "upstream unavailable")
+
+			// The failed superstep is checkpointed with the state it started from
+			snapshot, err := runnable.GetState(ctx, WithThreadID("writes-"+name))
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, snapshot.Next)
+			assert.Equal(t, "step_failed", snapshot.Metadata["event"])
+			assert.NotContains(t, snapshot.Values.(map[string]any), "a")
+
+			checkpointID := snapshot.Config.Configurable["checkpoint_id"].(string)
+			writes, err := cpStore.(store.PendingWriteStore).GetWrites(ctx, checkpointID)
+			require.NoError(t, err)
+			require.Len(t, writes, 2)
+			assert.ElementsMatch(t, []string{"a", "b"}, []string{writes[0].Node, writes[1].Node})
+
+			// Resuming reruns only the failed task
+			failC.Store(false)
+			res, err := runnable.InvokeWithConfig(ctx, map[string]any{}, WithThreadID("writes-"+name))
+			require.NoError(t, err)
+			assert.Equal(t, "done", res["a"])
+			assert.Equal(t, "done", res["b"])
+			assert.Equal(t, "done", res["c"])
+			assert.Equal(t, true, res["started"])
+
+			assert.Equal(t, int32(1), aCalls.Load())
+			assert.Equal(t, int32(1), bCalls.Load())
+			assert.Equal(t, int32(2), cCalls.Load())
+
+			// Replayed writes are removed once applied
+			writes, err = cpStore.(store.PendingWriteStore).GetWrites(ctx, checkpointID)
+			require.NoError(t, err)
+			assert.Empty(t, writes)
+		})
+	}
+}
+
+func TestPendingWritesNotRecordedWithoutSuccessfulTasks(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("fail", "fail", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, errors.New("boom")
+	})
+	g.SetEntryPoint("fail")
+	g.AddEdge("fail", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	recorder := &stepFailureRecorder{}
+	_, err = runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{
+		Callbacks: []CallbackHandler{recorder},
+	})
+	require.Error(t, err)
+	assert.Zero(t, recorder.calls)
+}
+
+type stepFailureRecorder struct {
+	NoOpCallbackHandler
+	calls int
+}
+
+func (r *stepFailureRecorder) OnStepFailure(ctx context.Context, nodes []string, state any, writes []PendingWrite) {
+	r.calls++
+}
+
+func TestPendingWriteValue(t *testing.T) {
+	type payload struct {
+		Count int `json:"count"`
+	}
+
+	v, err := storedValue[payload](payload{Count: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.Count)
+
+	// Values decoded from JSON stores are converted back into the state type
+	v, err = storedValue[payload](map[string]any{"count": float64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, v.Count)
+}
@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoopGraph builds a graph that loops between two nodes until count reaches stop.
+func newLoopGraph(stop int) *StateGraph[int] {
+	g := NewStateGraph[int]()
+	g.AddNode("agent", "agent", func(ctx context.Context, state int) (int, error) {
+		return state + 1, nil
+	})
+	g.AddNode("tools", "tools", func(ctx context.Context, state int) (int, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("agent")
+	g.AddConditionalEdge("agent", func(ctx context.Context, state int) string {
+		if state >= stop {
+			return END
+		}
+		return "tools"
+	})
+	g.AddEdge("tools", "agent")
+	return g
+}
+
+func TestRecursionLimitDefault(t *testing.T) {
+	runnable, err := newLoopGraph(1 << 30).Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), 0)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrGraphRecursion))
+
+	var recErr *GraphRecursionError
+	require.True(t, errors.As(err, &recErr))
+	assert.Equal(t, DefaultRecursionLimit, recErr.Limit)
+	assert.Equal(t, DefaultRecursionLimit, recErr.Steps)
+	assert.NotEmpty(t, recErr.Nodes)
+}
+
+func TestRecursionLimitConfig(t *testing.T) {
+	runnable, err := newLoopGraph(5).Compile()
+	require.NoError(t, err)
+
+	// 5 agent steps + 4 tools steps
+	res, err := runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 9})
+	require.NoError(t, err)
+	assert.Equal(t, 5, res)
+
+	_, err = runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 8})
+	var recErr *GraphRecursionError
+	require.True(t, errors.As(err, &recErr))
+	assert.Equal(t, 8, recErr.Limit)
+	assert.Equal(t, []string{"agent"}, recErr.Nodes)
+}
+
+func TestRecursionLimitSubgraphSharesBudget(t *testing.T) {
+	parent := NewStateGraph[int]()
+	require.NoError(t, AddSubgraph(parent, "child", newLoopGraph(5),
+		func(s int) int { return s },
+		func(s int) int { return s }))
+	parent.SetEntryPoint("child")
+	parent.AddEdge("child", END)
+
+	runnable, err := parent.Compile()
+	require.NoError(t, err)
+
+	// The parent step plus 9 child steps fit in a budget of 10
+	res, err := runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 5, res)
+
+	_, err = runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 9})
+	assert.True(t, errors.Is(err, ErrGraphRecursion))
+}
+
+func TestRecursionLimitListenable(t *testing.T) {
+	g := NewListenableStateGraph[int]()
+	g.AddNode("loop", "loop", func(ctx context.Context, state int) (int, error) {
+		return state + 1, nil
+	})
+	g.SetEntryPoint("loop")
+	g.AddEdge("loop", "loop")
+
+	runnable, err := g.CompileListenable()
+	require.NoError(t, err)
+
+	res, err := runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 3})
+	assert.True(t, errors.Is(err, ErrGraphRecursion))
+	assert.Equal(t, 0, res)
+}
@@ -2,10 +2,15 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 // RetryConfig configures retry behavior for nodes
@@ -108,6 +113,129 @@ func (g *StateGraph[S]) AddNodeWithRetry(
 	g.AddNode(name, description, retryNode.Execute)
 }
 
+// RetryAfterError is implemented by errors that carry a server-provided retry hint,
+// such as the Retry-After header of a rate-limit response.
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// RetryAfterHint returns the retry hint carried by err, if any.
+// It recognizes errors implementing RetryAfterError anywhere in the chain, and
+// *llms.Error values with a "retry_after" detail given as a time.Duration,
+// a number of seconds or a duration string.
+func RetryAfterHint(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var hinted RetryAfterError
+	if errors.As(err, &hinted) {
+		if d := hinted.RetryAfter(); d > 0 {
+			return d, true
+		}
+	}
+
+	var llmErr *llms.Error
+	if errors.As(err, &llmErr) {
+		switch v := llmErr.Details["retry_after"].(type) {
+		case time.Duration:
+			return v, v > 0
+		case int:
+			return time.Duration(v) * time.Second, v > 0
+		case float64:
+			return time.Duration(v * float64(time.Second)), v > 0
+		case string:
+			if d, err := time.ParseDuration(v); err == nil {
+				return d, d > 0
+			}
+			if secs, err := strconv.ParseFloat(v, 64); err == nil {
+				return time.Duration(secs * float64(time.Second)), secs > 0
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// shouldRetry reports whether err should be retried under the policy.
+func (p *RetryPolicy) shouldRetry(err error) bool {
+	if p == nil {
+		return false
+	}
+
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	errorStr := err.Error()
+	for _, retryablePattern := range p.RetryableErrors {
+		if strings.Contains(errorStr, retryablePattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns how long to wait before retrying after the given attempt (starting at 0) failed with err.
+// A Retry-After hint on err is honored when it is longer than the backoff, and MaxDelay caps the result.
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	if p == nil {
+		return 0
+	}
+
+	baseDelay := p.InitialDelay
+	if baseDelay <= 0 {
+		baseDelay = time.Second // Default 1 second base delay
+	}
+
+	var delay time.Duration
+	switch p.BackoffStrategy {
+	case ExponentialBackoff:
+		// Exponential backoff: 1s, 2s, 4s, 8s, ...
+		delay = baseDelay * time.Duration(1<<attempt)
+	case LinearBackoff:
+		// Linear backoff: 1s, 2s, 3s, 4s, ...
+		delay = baseDelay * time.Duration(attempt+1)
+	default:
+		delay = baseDelay
+	}
+
+	if p.Jitter > 0 {
+		//nolint:gosec // Using weak RNG for jitter is acceptable, not security-critical
+		delay += time.Duration(float64(delay) * p.Jitter * (2*rand.Float64() - 1))
+	}
+
+	if hint, ok := RetryAfterHint(err); ok && hint > delay {
+		delay = hint
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return max(delay, 0)
+}
+
+// NodeTimeoutError is returned when a single node execution exceeds its timeout.
+// It matches context.DeadlineExceeded with errors.Is.
+type NodeTimeoutError struct {
+	// Node is the name of the node that timed out
+	Node string
+	// Timeout is the configured node timeout
+	Timeout time.Duration
+}
+
+func (e *NodeTimeoutError) Error() string {
+	return fmt.Sprintf("node %s timed out after %v", e.Node, e.Timeout)
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *NodeTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
 // TimeoutNode wraps a node with timeout logic
 type TimeoutNode[S any] struct {
 	node    TypedNode[S]
@@ -147,7 +275,11 @@ func (tn *TimeoutNode[S]) Execute(ctx context.Context, state S) (S, error) {
 		return res.value, res.err
 	case <-timeoutCtx.Done():
 		var zero S
-		return zero, fmt.Errorf("node %s timed out after %v", tn.node.Name, tn.timeout)
+		if err := ctx.Err(); err != nil {
+			// The caller's context ended first, report that instead of the node timeout
+			return zero, err
+		}
+		return zero, &NodeTimeoutError{Node: tn.node.Name, Timeout: tn.timeout}
 	}
 }
 
@@ -184,6 +316,20 @@ const (
 	CircuitHalfOpen
 )
 
+// String returns the name of the state
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("CircuitBreakerState(%d)", int(s))
+	}
+}
+
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker[S any] struct {
 	node            TypedNode[S]
@@ -214,7 +360,7 @@ func (cb *CircuitBreaker[S]) Execute(ctx context.Context, state S) (S, error) {
 	case CircuitOpen:
 		// Check if enough time has passed to try again
 		if time.Since(cb.lastFailureTime) > cb.config.Timeout {
-			cb.state = CircuitHalfOpen
+			cb.setState(ctx, CircuitHalfOpen)
 			cb.halfOpenCalls = 0
 		} else {
 			return zero, fmt.Errorf("circuit breaker open for %s", cb.node.Name)
@@ -222,7 +368,7 @@ func (cb *CircuitBreaker[S]) Execute(ctx context.Context, state S) (S, error) {
 	case CircuitHalfOpen:
 		// Check if we've made too many calls in half-open state
 		if cb.halfOpenCalls >= cb.config.HalfOpenMaxCalls {
-			cb.state = CircuitOpen
+			cb.setState(ctx, CircuitOpen)
 			return zero, fmt.Errorf("circuit breaker half-open limit reached for %s", cb.node.Name)
 		}
 		cb.halfOpenCalls++
@@ -238,7 +384,7 @@ func (cb *CircuitBreaker[S]) Execute(ctx context.Context, state S) (S, error) {
 		cb.lastFailureTime = time.Now()
 
 		if cb.failures >= cb.config.FailureThreshold {
-			cb.state = CircuitOpen
+			cb.setState(ctx, CircuitOpen)
 		}
 
 		return zero, fmt.Errorf("circuit breaker error in %s: %w", cb.node.Name, err)
@@ -249,12 +395,29 @@ func (cb *CircuitBreaker[S]) Execute(ctx context.Context, state S) (S, error) {
 	cb.failures = 0
 
 	if cb.state == CircuitHalfOpen && cb.successes >= cb.config.SuccessThreshold {
-		cb.state = CircuitClosed
+		cb.setState(ctx, CircuitClosed)
 	}
 
 	return result, nil
 }
 
+// setState moves the circuit breaker to state and notifies the CircuitBreakerCallbackHandlers
+// of the config in ctx about the transition.
+func (cb *CircuitBreaker[S]) setState(ctx context.Context, state CircuitBreakerState) {
+	from := cb.state
+	cb.state = state
+	if from == state {
+		return
+	}
+	if config := GetConfig(ctx); config != nil {
+		for _, handler := range config.Callbacks {
+			if ch, ok := handler.(CircuitBreakerCallbackHandler); ok {
+				ch.OnCircuitBreakerStateChange(ctx, cb.node.Name, from, state)
+			}
+		}
+	}
+}
+
 // AddNodeWithCircuitBreaker adds a node with circuit breaker
 func (g *StateGraph[S]) AddNodeWithCircuitBreaker(
 	name string,
@@ -307,6 +470,13 @@ func (rl *RateLimiter[S]) Execute(ctx context.Context, state S) (S, error) {
 		// Calculate when we can make the next call
 		oldestCall := rl.calls[0]
 		waitTime := rl.window - now.Sub(oldestCall)
+		if config := GetConfig(ctx); config != nil {
+			for _, handler := range config.Callbacks {
+				if rh, ok := handler.(RateLimitCallbackHandler); ok {
+					rh.OnRateLimited(ctx, rl.node.Name, waitTime)
+				}
+			}
+		}
 		var zero S
 		return zero, fmt.Errorf("rate limit exceeded for %s, retry after %v", rl.node.Name, waitTime)
 	}
@@ -3,6 +3,8 @@ package graph_test
 import (
 	"context"
 	"errors"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -558,3 +560,112 @@ func TestExponentialBackoffRetry(t *testing.T) {
 		}
 	})
 }
+
+// resilienceRecorder records the circuit breaker, rate limit and checkpoint callbacks
+type resilienceRecorder struct {
+	graph.NoOpCallbackHandler
+	mu          sync.Mutex
+	transitions []string
+	waits       []time.Duration
+	saves       []string
+	ended       int
+}
+
+func (r *resilienceRecorder) OnCircuitBreakerStateChange(_ context.Context, nodeName string, from, to graph.CircuitBreakerState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, nodeName+":"+from.String()+"->"+to.String())
+}
+
+func (r *resilienceRecorder) OnRateLimited(_ context.Context, _ string, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waits = append(r.waits, wait)
+}
+
+func (r *resilienceRecorder) OnCheckpointSave(_ context.Context, store string, _ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.saves = append(r.saves, store)
+	}
+}
+
+func (r *resilienceRecorder) OnChainEnd(context.Context, map[string]any, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended++
+}
+
+func TestCircuitBreakerCallbacks(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph[map[string]any]()
+	fail := true
+	g.AddNodeWithCircuitBreaker("cb_node", "cb_node", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if fail {
+			return nil, errors.New("service unavailable")
+		}
+		return map[string]any{"value": successResult}, nil
+	}, graph.CircuitBreakerConfig{
+		FailureThreshold: 1,
+		SuccessThreshold: 1,
+		Timeout:          10 * time.Millisecond,
+		HalfOpenMaxCalls: 1,
+	})
+	g.AddEdge("cb_node", graph.END)
+	g.SetEntryPoint("cb_node")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	recorder := &resilienceRecorder{}
+	config := &graph.Config{Callbacks: []graph.CallbackHandler{recorder}}
+
+	_, _ = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+	_, _ = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+
+	time.Sleep(15 * time.Millisecond)
+	fail = false
+	if _, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, config); err != nil {
+		t.Fatalf("Expected success after circuit recovery: %v", err)
+	}
+
+	expected := []string{"cb_node:closed->open", "cb_node:open->half_open", "cb_node:half_open->closed"}
+	if !slices.Equal(recorder.transitions, expected) {
+		t.Errorf("Expected transitions %v, got %v", expected, recorder.transitions)
+	}
+}
+
+func TestRateLimiterCallbacks(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph[map[string]any]()
+	g.AddNodeWithRateLimit("rate_limited", "rate_limited", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"value": successResult}, nil
+	}, 1, time.Minute)
+	g.AddEdge("rate_limited", graph.END)
+	g.SetEntryPoint("rate_limited")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	recorder := &resilienceRecorder{}
+	config := &graph.Config{Callbacks: []graph.CallbackHandler{recorder}}
+
+	_, _ = runnable.InvokeWithConfig(context.Background(), map[string]any{}, config)
+	if _, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, config); err == nil {
+		t.Fatal("Expected rate limit error")
+	}
+
+	if len(recorder.waits) != 1 {
+		t.Fatalf("Expected 1 rate limited call, got %d", len(recorder.waits))
+	}
+	if recorder.waits[0] <= 0 || recorder.waits[0] > time.Minute {
+		t.Errorf("Expected a wait within the window, got %v", recorder.waits[0])
+	}
+}
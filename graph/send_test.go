@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSummarySchema() *MapSchema {
+	schema := NewMapSchema()
+	schema.RegisterReducer("summaries", AppendReducer)
+	return schema
+}
+
+func TestSendEdgeFanOut(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.SetSchema(newSummarySchema())
+
+	g.AddNode("split", "split", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	g.AddNode("summarize", "summarize", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		doc, ok := state["doc"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing doc in task input")
+		}
+		return map[string]any{"summaries": []string{"summary of " + doc}}, nil
+	})
+	var reduceRuns int
+	g.AddNode("reduce", "reduce", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		reduceRuns++
+		return map[string]any{"count": len(state["summaries"].([]string))}, nil
+	})
+
+	g.SetEntryPoint("split")
+	g.AddSendEdge("split", func(ctx context.Context, state map[string]any) []Send {
+		docs := state["docs"].([]string)
+		sends := make([]Send, 0, len(docs))
+		for _, doc := range docs {
+			sends = append(sends, NewSend("summarize", map[string]any{"doc": doc}))
+		}
+		return sends
+	})
+	g.AddEdge("summarize", "reduce")
+	g.AddEdge("reduce", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	docs := []string{"a", "b", "c", "d", "e"}
+	res, err := runnable.Invoke(context.Background(), map[string]any{"docs": docs})
+	require.NoError(t, err)
+
+	summaries := res["summaries"].([]string)
+	sort.Strings(summaries)
+	assert.Equal(t, []string{"summary of a", "summary of b", "summary of c", "summary of d", "summary of e"}, summaries)
+	assert.Equal(t, 5, res["count"])
+	assert.Equal(t, 1, reduceRuns, "reduce should run once after all Send tasks")
+}
+
+func TestSendEdgeEmpty(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.SetSchema(newSummarySchema())
+
+	g.AddNode("split", "split", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"split": true}, nil
+	})
+	g.AddNode("summarize", "summarize", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return nil, fmt.Errorf("should not run")
+	})
+	g.SetEntryPoint("split")
+	g.AddSendEdge("split", func(ctx context.Context, state map[string]any) []Send {
+		return nil
+	})
+	g.AddEdge("summarize", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, true, res["split"])
+}
+
+func TestCommandGotoSend(t *testing.T) {
+	g := NewStateGraph[any]()
+	schema := NewMapSchema()
+	schema.RegisterReducer("results", AppendReducer)
+	g.SetSchema(&mapSchemaAdapterForAny{MapSchema: schema})
+
+	g.AddNode("router", "router", func(ctx context.Context, state any) (any, error) {
+		return &Command{
+			Goto: []Send{
+				NewSend("worker", map[string]any{"n": 1}),
+				NewSend("worker", map[string]any{"n": 2}),
+				NewSend("worker", map[string]any{"n": 3}),
+			},
+		}, nil
+	})
+	g.AddNode("worker", "worker", func(ctx context.Context, state any) (any, error) {
+		n := state.(map[string]any)["n"].(int)
+		return map[string]any{"results": []int{n * n}}, nil
+	})
+	g.SetEntryPoint("router")
+	g.AddEdge("worker", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+
+	results := res.(map[string]any)["results"].([]int)
+	sort.Ints(results)
+	assert.Equal(t, []int{1, 4, 9}, results)
+}
+
+func TestSendInvalidArg(t *testing.T) {
+	type State struct {
+		Value int
+	}
+
+	g := NewStateGraph[State]()
+	g.AddNode("start", "start", func(ctx context.Context, state State) (State, error) {
+		return state, nil
+	})
+	g.AddNode("worker", "worker", func(ctx context.Context, state State) (State, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("start")
+	g.AddSendEdge("start", func(ctx context.Context, state State) []Send {
+		return []Send{NewSend("worker", "not a state")}
+	})
+	g.AddEdge("worker", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), State{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "send to node worker")
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"slices"
-	"strings"
 	"sync"
 	"time"
 )
@@ -32,8 +31,14 @@ type StateGraph[S any] struct {
 	// edges is a slice of Edge objects representing the connections between nodes
 	edges []Edge
 
-	// conditionalEdges contains a map between "From" node, while "To" node is derived based on the condition
-	conditionalEdges map[string]func(ctx context.Context, state S) string
+	// conditionalEdges contains a map between "From" node, while "To" nodes are derived based on the condition
+	conditionalEdges map[string]conditionalEdge[S]
+
+	// sendEdges contains routers that fan out from a node to Send tasks with per-task input
+	sendEdges map[string]func(ctx context.Context, state S) []Send
+
+	// joinEdges contains barrier edges whose target waits for all of its upstream nodes
+	joinEdges []JoinEdge
 
 	// entryPoint is the name of the entry point node in the graph
 	entryPoint string
@@ -44,8 +49,17 @@ type StateGraph[S any] struct {
 	// stateMerger is an optional function to merge states from parallel execution
 	stateMerger TypedStateMerger[S]
 
+	// nodeCache stores the results of nodes with a CachePolicy that has no cache of its own
+	nodeCache NodeCache
+
 	// Schema defines the state structure and update logic
 	Schema StateSchema[S]
+
+	// duplicateNodes records node names that were added more than once
+	duplicateNodes []string
+
+	// nodeStructures describes the internals of subgraph, parallel and map-reduce nodes
+	nodeStructures map[string]nodeStructure
 }
 
 // TypedNode represents a typed node in the graph.
@@ -53,6 +67,16 @@ type TypedNode[S any] struct {
 	Name        string
 	Description string
 	Function    func(ctx context.Context, state S) (S, error)
+	Options     NodeOptions
+}
+
+// conditionalEdge routes from a node to the targets chosen at runtime.
+type conditionalEdge[S any] struct {
+	// router returns the labels of the next nodes
+	router func(ctx context.Context, state S) []string
+
+	// pathMap optionally maps router labels to node names
+	pathMap map[string]string
 }
 
 // StateMerger is a typed function to merge states from parallel execution.
@@ -67,27 +91,41 @@ type TypedStateMerger[S any] func(ctx context.Context, currentState S, newStates
 func NewStateGraph[S any]() *StateGraph[S] {
 	return &StateGraph[S]{
 		nodes:            make(map[string]TypedNode[S]),
-		conditionalEdges: make(map[string]func(ctx context.Context, state S) string),
+		conditionalEdges: make(map[string]conditionalEdge[S]),
+		sendEdges:        make(map[string]func(ctx context.Context, state S) []Send),
+		nodeCache:        NewMemoryNodeCache(),
+		nodeStructures:   make(map[string]nodeStructure),
 	}
 }
 
 // AddNode adds a new node to the state graph with the given name, description and function.
 // The node function is fully typed - no type assertions needed!
+// Optional settings such as a per-node timeout can be passed as NodeOption values.
 //
 // Example:
 //
 //	g.AddNode("process", "Process data", func(ctx context.Context, state MyState) (MyState, error) {
 //	    state.Count++  // Type-safe access!
 //	    return state, nil
-//	})
-func (g *StateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error)) {
+//	}, graph.WithNodeTimeout(30*time.Second))
+func (g *StateGraph[S]) AddNode(name string, description string, fn func(ctx context.Context, state S) (S, error), opts ...NodeOption) {
+	if _, exists := g.nodes[name]; exists {
+		g.duplicateNodes = append(g.duplicateNodes, name)
+	}
 	g.nodes[name] = TypedNode[S]{
 		Name:        name,
 		Description: description,
 		Function:    fn,
+		Options:     newNodeOptions(opts),
 	}
 }
 
+// Node returns the node with the given name.
+func (g *StateGraph[S]) Node(name string) (TypedNode[S], bool) {
+	node, ok := g.nodes[name]
+	return node, ok
+}
+
 // AddEdge adds a new edge to the state graph between the "from" and "to" nodes.
 func (g *StateGraph[S]) AddEdge(from, to string) {
 	g.edges = append(g.edges, Edge{
@@ -96,9 +134,36 @@ func (g *StateGraph[S]) AddEdge(from, to string) {
 	})
 }
 
+// AddJoinEdge adds a barrier edge from several upstream nodes to a single target.
+// Unlike a set of regular edges, which schedule "to" as soon as any upstream node finishes,
+// the target waits until every node in "from" has completed in the current run and then
+// runs exactly once with the merged state. This keeps fan-in nodes from running repeatedly
+// with partial state when the upstream branches have different lengths.
+//
+// Completion is tracked per run; once the join fires its progress is reset, so the join
+// can fire again if the upstream nodes run again in a loop. An upstream node does not need
+// any other outgoing edge.
+//
+// Example:
+//
+//	g.AddEdge("A", "B")
+//	g.AddEdge("B", "B2")
+//	g.AddEdge("A", "C")
+//	g.AddJoinEdge([]string{"B2", "C"}, "D") // D runs once, after both B2 and C
+func (g *StateGraph[S]) AddJoinEdge(from []string, to string) {
+	g.joinEdges = append(g.joinEdges, JoinEdge{
+		From: uniqueNodes(from),
+		To:   to,
+	})
+}
+
 // AddConditionalEdge adds a conditional edge where the target node is determined at runtime.
 // The condition function is fully typed - no type assertions needed!
 //
+// An optional path map translates the labels returned by condition into node names.
+// Declaring it lets Compile check every possible target and lets the visualizers
+// draw each branch with its label.
+//
 // Example:
 //
 //	g.AddConditionalEdge("check", func(ctx context.Context, state MyState) string {
@@ -106,9 +171,52 @@ func (g *StateGraph[S]) AddEdge(from, to string) {
 //	        return "high"
 //	    }
 //	    return "low"
+//	}, map[string]string{"high": "alert", "low": graph.END})
+func (g *StateGraph[S]) AddConditionalEdge(from string, condition func(ctx context.Context, state S) string, pathMap ...map[string]string) {
+	g.conditionalEdges[from] = conditionalEdge[S]{
+		router: func(ctx context.Context, state S) []string {
+			return []string{condition(ctx, state)}
+		},
+		pathMap: firstPathMap(pathMap),
+	}
+}
+
+// AddConditionalEdges adds a conditional edge whose router can pick several targets at once.
+// All returned targets run in parallel in the next step. An optional path map translates
+// the returned labels into node names, as in AddConditionalEdge.
+//
+// Example:
+//
+//	g.AddConditionalEdges("classify", func(ctx context.Context, state MyState) []string {
+//	    var targets []string
+//	    if state.NeedsSearch {
+//	        targets = append(targets, "search")
+//	    }
+//	    return append(targets, "answer")
+//	}, map[string]string{"search": "web_search", "answer": "llm"})
+func (g *StateGraph[S]) AddConditionalEdges(from string, router func(ctx context.Context, state S) []string, pathMap ...map[string]string) {
+	g.conditionalEdges[from] = conditionalEdge[S]{
+		router:  router,
+		pathMap: firstPathMap(pathMap),
+	}
+}
+
+// AddSendEdge adds a conditional edge whose router returns Send values instead of node names.
+// Every returned Send is scheduled as a separate parallel task in the next step,
+// receiving Send.Arg as its input. The number of tasks is decided at runtime,
+// which makes map-reduce over inputs of unknown size possible.
+//
+// Example:
+//
+//	g.AddSendEdge("split", func(ctx context.Context, state MyState) []graph.Send {
+//	    sends := make([]graph.Send, 0, len(state.Docs))
+//	    for _, doc := range state.Docs {
+//	        sends = append(sends, graph.NewSend("summarize", MyState{Docs: []string{doc}}))
+//	    }
+//	    return sends
 //	})
-func (g *StateGraph[S]) AddConditionalEdge(from string, condition func(ctx context.Context, state S) string) {
-	g.conditionalEdges[from] = condition
+func (g *StateGraph[S]) AddSendEdge(from string, router func(ctx context.Context, state S) []Send) {
+	g.sendEdges[from] = router
 }
 
 // SetEntryPoint sets the entry point node name for the state graph.
@@ -116,11 +224,22 @@ func (g *StateGraph[S]) SetEntryPoint(name string) {
 	g.entryPoint = name
 }
 
+// EntryPoint returns the entry point node name of the state graph.
+func (g *StateGraph[S]) EntryPoint() string {
+	return g.entryPoint
+}
+
 // SetRetryPolicy sets the retry policy for the graph.
 func (g *StateGraph[S]) SetRetryPolicy(policy *RetryPolicy) {
 	g.retryPolicy = policy
 }
 
+// SetNodeCache sets the cache used by nodes whose CachePolicy has no Cache.
+// By default an in-memory cache is used.
+func (g *StateGraph[S]) SetNodeCache(cache NodeCache) {
+	g.nodeCache = cache
+}
+
 // SetStateMerger sets the state merger function for the state graph.
 func (g *StateGraph[S]) SetStateMerger(merger TypedStateMerger[S]) {
 	g.stateMerger = merger
@@ -144,6 +263,15 @@ func (g *StateGraph[S]) Compile() (*StateRunnable[S], error) {
 		return nil, ErrEntryPointNotSet
 	}
 
+	// Every target declared in a path map must exist
+	for from, edge := range g.conditionalEdges {
+		for label, target := range edge.pathMap {
+			if _, ok := g.nodes[target]; !ok && target != END {
+				return nil, fmt.Errorf("%w: conditional edge from %s maps %q to %s", ErrNodeNotFound, from, label, target)
+			}
+		}
+	}
+
 	return &StateRunnable[S]{
 		graph:  g,
 		tracer: nil, // Initialize with no tracer
@@ -163,8 +291,9 @@ func (r *StateRunnable[S]) GetTracer() *Tracer {
 // WithTracer returns a new StateRunnable with the given tracer.
 func (r *StateRunnable[S]) WithTracer(tracer *Tracer) *StateRunnable[S] {
 	return &StateRunnable[S]{
-		graph:  r.graph,
-		tracer: tracer,
+		graph:      r.graph,
+		tracer:     tracer,
+		nodeRunner: r.nodeRunner,
 	}
 }
 
@@ -182,6 +311,25 @@ func (r *StateRunnable[S]) Invoke(ctx context.Context, initialState S) (S, error
 
 // InvokeWithConfig executes the compiled state graph with the given input state and config.
 func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S, config *Config) (S, error) {
+	// Subgraphs report their spans to the tracer of the graph they run in
+	if r.tracer == nil {
+		if tracer := tracerFromContext(ctx); tracer != nil {
+			return r.WithTracer(tracer).InvokeWithConfig(ctx, initialState, config)
+		}
+		return r.invoke(ctx, initialState, config)
+	}
+
+	graphSpan := r.tracer.StartSpan(ctx, TraceEventGraphStart, "graph")
+	graphSpan.State = initialState
+	ctx = withTracer(r.tracer.ContextWithSpan(ctx, graphSpan), r.tracer)
+
+	result, err := r.invoke(ctx, initialState, config)
+	r.tracer.EndSpan(ctx, graphSpan, result, err)
+	return result, err
+}
+
+// invoke runs the supersteps of the graph.
+func (r *StateRunnable[S]) invoke(ctx context.Context, initialState S, config *Config) (S, error) {
 	state := initialState
 
 	// If schema is defined, merge initialState into schema's initial state
@@ -196,14 +344,38 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 	}
 
 	currentNodes := []string{r.graph.entryPoint}
+	var pendingSends []Send
 
 	// Handle ResumeFrom
 	if config != nil && len(config.ResumeFrom) > 0 {
 		currentNodes = config.ResumeFrom
 	}
 
-	// Generate run ID for callbacks
+	// Generate run ID for callbacks and streamed messages
 	runID := generateRunID()
+	ctx = withRunID(ctx, runID)
+
+	// Pending writes recorded for the resumed checkpoint are replayed in the first superstep
+	replay, ctx := takePendingWrites(ctx)
+
+	// Track the progress of join edges for this run
+	joins := newJoinTracker(r.graph.joinEdges)
+
+	// A debug session pauses this run only, not the subgraphs its nodes invoke
+	debug, ctx := takeDebugSession[S](ctx)
+	step := 0
+
+	// Share the superstep budget with nested graphs through the context
+	ctx, budget := withRecursionBudget(ctx, config)
+
+	// Apply the invocation deadline; in-flight nodes are cancelled through the context
+	var timeout time.Duration
+	if config != nil && config.Timeout != nil && *config.Timeout > 0 {
+		timeout = *config.Timeout
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	// Notify callbacks of graph start
 	if config != nil {
@@ -228,14 +400,7 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		}
 	}
 
-	// Start graph tracing if tracer is set
-	var graphSpan *TraceSpan
-	if r.tracer != nil {
-		graphSpan = r.tracer.StartSpan(ctx, TraceEventGraphStart, "graph")
-		graphSpan.State = initialState
-	}
-
-	for len(currentNodes) > 0 {
+	for len(currentNodes) > 0 || len(pendingSends) > 0 {
 		// Filter out END nodes
 		activeNodes := make([]string, 0, len(currentNodes))
 		for _, node := range currentNodes {
@@ -245,29 +410,93 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		}
 		currentNodes = activeNodes
 
-		if len(currentNodes) == 0 {
+		// Let the debug session pause the run and edit the superstep
+		if debug != nil && (len(currentNodes) > 0 || len(pendingSends) > 0) {
+			snap, err := debug.beforeStep(ctx, debugSnapshot[S]{step: step, nodes: currentNodes, sends: pendingSends, state: state})
+			if err != nil {
+				notifyChainError(ctx, config, err, runID)
+				var zero S
+				return zero, err
+			}
+			step, currentNodes, pendingSends, state = snap.step, snap.nodes, snap.sends, snap.state
+		}
+		step++
+
+		// Build the task list: regular nodes receive the shared state,
+		// Send tasks receive their own argument
+		regularCount := len(currentNodes)
+		taskNodes := make([]string, 0, len(currentNodes)+len(pendingSends))
+		taskInputs := make([]S, 0, len(currentNodes)+len(pendingSends))
+		for _, node := range currentNodes {
+			taskNodes = append(taskNodes, node)
+			taskInputs = append(taskInputs, state)
+		}
+		for _, send := range pendingSends {
+			if send.Node == END {
+				continue
+			}
+			input, err := sendInput[S](send)
+			if err != nil {
+				notifyChainError(ctx, config, err, runID)
+				var zero S
+				return zero, err
+			}
+			taskNodes = append(taskNodes, send.Node)
+			taskInputs = append(taskInputs, input)
+		}
+		pendingSends = nil
+
+		if len(taskNodes) == 0 {
 			break
 		}
 
+		// Enforce the recursion limit before running another superstep
+		if steps := budget.next(); steps > budget.limit {
+			err := &GraphRecursionError{
+				Limit: budget.limit,
+				Steps: steps - 1,
+				Nodes: uniqueNodes(taskNodes),
+			}
+			notifyChainError(ctx, config, err, runID)
+			var zero S
+			return zero, err
+		}
+
 		// Check InterruptBefore
 		if config != nil && len(config.InterruptBefore) > 0 {
-			for _, node := range currentNodes {
+			for _, node := range taskNodes {
 				if slices.Contains(config.InterruptBefore, node) {
+					notifyChainEnd(ctx, config, state, runID)
 					return state, &GraphInterrupt{Node: node, State: state}
 				}
 			}
 		}
 
+		// Stop if the deadline passed between supersteps
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return state, r.handleTimeout(ctx, state, currentNodes, uniqueNodes(taskNodes), timeout, config, runID)
+		}
+
 		// Execute nodes in parallel
-		results, errorsList := r.executeNodesParallel(ctx, currentNodes, state, config, runID)
+		results, errorsList, running := r.executeTasks(ctx, taskNodes, taskInputs, regularCount, replay, config, runID)
+		replay = nil
+
+		// On deadline, discard the partial superstep and report the nodes that did not finish
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			if timedOut := timedOutNodes(taskNodes, errorsList, running); len(timedOut) > 0 {
+				return state, r.handleTimeout(ctx, state, currentNodes, timedOut, timeout, config, runID)
+			}
+		}
 
 		// Process results (including results from interrupted nodes)
-		processedResults, nextNodesFromCommands := r.processNodeResults(results)
+		processedResults, nextNodesFromCommands, sendsFromCommands := r.processNodeResults(results)
 
 		// Merge results into state (this preserves state updates from interrupted nodes)
+		stepState := state
 		var mergeErr error
 		state, mergeErr = r.mergeState(ctx, state, processedResults)
 		if mergeErr != nil {
+			notifyChainError(ctx, config, mergeErr, runID)
 			var zero S
 			return zero, mergeErr
 		}
@@ -286,8 +515,7 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		}
 
 		// Keep track of nodes that ran for callbacks and interrupts
-		nodesRan := make([]string, len(currentNodes))
-		copy(nodesRan, currentNodes)
+		nodesRan := uniqueNodes(taskNodes)
 
 		// Notify callbacks of step completion (and save checkpoints)
 		// For NodeInterrupt: we DO want to save the checkpoint (Issue #70)
@@ -295,15 +523,10 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		if config != nil && len(config.Callbacks) > 0 {
 			if hasNodeInterrupt {
 				// Save checkpoint before returning the interrupt
+				stepCtx := withStepNext(ctx, []string{nodeInterrupt.Node})
 				for _, cb := range config.Callbacks {
 					if gcb, ok := cb.(GraphCallbackHandler); ok {
-						var nodeName string
-						if len(nodesRan) == 1 {
-							nodeName = nodesRan[0]
-						} else {
-							nodeName = fmt.Sprintf("step:%v", nodesRan)
-						}
-						gcb.OnGraphStep(ctx, nodeName, state)
+						gcb.OnGraphStep(stepCtx, stepNodeName(nodesRan), state)
 					}
 				}
 			}
@@ -315,6 +538,7 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 				if hasNodeInterrupt && nodeInterrupt != nil {
 					// Return GraphInterrupt with the merged state
 					// OnGraphStep has already been called, so checkpoint was saved
+					notifyChainEnd(ctx, config, state, runID)
 					return state, &GraphInterrupt{
 						Node:           nodeInterrupt.Node,
 						State:          state,
@@ -323,39 +547,46 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 					}
 				}
 
-				// For regular errors (not interrupts), don't save checkpoint
-				// Notify callbacks of error
+				// For regular errors (not interrupts), don't save a checkpoint of the merged state.
+				// The outputs of the tasks that succeeded are reported as pending writes on top
+				// of the state the superstep started from, so a resume only reruns the failed tasks.
 				if config != nil && len(config.Callbacks) > 0 {
-					for _, cb := range config.Callbacks {
-						cb.OnChainError(ctx, err, runID)
+					if writes := stepFailureWrites(taskNodes, results, errorsList, regularCount); len(writes) > 0 {
+						for _, cb := range config.Callbacks {
+							if fh, ok := cb.(StepFailureHandler); ok {
+								fh.OnStepFailure(ctx, currentNodes, stepState, writes)
+							}
+						}
 					}
 				}
+				notifyChainError(ctx, config, err, runID)
 				var zero S
 				return zero, err
 			}
 		}
 
 		// Determine next nodes
-		nextNodesList, err := r.determineNextNodes(ctx, currentNodes, state, nextNodesFromCommands)
+		nextNodesList, nextSends, err := r.determineNextNodes(ctx, nodesRan, state, nextNodesFromCommands, sendsFromCommands, joins)
 		if err != nil {
+			notifyChainError(ctx, config, err, runID)
 			var zero S
 			return zero, err
 		}
 
 		// Update currentNodes
 		currentNodes = nextNodesList
+		pendingSends = nextSends
 
 		// Notify callbacks of step completion for normal execution (no errors)
 		if config != nil && len(config.Callbacks) > 0 {
+			// Send tasks cannot be resumed from node names, so the next nodes are only reported without them
+			stepCtx := ctx
+			if len(nextSends) == 0 {
+				stepCtx = withStepNext(ctx, nextNodesList)
+			}
 			for _, cb := range config.Callbacks {
 				if gcb, ok := cb.(GraphCallbackHandler); ok {
-					var nodeName string
-					if len(nodesRan) == 1 {
-						nodeName = nodesRan[0]
-					} else {
-						nodeName = fmt.Sprintf("step:%v", nodesRan)
-					}
-					gcb.OnGraphStep(ctx, nodeName, state)
+					gcb.OnGraphStep(stepCtx, stepNodeName(nodesRan), state)
 				}
 			}
 		}
@@ -364,6 +595,7 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		if config != nil && len(config.InterruptAfter) > 0 {
 			for _, node := range nodesRan {
 				if slices.Contains(config.InterruptAfter, node) {
+					notifyChainEnd(ctx, config, state, runID)
 					return state, &GraphInterrupt{
 						Node:      node,
 						State:     state,
@@ -374,41 +606,84 @@ func (r *StateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S,
 		}
 	}
 
-	// End graph tracing
-	if r.tracer != nil && graphSpan != nil {
-		r.tracer.EndSpan(ctx, graphSpan, state, nil)
-	}
+	notifyChainEnd(ctx, config, state, runID)
+	return state, nil
+}
 
-	// Notify callbacks of graph end
+// notifyChainEnd notifies callbacks of graph end. Interrupted runs end their chain
+// like completed ones, so that handlers see every run that started finish.
+func notifyChainEnd[S any](ctx context.Context, config *Config, state S, runID string) {
 	if config != nil && len(config.Callbacks) > 0 {
 		outputs := convertStateToMap(state)
 		for _, cb := range config.Callbacks {
 			cb.OnChainEnd(ctx, outputs, runID)
 		}
 	}
+}
 
-	return state, nil
+// notifyChainError notifies callbacks that the graph failed. Every error returned after the
+// chain started goes through it, so that handlers see every run that started finish.
+func notifyChainError(ctx context.Context, config *Config, err error, runID string) {
+	if config != nil && len(config.Callbacks) > 0 {
+		for _, cb := range config.Callbacks {
+			cb.OnChainError(ctx, err, runID)
+		}
+	}
+}
+
+// handleTimeout notifies callbacks about an expired invocation deadline. The superstep is
+// reported as failed, with the state of the last completed superstep and no pending writes,
+// so that a checkpoint records its regular nodes and resuming the thread runs them again.
+// Send tasks are left out, as for other failures: they cannot be resumed from node names.
+func (r *StateRunnable[S]) handleTimeout(ctx context.Context, state S, currentNodes, timedOut []string, timeout time.Duration, config *Config, runID string) error {
+	err := &GraphTimeoutError{
+		Timeout: timeout,
+		Nodes:   timedOut,
+		State:   state,
+	}
+
+	if config != nil && len(config.Callbacks) > 0 {
+		// The invocation context has expired, but the checkpoint must still be written
+		saveCtx := context.WithoutCancel(ctx)
+		for _, cb := range config.Callbacks {
+			if fh, ok := cb.(StepFailureHandler); ok {
+				fh.OnStepFailure(saveCtx, currentNodes, state, nil)
+			}
+			cb.OnChainError(saveCtx, err, runID)
+		}
+	}
+
+	return err
 }
 
 // executeNodeWithRetry executes a node with retry logic based on the retry policy.
-func (r *StateRunnable[S]) executeNodeWithRetry(ctx context.Context, node TypedNode[S], state S) (S, error) {
+func (r *StateRunnable[S]) executeNodeWithRetry(ctx context.Context, node TypedNode[S], state S, config *Config, runID string) (S, error) {
 	var lastErr error
 	var zero S
 
-	maxRetries := 1 // Default: no retries
-	if r.graph.retryPolicy != nil {
-		maxRetries = r.graph.retryPolicy.MaxRetries + 1 // +1 for initial attempt
+	// A per-node policy takes precedence over the graph-wide one
+	policy := node.Options.RetryPolicy
+	if policy == nil {
+		policy = r.graph.retryPolicy
 	}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		var result S
-		var err error
+	maxRetries := 1 // Default: no retries
+	if policy != nil {
+		maxRetries = policy.MaxRetries + 1 // +1 for initial attempt
+	}
 
-		if r.nodeRunner != nil {
-			result, err = r.nodeRunner(ctx, node.Name, state)
-		} else {
-			result, err = node.Function(ctx, state)
+	run := node.Function
+	if r.nodeRunner != nil {
+		run = func(ctx context.Context, state S) (S, error) {
+			return r.nodeRunner(ctx, node.Name, state)
 		}
+	}
+	if node.Options.Timeout > 0 {
+		run = NewTimeoutNode(TypedNode[S]{Name: node.Name, Function: run}, node.Options.Timeout).Execute
+	}
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		result, err := run(ctx, state)
 
 		if err == nil {
 			return result, nil
@@ -424,10 +699,11 @@ func (r *StateRunnable[S]) executeNodeWithRetry(ctx context.Context, node TypedN
 		lastErr = err
 
 		// Check if error is retryable
-		if r.graph.retryPolicy != nil && attempt < maxRetries-1 {
-			if r.isRetryableError(err) {
+		if policy != nil && attempt < maxRetries-1 {
+			if policy.shouldRetry(err) {
 				// Apply backoff strategy
-				delay := r.calculateBackoffDelay(attempt)
+				delay := policy.delay(attempt, err)
+				r.reportRetry(ctx, node.Name, attempt+1, err, delay, state, config, runID)
 				if delay > 0 {
 					select {
 					case <-time.After(delay):
@@ -448,54 +724,50 @@ func (r *StateRunnable[S]) executeNodeWithRetry(ctx context.Context, node TypedN
 	return zero, lastErr
 }
 
-// isRetryableError checks if an error is retryable based on the retry policy.
-func (r *StateRunnable[S]) isRetryableError(err error) bool {
-	if r.graph.retryPolicy == nil {
-		return false
+// reportRetry records a failed attempt that is about to be retried on the tracer and callbacks.
+func (r *StateRunnable[S]) reportRetry(ctx context.Context, nodeName string, attempt int, err error, delay time.Duration, state S, config *Config, runID string) {
+	if r.tracer != nil {
+		span := r.tracer.StartSpan(ctx, TraceEventNodeRetry, nodeName)
+		span.Metadata["attempt"] = attempt
+		span.Metadata["delay"] = delay
+		r.tracer.EndSpan(ctx, span, state, err)
 	}
 
-	errorStr := err.Error()
-	for _, retryablePattern := range r.graph.retryPolicy.RetryableErrors {
-		if strings.Contains(errorStr, retryablePattern) {
-			return true
+	if config != nil {
+		for _, cb := range config.Callbacks {
+			if rcb, ok := cb.(RetryCallbackHandler); ok {
+				rcb.OnNodeRetry(ctx, nodeName, attempt, err, delay, runID)
+			}
 		}
 	}
+}
 
-	return false
+// isRetryableError checks if an error is retryable based on the graph's retry policy.
+func (r *StateRunnable[S]) isRetryableError(err error) bool {
+	return r.graph.retryPolicy.shouldRetry(err)
 }
 
-// calculateBackoffDelay calculates the delay for retry based on the backoff strategy.
+// calculateBackoffDelay calculates the delay for retry based on the graph's backoff strategy.
 func (r *StateRunnable[S]) calculateBackoffDelay(attempt int) time.Duration {
-	if r.graph.retryPolicy == nil {
-		return 0
-	}
-
-	baseDelay := time.Second // Default 1 second base delay
-
-	switch r.graph.retryPolicy.BackoffStrategy {
-	case FixedBackoff:
-		return baseDelay
-	case ExponentialBackoff:
-		// Exponential backoff: 1s, 2s, 4s, 8s, ...
-		return baseDelay * time.Duration(1<<attempt)
-	case LinearBackoff:
-		// Linear backoff: 1s, 2s, 3s, 4s, ...
-		return baseDelay * time.Duration(attempt+1)
-	default:
-		return baseDelay
-	}
+	return r.graph.retryPolicy.delay(attempt, nil)
 }
 
 // executeNodesParallel executes valid nodes in parallel and returns their results or errors.
-func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []string, state S, config *Config, runID string) ([]S, []error) {
+// Each node receives the input at the same index in states.
+// If ctx is done before all nodes finish, it returns without waiting for them and
+// reports the names of the nodes that were still running.
+func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []string, states []S, config *Config, runID string) ([]S, []error, []string) {
 	var wg sync.WaitGroup
+	var mu sync.Mutex
 	results := make([]S, len(nodes))
 	errorsList := make([]error, len(nodes))
+	finished := make([]bool, len(nodes))
 
 	for i, nodeName := range nodes {
 		node, ok := r.graph.nodes[nodeName]
 		if !ok {
 			errorsList[i] = fmt.Errorf("%w: %s", ErrNodeNotFound, nodeName)
+			finished[i] = true
 			continue
 		}
 
@@ -503,20 +775,34 @@ func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []str
 		idx := i
 		n := node
 		name := nodeName
+		state := states[i]
 
 		SafeGo(&wg, func() {
 			// Start node tracing
 			var nodeSpan *TraceSpan
+			nodeCtx := withNodeName(ctx, name)
 			if r.tracer != nil {
 				nodeSpan = r.tracer.StartSpan(ctx, TraceEventNodeStart, name)
 				nodeSpan.State = state
+				nodeCtx = r.tracer.ContextWithSpan(nodeCtx, nodeSpan)
 			}
 
 			var err error
 			var res S
 
-			// Execute node with retry logic
-			res, err = r.executeNodeWithRetry(ctx, n, state)
+			emitSubgraphEvent(nodeCtx, name, NodeEventStart, state, nil)
+
+			// Execute node with cache and retry logic
+			var cacheHit bool
+			res, cacheHit, err = r.executeNodeCached(nodeCtx, n, state, config, runID)
+			if err != nil {
+				emitSubgraphEvent(nodeCtx, name, NodeEventError, state, err)
+			} else {
+				emitSubgraphEvent(nodeCtx, name, NodeEventComplete, res, nil)
+			}
+			if nodeSpan != nil && n.Options.CachePolicy != nil {
+				nodeSpan.Metadata["cache_hit"] = cacheHit
+			}
 
 			// End node tracing
 			if r.tracer != nil && nodeSpan != nil {
@@ -534,16 +820,22 @@ func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []str
 
 			if err != nil {
 				var nodeInterrupt *NodeInterrupt
+				mu.Lock()
 				if errors.As(err, &nodeInterrupt) {
 					nodeInterrupt.Node = name
 					// For NodeInterrupt, save the result so state updates are preserved
 					results[idx] = res
 				}
 				errorsList[idx] = fmt.Errorf("error in node %s: %w", name, err)
+				finished[idx] = true
+				mu.Unlock()
 				return
 			}
 
+			mu.Lock()
 			results[idx] = res
+			finished[idx] = true
+			mu.Unlock()
 
 			// Notify callbacks of node execution (as tool)
 			if config != nil && len(config.Callbacks) > 0 {
@@ -558,16 +850,48 @@ func (r *StateRunnable[S]) executeNodesParallel(ctx context.Context, nodes []str
 				}
 			}
 		}, func(panicVal any) {
+			mu.Lock()
 			errorsList[idx] = fmt.Errorf("panic in node %s: %v", name, panicVal)
+			finished[idx] = true
+			mu.Unlock()
 		})
 	}
-	wg.Wait()
-	return results, errorsList
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return results, errorsList, nil
+	case <-ctx.Done():
+	}
+
+	// Nodes that ignore cancellation must not block the graph; take a snapshot of what finished
+	mu.Lock()
+	defer mu.Unlock()
+
+	snapshot := make([]S, len(nodes))
+	snapshotErrors := make([]error, len(nodes))
+	var running []string
+	for i, name := range nodes {
+		if finished[i] {
+			snapshot[i] = results[i]
+			snapshotErrors[i] = errorsList[i]
+			continue
+		}
+		running = append(running, name)
+		snapshotErrors[i] = fmt.Errorf("error in node %s: %w", name, ctx.Err())
+	}
+	return snapshot, snapshotErrors, running
 }
 
 // processNodeResults processes the raw results from nodes, handling Commands.
-func (r *StateRunnable[S]) processNodeResults(results []S) ([]S, []string) {
+func (r *StateRunnable[S]) processNodeResults(results []S) ([]S, []string, []Send) {
 	var nextNodesFromCommands []string
+	var sendsFromCommands []Send
 	processedResults := make([]S, len(results))
 
 	for i, res := range results {
@@ -597,6 +921,14 @@ func (r *StateRunnable[S]) processNodeResults(results []S) ([]S, []string) {
 					nextNodesFromCommands = append(nextNodesFromCommands, g)
 				case []string:
 					nextNodesFromCommands = append(nextNodesFromCommands, g...)
+				case Send:
+					sendsFromCommands = append(sendsFromCommands, g)
+				case *Send:
+					if g != nil {
+						sendsFromCommands = append(sendsFromCommands, *g)
+					}
+				case []Send:
+					sendsFromCommands = append(sendsFromCommands, g...)
 				}
 			}
 		} else {
@@ -605,7 +937,7 @@ func (r *StateRunnable[S]) processNodeResults(results []S) ([]S, []string) {
 		}
 	}
 
-	return processedResults, nextNodesFromCommands
+	return processedResults, nextNodesFromCommands, sendsFromCommands
 }
 
 // mergeState merges the processed results into the current state.
@@ -637,10 +969,14 @@ func (r *StateRunnable[S]) mergeState(ctx context.Context, currentState S, resul
 }
 
 // determineNextNodes determines the next nodes to execute based on static edges, conditional edges, or commands.
-func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes []string, state S, nextNodesFromCommands []string) ([]string, error) {
+// It also returns the Send tasks scheduled by send edges or commands.
+// Join edges are tracked for every node that ran, including nodes routed by commands.
+func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes []string, state S, nextNodesFromCommands []string, sendsFromCommands []Send, joins *joinTracker) ([]string, []Send, error) {
 	var nextNodesList []string
+	var nextSends []Send
 
-	if len(nextNodesFromCommands) > 0 {
+	if len(nextNodesFromCommands) > 0 || len(sendsFromCommands) > 0 {
+		nextSends = sendsFromCommands
 		// Command.Goto overrides static edges
 		// We deduplicate
 		seen := make(map[string]bool)
@@ -650,21 +986,36 @@ func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes
 				nextNodesList = append(nextNodesList, n)
 			}
 		}
+		for _, n := range joins.complete(currentNodes) {
+			if !seen[n] && n != END {
+				seen[n] = true
+				nextNodesList = append(nextNodesList, n)
+			}
+		}
 	} else {
 		// Use static edges
 		nextNodesSet := make(map[string]bool)
 
 		for _, nodeName := range currentNodes {
 			// First check for conditional edges
-			nextNodeFn, hasConditional := r.graph.conditionalEdges[nodeName]
+			conditional, hasConditional := r.graph.conditionalEdges[nodeName]
+			sendFn, hasSend := r.graph.sendEdges[nodeName]
 			if hasConditional {
-				nextNode := nextNodeFn(ctx, state)
-				if nextNode == "" {
-					var zero S
-					_ = zero
-					return nil, fmt.Errorf("conditional edge returned empty next node from %s", nodeName)
+				targets, err := conditional.targets(ctx, nodeName, state)
+				if err != nil {
+					return nil, nil, err
 				}
-				nextNodesSet[nextNode] = true
+				for _, target := range targets {
+					nextNodesSet[target] = true
+					r.traceEdge(ctx, nodeName, target)
+				}
+			} else if hasSend {
+				// An empty result is allowed: there is simply nothing to fan out to
+				sends := sendFn(ctx, state)
+				for _, send := range sends {
+					r.traceEdge(ctx, nodeName, send.Node)
+				}
+				nextSends = append(nextSends, sends...)
 			} else {
 				// Then check regular edges
 				foundNext := false
@@ -672,20 +1023,169 @@ func (r *StateRunnable[S]) determineNextNodes(ctx context.Context, currentNodes
 					if edge.From == nodeName {
 						nextNodesSet[edge.To] = true
 						foundNext = true
+						r.traceEdge(ctx, nodeName, edge.To)
 						// Do NOT break here, to allow fan-out (multiple edges from same node)
 					}
 				}
 
-				if !foundNext {
-					return nil, fmt.Errorf("%w: %s", ErrNoOutgoingEdge, nodeName)
+				// An upstream node of a join edge may have no other outgoing edge
+				if !foundNext && !joins.hasUpstream(nodeName) {
+					return nil, nil, fmt.Errorf("%w: %s", ErrNoOutgoingEdge, nodeName)
 				}
 			}
 		}
 
+		// Schedule the targets of join edges whose upstream nodes have all completed
+		for _, node := range joins.complete(currentNodes) {
+			nextNodesSet[node] = true
+		}
+
 		// Update nextNodesList from set
 		for node := range nextNodesSet {
 			nextNodesList = append(nextNodesList, node)
 		}
 	}
-	return nextNodesList, nil
+	return nextNodesList, nextSends, nil
+}
+
+// traceEdge records the traversal of an edge on the tracer, if any.
+func (r *StateRunnable[S]) traceEdge(ctx context.Context, from, to string) {
+	if r.tracer != nil {
+		r.tracer.TraceEdgeTraversal(ctx, from, to)
+	}
+}
+
+// targets runs the router and resolves its labels through the path map.
+func (e conditionalEdge[S]) targets(ctx context.Context, from string, state S) ([]string, error) {
+	labels := e.router(ctx, state)
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("conditional edge returned no next node from %s", from)
+	}
+	targets := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("conditional edge returned empty next node from %s", from)
+		}
+		if e.pathMap != nil {
+			target, ok := e.pathMap[label]
+			if !ok {
+				return nil, fmt.Errorf("conditional edge from %s returned %q, which is not in its path map", from, label)
+			}
+			label = target
+		}
+		targets = append(targets, label)
+	}
+	return targets, nil
+}
+
+// firstPathMap returns the optional path map passed to AddConditionalEdge(s).
+func firstPathMap(pathMap []map[string]string) map[string]string {
+	if len(pathMap) == 0 {
+		return nil
+	}
+	return pathMap[0]
+}
+
+// joinTracker records which upstream nodes of each join edge have completed in a run.
+type joinTracker struct {
+	edges []JoinEdge
+	done  []map[string]bool
+}
+
+// newJoinTracker creates a tracker with no completed upstream nodes.
+func newJoinTracker(edges []JoinEdge) *joinTracker {
+	done := make([]map[string]bool, len(edges))
+	for i := range done {
+		done[i] = make(map[string]bool)
+	}
+	return &joinTracker{edges: edges, done: done}
+}
+
+// hasUpstream reports whether node is an upstream node of any join edge.
+func (j *joinTracker) hasUpstream(node string) bool {
+	for _, edge := range j.edges {
+		if slices.Contains(edge.From, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// complete marks nodes as completed and returns the targets of the joins that became ready.
+// The progress of a fired join is reset so that it can fire again in a loop.
+func (j *joinTracker) complete(nodes []string) []string {
+	var ready []string
+	for i, edge := range j.edges {
+		for _, node := range nodes {
+			if slices.Contains(edge.From, node) {
+				j.done[i][node] = true
+			}
+		}
+		if len(edge.From) == 0 || len(j.done[i]) < len(edge.From) {
+			continue
+		}
+		j.done[i] = make(map[string]bool)
+		if !slices.Contains(ready, edge.To) {
+			ready = append(ready, edge.To)
+		}
+	}
+	return ready
+}
+
+// sendInput converts the argument of a Send into the graph state type.
+func sendInput[S any](send Send) (S, error) {
+	if input, ok := send.Arg.(S); ok {
+		return input, nil
+	}
+	var zero S
+	if send.Arg == nil {
+		return zero, nil
+	}
+	return zero, fmt.Errorf("send to node %s: argument of type %T is not assignable to state type %T", send.Node, send.Arg, zero)
+}
+
+// stepNextKey is the context key of the nodes scheduled after a superstep.
+type stepNextKey struct{}
+
+// withStepNext returns a context reporting the nodes scheduled after a superstep to OnGraphStep.
+func withStepNext(ctx context.Context, nodes []string) context.Context {
+	return context.WithValue(ctx, stepNextKey{}, nodes)
+}
+
+// stepNext returns the nodes scheduled after the superstep reported to OnGraphStep, if known.
+func stepNext(ctx context.Context) ([]string, bool) {
+	nodes, ok := ctx.Value(stepNextKey{}).([]string)
+	return nodes, ok
+}
+
+// stepNodeName returns the node name reported to OnGraphStep for the nodes of a superstep.
+func stepNodeName(nodes []string) string {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return fmt.Sprintf("step:%v", nodes)
+}
+
+// timedOutNodes returns the nodes that were still running or failed because the deadline passed.
+func timedOutNodes(nodes []string, errorsList []error, running []string) []string {
+	timedOut := append([]string(nil), running...)
+	for i, err := range errorsList {
+		if err != nil && errors.Is(err, context.DeadlineExceeded) && !slices.Contains(timedOut, nodes[i]) {
+			timedOut = append(timedOut, nodes[i])
+		}
+	}
+	return uniqueNodes(timedOut)
+}
+
+// uniqueNodes returns the node names in order of first appearance without duplicates.
+func uniqueNodes(nodes []string) []string {
+	seen := make(map[string]bool, len(nodes))
+	result := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if !seen[node] {
+			seen[node] = true
+			result = append(result, node)
+		}
+	}
+	return result
 }
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+	"strings"
+)
+
+// StreamWriter pushes a custom payload from node code to the stream as an EventCustom event.
+type StreamWriter func(payload any)
+
+// streamSink receives the events emitted through the context of a streamed run:
+// custom payloads from nodes and the node events of subgraphs.
+type streamSink interface {
+	emitNamespaced(ctx context.Context, namespace, nodeName string, event NodeEvent, state any, err error, metadata map[string]any)
+}
+
+type streamSinkKey struct{}
+
+type namespaceKey struct{}
+
+type runStreamKey struct{}
+
+// withStreamSink returns a context that forwards custom and subgraph events to sink.
+func withStreamSink(ctx context.Context, sink streamSink) context.Context {
+	return context.WithValue(ctx, streamSinkKey{}, sink)
+}
+
+// withRunStream returns a context whose sink also receives the node events of the graph itself.
+// It is used by streams scoped to a single run, which do not register graph listeners.
+func withRunStream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, runStreamKey{}, true)
+}
+
+// GetStreamWriter returns a StreamWriter for the node running with ctx.
+// Payloads written to it are streamed as EventCustom events tagged with the node name,
+// with the payload in Metadata["payload"]. The writer discards payloads when the graph
+// is not being streamed.
+//
+// Example:
+//
+//	g.AddNode("index", "index", func(ctx context.Context, state State) (State, error) {
+//	    write := graph.GetStreamWriter(ctx)
+//	    for i, doc := range state.Docs {
+//	        write(map[string]any{"indexed": i + 1, "total": len(state.Docs)})
+//	        ...
+//	    }
+//	    return state, nil
+//	})
+func GetStreamWriter(ctx context.Context) StreamWriter {
+	sink, ok := ctx.Value(streamSinkKey{}).(streamSink)
+	if !ok {
+		return func(any) {}
+	}
+
+	nodeName, _ := ctx.Value(nodeNameKey{}).(string)
+	namespace := streamNamespace(ctx)
+	return func(payload any) {
+		sink.emitNamespaced(ctx, namespace, nodeName, EventCustom, nil, nil, map[string]any{"payload": payload})
+	}
+}
+
+// streamNamespace returns the path of subgraph nodes the context runs in, e.g. "parent:child".
+func streamNamespace(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceKey{}).(string)
+	return namespace
+}
+
+// withSubgraphNamespace returns the context for a subgraph run by the current node,
+// appending the node name to the namespace.
+func withSubgraphNamespace(ctx context.Context) context.Context {
+	nodeName, _ := ctx.Value(nodeNameKey{}).(string)
+	if nodeName == "" {
+		return ctx
+	}
+	namespace := nodeName
+	if parent := streamNamespace(ctx); parent != "" {
+		namespace = strings.Join([]string{parent, nodeName}, ":")
+	}
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// emitSubgraphEvent forwards a node event of a subgraph to the stream of the parent graph.
+// Events of the streamed graph itself are reported by its listeners, so nothing is
+// emitted outside of a subgraph unless the stream is scoped to the run.
+func emitSubgraphEvent(ctx context.Context, nodeName string, event NodeEvent, state any, err error) {
+	namespace := streamNamespace(ctx)
+	if runStream, _ := ctx.Value(runStreamKey{}).(bool); namespace == "" && !runStream {
+		return
+	}
+	if sink, ok := ctx.Value(streamSinkKey{}).(streamSink); ok {
+		sink.emitNamespaced(ctx, namespace, nodeName, event, state, err, nil)
+	}
+}
@@ -0,0 +1,141 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectStream[S any](t *testing.T, runnable *StreamingRunnable[S], initialState S) []StreamEvent[S] {
+	t.Helper()
+	res := runnable.Stream(context.Background(), initialState)
+
+	var events []StreamEvent[S]
+	for event := range res.Events {
+		events = append(events, event)
+	}
+	require.NoError(t, <-res.Errors)
+	return events
+}
+
+func TestStreamMultipleModes(t *testing.T) {
+	model := &streamingModel{response: "hi there"}
+	g := NewStreamingStateGraph[map[string]any]()
+	g.AddNode("chat", "chat", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		GetStreamWriter(ctx)(map[string]any{"progress": 0.5})
+		resp, err := model.GenerateContent(ctx, nil, MessageStreamingOptions(ctx)...)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"answer": resp.Choices[0].Content}, nil
+	})
+	g.SetEntryPoint("chat")
+	g.AddEdge("chat", END)
+	g.SetStreamConfig(StreamConfig{
+		BufferSize: 100,
+		Modes:      []StreamMode{StreamModeUpdates, StreamModeMessages, StreamModeCustom},
+	})
+
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+
+	byMode := make(map[StreamMode][]StreamEvent[map[string]any])
+	for _, event := range collectStream(t, runnable, map[string]any{}) {
+		byMode[event.Mode] = append(byMode[event.Mode], event)
+	}
+
+	require.Len(t, byMode[StreamModeUpdates], 1)
+	assert.Equal(t, NodeEventComplete, byMode[StreamModeUpdates][0].Event)
+	assert.Equal(t, "hi there", byMode[StreamModeUpdates][0].State["answer"])
+
+	require.Len(t, byMode[StreamModeMessages], 2)
+	for _, event := range byMode[StreamModeMessages] {
+		assert.Equal(t, EventToken, event.Event)
+	}
+
+	require.Len(t, byMode[StreamModeCustom], 1)
+	custom := byMode[StreamModeCustom][0]
+	assert.Equal(t, EventCustom, custom.Event)
+	assert.Equal(t, "chat", custom.NodeName)
+	assert.Equal(t, map[string]any{"progress": 0.5}, custom.Metadata["payload"])
+
+	assert.Empty(t, byMode[StreamModeDebug])
+}
+
+func TestStreamSingleModeTagsEvents(t *testing.T) {
+	g := NewStreamingStateGraph[map[string]any]()
+	g.AddNode("A", "A", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		GetStreamWriter(ctx)("ignored in updates mode")
+		return map[string]any{"state": "A"}, nil
+	})
+	g.SetEntryPoint("A")
+	g.AddEdge("A", END)
+	g.SetStreamConfig(StreamConfig{BufferSize: 100, Mode: StreamModeUpdates})
+
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+
+	events := collectStream(t, runnable, map[string]any{})
+	require.Len(t, events, 1)
+	assert.Equal(t, StreamModeUpdates, events[0].Mode)
+	assert.Equal(t, NodeEventComplete, events[0].Event)
+}
+
+func TestStreamForwardsSubgraphEvents(t *testing.T) {
+	inner := NewStateGraph[map[string]any]()
+	inner.AddNode("child_node", "child_node", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		GetStreamWriter(ctx)("from child")
+		return map[string]any{"child": true}, nil
+	})
+	inner.SetEntryPoint("child_node")
+	inner.AddEdge("child_node", END)
+
+	sub, err := NewSubgraph("parent", inner)
+	require.NoError(t, err)
+
+	g := NewStreamingStateGraph[map[string]any]()
+	g.AddNode("parent", "parent", sub.Execute)
+	g.SetEntryPoint("parent")
+	g.AddEdge("parent", END)
+	g.SetStreamConfig(StreamConfig{BufferSize: 100, Mode: StreamModeDebug})
+
+	runnable, err := g.CompileStreaming()
+	require.NoError(t, err)
+
+	var paths []string
+	var custom *StreamEvent[map[string]any]
+	for _, event := range collectStream(t, runnable, map[string]any{}) {
+		if event.Namespace == "" {
+			continue
+		}
+		assert.Equal(t, "parent", event.Namespace)
+		paths = append(paths, event.Path()+"/"+string(event.Event))
+		if event.Event == EventCustom {
+			custom = &event
+		}
+	}
+
+	assert.ElementsMatch(t, []string{
+		"parent:child_node/start",
+		"parent:child_node/custom",
+		"parent:child_node/complete",
+	}, paths)
+	require.NotNil(t, custom)
+	assert.Equal(t, "from child", custom.Metadata["payload"])
+}
+
+func TestSubgraphNamespaceNesting(t *testing.T) {
+	ctx := withNodeName(context.Background(), "outer")
+	ctx = withSubgraphNamespace(ctx)
+	assert.Equal(t, "outer", streamNamespace(ctx))
+
+	ctx = withSubgraphNamespace(withNodeName(ctx, "inner"))
+	assert.Equal(t, "outer:inner", streamNamespace(ctx))
+}
+
+func TestGetStreamWriterWithoutStream(t *testing.T) {
+	// Writing outside of a stream is a no-op
+	GetStreamWriter(context.Background())("payload")
+}
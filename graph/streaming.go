@@ -16,6 +16,8 @@ const (
 	StreamModeUpdates StreamMode = "updates"
 	// StreamModeMessages emits LLM messages/tokens (if available)
 	StreamModeMessages StreamMode = "messages"
+	// StreamModeCustom emits the custom payloads written by nodes through GetStreamWriter
+	StreamModeCustom StreamMode = "custom"
 	// StreamModeDebug emits all events (default)
 	StreamModeDebug StreamMode = "debug"
 )
@@ -33,6 +35,19 @@ type StreamConfig struct {
 
 	// Mode specifies what kind of events to stream
 	Mode StreamMode
+
+	// Modes streams several modes at once, e.g. updates and messages.
+	// Each event is emitted once per mode that includes it, tagged with that mode.
+	// It takes precedence over Mode when set.
+	Modes []StreamMode
+}
+
+// streamModes returns the modes to stream.
+func (c StreamConfig) streamModes() []StreamMode {
+	if len(c.Modes) > 0 {
+		return c.Modes
+	}
+	return []StreamMode{c.Mode}
 }
 
 // DefaultStreamConfig returns the default streaming configuration
@@ -91,40 +106,44 @@ func (sl *StreamingListener[S]) emitEvent(event StreamEvent[S]) {
 	}
 	sl.mutex.RUnlock()
 
-	// Filter based on Mode
-	if !sl.shouldEmit(event) {
-		return
-	}
+	// Emit the event once for every mode that includes it
+	for _, mode := range sl.config.streamModes() {
+		if !shouldEmit(mode, event) {
+			continue
+		}
+		event.Mode = mode
 
-	// Try to send event without blocking
-	select {
-	case sl.eventChan <- event:
-		// Event sent successfully
-	default:
-		// Channel is full
-		if sl.config.EnableBackpressure {
-			sl.handleBackpressure()
+		// Try to send event without blocking
+		select {
+		case sl.eventChan <- event:
+			// Event sent successfully
+		default:
+			// Channel is full
+			if sl.config.EnableBackpressure {
+				sl.handleBackpressure()
+			}
+			// Drop the event if backpressure handling is disabled or channel is still full
 		}
-		// Drop the event if backpressure handling is disabled or channel is still full
 	}
 }
 
-func (sl *StreamingListener[S]) shouldEmit(event StreamEvent[S]) bool {
-	switch sl.config.Mode {
+func shouldEmit[S any](mode StreamMode, event StreamEvent[S]) bool {
+	switch mode {
 	case StreamModeDebug:
 		return true
 	case StreamModeValues:
 		// Only emit OnGraphStep events (which contain full state)
 		// We expect a custom event type or we rely on node complete if it returns full state?
 		// For now, emit everything that looks like a state update
-		return event.Event == NodeEventComplete || event.Event == EventChainEnd
+		return event.Event == NodeEventComplete || event.Event == EventChainEnd || event.Event == EventStep
 	case StreamModeUpdates:
 		// Emit node outputs
 		return event.Event == NodeEventComplete || event.Event == EventChainEnd
 	case StreamModeMessages:
-		// Emit LLM events - this is tricky because generic S doesn't imply LLM events
-		// But if the event metadata says it's LLM...
-		return event.Event == EventLLMEnd || event.Event == EventLLMStart
+		// Emit tokens streamed by models inside nodes and LLM events
+		return event.Event == EventToken || event.Event == EventLLMEnd || event.Event == EventLLMStart
+	case StreamModeCustom:
+		return event.Event == EventCustom
 	default:
 		return true
 	}
@@ -143,6 +162,44 @@ func (sl *StreamingListener[S]) OnNodeEvent(ctx context.Context, event NodeEvent
 	sl.emitEvent(streamEvent)
 }
 
+// OnMessageChunk emits a chunk streamed by a model inside a node as an EventToken event.
+// The metadata holds the run ID, the message ID and the chunk text.
+func (sl *StreamingListener[S]) OnMessageChunk(ctx context.Context, chunk MessageChunk) {
+	sl.emitEvent(StreamEvent[S]{
+		Timestamp: time.Now(),
+		NodeName:  chunk.NodeName,
+		Namespace: chunk.Namespace,
+		Event:     EventToken,
+		Metadata: map[string]any{
+			"run_id":     chunk.RunID,
+			"message_id": chunk.MessageID,
+			"chunk":      chunk.Content,
+		},
+	})
+}
+
+// emitNamespaced emits a custom event written by a node or a node event forwarded from a subgraph.
+// States of another type than S are passed in Metadata["state"].
+func (sl *StreamingListener[S]) emitNamespaced(ctx context.Context, namespace, nodeName string, event NodeEvent, state any, err error, metadata map[string]any) {
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	streamEvent := StreamEvent[S]{
+		Timestamp: time.Now(),
+		NodeName:  nodeName,
+		Namespace: namespace,
+		Event:     event,
+		Error:     err,
+		Metadata:  metadata,
+	}
+	if typed, ok := state.(S); ok {
+		streamEvent.State = typed
+	} else if state != nil {
+		metadata["state"] = state
+	}
+	sl.emitEvent(streamEvent)
+}
+
 // Close marks the listener as closed to prevent sending to closed channels
 func (sl *StreamingListener[S]) Close() {
 	sl.mutex.Lock()
@@ -202,6 +259,10 @@ func (sr *StreamingRunnable[S]) Stream(ctx context.Context, initialState S) *Str
 	// We add it globally using the graph
 	sr.runnable.GetListenableGraph().AddGlobalListener(streamingListener)
 
+	// Forward tokens streamed by models inside nodes, custom events and subgraph events
+	streamCtx = WithMessageHandler(streamCtx, streamingListener.OnMessageChunk)
+	streamCtx = withStreamSink(streamCtx, streamingListener)
+
 	// Execute in goroutine
 	go func() {
 		defer func() {
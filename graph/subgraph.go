@@ -28,7 +28,7 @@ func NewSubgraph[S any](name string, graph *StateGraph[S]) (*Subgraph[S], error)
 
 // Execute runs the subgraph as a node
 func (s *Subgraph[S]) Execute(ctx context.Context, state S) (S, error) {
-	result, err := s.runnable.Invoke(ctx, state)
+	result, err := s.runnable.Invoke(withSubgraphNamespace(ctx), state)
 	if err != nil {
 		var zero S
 		return zero, fmt.Errorf("subgraph %s execution failed: %w", s.name, err)
@@ -57,6 +57,7 @@ func AddSubgraph[S, SubS any](g *StateGraph[S], name string, subgraph *StateGrap
 	}
 
 	g.AddNode(name, "Subgraph: "+name, wrappedFn)
+	g.setNodeStructure(name, nodeStructure{kind: NodeKindSubgraph, subgraph: subgraph.Topology})
 	return nil
 }
 
@@ -168,7 +169,7 @@ func (rs *RecursiveSubgraph[S]) executeRecursive(ctx context.Context, state S, d
 		return zero, fmt.Errorf("failed to compile recursive subgraph at depth %d: %w", depth, err)
 	}
 
-	result, err := runnable.Invoke(ctx, state)
+	result, err := runnable.Invoke(withSubgraphNamespace(ctx), state)
 	if err != nil {
 		var zero S
 		return zero, fmt.Errorf("recursive execution failed at depth %d: %w", depth, err)
@@ -204,6 +205,7 @@ func AddRecursiveSubgraph[S, SubS any](
 	}
 
 	g.AddNode(name, "Recursive subgraph: "+name, wrappedFn)
+	g.setNodeStructure(name, nodeStructure{kind: NodeKindSubgraph, subgraph: rs.graph.Topology})
 	return nil
 }
 
@@ -237,7 +239,7 @@ func AddNestedConditionalSubgraph[S, SubS any](
 			return zero, fmt.Errorf("failed to compile subgraph %s: %w", subgraphName, err)
 		}
 
-		result, err := runnable.Invoke(ctx, subState)
+		result, err := runnable.Invoke(withSubgraphNamespace(ctx), subState)
 		if err != nil {
 			var zero S
 			return zero, err
@@ -0,0 +1,231 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigTimeout(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("fast", "fast", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"fast": true}, nil
+	})
+	// slow ignores its context on purpose
+	g.AddNode("slow", "slow", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		time.Sleep(500 * time.Millisecond)
+		return map[string]any{"slow": true}, nil
+	})
+	g.SetEntryPoint("fast")
+	g.AddEdge("fast", "slow")
+	g.AddEdge("slow", END)
+	g.SetSchema(NewMapSchema())
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	timeout := 50 * time.Millisecond
+	start := time.Now()
+	res, err := runnable.InvokeWithConfig(context.Background(), map[string]any{}, &Config{Timeout: &timeout})
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrGraphTimeout))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	var timeoutErr *GraphTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, timeout, timeoutErr.Timeout)
+	assert.Equal(t, []string{"slow"}, timeoutErr.Nodes)
+
+	// The state of the last completed superstep is returned
+	assert.Equal(t, true, res["fast"])
+	assert.Nil(t, res["slow"])
+}
+
+func TestNodeTimeoutOption(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("slow", "slow", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return state, nil
+		}
+	}, WithNodeTimeout(20*time.Millisecond))
+	g.SetEntryPoint("slow")
+	g.AddEdge("slow", END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), map[string]any{})
+	require.Error(t, err)
+
+	var nodeErr *NodeTimeoutError
+	require.True(t, errors.As(err, &nodeErr))
+	assert.Equal(t, "slow", nodeErr.Node)
+	assert.Equal(t, 20*time.Millisecond, nodeErr.Timeout)
+	assert.False(t, errors.Is(err, ErrGraphTimeout))
+}
+
+func TestTimeoutCheckpointResume(t *testing.T) {
+	var slowMode atomic.Bool
+	slowMode.Store(true)
+
+	g := NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("first", "first", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"first": true}, nil
+	})
+	g.AddNode("second", "second", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if slowMode.Load() {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return map[string]any{"second": true}, nil
+	})
+	g.SetEntryPoint("first")
+	g.AddEdge("first", "second")
+	g.AddEdge("second", END)
+	g.SetSchema(NewMapSchema())
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	timeout := 30 * time.Millisecond
+	config := WithThreadID("timeout-thread")
+	config.Timeout = &timeout
+
+	_, err = runnable.InvokeWithConfig(ctx, map[string]any{}, config)
+	require.True(t, errors.Is(err, ErrGraphTimeout))
+
+	// The checkpoint points at the node that timed out, with the state of the last completed step
+	snapshot, err := runnable.GetState(ctx, WithThreadID("timeout-thread"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second"}, snapshot.Next)
+	assert.Equal(t, true, snapshot.Values.(map[string]any)["first"])
+
+	// Resuming the thread re-runs only the timed-out node
+	slowMode.Store(false)
+	res, err := runnable.InvokeWithConfig(ctx, map[string]any{}, WithThreadID("timeout-thread"))
+	require.NoError(t, err)
+	assert.Equal(t, true, res["first"])
+	assert.Equal(t, true, res["second"])
+}
+
+func TestTimeoutCheckpointResume_Parallel(t *testing.T) {
+	var slowMode atomic.Bool
+	var runs atomic.Int32
+
+	g := NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("start", "start", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"start": true}, nil
+	})
+	for _, name := range []string{"a", "b"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			runs.Add(1)
+			if slowMode.Load() {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return map[string]any{name: true}, nil
+		})
+		g.AddEdge("start", name)
+		g.AddEdge(name, END)
+	}
+	g.SetEntryPoint("start")
+	g.SetSchema(NewMapSchema())
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	timeout := 30 * time.Millisecond
+	timeOut := func(threadID string) {
+		slowMode.Store(true)
+		config := WithThreadID(threadID)
+		config.Timeout = &timeout
+		_, err := runnable.InvokeWithConfig(ctx, map[string]any{}, config)
+		var timeoutErr *GraphTimeoutError
+		require.True(t, errors.As(err, &timeoutErr))
+		assert.ElementsMatch(t, []string{"a", "b"}, timeoutErr.Nodes)
+
+		// The checkpoint records both scheduled nodes, under a real node name
+		snapshot, err := runnable.GetState(ctx, WithThreadID(threadID))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, snapshot.Next)
+		assert.ElementsMatch(t, []string{"a", "b"}, snapshot.Metadata["next_nodes"])
+		assert.Equal(t, true, snapshot.Values.(map[string]any)["start"])
+		slowMode.Store(false)
+		runs.Store(0)
+	}
+
+	// Resuming with InvokeWithConfig
+	timeOut("parallel-invoke")
+	res, err := runnable.InvokeWithConfig(ctx, map[string]any{}, WithThreadID("parallel-invoke"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"start": true, "a": true, "b": true}, res)
+	assert.Equal(t, int32(2), runs.Load())
+
+	// Resuming with Resume
+	timeOut("parallel-resume")
+	res, err = runnable.Resume(ctx, WithThreadID("parallel-resume"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"start": true, "a": true, "b": true}, res)
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestTimeoutCheckpointResume_Send(t *testing.T) {
+	var slowMode atomic.Bool
+	var runs atomic.Int32
+
+	g := NewCheckpointableStateGraph[map[string]any]()
+	g.AddNode("split", "split", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"split": true}, nil
+	})
+	g.AddNode("worker", "worker", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		runs.Add(1)
+		if slowMode.Load() {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return map[string]any{"items": []any{state["item"]}}, nil
+	})
+	g.SetEntryPoint("split")
+	g.AddSendEdge("split", func(ctx context.Context, state map[string]any) []Send {
+		return []Send{
+			NewSend("worker", map[string]any{"item": "x"}),
+			NewSend("worker", map[string]any{"item": "y"}),
+		}
+	})
+	g.AddEdge("worker", END)
+	schema := NewMapSchema()
+	schema.RegisterReducer("items", AppendReducer)
+	g.SetSchema(schema)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	timeout := 30 * time.Millisecond
+	slowMode.Store(true)
+	config := WithThreadID("send-timeout")
+	config.Timeout = &timeout
+	_, err = runnable.InvokeWithConfig(ctx, map[string]any{}, config)
+	require.True(t, errors.Is(err, ErrGraphTimeout))
+
+	// Send tasks are not recorded as node names, so resuming routes the fan-out again and each
+	// copy runs with its own argument
+	slowMode.Store(false)
+	runs.Store(0)
+	res, err := runnable.Resume(ctx, WithThreadID("send-timeout"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"x", "y"}, res["items"])
+	assert.Equal(t, int32(2), runs.Load())
+}
@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Node kinds of a GraphTopology.
+const (
+	NodeKindNode      = "node"
+	NodeKindSubgraph  = "subgraph"
+	NodeKindParallel  = "parallel"
+	NodeKindMapReduce = "map_reduce"
+)
+
+// Edge kinds of a GraphTopology.
+const (
+	EdgeKindStatic      = "static"
+	EdgeKindConditional = "conditional"
+	EdgeKindJoin        = "join"
+	EdgeKindSend        = "send"
+)
+
+// GraphTopology describes the declared structure of a graph, with the internals of its
+// subgraph, parallel and map-reduce nodes. It is the JSON export of the graph.
+type GraphTopology struct {
+	EntryPoint string         `json:"entry_point,omitempty"`
+	Nodes      []TopologyNode `json:"nodes"`
+	Edges      []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode describes a node of a GraphTopology.
+type TopologyNode struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Kind is one of the NodeKind constants
+	Kind string `json:"kind"`
+
+	// Branches are the functions a parallel or map-reduce node runs concurrently
+	Branches []string `json:"branches,omitempty"`
+
+	// Subgraph is the topology of the graph a subgraph node runs
+	Subgraph *GraphTopology `json:"subgraph,omitempty"`
+}
+
+// TopologyEdge describes an edge of a GraphTopology.
+type TopologyEdge struct {
+	From string `json:"from"`
+
+	// To is empty for conditional edges without a path map and Send edges, whose targets are
+	// only known at runtime
+	To string `json:"to,omitempty"`
+
+	// Kind is one of the EdgeKind constants
+	Kind string `json:"kind"`
+
+	// Label is the router label of a conditional branch
+	Label string `json:"label,omitempty"`
+}
+
+// nodeStructure records how a node was built, for visualization.
+type nodeStructure struct {
+	kind     string
+	branches []string
+	// subgraph returns the topology of the graph a subgraph node runs
+	subgraph func() *GraphTopology
+}
+
+// setNodeStructure records the internals of a node.
+func (g *StateGraph[S]) setNodeStructure(name string, structure nodeStructure) {
+	if g.nodeStructures == nil {
+		g.nodeStructures = make(map[string]nodeStructure)
+	}
+	g.nodeStructures[name] = structure
+}
+
+// Topology returns the declared structure of the graph. Nodes are sorted by name, the entry
+// point first. Static and join edges keep the order they were added in; conditional and send
+// edges follow, sorted by source node and then by label.
+func (g *StateGraph[S]) Topology() *GraphTopology {
+	topology := &GraphTopology{
+		EntryPoint: g.entryPoint,
+		Nodes:      []TopologyNode{},
+		Edges:      []TopologyEdge{},
+	}
+
+	names := sortedKeys(g.nodes)
+	if i := slices.Index(names, g.entryPoint); i > 0 {
+		names = append(append([]string{g.entryPoint}, names[:i]...), names[i+1:]...)
+	}
+	for _, name := range names {
+		node := g.nodes[name]
+		tn := TopologyNode{Name: name, Description: node.Description, Kind: NodeKindNode}
+		if structure, ok := g.nodeStructures[name]; ok {
+			tn.Kind = structure.kind
+			tn.Branches = structure.branches
+			if structure.subgraph != nil {
+				tn.Subgraph = structure.subgraph()
+			}
+		}
+		topology.Nodes = append(topology.Nodes, tn)
+	}
+
+	for _, edge := range g.edges {
+		topology.Edges = append(topology.Edges, TopologyEdge{From: edge.From, To: edge.To, Kind: EdgeKindStatic})
+	}
+	for _, join := range g.joinEdges {
+		for _, from := range join.From {
+			topology.Edges = append(topology.Edges, TopologyEdge{From: from, To: join.To, Kind: EdgeKindJoin})
+		}
+	}
+	for _, from := range sortedKeys(g.conditionalEdges) {
+		pathMap := g.conditionalEdges[from].pathMap
+		if pathMap == nil {
+			topology.Edges = append(topology.Edges, TopologyEdge{From: from, Kind: EdgeKindConditional})
+			continue
+		}
+		for _, label := range sortedKeys(pathMap) {
+			topology.Edges = append(topology.Edges, TopologyEdge{From: from, To: pathMap[label], Kind: EdgeKindConditional, Label: label})
+		}
+	}
+	for _, from := range sortedKeys(g.sendEdges) {
+		topology.Edges = append(topology.Edges, TopologyEdge{From: from, Kind: EdgeKindSend})
+	}
+	return topology
+}
+
+// node returns the node with the given name, or nil.
+func (t *GraphTopology) node(name string) *TopologyNode {
+	for i := range t.Nodes {
+		if t.Nodes[i].Name == name {
+			return &t.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Topology returns the declared structure of the graph.
+func (ge *Exporter[S]) Topology() *GraphTopology {
+	return ge.graph.Topology()
+}
+
+// DrawJSON exports the declared structure of the graph as indented JSON, for other tools.
+func (ge *Exporter[S]) DrawJSON() (string, error) {
+	data, err := json.MarshalIndent(ge.Topology(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -2,7 +2,11 @@ package graph
 
 import (
 	"context"
+	"maps"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TraceEvent represents different types of events in graph execution
@@ -24,6 +28,10 @@ const (
 	// TraceEventNodeError indicates an error occurred in node execution
 	TraceEventNodeError TraceEvent = "node_error"
 
+	// TraceEventNodeRetry indicates a failed node attempt that will be retried.
+	// The span metadata holds the "attempt" number and the "delay" before the next attempt.
+	TraceEventNodeRetry TraceEvent = "node_retry"
+
 	// TraceEventEdgeTraversal indicates traversal from one node to another
 	TraceEventEdgeTraversal TraceEvent = "edge_traversal"
 )
@@ -73,6 +81,15 @@ type TraceHook interface {
 	OnEvent(ctx context.Context, span *TraceSpan)
 }
 
+// TraceContextHook can be implemented by trace hooks that keep their own context for a span,
+// e.g. to make it the parent of the spans started by the code running inside a node.
+type TraceContextHook interface {
+	TraceHook
+
+	// ContextWithSpan returns ctx decorated for the code running within span
+	ContextWithSpan(ctx context.Context, span *TraceSpan) context.Context
+}
+
 // TraceHookFunc is a function adapter for TraceHook
 type TraceHookFunc func(ctx context.Context, span *TraceSpan)
 
@@ -85,6 +102,7 @@ func (f TraceHookFunc) OnEvent(ctx context.Context, span *TraceSpan) {
 type Tracer struct {
 	hooks []TraceHook
 	spans map[string]*TraceSpan
+	mu    sync.RWMutex
 }
 
 // NewTracer creates a new tracer instance
@@ -115,7 +133,9 @@ func (t *Tracer) StartSpan(ctx context.Context, event TraceEvent, nodeName strin
 		span.ParentID = parentSpan.ID
 	}
 
+	t.mu.Lock()
 	t.spans[span.ID] = span
+	t.mu.Unlock()
 
 	// Notify hooks
 	for _, hook := range t.hooks {
@@ -165,7 +185,9 @@ func (t *Tracer) TraceEdgeTraversal(ctx context.Context, fromNode, toNode string
 		span.ParentID = parentSpan.ID
 	}
 
+	t.mu.Lock()
 	t.spans[span.ID] = span
+	t.mu.Unlock()
 
 	// Notify hooks
 	for _, hook := range t.hooks {
@@ -173,13 +195,29 @@ func (t *Tracer) TraceEdgeTraversal(ctx context.Context, fromNode, toNode string
 	}
 }
 
+// ContextWithSpan returns a context carrying the span, as ContextWithSpan does,
+// decorated by the hooks implementing TraceContextHook.
+func (t *Tracer) ContextWithSpan(ctx context.Context, span *TraceSpan) context.Context {
+	ctx = ContextWithSpan(ctx, span)
+	for _, hook := range t.hooks {
+		if ch, ok := hook.(TraceContextHook); ok {
+			ctx = ch.ContextWithSpan(ctx, span)
+		}
+	}
+	return ctx
+}
+
 // GetSpans returns all collected spans
 func (t *Tracer) GetSpans() map[string]*TraceSpan {
-	return t.spans
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return maps.Clone(t.spans)
 }
 
 // Clear removes all collected spans
 func (t *Tracer) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.spans = make(map[string]*TraceSpan)
 }
 
@@ -188,6 +226,8 @@ type contextKey string
 
 const spanContextKey contextKey = "langgraph_span"
 
+const tracerContextKey contextKey = "langgraph_tracer"
+
 // ContextWithSpan returns a new context with the span stored
 func ContextWithSpan(ctx context.Context, span *TraceSpan) context.Context {
 	return context.WithValue(ctx, spanContextKey, span)
@@ -201,9 +241,21 @@ func SpanFromContext(ctx context.Context) *TraceSpan {
 	return nil
 }
 
+// withTracer returns a context carrying the tracer, so that subgraphs and parallel nodes
+// running inside a traced graph report their spans to it.
+func withTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerContextKey, tracer)
+}
+
+// tracerFromContext returns the tracer of the graph the context runs in, if any.
+func tracerFromContext(ctx context.Context) *Tracer {
+	tracer, _ := ctx.Value(tracerContextKey).(*Tracer)
+	return tracer
+}
+
 // generateSpanID creates a unique span identifier
 func generateSpanID() string {
-	return time.Now().Format("20060102150405.000000")
+	return uuid.New().String()
 }
 
 // TracedRunnable wraps a Runnable with tracing capabilities
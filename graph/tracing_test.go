@@ -309,6 +309,70 @@ func TestTracer_SpanHierarchy(t *testing.T) {
 }
 
 // Benchmark tests
+func TestTracer_SubgraphSpansNestUnderNode(t *testing.T) {
+	t.Parallel()
+
+	inner := graph.NewStateGraph[map[string]any]()
+	inner.AddNode("child", "child", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	inner.SetEntryPoint("child")
+	inner.AddEdge("child", graph.END)
+
+	g := graph.NewStateGraph[map[string]any]()
+	if err := graph.AddSubgraph(g, "sub", inner,
+		func(s map[string]any) map[string]any { return s },
+		func(s map[string]any) map[string]any { return s }); err != nil {
+		t.Fatalf("Failed to add subgraph: %v", err)
+	}
+	g.AddNode("last", "last", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("sub")
+	g.AddEdge("sub", "last")
+	g.AddEdge("last", graph.END)
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	tracer := graph.NewTracer()
+	runnable.SetTracer(tracer)
+
+	if _, err := runnable.Invoke(context.Background(), map[string]any{}); err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+
+	byNode := make(map[string]*graph.TraceSpan)
+	var edges []string
+	var graphSpans int
+	for _, span := range tracer.GetSpans() {
+		switch span.Event {
+		case graph.TraceEventNodeEnd:
+			byNode[span.NodeName] = span
+		case graph.TraceEventEdgeTraversal:
+			edges = append(edges, span.FromNode+"->"+span.ToNode)
+		case graph.TraceEventGraphEnd:
+			graphSpans++
+		}
+	}
+
+	if graphSpans != 2 {
+		t.Errorf("Expected spans for the graph and the subgraph, got %d", graphSpans)
+	}
+	child, sub := byNode["child"], byNode["sub"]
+	if child == nil || sub == nil {
+		t.Fatalf("Expected spans for nodes sub and child, got %v", byNode)
+	}
+	subgraphSpan := tracer.GetSpans()[child.ParentID]
+	if subgraphSpan == nil || subgraphSpan.ParentID != sub.ID {
+		t.Error("Expected the subgraph span to be a child of the span of node sub")
+	}
+	if !strings.Contains(strings.Join(edges, ","), "sub->last") {
+		t.Errorf("Expected an edge traversal from sub to last, got %v", edges)
+	}
+}
+
 func BenchmarkTracer_StartEndSpan(b *testing.B) {
 	tracer := graph.NewTracer()
 	ctx := context.Background()
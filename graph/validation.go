@@ -0,0 +1,431 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidGraph is returned by ValidationReport.Err when validation found errors.
+// Use errors.As with *ValidationError to get the report.
+var ErrInvalidGraph = errors.New("invalid graph")
+
+// ValidationSeverity tells whether a validation issue prevents the graph from running correctly.
+type ValidationSeverity string
+
+const (
+	// SeverityError marks a problem that makes execution fail or never finish.
+	SeverityError ValidationSeverity = "error"
+
+	// SeverityWarning marks a likely mistake that does not necessarily break execution.
+	SeverityWarning ValidationSeverity = "warning"
+)
+
+// ValidationCode identifies the kind of a validation issue.
+type ValidationCode string
+
+const (
+	// CodeMissingEntryPoint means SetEntryPoint was not called.
+	CodeMissingEntryPoint ValidationCode = "missing_entry_point"
+
+	// CodeUnknownNode means an edge or the entry point refers to a node that was never added.
+	CodeUnknownNode ValidationCode = "unknown_node"
+
+	// CodeDuplicateNode means a node name was added more than once; the last definition wins.
+	CodeDuplicateNode ValidationCode = "duplicate_node"
+
+	// CodeUnreachableNode means a node cannot be reached from the entry point.
+	CodeUnreachableNode ValidationCode = "unreachable_node"
+
+	// CodeDeadEnd means a node has no outgoing edge, so execution fails with ErrNoOutgoingEdge.
+	CodeDeadEnd ValidationCode = "dead_end"
+
+	// CodeCycleWithoutExit means no conditional edge leads out of a cycle, so it never ends.
+	CodeCycleWithoutExit ValidationCode = "cycle_without_exit"
+
+	// CodeInvalidRetryPolicy means the retry policy cannot behave as configured.
+	CodeInvalidRetryPolicy ValidationCode = "invalid_retry_policy"
+)
+
+// ValidationIssue describes a single problem found by Validate.
+type ValidationIssue struct {
+	Severity ValidationSeverity `json:"severity"`
+	Code     ValidationCode     `json:"code"`
+
+	// Nodes lists the nodes involved in the issue, if any
+	Nodes []string `json:"nodes,omitempty"`
+
+	Message string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s [%s]: %s", i.Severity, i.Code, i.Message)
+}
+
+// ValidationReport is the result of StateGraph.Validate.
+type ValidationReport struct {
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// Valid reports whether the graph has no validation errors. Warnings are allowed.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns a *ValidationError when the report contains errors, or nil otherwise.
+func (r *ValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	return &ValidationError{Report: r}
+}
+
+// Issues returns the errors followed by the warnings.
+func (r *ValidationReport) Issues() []ValidationIssue {
+	return append(slices.Clone(r.Errors), r.Warnings...)
+}
+
+func (r *ValidationReport) add(severity ValidationSeverity, code ValidationCode, nodes []string, format string, args ...any) {
+	issue := ValidationIssue{
+		Severity: severity,
+		Code:     code,
+		Nodes:    nodes,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if severity == SeverityError {
+		r.Errors = append(r.Errors, issue)
+	} else {
+		r.Warnings = append(r.Warnings, issue)
+	}
+}
+
+// ValidationError wraps a report that contains errors. It matches ErrInvalidGraph with errors.Is.
+type ValidationError struct {
+	Report *ValidationReport
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Report.Errors))
+	for _, issue := range e.Report.Errors {
+		messages = append(messages, issue.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidGraph, strings.Join(messages, "; "))
+}
+
+// Is reports whether target is ErrInvalidGraph.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidGraph
+}
+
+// Validate checks the structure of the graph without running it and returns every issue found.
+// It reports unknown edge targets and sources, duplicate node names, nodes without an
+// outgoing edge, cycles that cannot be left, unreachable nodes and retry policies that
+// cannot work as configured.
+//
+// Targets of conditional and send edges are only known when a path map is declared
+// (see AddConditionalEdge). When routing cannot be fully analyzed, reachability is not
+// reported, and for graphs whose nodes may return a Command (state type any), dead ends
+// and cycles are reported as warnings since Command.Goto can route anywhere.
+//
+// Compile does not call Validate, so graphs can be checked in unit tests:
+//
+//	report := g.Validate()
+//	if err := report.Err(); err != nil {
+//	    t.Fatal(err)
+//	}
+func (g *StateGraph[S]) Validate() *ValidationReport {
+	report := &ValidationReport{}
+
+	nodeNames := make([]string, 0, len(g.nodes))
+	for name := range g.nodes {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+
+	exists := func(name string) bool {
+		_, ok := g.nodes[name]
+		return ok
+	}
+	isTarget := func(name string) bool {
+		return name == END || exists(name)
+	}
+
+	// Entry point
+	if g.entryPoint == "" {
+		report.add(SeverityError, CodeMissingEntryPoint, nil, "entry point is not set")
+	} else if !exists(g.entryPoint) {
+		report.add(SeverityError, CodeUnknownNode, []string{g.entryPoint}, "entry point %s is not a node", g.entryPoint)
+	}
+
+	// Duplicate names
+	for _, name := range uniqueNodes(g.duplicateNodes) {
+		report.add(SeverityError, CodeDuplicateNode, []string{name}, "node %s is added more than once", name)
+	}
+
+	// Unknown sources and targets
+	for _, edge := range g.edges {
+		if !exists(edge.From) {
+			report.add(SeverityError, CodeUnknownNode, []string{edge.From}, "edge %s -> %s starts at unknown node %s", edge.From, edge.To, edge.From)
+		}
+		if !isTarget(edge.To) {
+			report.add(SeverityError, CodeUnknownNode, []string{edge.To}, "edge %s -> %s points to unknown node %s", edge.From, edge.To, edge.To)
+		}
+	}
+	for _, join := range g.joinEdges {
+		for _, from := range join.From {
+			if !exists(from) {
+				report.add(SeverityError, CodeUnknownNode, []string{from}, "join edge to %s waits for unknown node %s", join.To, from)
+			}
+		}
+		if !isTarget(join.To) {
+			report.add(SeverityError, CodeUnknownNode, []string{join.To}, "join edge points to unknown node %s", join.To)
+		}
+	}
+	for _, from := range sortedKeys(g.conditionalEdges) {
+		if !exists(from) {
+			report.add(SeverityError, CodeUnknownNode, []string{from}, "conditional edge starts at unknown node %s", from)
+		}
+		pathMap := g.conditionalEdges[from].pathMap
+		for _, label := range sortedKeys(pathMap) {
+			if !isTarget(pathMap[label]) {
+				report.add(SeverityError, CodeUnknownNode, []string{pathMap[label]}, "conditional edge from %s maps %q to unknown node %s", from, label, pathMap[label])
+			}
+		}
+	}
+	for _, from := range sortedKeys(g.sendEdges) {
+		if !exists(from) {
+			report.add(SeverityError, CodeUnknownNode, []string{from}, "send edge starts at unknown node %s", from)
+		}
+	}
+
+	// Commands can route anywhere when the state type is any
+	var zero S
+	_, commandsPossible := any(&zero).(*any)
+	routingSeverity := SeverityError
+	if commandsPossible {
+		routingSeverity = SeverityWarning
+	}
+
+	// Dead ends
+	for _, name := range nodeNames {
+		if !g.hasOutgoing(name) {
+			report.add(routingSeverity, CodeDeadEnd, []string{name}, "node %s has no outgoing edge", name)
+		}
+	}
+
+	// Cycles without an exit
+	for _, component := range g.stronglyConnected(nodeNames) {
+		if !g.canLeave(component) {
+			cycle := append(slices.Clone(component), component[0])
+			report.add(routingSeverity, CodeCycleWithoutExit, component, "cycle %s has no conditional edge leaving it", strings.Join(cycle, " -> "))
+		}
+	}
+
+	// Unreachable nodes, when every route is declared
+	if g.entryPoint != "" && exists(g.entryPoint) && !commandsPossible {
+		reachable, complete := g.reachableFrom(g.entryPoint)
+		if complete {
+			for _, name := range nodeNames {
+				if !reachable[name] {
+					report.add(SeverityWarning, CodeUnreachableNode, []string{name}, "node %s is not reachable from entry point %s", name, g.entryPoint)
+				}
+			}
+		}
+	}
+
+	// Retry policies
+	validateRetryPolicy(report, g.retryPolicy, nil)
+	for _, name := range nodeNames {
+		validateRetryPolicy(report, g.nodes[name].Options.RetryPolicy, []string{name})
+	}
+
+	return report
+}
+
+// validateRetryPolicy reports retry policy settings that cannot work as configured.
+// nodes is empty for the graph-wide policy.
+func validateRetryPolicy(report *ValidationReport, policy *RetryPolicy, nodes []string) {
+	if policy == nil {
+		return
+	}
+	owner := "graph retry policy"
+	if len(nodes) > 0 {
+		owner = fmt.Sprintf("retry policy of node %s", nodes[0])
+	}
+
+	if policy.MaxRetries < 0 {
+		report.add(SeverityError, CodeInvalidRetryPolicy, nodes, "%s has negative MaxRetries %d", owner, policy.MaxRetries)
+	}
+	if policy.MaxRetries > 0 && policy.Retryable == nil && len(policy.RetryableErrors) == 0 {
+		report.add(SeverityWarning, CodeInvalidRetryPolicy, nodes, "%s allows %d retries but classifies no error as retryable, so nothing is retried", owner, policy.MaxRetries)
+	}
+	if policy.Retryable == nil && slices.Contains(policy.RetryableErrors, "") {
+		report.add(SeverityWarning, CodeInvalidRetryPolicy, nodes, "%s has an empty retryable error pattern, which matches every error", owner)
+	}
+	switch policy.BackoffStrategy {
+	case FixedBackoff, ExponentialBackoff, LinearBackoff:
+	default:
+		report.add(SeverityWarning, CodeInvalidRetryPolicy, nodes, "%s has unknown backoff strategy %d, fixed backoff is used", owner, policy.BackoffStrategy)
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		report.add(SeverityWarning, CodeInvalidRetryPolicy, nodes, "%s has jitter %v outside [0, 1]", owner, policy.Jitter)
+	}
+	if policy.MaxDelay < 0 || policy.InitialDelay < 0 {
+		report.add(SeverityError, CodeInvalidRetryPolicy, nodes, "%s has a negative delay", owner)
+	}
+}
+
+// hasOutgoing reports whether the node has any way to route to a next node.
+func (g *StateGraph[S]) hasOutgoing(name string) bool {
+	if _, ok := g.conditionalEdges[name]; ok {
+		return true
+	}
+	if _, ok := g.sendEdges[name]; ok {
+		return true
+	}
+	for _, edge := range g.edges {
+		if edge.From == name {
+			return true
+		}
+	}
+	for _, join := range g.joinEdges {
+		if slices.Contains(join.From, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// successors returns the statically known next nodes of name and whether that list is complete.
+func (g *StateGraph[S]) successors(name string) ([]string, bool) {
+	var next []string
+	complete := true
+	for _, edge := range g.edges {
+		if edge.From == name {
+			next = append(next, edge.To)
+		}
+	}
+	for _, join := range g.joinEdges {
+		if slices.Contains(join.From, name) {
+			next = append(next, join.To)
+		}
+	}
+	if edge, ok := g.conditionalEdges[name]; ok {
+		if edge.pathMap == nil {
+			complete = false
+		}
+		for _, label := range sortedKeys(edge.pathMap) {
+			next = append(next, edge.pathMap[label])
+		}
+	}
+	if _, ok := g.sendEdges[name]; ok {
+		complete = false
+	}
+	return uniqueNodes(next), complete
+}
+
+// reachableFrom returns the nodes reachable from start through declared edges,
+// and whether every reachable node has fully declared routing.
+func (g *StateGraph[S]) reachableFrom(start string) (map[string]bool, bool) {
+	reachable := map[string]bool{start: true}
+	complete := true
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		next, ok := g.successors(name)
+		complete = complete && ok
+		for _, n := range next {
+			if n != END && !reachable[n] {
+				reachable[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return reachable, complete
+}
+
+// stronglyConnected returns the cycles of the declared edges as strongly connected components,
+// using Tarjan's algorithm. Components are single nodes only when the node loops to itself.
+func (g *StateGraph[S]) stronglyConnected(nodeNames []string) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	counter := 0
+
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = counter
+		low[name] = counter
+		counter++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		next, _ := g.successors(name)
+		for _, n := range next {
+			if _, ok := g.nodes[n]; !ok {
+				continue
+			}
+			if _, seen := index[n]; !seen {
+				visit(n)
+				low[name] = min(low[name], low[n])
+			} else if onStack[n] {
+				low[name] = min(low[name], index[n])
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+		var component []string
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[n] = false
+			component = append(component, n)
+			if n == name {
+				break
+			}
+		}
+		next, _ = g.successors(name)
+		if len(component) > 1 || slices.Contains(next, name) {
+			slices.Reverse(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, name := range nodeNames {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+	return components
+}
+
+// canLeave reports whether a conditional or send edge can route execution out of the cycle.
+// Regular edges leaving a cycle do not count: they fan out while the cycle keeps running.
+func (g *StateGraph[S]) canLeave(component []string) bool {
+	for _, name := range component {
+		if _, ok := g.sendEdges[name]; ok {
+			return true
+		}
+		edge, ok := g.conditionalEdges[name]
+		if !ok {
+			continue
+		}
+		if edge.pathMap == nil {
+			return true
+		}
+		for _, target := range edge.pathMap {
+			if !slices.Contains(component, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopNode(ctx context.Context, state map[string]any) (map[string]any, error) {
+	return state, nil
+}
+
+// issueCodes returns the codes of issues in order.
+func issueCodes(issues []ValidationIssue) []ValidationCode {
+	codes := make([]ValidationCode, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestValidateValidGraph(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode)
+	g.AddNode("b", "b", noopNode)
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "b")
+	g.AddConditionalEdge("b", func(ctx context.Context, state map[string]any) string {
+		return END
+	}, map[string]string{"again": "a", "done": END})
+
+	report := g.Validate()
+	assert.True(t, report.Valid())
+	assert.Empty(t, report.Issues())
+	assert.NoError(t, report.Err())
+}
+
+func TestValidateUnknownTargetsAndDuplicates(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode)
+	g.AddNode("a", "a again", noopNode)
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "missing")
+	g.AddEdge("ghost", END)
+
+	report := g.Validate()
+	assert.False(t, report.Valid())
+	assert.ElementsMatch(t, []ValidationCode{CodeDuplicateNode, CodeUnknownNode, CodeUnknownNode}, issueCodes(report.Errors))
+
+	err := report.Err()
+	assert.True(t, errors.Is(err, ErrInvalidGraph))
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Contains(t, err.Error(), "points to unknown node missing")
+	assert.Contains(t, err.Error(), "starts at unknown node ghost")
+}
+
+func TestValidateEntryPoint(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	assert.Equal(t, []ValidationCode{CodeMissingEntryPoint}, issueCodes(g.Validate().Errors))
+
+	g.SetEntryPoint("nowhere")
+	assert.Equal(t, []ValidationCode{CodeUnknownNode}, issueCodes(g.Validate().Errors))
+}
+
+func TestValidateDeadEndAndUnreachable(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode)
+	g.AddNode("b", "b", noopNode)
+	g.AddNode("orphan", "orphan", noopNode)
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "b")
+	g.AddEdge("orphan", END)
+
+	report := g.Validate()
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, CodeDeadEnd, report.Errors[0].Code)
+	assert.Equal(t, []string{"b"}, report.Errors[0].Nodes)
+
+	require.Len(t, report.Warnings, 1)
+	assert.Equal(t, CodeUnreachableNode, report.Warnings[0].Code)
+	assert.Equal(t, []string{"orphan"}, report.Warnings[0].Nodes)
+}
+
+func TestValidateSkipsReachabilityForOpaqueRouters(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode)
+	g.AddNode("b", "b", noopNode)
+	g.SetEntryPoint("a")
+	g.AddConditionalEdge("a", func(ctx context.Context, state map[string]any) string {
+		return "b"
+	})
+	g.AddEdge("b", END)
+
+	assert.Empty(t, g.Validate().Issues())
+}
+
+func TestValidateCycleWithoutExit(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode)
+	g.AddNode("b", "b", noopNode)
+	g.AddNode("c", "c", noopNode)
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "b")
+	// A regular edge leaving the cycle fans out; the cycle keeps running
+	g.AddEdge("c", END)
+
+	report := g.Validate()
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, CodeCycleWithoutExit, report.Errors[0].Code)
+	assert.Equal(t, []string{"b", "c"}, report.Errors[0].Nodes)
+	assert.Contains(t, report.Errors[0].Message, "b -> c -> b")
+
+	// The loop graph exits through its conditional edge
+	assert.Empty(t, newLoopGraph(3).Validate().Issues())
+}
+
+func TestValidateCommandGraphsWarn(t *testing.T) {
+	g := NewStateGraph[any]()
+	g.AddNode("router", "router", func(ctx context.Context, state any) (any, error) {
+		return &Command{Goto: END}, nil
+	})
+	g.SetEntryPoint("router")
+
+	report := g.Validate()
+	assert.True(t, report.Valid())
+	assert.Equal(t, []ValidationCode{CodeDeadEnd}, issueCodes(report.Warnings))
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("a", "a", noopNode)
+	g.SetEntryPoint("a")
+	g.AddEdge("a", END)
+
+	g.SetRetryPolicy(&RetryPolicy{MaxRetries: -1})
+	assert.Equal(t, []ValidationCode{CodeInvalidRetryPolicy}, issueCodes(g.Validate().Errors))
+
+	g.SetRetryPolicy(&RetryPolicy{MaxRetries: 3, BackoffStrategy: BackoffStrategy(42)})
+	report := g.Validate()
+	assert.True(t, report.Valid())
+	assert.Equal(t, []ValidationCode{CodeInvalidRetryPolicy, CodeInvalidRetryPolicy}, issueCodes(report.Warnings))
+
+	g.SetRetryPolicy(&RetryPolicy{MaxRetries: 3, RetryableErrors: []string{"timeout"}})
+	assert.Empty(t, g.Validate().Issues())
+}
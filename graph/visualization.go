@@ -2,6 +2,7 @@ package graph
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -29,7 +30,9 @@ func (ge *Exporter[S]) DrawMermaid() string {
 	})
 }
 
-// DrawMermaidWithOptions generates a Mermaid diagram with custom options
+// DrawMermaidWithOptions generates a Mermaid diagram with custom options.
+// Subgraph nodes are drawn as nested subgraph blocks, parallel and map-reduce nodes as a
+// fan-out to their branches and a fan-in, and node descriptions are shown as tooltips.
 func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 	var sb strings.Builder
 
@@ -40,62 +43,117 @@ func (ge *Exporter[S]) DrawMermaidWithOptions(opts MermaidOptions) string {
 	}
 	sb.WriteString(fmt.Sprintf("flowchart %s\n", direction))
 
+	writeMermaidGraph(&sb, ge.Topology(), "", "    ")
+	return sb.String()
+}
+
+// writeMermaidGraph writes the nodes and edges of a topology. Nested graphs prefix the IDs
+// of their nodes with the ID of the node embedding them.
+func writeMermaidGraph(sb *strings.Builder, t *GraphTopology, prefix, indent string) {
+	nested := prefix != ""
+	id := func(name string) string { return prefix + name }
+
 	// Add entry point styling
-	if ge.graph.entryPoint != "" {
-		sb.WriteString(fmt.Sprintf("    %s[[\"%s\"]]\n", ge.graph.entryPoint, ge.graph.entryPoint))
-		sb.WriteString(fmt.Sprintf("    %s --> %s\n", "START", ge.graph.entryPoint))
-		sb.WriteString("    START([\"START\"])\n")
-		sb.WriteString("    style START fill:#90EE90\n")
+	if t.EntryPoint != "" {
+		if entry := t.node(t.EntryPoint); entry != nil && entry.Kind != NodeKindNode {
+			writeMermaidNode(sb, entry, id(entry.Name), indent)
+		} else {
+			sb.WriteString(fmt.Sprintf("%s%s[[\"%s\"]]\n", indent, id(t.EntryPoint), t.EntryPoint))
+		}
+		sb.WriteString(fmt.Sprintf("%s%s --> %s\n", indent, id("START"), entryAnchor(t, t.EntryPoint, prefix)))
+		sb.WriteString(fmt.Sprintf("%s%s([\"START\"])\n", indent, id("START")))
+		sb.WriteString(fmt.Sprintf("%sstyle %s fill:#90EE90\n", indent, id("START")))
 	}
 
-	// Get sorted node names for consistent output
-	nodeNames := make([]string, 0, len(ge.graph.nodes))
-	for name := range ge.graph.nodes {
-		if name != ge.graph.entryPoint && name != END {
-			nodeNames = append(nodeNames, name)
+	// Add regular nodes, sorted for consistent output
+	for i := range t.Nodes {
+		node := &t.Nodes[i]
+		if node.Name != t.EntryPoint && node.Name != END {
+			writeMermaidNode(sb, node, id(node.Name), indent)
 		}
 	}
-	sort.Strings(nodeNames)
 
-	// Add regular nodes
-	for _, name := range nodeNames {
-		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", name, name))
+	// Add END node if referenced; nested graphs always have one to leave them
+	if nested || topologyReferencesEnd(t) {
+		sb.WriteString(fmt.Sprintf("%s%s([\"END\"])\n", indent, id(END)))
+		sb.WriteString(fmt.Sprintf("%sstyle %s fill:#FFB6C1\n", indent, id(END)))
 	}
 
-	// Add END node if referenced
-	hasEnd := false
-	for _, edge := range ge.graph.edges {
-		if edge.To == END {
-			hasEnd = true
-			break
+	// Add edges
+	for _, edge := range t.Edges {
+		if edge.Kind == EdgeKindStatic {
+			sb.WriteString(fmt.Sprintf("%s%s --> %s\n", indent, exitAnchor(t, edge.From, prefix), entryAnchor(t, edge.To, prefix)))
 		}
 	}
 
-	if hasEnd {
-		sb.WriteString("    END([\"END\"])\n")
-		sb.WriteString("    style END fill:#FFB6C1\n")
+	// Add join edges, one arrow per target
+	for _, target := range joinTargets(t) {
+		var sources []string
+		for _, edge := range t.Edges {
+			if edge.Kind == EdgeKindJoin && edge.To == target {
+				sources = append(sources, exitAnchor(t, edge.From, prefix))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%s%s ==>|join| %s\n", indent, strings.Join(sources, " & "), entryAnchor(t, target, prefix)))
 	}
 
-	// Add edges
-	for _, edge := range ge.graph.edges {
-		sb.WriteString(fmt.Sprintf("    %s --> %s\n", edge.From, edge.To))
+	// Add conditional edges, with one labeled branch per path map entry when declared
+	for _, edge := range t.Edges {
+		from := exitAnchor(t, edge.From, prefix)
+		switch {
+		case edge.Kind == EdgeKindConditional && edge.To == "":
+			sb.WriteString(fmt.Sprintf("%s%s -.-> %s_condition((?))\n", indent, from, id(edge.From)))
+			sb.WriteString(fmt.Sprintf("%sstyle %s_condition fill:#FFFFE0,stroke:#333,stroke-dasharray: 5 5\n", indent, id(edge.From)))
+		case edge.Kind == EdgeKindConditional:
+			sb.WriteString(fmt.Sprintf("%s%s -.->|%s| %s\n", indent, from, edge.Label, entryAnchor(t, edge.To, prefix)))
+		case edge.Kind == EdgeKindSend:
+			sb.WriteString(fmt.Sprintf("%s%s -.->|Send| %s_send((*))\n", indent, from, id(edge.From)))
+			sb.WriteString(fmt.Sprintf("%sstyle %s_send fill:#FFFFE0,stroke:#333,stroke-dasharray: 5 5\n", indent, id(edge.From)))
+		}
 	}
 
-	// Add conditional edges
-	for from := range ge.graph.conditionalEdges {
-		sb.WriteString(fmt.Sprintf("    %s -.-> %s_condition((?))\n", from, from))
-		sb.WriteString(fmt.Sprintf("    style %s_condition fill:#FFFFE0,stroke:#333,stroke-dasharray: 5 5\n", from))
+	// Style entry point
+	if t.EntryPoint != "" {
+		sb.WriteString(fmt.Sprintf("%sstyle %s fill:#87CEEB\n", indent, id(t.EntryPoint)))
 	}
 
-	// Style entry point
-	if ge.graph.entryPoint != "" {
-		sb.WriteString(fmt.Sprintf("    style %s fill:#87CEEB\n", ge.graph.entryPoint))
+	// Show node descriptions as tooltips
+	for _, node := range t.Nodes {
+		if node.Kind == NodeKindNode && node.Description != "" && node.Description != node.Name {
+			sb.WriteString(fmt.Sprintf("%sclick %s href \"#\" \"%s\"\n", indent, id(node.Name), mermaidEscape(node.Description)))
+		}
 	}
+}
 
-	return sb.String()
+// writeMermaidNode writes a node; subgraph, parallel and map-reduce nodes become blocks.
+func writeMermaidNode(sb *strings.Builder, node *TopologyNode, id, indent string) {
+	switch node.Kind {
+	case NodeKindSubgraph:
+		sb.WriteString(fmt.Sprintf("%ssubgraph %s[\"%s\"]\n", indent, id, node.Name))
+		if node.Subgraph != nil {
+			writeMermaidGraph(sb, node.Subgraph, id+"__", indent+"    ")
+		}
+		sb.WriteString(indent + "end\n")
+	case NodeKindParallel, NodeKindMapReduce:
+		fanOut, fanIn := fanLabels(node.Kind)
+		sb.WriteString(fmt.Sprintf("%ssubgraph %s[\"%s\"]\n", indent, id, node.Name))
+		sb.WriteString(fmt.Sprintf("%s    %s__fork[/\"%s\"\\]\n", indent, id, fanOut))
+		for _, branch := range node.Branches {
+			sb.WriteString(fmt.Sprintf("%s    %s__%s[\"%s\"]\n", indent, id, branch, branch))
+		}
+		sb.WriteString(fmt.Sprintf("%s    %s__join[\\\"%s\"/]\n", indent, id, fanIn))
+		for _, branch := range node.Branches {
+			sb.WriteString(fmt.Sprintf("%s    %s__fork --> %s__%s --> %s__join\n", indent, id, id, branch, id))
+		}
+		sb.WriteString(indent + "end\n")
+	default:
+		sb.WriteString(fmt.Sprintf("%s%s[\"%s\"]\n", indent, id, node.Name))
+	}
 }
 
-// DrawDOT generates a DOT (Graphviz) representation of the graph
+// DrawDOT generates a DOT (Graphviz) representation of the graph.
+// Subgraph nodes are drawn as clusters, parallel and map-reduce nodes as a fan-out to their
+// branches and a fan-in, and node descriptions are shown as tooltips.
 func (ge *Exporter[S]) DrawDOT() string {
 	var sb strings.Builder
 
@@ -103,43 +161,168 @@ func (ge *Exporter[S]) DrawDOT() string {
 	sb.WriteString("    rankdir=TD;\n")
 	sb.WriteString("    node [shape=box];\n")
 
+	writeDOTGraph(&sb, ge.Topology(), "", "    ")
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeDOTGraph writes the nodes and edges of a topology, like writeMermaidGraph.
+func writeDOTGraph(sb *strings.Builder, t *GraphTopology, prefix, indent string) {
+	nested := prefix != ""
+	id := func(name string) string { return prefix + name }
+
 	// Add START node if there's an entry point
-	if ge.graph.entryPoint != "" {
-		sb.WriteString("    START [label=\"START\", shape=ellipse, style=filled, fillcolor=lightgreen];\n")
-		sb.WriteString(fmt.Sprintf("    START -> %s;\n", ge.graph.entryPoint))
+	if t.EntryPoint != "" {
+		sb.WriteString(fmt.Sprintf("%s%s [label=\"START\", shape=ellipse, style=filled, fillcolor=lightgreen];\n", indent, id("START")))
+		sb.WriteString(fmt.Sprintf("%s%s -> %s;\n", indent, id("START"), entryAnchor(t, t.EntryPoint, prefix)))
+	}
+
+	// Nodes of nested graphs are declared inside their cluster; composite nodes are clusters
+	for i := range t.Nodes {
+		node := &t.Nodes[i]
+		switch {
+		case node.Kind != NodeKindNode:
+			writeDOTCluster(sb, node, id(node.Name), indent)
+		case nested:
+			sb.WriteString(fmt.Sprintf("%s%s [label=\"%s\"];\n", indent, id(node.Name), node.Name))
+		}
 	}
 
 	// Add entry point styling
-	if ge.graph.entryPoint != "" {
-		sb.WriteString(fmt.Sprintf("    %s [style=filled, fillcolor=lightblue];\n", ge.graph.entryPoint))
+	if entry := t.node(t.EntryPoint); entry != nil && entry.Kind == NodeKindNode {
+		sb.WriteString(fmt.Sprintf("%s%s [style=filled, fillcolor=lightblue];\n", indent, id(t.EntryPoint)))
 	}
 
-	// Add END node styling if referenced
-	hasEnd := false
-	for _, edge := range ge.graph.edges {
-		if edge.To == END {
-			hasEnd = true
-			break
+	// Add END node styling if referenced; nested graphs always have one to leave them
+	if nested || topologyReferencesEnd(t) {
+		sb.WriteString(fmt.Sprintf("%s%s [label=\"END\", shape=ellipse, style=filled, fillcolor=lightpink];\n", indent, id(END)))
+	}
+
+	// Add edges
+	for _, edge := range t.Edges {
+		if edge.Kind == EdgeKindStatic {
+			sb.WriteString(fmt.Sprintf("%s%s -> %s;\n", indent, exitAnchor(t, edge.From, prefix), entryAnchor(t, edge.To, prefix)))
 		}
 	}
 
-	if hasEnd {
-		sb.WriteString("    END [label=\"END\", shape=ellipse, style=filled, fillcolor=lightpink];\n")
+	// Add join edges
+	for _, edge := range t.Edges {
+		if edge.Kind == EdgeKindJoin {
+			sb.WriteString(fmt.Sprintf("%s%s -> %s [style=bold, label=\"join\"];\n", indent, exitAnchor(t, edge.From, prefix), entryAnchor(t, edge.To, prefix)))
+		}
 	}
 
-	// Add edges
-	for _, edge := range ge.graph.edges {
-		sb.WriteString(fmt.Sprintf("    %s -> %s;\n", edge.From, edge.To))
+	// Add conditional edges, with one labeled branch per path map entry when declared
+	for _, edge := range t.Edges {
+		from := exitAnchor(t, edge.From, prefix)
+		switch {
+		case edge.Kind == EdgeKindConditional && edge.To == "":
+			sb.WriteString(fmt.Sprintf("%s%s -> %s_condition [style=dashed, label=\"?\"];\n", indent, from, id(edge.From)))
+			sb.WriteString(fmt.Sprintf("%s%s_condition [label=\"?\", shape=diamond, style=filled, fillcolor=lightyellow];\n", indent, id(edge.From)))
+		case edge.Kind == EdgeKindConditional:
+			sb.WriteString(fmt.Sprintf("%s%s -> %s [style=dashed, label=\"%s\"];\n", indent, from, entryAnchor(t, edge.To, prefix), edge.Label))
+		case edge.Kind == EdgeKindSend:
+			sb.WriteString(fmt.Sprintf("%s%s -> %s_send [style=dashed, label=\"Send\"];\n", indent, from, id(edge.From)))
+			sb.WriteString(fmt.Sprintf("%s%s_send [label=\"*\", shape=circle, style=filled, fillcolor=lightyellow];\n", indent, id(edge.From)))
+		}
 	}
 
-	// Add conditional edges
-	for from := range ge.graph.conditionalEdges {
-		sb.WriteString(fmt.Sprintf("    %s -> %s_condition [style=dashed, label=\"?\"];\n", from, from))
-		sb.WriteString(fmt.Sprintf("    %s_condition [label=\"?\", shape=diamond, style=filled, fillcolor=lightyellow];\n", from))
+	// Show node descriptions as tooltips
+	for _, node := range t.Nodes {
+		if node.Kind == NodeKindNode && node.Description != "" && node.Description != node.Name {
+			sb.WriteString(fmt.Sprintf("%s%s [tooltip=\"%s\"];\n", indent, id(node.Name), dotEscape(node.Description)))
+		}
 	}
+}
 
-	sb.WriteString("}\n")
-	return sb.String()
+// writeDOTCluster writes a subgraph, parallel or map-reduce node as a cluster.
+func writeDOTCluster(sb *strings.Builder, node *TopologyNode, id, indent string) {
+	sb.WriteString(fmt.Sprintf("%ssubgraph cluster_%s {\n", indent, id))
+	sb.WriteString(fmt.Sprintf("%s    label=\"%s\";\n", indent, node.Name))
+	if node.Description != "" {
+		sb.WriteString(fmt.Sprintf("%s    tooltip=\"%s\";\n", indent, dotEscape(node.Description)))
+	}
+
+	switch node.Kind {
+	case NodeKindSubgraph:
+		if node.Subgraph != nil {
+			writeDOTGraph(sb, node.Subgraph, id+"__", indent+"    ")
+		}
+	default:
+		fanOut, fanIn := fanLabels(node.Kind)
+		sb.WriteString(fmt.Sprintf("%s    %s__fork [label=\"%s\", shape=trapezium];\n", indent, id, fanOut))
+		for _, branch := range node.Branches {
+			sb.WriteString(fmt.Sprintf("%s    %s__%s [label=\"%s\"];\n", indent, id, branch, branch))
+		}
+		sb.WriteString(fmt.Sprintf("%s    %s__join [label=\"%s\", shape=invtrapezium];\n", indent, id, fanIn))
+		for _, branch := range node.Branches {
+			sb.WriteString(fmt.Sprintf("%s    %s__fork -> %s__%s -> %s__join;\n", indent, id, id, branch, id))
+		}
+	}
+
+	sb.WriteString(indent + "}\n")
+}
+
+// entryAnchor returns the ID edges into a node point to: the START of a subgraph, the fan-out
+// of a parallel node, or the node itself.
+func entryAnchor(t *GraphTopology, name, prefix string) string {
+	if node := t.node(name); node != nil {
+		switch node.Kind {
+		case NodeKindSubgraph:
+			if node.Subgraph != nil && node.Subgraph.EntryPoint != "" {
+				return prefix + name + "__START"
+			}
+		case NodeKindParallel, NodeKindMapReduce:
+			return prefix + name + "__fork"
+		}
+	}
+	return prefix + name
+}
+
+// exitAnchor returns the ID edges out of a node start from, like entryAnchor.
+func exitAnchor(t *GraphTopology, name, prefix string) string {
+	if node := t.node(name); node != nil {
+		switch node.Kind {
+		case NodeKindSubgraph:
+			if node.Subgraph != nil {
+				return prefix + name + "__" + END
+			}
+		case NodeKindParallel, NodeKindMapReduce:
+			return prefix + name + "__join"
+		}
+	}
+	return prefix + name
+}
+
+// fanLabels returns the labels of the fan-out and fan-in of a parallel or map-reduce node.
+func fanLabels(kind string) (string, string) {
+	if kind == NodeKindMapReduce {
+		return "map", "reduce"
+	}
+	return "fan-out", "fan-in"
+}
+
+// joinTargets returns the targets of join edges in order of first appearance.
+func joinTargets(t *GraphTopology) []string {
+	var targets []string
+	for _, edge := range t.Edges {
+		if edge.Kind == EdgeKindJoin && !slices.Contains(targets, edge.To) {
+			targets = append(targets, edge.To)
+		}
+	}
+	return targets
+}
+
+// topologyReferencesEnd reports whether any edge of a topology points to END.
+func topologyReferencesEnd(t *GraphTopology) bool {
+	return slices.ContainsFunc(t.Edges, func(e TopologyEdge) bool {
+		return e.To == END && (e.Kind == EdgeKindStatic || e.Kind == EdgeKindConditional)
+	})
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
 }
 
 // DrawASCII generates an ASCII tree representation of the graph
@@ -193,10 +376,22 @@ func (ge *Exporter[S]) drawASCIINode(nodeName string, prefix string, isLast bool
 			outgoingEdges = append(outgoingEdges, edge.To)
 		}
 	}
+	for _, join := range ge.graph.joinEdges {
+		if slices.Contains(join.From, nodeName) {
+			outgoingEdges = append(outgoingEdges, join.To)
+		}
+	}
 
-	// Check for conditional edge
-	if _, ok := ge.graph.conditionalEdges[nodeName]; ok {
-		outgoingEdges = append(outgoingEdges, "(Conditional)")
+	// Check for conditional edge; declared path map targets are drawn as regular children
+	if edge, ok := ge.graph.conditionalEdges[nodeName]; ok {
+		if edge.pathMap == nil {
+			outgoingEdges = append(outgoingEdges, "(Conditional)")
+		}
+		for _, target := range edge.pathMap {
+			if !slices.Contains(outgoingEdges, target) {
+				outgoingEdges = append(outgoingEdges, target)
+			}
+		}
 	}
 
 	// Sort for consistent output
@@ -219,6 +414,47 @@ func (ge *Exporter[S]) drawASCIINode(nodeName string, prefix string, isLast bool
 	}
 }
 
+// DrawMermaidTrace draws the graph with an execution trace on top: the nodes the run visited
+// are highlighted with their durations and failures, edges are labeled with traversal counts,
+// and the declared nodes and edges the run did not take are greyed out.
+// With opts.Unroll, only the visits are drawn, as for ExecutionTrace.DrawMermaid.
+func (ge *Exporter[S]) DrawMermaidTrace(trace *ExecutionTrace, opts TraceDiagramOptions) string {
+	return trace.diagram(opts, ge.topology()).mermaid(opts.Direction)
+}
+
+// DrawDOTTrace draws the graph with an execution trace on top, like DrawMermaidTrace.
+func (ge *Exporter[S]) DrawDOTTrace(trace *ExecutionTrace, opts TraceDiagramOptions) string {
+	return trace.diagram(opts, ge.topology()).dot(opts.Direction)
+}
+
+// topology returns the declared nodes and edges of the graph an execution trace is drawn over.
+func (ge *Exporter[S]) topology() *traceTopology {
+	declared := ge.Topology()
+	topology := &traceTopology{}
+	for _, node := range declared.Nodes {
+		topology.nodes = append(topology.nodes, node.Name)
+	}
+	if declared.EntryPoint != "" {
+		topology.edges = append(topology.edges, [2]string{"START", declared.EntryPoint})
+	}
+	for _, edge := range declared.Edges {
+		if edge.To != "" {
+			topology.edges = append(topology.edges, [2]string{edge.From, edge.To})
+		}
+	}
+	return topology
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // GetGraphForRunnable returns a Exporter for the compiled graph's visualization
 func GetGraphForRunnable(r *Runnable) *Exporter[map[string]any] {
 	return NewExporter[map[string]any](r.graph)
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +50,89 @@ func TestVisualization(t *testing.T) {
 	// C is not reachable via static edges from B, so it won't be shown under B.
 	// This is expected behavior for static visualization of dynamic graphs.
 }
+
+func compositeGraph(t *testing.T) *StateGraph[map[string]any] {
+	t.Helper()
+	fn := func(ctx context.Context, state map[string]any) (map[string]any, error) { return state, nil }
+
+	research := NewStateGraph[map[string]any]()
+	research.AddNode("plan", "Plan the research", fn)
+	research.AddNode("search", "search", fn)
+	research.SetEntryPoint("plan")
+	research.AddEdge("plan", "search")
+	research.AddEdge("search", END)
+
+	g := NewStateGraph[map[string]any]()
+	g.AddNode("intake", `Receive the "query"`, fn)
+	identity := func(s map[string]any) map[string]any { return s }
+	assert.NoError(t, AddSubgraph(g, "research", research, identity, identity))
+	g.AddParallelNodes("workers", map[string]func(context.Context, map[string]any) (map[string]any, error){"a": fn, "b": fn},
+		func(results []map[string]any) map[string]any { return results[0] })
+	g.AddMapReduceNode("summarize", map[string]func(context.Context, map[string]any) (map[string]any, error){"x": fn},
+		func(results []map[string]any) (map[string]any, error) { return results[0], nil })
+	g.AddNode("fanout", "fanout", fn)
+
+	g.SetEntryPoint("intake")
+	g.AddEdge("intake", "research")
+	g.AddEdge("research", "workers")
+	g.AddConditionalEdge("workers", func(ctx context.Context, state map[string]any) string { return "ok" },
+		map[string]string{"ok": "summarize", "stop": END})
+	g.AddEdge("summarize", "fanout")
+	g.AddSendEdge("fanout", func(ctx context.Context, state map[string]any) []Send { return nil })
+	return g
+}
+
+func TestVisualizationCompositeNodes(t *testing.T) {
+	exporter := NewExporter(compositeGraph(t))
+
+	mermaid := exporter.DrawMermaid()
+	assert.Contains(t, mermaid, "    subgraph research[\"research\"]\n")
+	assert.Contains(t, mermaid, "        research__START --> research__plan\n")
+	assert.Contains(t, mermaid, "        research__search --> research__END\n")
+	assert.Contains(t, mermaid, "    intake --> research__START\n")
+	assert.Contains(t, mermaid, "    research__END --> workers__fork\n")
+	assert.Contains(t, mermaid, `workers__fork[/"fan-out"\]`)
+	assert.Contains(t, mermaid, `workers__join[\"fan-in"/]`)
+	assert.Contains(t, mermaid, "workers__fork --> workers__a --> workers__join\n")
+	assert.Contains(t, mermaid, `summarize__join[\"reduce"/]`)
+	assert.Contains(t, mermaid, "workers__join -.->|ok| summarize__fork\n")
+	assert.Contains(t, mermaid, "workers__join -.->|stop| END\n")
+	assert.Contains(t, mermaid, "fanout -.->|Send| fanout_send((*))\n")
+	assert.Contains(t, mermaid, `click intake href "#" "Receive the #quot;query#quot;"`)
+	assert.Contains(t, mermaid, `click research__plan href "#" "Plan the research"`)
+	assert.NotContains(t, mermaid, "click research__search")
+
+	dot := exporter.DrawDOT()
+	assert.Contains(t, dot, "    subgraph cluster_research {\n        label=\"research\";\n")
+	assert.Contains(t, dot, "        research__plan -> research__search;\n")
+	assert.Contains(t, dot, "    intake -> research__START;\n")
+	assert.Contains(t, dot, `workers__fork [label="fan-out", shape=trapezium];`)
+	assert.Contains(t, dot, `workers__join [label="fan-in", shape=invtrapezium];`)
+	assert.Contains(t, dot, `workers__join -> summarize__fork [style=dashed, label="ok"];`)
+	assert.Contains(t, dot, `intake [tooltip="Receive the \"query\""];`)
+	assert.Contains(t, dot, `fanout -> fanout_send [style=dashed, label="Send"];`)
+}
+
+func TestTopologyJSON(t *testing.T) {
+	exporter := NewExporter(compositeGraph(t))
+
+	data, err := exporter.DrawJSON()
+	assert.NoError(t, err)
+
+	var topology GraphTopology
+	assert.NoError(t, json.Unmarshal([]byte(data), &topology))
+	assert.Equal(t, "intake", topology.EntryPoint)
+	assert.Equal(t, "intake", topology.Nodes[0].Name)
+
+	research := topology.node("research")
+	if assert.NotNil(t, research) && assert.NotNil(t, research.Subgraph) {
+		assert.Equal(t, NodeKindSubgraph, research.Kind)
+		assert.Equal(t, "plan", research.Subgraph.EntryPoint)
+		assert.Contains(t, research.Subgraph.Edges, TopologyEdge{From: "plan", To: "search", Kind: EdgeKindStatic})
+	}
+	assert.Equal(t, []string{"a", "b"}, topology.node("workers").Branches)
+	assert.Equal(t, NodeKindMapReduce, topology.node("summarize").Kind)
+
+	assert.Contains(t, topology.Edges, TopologyEdge{From: "workers", To: "summarize", Kind: EdgeKindConditional, Label: "ok"})
+	assert.Contains(t, topology.Edges, TopologyEdge{From: "fanout", Kind: EdgeKindSend})
+}
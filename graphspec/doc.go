@@ -0,0 +1,48 @@
+// Package graphspec builds graphs from declarative YAML or JSON specs, and exports them back.
+//
+// Node implementations are registered by name in a Registry, and a spec lists the nodes to
+// build from them with their parameters, the edges between them and the reducers of the state:
+//
+//	entry_point: classify
+//	nodes:
+//	  - name: classify
+//	    type: classifier
+//	    params: {labels: [billing, support]}
+//	    timeout: 10s
+//	    retry: {max_retries: 3, backoff: exponential, initial_delay: 500ms}
+//	  - name: billing
+//	    type: reply
+//	    params: {template: "Forwarded to billing"}
+//	  - name: support
+//	    type: reply
+//	    params: {template: "Forwarded to support"}
+//	conditional_edges:
+//	  - from: classify
+//	    branches:
+//	      - when: label == 'billing' && confidence > 0.5
+//	        to: billing
+//	    default: support
+//	edges:
+//	  - {from: billing, to: END}
+//	  - {from: support, to: END}
+//	reducers:
+//	  messages: add_messages
+//
+// Conditions are expressions over the state keys, see Expression. The branches of a
+// conditional edge are tried in order; when none matches, the default branch is taken, or the
+// run ends if there is none.
+//
+//	registry := graphspec.NewRegistry()
+//	registry.RegisterNode("classifier", newClassifier)
+//	registry.RegisterNode("reply", newReply)
+//
+//	spec, _ := graphspec.LoadFile("support.yaml")
+//	g, _ := registry.Build(spec)
+//	runnable, _ := g.Compile()
+//
+// Export emits the spec of a graph built this way, so definitions round-trip between files and
+// code:
+//
+//	spec, _ = registry.Export(g)
+//	data, _ := spec.YAML()
+package graphspec
@@ -0,0 +1,63 @@
+package graphspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpression(t *testing.T) {
+	state := map[string]any{
+		"count":  3,
+		"limit":  int64(5),
+		"score":  0.75,
+		"status": "ok",
+		"done":   false,
+		"items":  []any{"a", "b"},
+		"user":   map[string]any{"name": "ada", "age": 36},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"count < limit", true},
+		{"count >= limit", false},
+		{"count == 3", true},
+		{"count != 3.0", false},
+		{"score > 0.5 && status == 'ok'", true},
+		{`status == "ok"`, true},
+		{"status < 'z'", true},
+		{"done || count > 10", false},
+		{"!done", true},
+		{"!(count < limit && done)", true},
+		{"done == false", true},
+		{"missing", false},
+		{"missing == null", true},
+		{"missing < 3", false},
+		{"status > 3", false},
+		{"items", true},
+		{"len(items) == 2", true},
+		{"len(status) > 5", false},
+		{"user.age > 30 && user.name == 'ada'", true},
+		{"user.missing.deep == null", true},
+		{"count > -1", true},
+		{"'it\\'s' == \"it's\"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := CompileExpression(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.Eval(state))
+			assert.Equal(t, tt.expr, expr.String())
+		})
+	}
+}
+
+func TestExpression_Errors(t *testing.T) {
+	for _, source := range []string{"", "count <", "(count", "count == 'open", "count = 3", "a b", "len(a", "1.2.3"} {
+		_, err := CompileExpression(source)
+		assert.Error(t, err, source)
+	}
+}
@@ -0,0 +1,396 @@
+package graphspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+var (
+	// ErrUnknownNodeType is returned when a spec uses a node type that is not registered.
+	ErrUnknownNodeType = errors.New("unknown node type")
+
+	// ErrUnknownReducer is returned when a spec uses a reducer that is not registered.
+	ErrUnknownReducer = errors.New("unknown reducer")
+
+	// ErrNotExportable is returned when a graph has parts a spec cannot describe, such as
+	// nodes added with Go functions instead of registered implementations.
+	ErrNotExportable = errors.New("graph cannot be exported as a spec")
+)
+
+// NodeFunc is the function of a node of a map state graph.
+type NodeFunc = func(ctx context.Context, state map[string]any) (map[string]any, error)
+
+// NodeFactory builds a node function from the params of a NodeSpec.
+type NodeFactory func(params map[string]any) (NodeFunc, error)
+
+// metadataKey is the node metadata key under which Build records how a node was declared.
+const metadataKey = "graphspec"
+
+// nodeMetadata is what Build records on a node, for Export.
+type nodeMetadata struct {
+	typ    string
+	params map[string]any
+
+	// conditional is the conditional edge leaving the node, if any
+	conditional *ConditionalEdgeSpec
+}
+
+// defaultLabel is the path map label of the default branch of a conditional edge.
+const defaultLabel = "default"
+
+// Registry holds the node implementations and reducers specs can refer to by name.
+type Registry struct {
+	mu       sync.RWMutex
+	nodes    map[string]NodeFactory
+	reducers map[string]graph.Reducer
+}
+
+// NewRegistry creates a registry with the "append", "overwrite" and "add_messages" reducers.
+func NewRegistry() *Registry {
+	return &Registry{
+		nodes: make(map[string]NodeFactory),
+		reducers: map[string]graph.Reducer{
+			"append":       graph.AppendReducer,
+			"overwrite":    graph.OverwriteReducer,
+			"add_messages": graph.AddMessages,
+		},
+	}
+}
+
+// RegisterNode registers a node implementation under a type name.
+//
+// Example:
+//
+//	registry.RegisterNode("greet", func(params map[string]any) (graphspec.NodeFunc, error) {
+//	    greeting, _ := params["greeting"].(string)
+//	    return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+//	        return map[string]any{"reply": greeting + ", " + state["name"].(string)}, nil
+//	    }, nil
+//	})
+func (r *Registry) RegisterNode(typ string, factory NodeFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[typ] = factory
+}
+
+// RegisterReducer registers a reducer under a name. Export recognizes reducers by function, so
+// each function should be registered under a single name.
+func (r *Registry) RegisterReducer(name string, reducer graph.Reducer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reducers[name] = reducer
+}
+
+// Build creates the graph a spec declares. The graph is not compiled, so it can be extended
+// before compiling it.
+func (r *Registry) Build(spec *Spec) (*graph.StateGraph[map[string]any], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if spec.EntryPoint == "" {
+		return nil, graph.ErrEntryPointNotSet
+	}
+
+	declared := make(map[string]bool, len(spec.Nodes))
+	for _, node := range spec.Nodes {
+		if node.Name == "" {
+			return nil, errors.New("node without a name")
+		}
+		if node.Name == graph.END {
+			return nil, fmt.Errorf("node name %s is reserved", graph.END)
+		}
+		if declared[node.Name] {
+			return nil, fmt.Errorf("node %s is declared twice", node.Name)
+		}
+		declared[node.Name] = true
+	}
+	checkTarget := func(where, to string) error {
+		if to != graph.END && !declared[to] {
+			return fmt.Errorf("%w: %s targets %s", graph.ErrNodeNotFound, where, to)
+		}
+		return nil
+	}
+	if !declared[spec.EntryPoint] {
+		return nil, fmt.Errorf("%w: entry point %s", graph.ErrNodeNotFound, spec.EntryPoint)
+	}
+
+	// Conditional edges are compiled first, as they are recorded on their source node
+	conditionals := make(map[string]*ConditionalEdgeSpec, len(spec.ConditionalEdges))
+	routers := make(map[string]func(ctx context.Context, state map[string]any) string, len(spec.ConditionalEdges))
+	for i := range spec.ConditionalEdges {
+		edge := &spec.ConditionalEdges[i]
+		if !declared[edge.From] {
+			return nil, fmt.Errorf("%w: conditional edge from %s", graph.ErrNodeNotFound, edge.From)
+		}
+		if conditionals[edge.From] != nil {
+			return nil, fmt.Errorf("node %s has two conditional edges", edge.From)
+		}
+		router, err := compileRouter(edge, checkTarget)
+		if err != nil {
+			return nil, err
+		}
+		conditionals[edge.From] = edge
+		routers[edge.From] = router
+	}
+
+	g := graph.NewStateGraph[map[string]any]()
+	schema := graph.NewMapSchema()
+	for _, key := range slices.Sorted(maps.Keys(spec.Reducers)) {
+		reducer, ok := r.reducers[spec.Reducers[key]]
+		if !ok {
+			return nil, fmt.Errorf("%w %q for key %s", ErrUnknownReducer, spec.Reducers[key], key)
+		}
+		schema.RegisterReducer(key, reducer)
+	}
+	g.SetSchema(schema)
+
+	for _, node := range spec.Nodes {
+		factory, ok := r.nodes[node.Type]
+		if !ok {
+			return nil, fmt.Errorf("%w %q for node %s", ErrUnknownNodeType, node.Type, node.Name)
+		}
+		fn, err := factory(node.Params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build node %s: %w", node.Name, err)
+		}
+
+		opts := []graph.NodeOption{graph.WithNodeMetadata(metadataKey, &nodeMetadata{
+			typ:         node.Type,
+			params:      maps.Clone(node.Params),
+			conditional: conditionals[node.Name],
+		})}
+		if node.Timeout < 0 {
+			return nil, fmt.Errorf("node %s has a negative timeout", node.Name)
+		}
+		if node.Timeout > 0 {
+			opts = append(opts, graph.WithNodeTimeout(time.Duration(node.Timeout)))
+		}
+		if node.Retry != nil {
+			policy, err := retryPolicy(node.Retry)
+			if err != nil {
+				return nil, fmt.Errorf("node %s: %w", node.Name, err)
+			}
+			opts = append(opts, graph.WithRetryPolicy(policy))
+		}
+		g.AddNode(node.Name, node.Description, fn, opts...)
+	}
+
+	for _, edge := range spec.Edges {
+		if !declared[edge.From] {
+			return nil, fmt.Errorf("%w: edge from %s", graph.ErrNodeNotFound, edge.From)
+		}
+		if err := checkTarget("edge from "+edge.From, edge.To); err != nil {
+			return nil, err
+		}
+		g.AddEdge(edge.From, edge.To)
+	}
+
+	for _, edge := range spec.ConditionalEdges {
+		g.AddConditionalEdge(edge.From, routers[edge.From], pathMap(&edge))
+	}
+
+	g.SetEntryPoint(spec.EntryPoint)
+	return g, nil
+}
+
+// compileRouter compiles the branches of a conditional edge into a router returning path map
+// labels: the condition of the branch taken, or defaultLabel.
+func compileRouter(edge *ConditionalEdgeSpec, checkTarget func(where, to string) error) (func(ctx context.Context, state map[string]any) string, error) {
+	if len(edge.Branches) == 0 {
+		return nil, fmt.Errorf("conditional edge from %s has no branches", edge.From)
+	}
+	where := "conditional edge from " + edge.From
+
+	conditions := make([]*Expression, len(edge.Branches))
+	seen := make(map[string]bool, len(edge.Branches))
+	for i, branch := range edge.Branches {
+		if branch.When == defaultLabel || seen[branch.When] {
+			return nil, fmt.Errorf("%s has a duplicate condition %q", where, branch.When)
+		}
+		seen[branch.When] = true
+		if err := checkTarget(where, branch.To); err != nil {
+			return nil, err
+		}
+		condition, err := CompileExpression(branch.When)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		conditions[i] = condition
+	}
+	if edge.Default != "" {
+		if err := checkTarget(where, edge.Default); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, state map[string]any) string {
+		for _, condition := range conditions {
+			if condition.Eval(state) {
+				return condition.String()
+			}
+		}
+		return defaultLabel
+	}, nil
+}
+
+// pathMap maps the labels returned by the router of a conditional edge to their targets.
+func pathMap(edge *ConditionalEdgeSpec) map[string]string {
+	paths := make(map[string]string, len(edge.Branches)+1)
+	for _, branch := range edge.Branches {
+		paths[branch.When] = branch.To
+	}
+	paths[defaultLabel] = edge.Default
+	if edge.Default == "" {
+		paths[defaultLabel] = graph.END
+	}
+	return paths
+}
+
+var backoffStrategies = map[string]graph.BackoffStrategy{
+	"fixed":       graph.FixedBackoff,
+	"exponential": graph.ExponentialBackoff,
+	"linear":      graph.LinearBackoff,
+}
+
+func retryPolicy(spec *RetrySpec) (*graph.RetryPolicy, error) {
+	policy := &graph.RetryPolicy{
+		MaxRetries:      spec.MaxRetries,
+		RetryableErrors: slices.Clone(spec.RetryableErrors),
+		InitialDelay:    time.Duration(spec.InitialDelay),
+		MaxDelay:        time.Duration(spec.MaxDelay),
+		Jitter:          spec.Jitter,
+	}
+	if spec.Backoff != "" {
+		strategy, ok := backoffStrategies[spec.Backoff]
+		if !ok {
+			return nil, fmt.Errorf("unknown backoff %q, expected fixed, exponential or linear", spec.Backoff)
+		}
+		policy.BackoffStrategy = strategy
+	}
+	if len(spec.RetryableErrors) == 0 {
+		// The graph retries nothing without a classifier
+		policy.Retryable = func(error) bool { return true }
+	}
+	return policy, nil
+}
+
+// Export returns the spec of a graph built by Build, reflecting changes made to it since, such
+// as added edges. Nodes are listed entry point first, then by name, and conditional edges by
+// source node. It fails with ErrNotExportable if the graph has parts a spec cannot describe.
+func (r *Registry) Export(g *graph.StateGraph[map[string]any]) (*Spec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	topology := g.Topology()
+	spec := &Spec{EntryPoint: topology.EntryPoint, Nodes: []NodeSpec{}}
+
+	metadata := make(map[string]*nodeMetadata, len(topology.Nodes))
+	for _, tn := range topology.Nodes {
+		node, _ := g.Node(tn.Name)
+		meta, ok := node.Options.Metadata[metadataKey].(*nodeMetadata)
+		if tn.Kind != graph.NodeKindNode || !ok {
+			return nil, fmt.Errorf("%w: node %s was not built from a registered implementation", ErrNotExportable, tn.Name)
+		}
+		metadata[tn.Name] = meta
+
+		ns := NodeSpec{
+			Name:        tn.Name,
+			Description: tn.Description,
+			Type:        meta.typ,
+			Params:      maps.Clone(meta.params),
+			Timeout:     Duration(node.Options.Timeout),
+		}
+		if policy := node.Options.RetryPolicy; policy != nil {
+			ns.Retry = retrySpec(policy)
+		}
+		spec.Nodes = append(spec.Nodes, ns)
+	}
+
+	labels := make(map[string][]graph.TopologyEdge)
+	for _, edge := range topology.Edges {
+		switch edge.Kind {
+		case graph.EdgeKindStatic:
+			spec.Edges = append(spec.Edges, EdgeSpec{From: edge.From, To: edge.To})
+		case graph.EdgeKindConditional:
+			labels[edge.From] = append(labels[edge.From], edge)
+		default:
+			return nil, fmt.Errorf("%w: %s edge from %s", ErrNotExportable, edge.Kind, edge.From)
+		}
+	}
+
+	for _, from := range slices.Sorted(maps.Keys(labels)) {
+		conditional := metadata[from].conditional
+		if conditional == nil || !samePaths(pathMap(conditional), labels[from]) {
+			return nil, fmt.Errorf("%w: conditional edge from %s was not declared by a spec", ErrNotExportable, from)
+		}
+		edge := *conditional
+		edge.Branches = slices.Clone(conditional.Branches)
+		spec.ConditionalEdges = append(spec.ConditionalEdges, edge)
+	}
+
+	if g.Schema != nil {
+		schema, ok := g.Schema.(*graph.MapSchema)
+		if !ok {
+			return nil, fmt.Errorf("%w: the schema is a %T, not a *graph.MapSchema", ErrNotExportable, g.Schema)
+		}
+		for key, reducer := range schema.Reducers {
+			name, ok := r.reducerName(reducer)
+			if !ok {
+				return nil, fmt.Errorf("%w: the reducer of key %s is not registered", ErrNotExportable, key)
+			}
+			if spec.Reducers == nil {
+				spec.Reducers = make(map[string]string)
+			}
+			spec.Reducers[key] = name
+		}
+	}
+	return spec, nil
+}
+
+// samePaths reports whether the conditional edges of a topology have the given path map.
+func samePaths(paths map[string]string, edges []graph.TopologyEdge) bool {
+	if len(paths) != len(edges) {
+		return false
+	}
+	for _, edge := range edges {
+		if to, ok := paths[edge.Label]; !ok || to != edge.To {
+			return false
+		}
+	}
+	return true
+}
+
+// reducerName returns the name a reducer function is registered with.
+func (r *Registry) reducerName(reducer graph.Reducer) (string, bool) {
+	pointer := reflect.ValueOf(reducer).Pointer()
+	for _, name := range slices.Sorted(maps.Keys(r.reducers)) {
+		if reflect.ValueOf(r.reducers[name]).Pointer() == pointer {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func retrySpec(policy *graph.RetryPolicy) *RetrySpec {
+	spec := &RetrySpec{
+		MaxRetries:      policy.MaxRetries,
+		RetryableErrors: slices.Clone(policy.RetryableErrors),
+		InitialDelay:    Duration(policy.InitialDelay),
+		MaxDelay:        Duration(policy.MaxDelay),
+		Jitter:          policy.Jitter,
+	}
+	for name, strategy := range backoffStrategies {
+		if strategy == policy.BackoffStrategy && strategy != graph.FixedBackoff {
+			spec.Backoff = name
+		}
+	}
+	return spec
+}
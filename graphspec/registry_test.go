@@ -0,0 +1,254 @@
+package graphspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const counterSpec = `
+entry_point: inc
+nodes:
+  - name: inc
+    description: Increment the counter
+    type: add
+    params: {key: count, amount: 2}
+    timeout: 5s
+    retry:
+      max_retries: 2
+      backoff: exponential
+      initial_delay: 1ms
+  - name: flaky
+    type: flaky
+    params: {failures: 1}
+    retry: {max_retries: 1, initial_delay: 1ms, retryable_errors: [temporary]}
+  - name: done
+    type: log
+    params: {message: done}
+edges:
+  - {from: flaky, to: done}
+  - {from: done, to: END}
+conditional_edges:
+  - from: inc
+    branches:
+      - when: count < limit
+        to: inc
+      - when: mode == 'flaky'
+        to: flaky
+    default: done
+reducers:
+  log: append
+`
+
+func testRegistry() *Registry {
+	registry := NewRegistry()
+	registry.RegisterNode("add", func(params map[string]any) (NodeFunc, error) {
+		key, _ := params["key"].(string)
+		amount, ok := params["amount"].(int)
+		if key == "" || !ok {
+			return nil, errors.New("add needs a key and an integer amount")
+		}
+		return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			current, _ := state[key].(int)
+			return map[string]any{key: current + amount, "log": []any{fmt.Sprintf("%s=%d", key, current+amount)}}, nil
+		}, nil
+	})
+	registry.RegisterNode("flaky", func(params map[string]any) (NodeFunc, error) {
+		failures, _ := params["failures"].(int)
+		return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			if failures > 0 {
+				failures--
+				return nil, errors.New("temporary failure")
+			}
+			return map[string]any{"log": []any{"flaky"}}, nil
+		}, nil
+	})
+	registry.RegisterNode("log", func(params map[string]any) (NodeFunc, error) {
+		message, _ := params["message"].(string)
+		return func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{"log": []any{message}}, nil
+		}, nil
+	})
+	return registry
+}
+
+func TestBuild(t *testing.T) {
+	spec, err := Parse([]byte(counterSpec))
+	require.NoError(t, err)
+
+	registry := testRegistry()
+	g, err := registry.Build(spec)
+	require.NoError(t, err)
+
+	node, ok := g.Node("inc")
+	require.True(t, ok)
+	assert.Equal(t, "Increment the counter", node.Description)
+	assert.Equal(t, 5*time.Second, node.Options.Timeout)
+	require.NotNil(t, node.Options.RetryPolicy)
+	assert.Equal(t, 2, node.Options.RetryPolicy.MaxRetries)
+	assert.Equal(t, graph.ExponentialBackoff, node.Options.RetryPolicy.BackoffStrategy)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	final, err := runnable.Invoke(context.Background(), map[string]any{"count": 0, "limit": 5})
+	require.NoError(t, err)
+	assert.Equal(t, 6, final["count"])
+	assert.Equal(t, []any{"count=2", "count=4", "count=6", "done"}, final["log"])
+
+	// The second branch is tried when the first does not match, and the retry policy applies
+	final, err = runnable.Invoke(context.Background(), map[string]any{"count": 0, "limit": 1, "mode": "flaky"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"count=2", "flaky", "done"}, final["log"])
+}
+
+func TestBuild_JSON(t *testing.T) {
+	spec, err := Parse([]byte(`{
+		"entry_point": "hello",
+		"nodes": [{"name": "hello", "type": "log", "params": {"message": "hi"}}],
+		"conditional_edges": [{"from": "hello", "branches": [{"when": "len(log) < 3", "to": "hello"}]}],
+		"reducers": {"log": "append"}
+	}`))
+	require.NoError(t, err)
+
+	g, err := testRegistry().Build(spec)
+	require.NoError(t, err)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	// Without a default branch the run ends when no condition holds
+	final, err := runnable.Invoke(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"hi", "hi", "hi"}, final["log"])
+}
+
+func TestBuild_Errors(t *testing.T) {
+	registry := testRegistry()
+	logNode := NodeSpec{Name: "a", Type: "log"}
+
+	tests := []struct {
+		name string
+		spec Spec
+		want error
+		msg  string
+	}{
+		{name: "no entry point", spec: Spec{Nodes: []NodeSpec{logNode}}, want: graph.ErrEntryPointNotSet},
+		{name: "unknown entry point", spec: Spec{EntryPoint: "b", Nodes: []NodeSpec{logNode}}, want: graph.ErrNodeNotFound},
+		{name: "duplicate node", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{logNode, logNode}}, msg: "declared twice"},
+		{name: "unknown type", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{{Name: "a", Type: "missing"}}}, want: ErrUnknownNodeType},
+		{name: "factory error", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{{Name: "a", Type: "add"}}}, msg: "integer amount"},
+		{name: "unknown reducer", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{logNode}, Reducers: map[string]string{"k": "sum"}}, want: ErrUnknownReducer},
+		{name: "unknown backoff", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{{Name: "a", Type: "log", Retry: &RetrySpec{Backoff: "random"}}}}, msg: "unknown backoff"},
+		{name: "unknown edge target", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{logNode}, Edges: []EdgeSpec{{From: "a", To: "b"}}}, want: graph.ErrNodeNotFound},
+		{name: "unknown branch target", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{logNode}, ConditionalEdges: []ConditionalEdgeSpec{
+			{From: "a", Branches: []BranchSpec{{When: "x", To: "b"}}},
+		}}, want: graph.ErrNodeNotFound},
+		{name: "invalid condition", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{logNode}, ConditionalEdges: []ConditionalEdgeSpec{
+			{From: "a", Branches: []BranchSpec{{When: "x <", To: "a"}}},
+		}}, msg: "invalid expression"},
+		{name: "duplicate condition", spec: Spec{EntryPoint: "a", Nodes: []NodeSpec{logNode}, ConditionalEdges: []ConditionalEdgeSpec{
+			{From: "a", Branches: []BranchSpec{{When: "x", To: "a"}, {When: "x", To: graph.END}}},
+		}}, msg: "duplicate condition"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Build(&tt.spec)
+			require.Error(t, err)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+			}
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(""))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("entry_point: a\nnodez: []\n"))
+	assert.ErrorContains(t, err, "nodez")
+
+	_, err = Parse([]byte("entry_point: a\nnodes: [{name: a, type: log, timeout: soon}]\n"))
+	assert.Error(t, err)
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	spec, err := Parse([]byte(counterSpec))
+	require.NoError(t, err)
+
+	registry := testRegistry()
+	g, err := registry.Build(spec)
+	require.NoError(t, err)
+
+	exported, err := registry.Export(g)
+	require.NoError(t, err)
+
+	// Nodes are sorted, the entry point first
+	assert.Equal(t, []string{"inc", "done", "flaky"}, []string{exported.Nodes[0].Name, exported.Nodes[1].Name, exported.Nodes[2].Name})
+	assert.Equal(t, spec.Edges, exported.Edges)
+	assert.Equal(t, spec.ConditionalEdges, exported.ConditionalEdges)
+	assert.Equal(t, spec.Reducers, exported.Reducers)
+	assert.Equal(t, Duration(5*time.Second), exported.Nodes[0].Timeout)
+	assert.Equal(t, "exponential", exported.Nodes[0].Retry.Backoff)
+	assert.Equal(t, map[string]any{"key": "count", "amount": 2}, exported.Nodes[0].Params)
+	assert.Equal(t, []string{"temporary"}, exported.Nodes[2].Retry.RetryableErrors)
+
+	for _, encode := range []func(*Spec) ([]byte, error){(*Spec).YAML, (*Spec).JSON} {
+		data, err := encode(exported)
+		require.NoError(t, err)
+
+		decoded, err := Parse(data)
+		require.NoError(t, err)
+		assert.Equal(t, exported, decoded)
+
+		rebuilt, err := registry.Build(decoded)
+		require.NoError(t, err)
+		again, err := registry.Export(rebuilt)
+		require.NoError(t, err)
+		assert.Equal(t, exported, again)
+	}
+}
+
+func TestExport_Changes(t *testing.T) {
+	spec, err := Parse([]byte(counterSpec))
+	require.NoError(t, err)
+	registry := testRegistry()
+
+	// Edges added in code are exported
+	g, err := registry.Build(spec)
+	require.NoError(t, err)
+	g.AddEdge("inc", "flaky")
+	exported, err := registry.Export(g)
+	require.NoError(t, err)
+	assert.Contains(t, exported.Edges, EdgeSpec{From: "inc", To: "flaky"})
+
+	// Go nodes cannot be described
+	g, err = registry.Build(spec)
+	require.NoError(t, err)
+	g.AddNode("custom", "custom", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return state, nil
+	})
+	_, err = registry.Export(g)
+	assert.ErrorIs(t, err, ErrNotExportable)
+
+	// Nor replaced conditional edges
+	g, err = registry.Build(spec)
+	require.NoError(t, err)
+	g.AddConditionalEdge("inc", func(ctx context.Context, state map[string]any) string { return graph.END })
+	_, err = registry.Export(g)
+	assert.ErrorIs(t, err, ErrNotExportable)
+
+	// Nor unregistered reducers
+	g, err = registry.Build(spec)
+	require.NoError(t, err)
+	g.Schema.(*graph.MapSchema).RegisterReducer("other", func(current, new any) (any, error) { return new, nil })
+	_, err = registry.Export(g)
+	assert.ErrorIs(t, err, ErrNotExportable)
+}
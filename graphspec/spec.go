@@ -0,0 +1,164 @@
+package graphspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the declarative definition of a StateGraph[map[string]any].
+type Spec struct {
+	// EntryPoint is the name of the first node to run
+	EntryPoint string `json:"entry_point" yaml:"entry_point"`
+
+	Nodes            []NodeSpec            `json:"nodes" yaml:"nodes"`
+	Edges            []EdgeSpec            `json:"edges,omitempty" yaml:"edges,omitempty"`
+	ConditionalEdges []ConditionalEdgeSpec `json:"conditional_edges,omitempty" yaml:"conditional_edges,omitempty"`
+
+	// Reducers maps state keys to the names of the reducers merging their updates, such as
+	// "append", "overwrite" or "add_messages". Keys without a reducer are overwritten.
+	Reducers map[string]string `json:"reducers,omitempty" yaml:"reducers,omitempty"`
+}
+
+// NodeSpec declares a node built by a registered implementation.
+type NodeSpec struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Type is the name the implementation was registered with
+	Type string `json:"type" yaml:"type"`
+
+	// Params are passed to the factory of the implementation
+	Params map[string]any `json:"params,omitempty" yaml:"params,omitempty"`
+
+	// Timeout bounds a single execution of the node, e.g. "30s"
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	Retry *RetrySpec `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// RetrySpec declares the retry policy of a node. See graph.RetryPolicy.
+type RetrySpec struct {
+	MaxRetries int `json:"max_retries" yaml:"max_retries"`
+
+	// Backoff is "fixed" (the default), "exponential" or "linear"
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+
+	InitialDelay Duration `json:"initial_delay,omitempty" yaml:"initial_delay,omitempty"`
+	MaxDelay     Duration `json:"max_delay,omitempty" yaml:"max_delay,omitempty"`
+	Jitter       float64  `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+
+	// RetryableErrors lists substrings of retryable error messages. Empty means every error.
+	RetryableErrors []string `json:"retryable_errors,omitempty" yaml:"retryable_errors,omitempty"`
+}
+
+// EdgeSpec declares a static edge. To may be graph.END.
+type EdgeSpec struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// ConditionalEdgeSpec declares a conditional edge: the first branch whose condition holds is
+// taken, and Default when none does. An empty Default ends the run.
+type ConditionalEdgeSpec struct {
+	From     string       `json:"from" yaml:"from"`
+	Branches []BranchSpec `json:"branches" yaml:"branches"`
+	Default  string       `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// BranchSpec is a branch of a conditional edge.
+type BranchSpec struct {
+	// When is an expression over the state keys, e.g. "count < limit && status == 'ok'"
+	When string `json:"when" yaml:"when"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// Parse decodes a spec from YAML or JSON. Unknown fields are rejected.
+func Parse(data []byte) (*Spec, error) {
+	// JSON documents are YAML documents too
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty graph spec")
+		}
+		return nil, fmt.Errorf("invalid graph spec: %w", err)
+	}
+	return &spec, nil
+}
+
+// LoadFile reads and decodes a YAML or JSON spec file.
+func LoadFile(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// YAML encodes the spec as YAML.
+func (s *Spec) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// JSON encodes the spec as indented JSON.
+func (s *Spec) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
@@ -123,7 +123,7 @@ func CreateAgentMap(model llms.Model, inputTools []tools.Tool, opts ...CreateAge
 			msgsToSend = options.StateModifier(msgsToSend)
 		}
 
-		resp, err := model.GenerateContent(ctx, msgsToSend, llms.WithTools(toolDefs))
+		resp, err := model.GenerateContent(ctx, msgsToSend, graph.MessageStreamingOptions(ctx, llms.WithTools(toolDefs))...)
 		if err != nil {
 			return nil, err
 		}
@@ -241,7 +241,7 @@ func CreateAgent[S any](
 			msgsToSend = options.StateModifier(msgsToSend)
 		}
 
-		resp, err := model.GenerateContent(ctx, msgsToSend, llms.WithTools(toolDefs))
+		resp, err := model.GenerateContent(ctx, msgsToSend, graph.MessageStreamingOptions(ctx, llms.WithTools(toolDefs))...)
 		if err != nil {
 			return state, err
 		}
@@ -319,7 +319,7 @@ func selectSkill(ctx context.Context, model llms.Model, userPrompt string, avail
 		skillDescriptions.WriteString(fmt.Sprintf("- %s: %s\n", name, pkg.Meta.Description))
 	}
 	prompt := fmt.Sprintf("Select the most appropriate skill for: \"%s\"\n\nSkills:\n%s\nReturn only the skill name or 'None'.", userPrompt, skillDescriptions.String())
-	resp, err := model.GenerateContent(ctx, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, prompt)})
+	resp, err := model.GenerateContent(ctx, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, prompt)}, graph.MessageStreamingOptions(ctx)...)
 	if err != nil {
 		return "", err
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/stretchr/testify/assert"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
@@ -368,3 +369,29 @@ func (t *MockToolWithResponse) Call(ctx context.Context, input string) (string,
 	}
 	return "Mock tool response", nil
 }
+
+func TestCreateAgentMapStreamsMessageChunks(t *testing.T) {
+	model := &MockModel{responses: []string{"Hello from the agent"}}
+	agent, err := CreateAgentMap(model, []tools.Tool{})
+	assert.NoError(t, err)
+
+	var chunks []graph.MessageChunk
+	ctx := graph.WithMessageHandler(context.Background(), func(ctx context.Context, chunk graph.MessageChunk) {
+		chunks = append(chunks, chunk)
+	})
+
+	_, err = agent.Invoke(ctx, map[string]any{
+		"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Hi")},
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, chunks, 4)
+	var content string
+	for _, chunk := range chunks {
+		assert.Equal(t, "agent", chunk.NodeName)
+		assert.NotEmpty(t, chunk.RunID)
+		assert.Equal(t, chunks[0].MessageID, chunk.MessageID)
+		content += chunk.Content
+	}
+	assert.Equal(t, "Hello from the agent", content)
+}
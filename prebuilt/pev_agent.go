@@ -69,7 +69,7 @@ func CreatePEVAgentMap(config PEVAgentConfig) (*graph.StateRunnable[map[string]a
 			}
 		}
 
-		resp, err := config.Model.GenerateContent(ctx, promptMessages)
+		resp, err := config.Model.GenerateContent(ctx, promptMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -107,7 +107,7 @@ func CreatePEVAgentMap(config PEVAgentConfig) (*graph.StateRunnable[map[string]a
 			{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(config.VerificationPrompt)}},
 			{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(verifyPrompt)}},
 		}
-		resp, err := config.Model.GenerateContent(ctx, promptMessages)
+		resp, err := config.Model.GenerateContent(ctx, promptMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -121,7 +121,7 @@ func CreatePEVAgentMap(config PEVAgentConfig) (*graph.StateRunnable[map[string]a
 		messages, _ := state["messages"].([]llms.MessageContent)
 		steps, _ := state["intermediate_steps"].([]string)
 		prompt := fmt.Sprintf("Synthesize: Request: %s\nSteps: %s", getPEVOriginalRequest(messages), strings.Join(steps, "\n"))
-		resp, err := config.Model.GenerateContent(ctx, []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}}})
+		resp, err := config.Model.GenerateContent(ctx, []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}}}, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -217,7 +217,7 @@ func CreatePEVAgent[S any](
 			}
 		}
 
-		resp, err := config.Model.GenerateContent(ctx, promptMessages)
+		resp, err := config.Model.GenerateContent(ctx, promptMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return state, err
 		}
@@ -246,7 +246,7 @@ func CreatePEVAgent[S any](
 		resp, err := config.Model.GenerateContent(ctx, []llms.MessageContent{
 			{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(config.VerificationPrompt)}},
 			{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}},
-		})
+		}, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return state, err
 		}
@@ -264,7 +264,7 @@ func CreatePEVAgent[S any](
 
 	workflow.AddNode("synthesizer", "Synthesize final answer", func(ctx context.Context, state S) (S, error) {
 		prompt := fmt.Sprintf("Synthesize: Request: %s\nSteps: %s", getPEVOriginalRequest(getMessages(state)), strings.Join(getIntermediateSteps(state), "\n"))
-		resp, err := config.Model.GenerateContent(ctx, []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}}})
+		resp, err := config.Model.GenerateContent(ctx, []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}}}, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return state, err
 		}
@@ -325,7 +325,7 @@ func executePEVStep(ctx context.Context, step string, te *ToolExecutor, model ll
 		toolsInfo.WriteString(fmt.Sprintf("- %s: %s\n", name, tool.Description()))
 	}
 	prompt := fmt.Sprintf("Select tool for: %s\nTools:\n%s\nReturn JSON: {\"tool\": \"name\", \"tool_input\": \"input\"}", step, toolsInfo.String())
-	resp, err := model.GenerateContent(ctx, []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}}})
+	resp, err := model.GenerateContent(ctx, []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(prompt)}}}, graph.MessageStreamingOptions(ctx)...)
 	if err != nil {
 		return "", err
 	}
@@ -42,7 +42,7 @@ func CreatePlanningAgentMap(model llms.Model, availableNodes []graph.TypedNode[m
 		}
 		planningMessages = append(planningMessages, messages...)
 
-		resp, err := model.GenerateContent(ctx, planningMessages)
+		resp, err := model.GenerateContent(ctx, planningMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -166,7 +166,7 @@ func CreatePlanningAgent[S any](
 		}
 		planningMessages = append(planningMessages, messages...)
 
-		resp, err := model.GenerateContent(ctx, planningMessages)
+		resp, err := model.GenerateContent(ctx, planningMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return state, err
 		}
@@ -75,7 +75,7 @@ func CreateReactAgentMap(model llms.Model, inputTools []tools.Tool, maxIteration
 		}
 
 		// Call model with tools
-		resp, err := model.GenerateContent(ctx, messages, llms.WithTools(toolDefs))
+		resp, err := model.GenerateContent(ctx, messages, graph.MessageStreamingOptions(ctx, llms.WithTools(toolDefs))...)
 		if err != nil {
 			return nil, err
 		}
@@ -212,7 +212,7 @@ func CreateReactAgent[S any](
 		}
 
 		messages := getMessages(state)
-		resp, err := model.GenerateContent(ctx, messages, llms.WithTools(toolDefs))
+		resp, err := model.GenerateContent(ctx, messages, graph.MessageStreamingOptions(ctx, llms.WithTools(toolDefs))...)
 		if err != nil {
 			return state, err
 		}
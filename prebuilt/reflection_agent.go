@@ -63,7 +63,7 @@ func CreateReflectionAgentMap(config ReflectionAgentConfig) (*graph.StateRunnabl
 			}
 		}
 
-		resp, err := config.Model.GenerateContent(ctx, promptMessages)
+		resp, err := config.Model.GenerateContent(ctx, promptMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -82,7 +82,7 @@ func CreateReflectionAgentMap(config ReflectionAgentConfig) (*graph.StateRunnabl
 			{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(config.ReflectionPrompt)}},
 			{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(fmt.Sprintf("Request: %s\nResponse: %s", getOriginalRequest(messages), draft))}},
 		}
-		resp, err := reflectionModel.GenerateContent(ctx, reflectionMessages)
+		resp, err := reflectionModel.GenerateContent(ctx, reflectionMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return nil, err
 		}
@@ -159,7 +159,7 @@ func CreateReflectionAgent[S any](
 			}
 		}
 
-		resp, err := config.Model.GenerateContent(ctx, promptMessages)
+		resp, err := config.Model.GenerateContent(ctx, promptMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return state, err
 		}
@@ -178,7 +178,7 @@ func CreateReflectionAgent[S any](
 			{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextPart(config.ReflectionPrompt)}},
 			{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextPart(fmt.Sprintf("Request: %s\nResponse: %s", getOriginalRequest(messages), draft))}},
 		}
-		resp, err := reflectionModel.GenerateContent(ctx, reflectionMessages)
+		resp, err := reflectionModel.GenerateContent(ctx, reflectionMessages, graph.MessageStreamingOptions(ctx)...)
 		if err != nil {
 			return state, err
 		}
@@ -55,7 +55,7 @@ func CreateSupervisorMap(model llms.Model, members map[string]*graph.StateRunnab
 		inputMessages := append([]llms.MessageContent{llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt)}, messages...)
 
 		toolChoice := llms.ToolChoice{Type: "function", Function: &llms.FunctionReference{Name: "route"}}
-		resp, err := model.GenerateContent(ctx, inputMessages, llms.WithTools([]llms.Tool{routeTool}), llms.WithToolChoice(toolChoice))
+		resp, err := model.GenerateContent(ctx, inputMessages, graph.MessageStreamingOptions(ctx, llms.WithTools([]llms.Tool{routeTool}), llms.WithToolChoice(toolChoice))...)
 		if err != nil {
 			return nil, err
 		}
@@ -143,7 +143,7 @@ func CreateSupervisor[S any](
 		inputMessages := append([]llms.MessageContent{llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt)}, messages...)
 
 		toolChoice := llms.ToolChoice{Type: "function", Function: &llms.FunctionReference{Name: "route"}}
-		resp, err := model.GenerateContent(ctx, inputMessages, llms.WithTools([]llms.Tool{routeTool}), llms.WithToolChoice(toolChoice))
+		resp, err := model.GenerateContent(ctx, inputMessages, graph.MessageStreamingOptions(ctx, llms.WithTools([]llms.Tool{routeTool}), llms.WithToolChoice(toolChoice))...)
 		if err != nil {
 			return state, err
 		}
@@ -175,6 +175,7 @@ import sys
 	defer cancel()
 
 	cmd := exec.CommandContext(execCtx, "python3", scriptPath)
+	cmd.Env = append(os.Environ(), traceEnv(ctx)...)
 	output, err := cmd.CombinedOutput()
 
 	result := &ExecutionResult{
@@ -245,6 +246,7 @@ func main() {
 	defer cancel()
 
 	cmd := exec.CommandContext(execCtx, "go", "run", scriptPath)
+	cmd.Env = append(os.Environ(), traceEnv(ctx)...)
 	output, err := cmd.CombinedOutput()
 
 	result := &ExecutionResult{
@@ -277,6 +279,7 @@ func (ce *CodeExecutor) generatePythonToolWrappersServer() string {
 	wrapper := fmt.Sprintf(`
 # Available tools: %s
 import json
+import os
 try:
     import urllib.request
 except ImportError:
@@ -284,6 +287,15 @@ except ImportError:
 
 TOOL_SERVER_URL = "%s"
 
+def _trace_headers():
+    """Request headers propagating the trace context of the calling graph node"""
+    headers = {'Content-Type': 'application/json'}
+    for key in ('traceparent', 'tracestate', 'baggage'):
+        value = os.environ.get(key.upper())
+        if value:
+            headers[key] = value
+    return headers
+
 def call_tool(tool_name, tool_input):
     """Call a tool through the HTTP tool server"""
     try:
@@ -293,7 +305,7 @@ def call_tool(tool_name, tool_input):
             "input": tool_input
         }).encode('utf-8')
 
-        req = urllib.request.Request(url, data=data, headers={'Content-Type': 'application/json'})
+        req = urllib.request.Request(url, data=data, headers=_trace_headers())
         response = urllib.request.urlopen(req)
         result = json.loads(response.read().decode('utf-8'))
 
@@ -344,6 +356,15 @@ except ImportError:
 
 INTERNAL_TOOL_SERVER = "%s"
 
+def _trace_headers():
+    """Request headers propagating the trace context of the calling graph node"""
+    headers = {'Content-Type': 'application/json'}
+    for key in ('traceparent', 'tracestate', 'baggage'):
+        value = os.environ.get(key.upper())
+        if value:
+            headers[key] = value
+    return headers
+
 # Helper function to call generic tools via internal server
 def _call_generic_tool(tool_name, tool_input):
     """Call a generic tool through the internal tool server"""
@@ -354,7 +375,7 @@ def _call_generic_tool(tool_name, tool_input):
             "input": tool_input
         }).encode('utf-8')
 
-        req = urllib.request.Request(url, data=data, headers={'Content-Type': 'application/json'})
+        req = urllib.request.Request(url, data=data, headers=_trace_headers())
         response = urllib.request.urlopen(req)
         result = json.loads(response.read().decode('utf-8'))
 
@@ -581,6 +602,13 @@ func callTool(ctx context.Context, toolName string, toolInput any) (string, erro
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	// Propagate the trace context of the calling graph node
+	for _, key := range []string{"traceparent", "tracestate", "baggage"} {
+		if value := os.Getenv(strings.ToUpper(key)); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -658,6 +686,13 @@ func callGenericTool(ctx context.Context, toolName string, input string) (string
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	// Propagate the trace context of the calling graph node
+	for _, key := range []string{"traceparent", "tracestate", "baggage"} {
+		if value := os.Getenv(strings.ToUpper(key)); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
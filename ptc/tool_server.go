@@ -202,11 +202,13 @@ func (ts *ToolServer) handleCallTool(w http.ResponseWriter, r *http.Request) {
 
 	log.Debug("Executing tool %s with input length: %d bytes", req.ToolName, len(inputStr))
 
-	// Execute tool
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	// Execute tool, continuing the trace of the caller
+	ctx, span := startToolSpan(r, req.ToolName)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	result, err := tool.Call(ctx, inputStr)
+	endToolSpan(span, err)
 	if err != nil {
 		log.Error("Tool %s execution failed: %v", req.ToolName, err)
 		ts.sendErrorResponse(w, req.ToolName, req.Input, fmt.Sprintf("Tool execution failed: %v", err))
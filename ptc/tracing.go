@@ -0,0 +1,47 @@
+package ptc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/smallnest/langgraphgo/ptc"
+
+// traceEnv returns the trace context of ctx as environment variables (TRACEPARENT, TRACESTATE, ...)
+// for the generated code, which forwards them as headers of its tool server requests.
+// It uses the global TextMapPropagator.
+func traceEnv(ctx context.Context) []string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	env := make([]string, 0, len(carrier))
+	for _, key := range carrier.Keys() {
+		env = append(env, strings.ToUpper(key)+"="+carrier.Get(key))
+	}
+	return env
+}
+
+// startToolSpan starts the span of a tool call received by the tool server,
+// as a child of the trace context propagated in the request headers.
+func startToolSpan(r *http.Request, toolName string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.GetTracerProvider().Tracer(instrumentationName).Start(ctx, "tool "+toolName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("langgraph.tool", toolName)))
+}
+
+// endToolSpan ends the span of a tool call, recording err if the call failed.
+func endToolSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,109 @@
+package ptc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceCaptureTool records the span context its calls run in
+type traceCaptureTool struct {
+	spanContext trace.SpanContext
+}
+
+func (t *traceCaptureTool) Name() string        { return "capture" }
+func (t *traceCaptureTool) Description() string { return "Captures the trace context" }
+func (t *traceCaptureTool) Call(ctx context.Context, input string) (string, error) {
+	t.spanContext = trace.SpanContextFromContext(ctx)
+	return "ok", nil
+}
+
+func setupTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func TestToolServerContinuesTrace(t *testing.T) {
+	exporter := setupTracing(t)
+
+	tool := &traceCaptureTool{}
+	server := NewToolServer([]tools.Tool{tool})
+	ctx := context.Background()
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(ctx)
+
+	ctx, parent := otel.Tracer("test").Start(ctx, "node")
+	defer parent.End()
+
+	body, _ := json.Marshal(ToolRequest{ToolName: "capture", Input: "x"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.GetBaseURL()+"/call", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	resp.Body.Close()
+
+	if tool.spanContext.TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Expected tool to run in trace %s, got %s", parent.SpanContext().TraceID(), tool.spanContext.TraceID())
+	}
+
+	var found bool
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "tool capture" {
+			found = true
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("Expected tool span to be a child of the node span")
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected a span for the tool call")
+	}
+}
+
+func TestTraceEnv(t *testing.T) {
+	setupTracing(t)
+
+	if env := traceEnv(context.Background()); len(env) != 0 {
+		t.Errorf("Expected no trace environment without a span, got %v", env)
+	}
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "node")
+	defer span.End()
+
+	env := traceEnv(ctx)
+	if len(env) != 1 || !strings.HasPrefix(env[0], "TRACEPARENT=") {
+		t.Fatalf("Expected a TRACEPARENT variable, got %v", env)
+	}
+	if !strings.Contains(env[0], span.SpanContext().TraceID().String()) {
+		t.Errorf("Expected TRACEPARENT to carry trace %s, got %s", span.SpanContext().TraceID(), env[0])
+	}
+}
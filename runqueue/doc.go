@@ -0,0 +1,40 @@
+// Package runqueue executes runs of a graph.CheckpointableRunnable in the background, on a
+// bounded pool of workers, and keeps a durable record of each run in a store.RunStore.
+//
+// Submitting a run saves a pending record and returns immediately; a worker then invokes the
+// graph on the thread of the run and records its outcome: succeeded, failed, interrupted (the
+// graph raised an interrupt and the thread waits to be resumed) or cancelled. Runs of the same
+// thread execute one at a time, in submission order.
+//
+// Run records are usually kept next to the checkpoints of the threads, in the SQLite, Postgres
+// or Redis database of the checkpoint store:
+//
+//	checkpoints, _ := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{Path: "agent.db"})
+//	runs, _ := checkpoints.RunStore(ctx)
+//
+//	g.SetCheckpointConfig(graph.CheckpointConfig{Store: checkpoints, AutoSave: true})
+//	runnable, _ := g.CompileCheckpointable()
+//
+//	queue := runqueue.New(runnable, runs, runqueue.WithWorkers(8))
+//	if err := queue.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer queue.Shutdown(context.Background())
+//
+//	run, _ := queue.Submit(ctx, runqueue.Request[map[string]any]{
+//		ThreadID: "thread-1",
+//		Input:    map[string]any{"query": "..."},
+//	})
+//	run, _ = queue.Wait(ctx, run.ID)
+//
+// # Recovery
+//
+// Start picks up the runs a previous process left pending or running. A run that was cut off
+// while running resumes from the latest checkpoint of its thread, without its input; a run that
+// saved no checkpoint before the process stopped starts over.
+//
+// # Cancellation
+//
+// Cancel stops a run by ID: a pending run is never executed, and the context of a running run
+// is cancelled, which stops the graph before its next node.
+package runqueue
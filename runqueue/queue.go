@@ -0,0 +1,434 @@
+package runqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/log"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+var (
+	// ErrClosed is returned when submitting runs to a queue that is shut down
+	ErrClosed = errors.New("run queue is closed")
+
+	// ErrRunDone is returned when cancelling a run that already completed
+	ErrRunDone = errors.New("run is done")
+)
+
+// Request describes a run to submit.
+type Request[S any] struct {
+	// ThreadID is the thread the run executes on; a new thread ID is generated if it is empty
+	ThreadID string
+
+	// Input is the input state of the run, merged into the state of the thread
+	Input S
+
+	// ResumeValue is returned by the graph.Interrupt call that interrupted the thread
+	ResumeValue any
+
+	Metadata map[string]any
+}
+
+// Option configures a Queue.
+type Option func(*options)
+
+type options struct {
+	workers int
+}
+
+// WithWorkers sets the number of runs executed concurrently. The default is 4.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// Queue executes the runs of a CheckpointableRunnable on a pool of workers.
+type Queue[S any] struct {
+	runnable *graph.CheckpointableRunnable[S]
+	runs     store.RunStore
+	opts     options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	started bool
+	closed  bool
+	// pending holds the runs waiting for a worker, oldest first
+	pending []queuedRun
+	// active holds the runs being executed
+	active map[string]*activeRun
+	// busy holds the threads with an active run
+	busy map[string]bool
+	// done is closed when the run completes, for runs submitted or recovered by this queue
+	done map[string]chan struct{}
+}
+
+// queuedRun is a run waiting for a worker.
+type queuedRun struct {
+	id       string
+	threadID string
+}
+
+// activeRun is a run being executed by a worker.
+type activeRun struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// New creates a queue executing runs of runnable and recording them in runs.
+// Call Start to start the workers.
+func New[S any](runnable *graph.CheckpointableRunnable[S], runs store.RunStore, opts ...Option) *Queue[S] {
+	o := options{workers: 4}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	q := &Queue[S]{
+		runnable: runnable,
+		runs:     runs,
+		opts:     o,
+		active:   make(map[string]*activeRun),
+		busy:     make(map[string]bool),
+		done:     make(map[string]chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Start recovers the runs left pending or running by a previous process and starts the workers.
+// The context only bounds the recovery; runs execute until Shutdown is called.
+func (q *Queue[S]) Start(ctx context.Context) error {
+	q.mu.Lock()
+	if q.started {
+		q.mu.Unlock()
+		return errors.New("run queue is already started")
+	}
+	q.started = true
+	q.mu.Unlock()
+
+	orphans, err := q.runs.ListRuns(ctx, store.RunFilter{Statuses: []store.RunStatus{store.RunPending, store.RunRunning}})
+	if err != nil {
+		return fmt.Errorf("failed to recover runs: %w", err)
+	}
+
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	q.mu.Lock()
+	for _, run := range orphans {
+		q.enqueue(run)
+	}
+	q.mu.Unlock()
+
+	for range q.opts.workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return nil
+}
+
+// Submit records a pending run and queues it for execution.
+func (q *Queue[S]) Submit(ctx context.Context, req Request[S]) (*store.Run, error) {
+	q.mu.Lock()
+	closed := q.closed
+	q.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	threadID := req.ThreadID
+	if threadID == "" {
+		threadID = uuid.NewString()
+	}
+
+	now := time.Now()
+	run := &store.Run{
+		ID:          uuid.NewString(),
+		ThreadID:    threadID,
+		Status:      store.RunPending,
+		Input:       req.Input,
+		ResumeValue: req.ResumeValue,
+		Metadata:    req.Metadata,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := q.runs.SaveRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		// The record stays pending and is picked up by the next Start
+		return nil, ErrClosed
+	}
+	q.enqueue(run)
+	return run, nil
+}
+
+// Get returns the record of a run.
+func (q *Queue[S]) Get(ctx context.Context, runID string) (*store.Run, error) {
+	return q.runs.GetRun(ctx, runID)
+}
+
+// Wait waits for a run of this queue to complete and returns its record.
+// Runs that are not queued in this process are returned as they are.
+func (q *Queue[S]) Wait(ctx context.Context, runID string) (*store.Run, error) {
+	q.mu.Lock()
+	done, ok := q.done[runID]
+	q.mu.Unlock()
+
+	if ok {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return q.runs.GetRun(ctx, runID)
+}
+
+// Cancel cancels a run. A pending run is never executed; a running run is stopped.
+// Runs executed by another process are only marked cancelled in the store.
+// It returns ErrRunDone if the run already completed.
+func (q *Queue[S]) Cancel(ctx context.Context, runID string) error {
+	q.mu.Lock()
+	if active, ok := q.active[runID]; ok {
+		active.cancelled = true
+		active.cancel()
+		q.mu.Unlock()
+		return nil
+	}
+	if i := slices.IndexFunc(q.pending, func(r queuedRun) bool { return r.id == runID }); i >= 0 {
+		q.pending = slices.Delete(q.pending, i, i+1)
+		defer q.finish(runID)
+	}
+	q.mu.Unlock()
+
+	run, err := q.runs.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.Status.Done() {
+		return fmt.Errorf("%w: %s is %s", ErrRunDone, runID, run.Status)
+	}
+
+	run.Status = store.RunCancelled
+	run.UpdatedAt = time.Now()
+	return q.runs.SaveRun(ctx, run)
+}
+
+// Shutdown stops accepting runs and waits for the active runs to complete. When ctx is done
+// first, the active runs are cancelled and left running in the store, to be resumed by the
+// next Start. Pending runs stay pending.
+func (q *Queue[S]) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	started := q.started
+	q.mu.Unlock()
+
+	if !started || q.cancel == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+// enqueue queues a run for execution. q.mu must be held.
+func (q *Queue[S]) enqueue(run *store.Run) {
+	if _, ok := q.done[run.ID]; !ok {
+		q.done[run.ID] = make(chan struct{})
+	}
+	q.pending = append(q.pending, queuedRun{id: run.ID, threadID: run.ThreadID})
+	q.cond.Signal()
+}
+
+// finish marks a run of this queue as completed. q.mu must not be held.
+func (q *Queue[S]) finish(runID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if done, ok := q.done[runID]; ok {
+		close(done)
+		delete(q.done, runID)
+	}
+}
+
+// work executes queued runs until the queue is shut down.
+func (q *Queue[S]) work() {
+	defer q.wg.Done()
+	for {
+		queued, active, ok := q.next()
+		if !ok {
+			return
+		}
+		q.execute(queued, active)
+	}
+}
+
+// next waits for a run whose thread is idle, and marks it active.
+func (q *Queue[S]) next() (queuedRun, *activeRun, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if q.closed {
+			return queuedRun{}, nil, false
+		}
+		for i, queued := range q.pending {
+			if q.busy[queued.threadID] {
+				continue
+			}
+
+			q.pending = slices.Delete(q.pending, i, i+1)
+			ctx, cancel := context.WithCancel(q.ctx)
+			active := &activeRun{ctx: ctx, cancel: cancel}
+			q.active[queued.id] = active
+			q.busy[queued.threadID] = true
+			return queued, active, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// execute runs the graph for a run and records its outcome.
+func (q *Queue[S]) execute(queued queuedRun, active *activeRun) {
+	defer func() {
+		active.cancel()
+		q.mu.Lock()
+		delete(q.active, queued.id)
+		delete(q.busy, queued.threadID)
+		q.cond.Broadcast()
+		q.mu.Unlock()
+		q.finish(queued.id)
+	}()
+
+	run, err := q.runs.GetRun(q.ctx, queued.id)
+	if err != nil {
+		log.Error("failed to load run %s: %v", queued.id, err)
+		return
+	}
+	if run.Status.Done() {
+		// Cancelled by another process
+		return
+	}
+
+	q.mu.Lock()
+	cancelled := active.cancelled
+	q.mu.Unlock()
+	if cancelled {
+		q.save(run, store.RunCancelled, nil)
+		return
+	}
+
+	// Runs that already started were cut off by a restart
+	orphaned := run.Attempts > 0
+	startedAt := run.UpdatedAt
+
+	run.Attempts++
+	q.save(run, store.RunRunning, nil)
+
+	_, err = q.invoke(active.ctx, run, orphaned, startedAt)
+
+	q.mu.Lock()
+	cancelled = active.cancelled
+	q.mu.Unlock()
+
+	var interrupt *graph.GraphInterrupt
+	switch {
+	case err == nil:
+		q.save(run, store.RunSucceeded, nil)
+	case errors.As(err, &interrupt):
+		q.save(run, store.RunInterrupted, nil)
+	case cancelled:
+		q.save(run, store.RunCancelled, err)
+	case q.ctx.Err() != nil:
+		// Shut down: the run stays running and is resumed by the next Start
+	default:
+		q.save(run, store.RunFailed, err)
+	}
+}
+
+// invoke executes the graph for a run. Orphaned runs resume from the latest checkpoint of their
+// thread, unless they saved none since they started.
+func (q *Queue[S]) invoke(ctx context.Context, run *store.Run, orphaned bool, startedAt time.Time) (S, error) {
+	config := graph.WithThreadID(run.ThreadID)
+	config.ResumeValue = run.ResumeValue
+
+	if orphaned {
+		latest, err := q.runnable.GetCheckpointStore().GetLatestByThread(ctx, run.ThreadID)
+		if err == nil && latest != nil && !latest.Timestamp.Before(startedAt) {
+			return q.runnable.Resume(ctx, config)
+		}
+	}
+
+	input, err := decodeInput[S](run.Input)
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+	return q.runnable.InvokeWithConfig(ctx, input, config)
+}
+
+// save records the status of a run. Errors of the store are logged, as the run itself is done.
+func (q *Queue[S]) save(run *store.Run, status store.RunStatus, runErr error) {
+	run.Status = status
+	run.UpdatedAt = time.Now()
+	run.Error = ""
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	// Status updates must be recorded even when the run was cancelled
+	if err := q.runs.SaveRun(context.WithoutCancel(q.ctx), run); err != nil {
+		log.Error("failed to save run %s as %s: %v", run.ID, status, err)
+	}
+}
+
+// decodeInput converts the input of a run record to the state type. Records loaded from a
+// database hold the JSON form of the input.
+func decodeInput[S any](input any) (S, error) {
+	var state S
+	if input == nil {
+		return state, nil
+	}
+	if s, ok := input.(S); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return state, fmt.Errorf("failed to encode run input: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to decode run input as %T: %w", state, err)
+	}
+	return state, nil
+}
@@ -0,0 +1,344 @@
+package runqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/memory"
+	"github.com/smallnest/langgraphgo/store/sqlite"
+)
+
+type testGraph struct {
+	checkpoints store.CheckpointStore
+	// block makes the second node wait until it is closed or its context is done
+	block chan struct{}
+	// firstCalls counts the executions of the first node
+	firstCalls atomic.Int32
+
+	mu sync.Mutex
+	// order records the "n" value of the state in each execution of the second node
+	order []any
+}
+
+func newTestGraph() *testGraph {
+	return &testGraph{checkpoints: memory.NewMemoryCheckpointStore()}
+}
+
+// compile builds a graph appending the names of its nodes to the "steps" key of the state.
+// The second node fails if the state holds "fail", and asks for an approval if it holds "approve".
+func (tg *testGraph) compile(t *testing.T) *graph.CheckpointableRunnable[map[string]any] {
+	t.Helper()
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("steps", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("first", "first", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		tg.firstCalls.Add(1)
+		return map[string]any{"steps": []any{"first"}}, nil
+	})
+	g.AddNode("second", "second", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		if tg.block != nil {
+			select {
+			case <-tg.block:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		tg.mu.Lock()
+		tg.order = append(tg.order, state["n"])
+		tg.mu.Unlock()
+
+		if state["fail"] == true {
+			return nil, errors.New("second failed")
+		}
+		if state["approve"] == true {
+			answer, err := graph.Interrupt(ctx, "approve?")
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"steps": []any{"second"}, "answer": answer}, nil
+		}
+		return map[string]any{"steps": []any{"second"}}, nil
+	})
+	g.SetEntryPoint("first")
+	g.AddEdge("first", "second")
+	g.AddEdge("second", graph.END)
+	g.SetCheckpointConfig(graph.CheckpointConfig{Store: tg.checkpoints, AutoSave: true})
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func startQueue(t *testing.T, runnable *graph.CheckpointableRunnable[map[string]any], runs store.RunStore, opts ...Option) *Queue[map[string]any] {
+	t.Helper()
+	q := New(runnable, runs, opts...)
+	require.NoError(t, q.Start(context.Background()))
+	t.Cleanup(func() { _ = q.Shutdown(context.Background()) })
+	return q
+}
+
+func wait(t *testing.T, q *Queue[map[string]any], runID string) *store.Run {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	run, err := q.Wait(ctx, runID)
+	require.NoError(t, err)
+	return run
+}
+
+func threadState(t *testing.T, runnable *graph.CheckpointableRunnable[map[string]any], threadID string) map[string]any {
+	t.Helper()
+	snapshot, err := runnable.GetState(context.Background(), graph.WithThreadID(threadID))
+	require.NoError(t, err)
+	return snapshot.Values.(map[string]any)
+}
+
+func TestQueue_Outcomes(t *testing.T) {
+	tg := newTestGraph()
+	runnable := tg.compile(t)
+	q := startQueue(t, runnable, memory.NewMemoryRunStore())
+	ctx := context.Background()
+
+	run, err := q.Submit(ctx, Request[map[string]any]{ThreadID: "ok", Input: map[string]any{}, Metadata: map[string]any{"user": "ada"}})
+	require.NoError(t, err)
+	assert.Equal(t, store.RunPending, run.Status)
+
+	run = wait(t, q, run.ID)
+	assert.Equal(t, store.RunSucceeded, run.Status)
+	assert.Equal(t, 1, run.Attempts)
+	assert.Equal(t, "ada", run.Metadata["user"])
+	assert.Equal(t, []any{"first", "second"}, threadState(t, runnable, "ok")["steps"])
+
+	run, err = q.Submit(ctx, Request[map[string]any]{ThreadID: "fail", Input: map[string]any{"fail": true}})
+	require.NoError(t, err)
+	run = wait(t, q, run.ID)
+	assert.Equal(t, store.RunFailed, run.Status)
+	assert.Contains(t, run.Error, "second failed")
+
+	run, err = q.Submit(ctx, Request[map[string]any]{ThreadID: "approve", Input: map[string]any{"approve": true}})
+	require.NoError(t, err)
+	run = wait(t, q, run.ID)
+	assert.Equal(t, store.RunInterrupted, run.Status)
+
+	run, err = q.Submit(ctx, Request[map[string]any]{ThreadID: "approve", ResumeValue: "yes"})
+	require.NoError(t, err)
+	run = wait(t, q, run.ID)
+	assert.Equal(t, store.RunSucceeded, run.Status)
+	assert.Equal(t, "yes", threadState(t, runnable, "approve")["answer"])
+
+	err = q.Cancel(ctx, run.ID)
+	assert.ErrorIs(t, err, ErrRunDone)
+}
+
+func TestQueue_ThreadRunsExecuteInOrder(t *testing.T) {
+	tg := newTestGraph()
+	runnable := tg.compile(t)
+	q := startQueue(t, runnable, memory.NewMemoryRunStore(), WithWorkers(4))
+	ctx := context.Background()
+
+	var ids []string
+	for i := range 5 {
+		run, err := q.Submit(ctx, Request[map[string]any]{ThreadID: "ordered", Input: map[string]any{"n": i}})
+		require.NoError(t, err)
+		ids = append(ids, run.ID)
+	}
+	for _, id := range ids {
+		assert.Equal(t, store.RunSucceeded, wait(t, q, id).Status)
+	}
+
+	assert.Equal(t, []any{0, 1, 2, 3, 4}, tg.order)
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	tg := newTestGraph()
+	tg.block = make(chan struct{})
+	runnable := tg.compile(t)
+	runs := memory.NewMemoryRunStore()
+	q := startQueue(t, runnable, runs, WithWorkers(1))
+	ctx := context.Background()
+
+	running, err := q.Submit(ctx, Request[map[string]any]{ThreadID: "a", Input: map[string]any{}})
+	require.NoError(t, err)
+	pending, err := q.Submit(ctx, Request[map[string]any]{ThreadID: "b", Input: map[string]any{}})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		run, err := runs.GetRun(ctx, running.ID)
+		return err == nil && run.Status == store.RunRunning
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, q.Cancel(ctx, pending.ID))
+	require.NoError(t, q.Cancel(ctx, running.ID))
+
+	assert.Equal(t, store.RunCancelled, wait(t, q, running.ID).Status)
+	run := wait(t, q, pending.ID)
+	assert.Equal(t, store.RunCancelled, run.Status)
+	assert.Equal(t, 0, run.Attempts)
+	assert.Equal(t, int32(1), tg.firstCalls.Load())
+}
+
+func TestQueue_RecoverOrphanedRuns(t *testing.T) {
+	tg := newTestGraph()
+	tg.block = make(chan struct{})
+	runs := memory.NewMemoryRunStore()
+	ctx := context.Background()
+
+	// The first process is stopped while the second node of a run waits
+	q := New(tg.compile(t), runs)
+	require.NoError(t, q.Start(ctx))
+	orphan, err := q.Submit(ctx, Request[map[string]any]{ThreadID: "orphan", Input: map[string]any{}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		checkpoint, err := tg.checkpoints.GetLatestByThread(ctx, "orphan")
+		return err == nil && checkpoint != nil
+	}, time.Second, 5*time.Millisecond)
+
+	stopCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, q.Shutdown(stopCtx), context.Canceled)
+
+	run, err := runs.GetRun(ctx, orphan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.RunRunning, run.Status)
+
+	// A run submitted while no process was running
+	now := time.Now()
+	require.NoError(t, runs.SaveRun(ctx, &store.Run{
+		ID:        "queued",
+		ThreadID:  "queued",
+		Status:    store.RunPending,
+		Input:     map[string]any{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	// The next process resumes the orphan after its first node
+	close(tg.block)
+	runnable := tg.compile(t)
+	q = startQueue(t, runnable, runs)
+
+	run = wait(t, q, orphan.ID)
+	assert.Equal(t, store.RunSucceeded, run.Status)
+	assert.Equal(t, 2, run.Attempts)
+	assert.Equal(t, []any{"first", "second"}, threadState(t, runnable, "orphan")["steps"])
+
+	assert.Equal(t, store.RunSucceeded, wait(t, q, "queued").Status)
+	assert.Equal(t, int32(2), tg.firstCalls.Load())
+}
+
+// structState is the state of TestQueue_RecoverOrphanedRuns_Sqlite, which the SQLite store
+// returns as a generic JSON value.
+type structState struct {
+	Steps []string `json:"steps"`
+}
+
+func TestQueue_RecoverOrphanedRuns_Sqlite(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir() + "/checkpoints.db"
+	block := make(chan struct{})
+	var firstCalls atomic.Int32
+
+	// open opens the store of a process and compiles its graph
+	open := func() (*graph.CheckpointableRunnable[structState], *sqlite.SqliteCheckpointStore, store.RunStore) {
+		checkpoints, err := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{Path: path})
+		require.NoError(t, err)
+		t.Cleanup(func() { checkpoints.Close() })
+		runs, err := checkpoints.RunStore(ctx)
+		require.NoError(t, err)
+
+		g := graph.NewCheckpointableStateGraph[structState]()
+		g.AddNode("first", "first", func(ctx context.Context, state structState) (structState, error) {
+			firstCalls.Add(1)
+			state.Steps = append(state.Steps, "first")
+			return state, nil
+		})
+		g.AddNode("second", "second", func(ctx context.Context, state structState) (structState, error) {
+			select {
+			case <-block:
+			case <-ctx.Done():
+				return state, ctx.Err()
+			}
+			state.Steps = append(state.Steps, "second")
+			return state, nil
+		})
+		g.SetEntryPoint("first")
+		g.AddEdge("first", "second")
+		g.AddEdge("second", graph.END)
+		g.SetCheckpointConfig(graph.CheckpointConfig{Store: checkpoints, AutoSave: true})
+
+		runnable, err := g.CompileCheckpointable()
+		require.NoError(t, err)
+		return runnable, checkpoints, runs
+	}
+
+	// The first process is stopped while the second node of the run waits
+	runnable, checkpoints, runs := open()
+	q := New(runnable, runs)
+	require.NoError(t, q.Start(ctx))
+	orphan, err := q.Submit(ctx, Request[structState]{ThreadID: "orphan", Input: structState{}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		checkpoint, err := checkpoints.GetLatestByThread(ctx, "orphan")
+		return err == nil && checkpoint != nil
+	}, time.Second, 5*time.Millisecond)
+
+	stopCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, q.Shutdown(stopCtx), context.Canceled)
+
+	// The next process resumes the run from the struct state decoded from its checkpoint
+	close(block)
+	runnable, _, runs = open()
+	q = New(runnable, runs)
+	require.NoError(t, q.Start(ctx))
+	t.Cleanup(func() { _ = q.Shutdown(context.Background()) })
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelWait()
+	run, err := q.Wait(waitCtx, orphan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.RunSucceeded, run.Status, run.Error)
+	assert.Equal(t, 2, run.Attempts)
+	assert.Equal(t, int32(1), firstCalls.Load())
+
+	snapshot, err := runnable.GetState(ctx, graph.WithThreadID("orphan"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"steps": []any{"first", "second"}}, snapshot.Values)
+}
+
+func TestQueue_SubmitAfterShutdown(t *testing.T) {
+	q := New(newTestGraph().compile(t), memory.NewMemoryRunStore())
+	require.NoError(t, q.Start(context.Background()))
+	require.NoError(t, q.Shutdown(context.Background()))
+
+	_, err := q.Submit(context.Background(), Request[map[string]any]{Input: map[string]any{}})
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestDecodeInput(t *testing.T) {
+	type input struct {
+		Query string `json:"query"`
+	}
+
+	decoded, err := decodeInput[input](map[string]any{"query": "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, input{Query: "hello"}, decoded)
+
+	decoded, err = decodeInput[input](input{Query: "typed"})
+	require.NoError(t, err)
+	assert.Equal(t, "typed", decoded.Query)
+
+	_, err = decodeInput[input]("not an object")
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+// Package server exposes a compiled graph over HTTP, following the REST shapes of the
+// LangGraph Platform API.
+//
+// A Server mounts a graph.CheckpointableRunnable: the state of each thread is persisted
+// in the checkpoint store of the runnable, and interrupts raised by the graph (static
+// interrupts as well as graph.Interrupt calls) pause the thread until a run resumes it.
+// Thread metadata and interrupts are kept in the store too, which must implement
+// store.ThreadStore, so a server restarted on the same store picks the threads up. Runs are
+// kept in memory only.
+//
+// # Endpoints
+//
+//	POST   /threads                                create a thread
+//	GET    /threads/{thread_id}                    get a thread
+//	DELETE /threads/{thread_id}                    delete a thread and its checkpoints
+//	GET    /threads/{thread_id}/state              get the state of a thread
+//	GET    /threads/{thread_id}/state/{checkpoint_id}
+//	POST   /threads/{thread_id}/state              update the state of a thread
+//	GET    /threads/{thread_id}/history            list the checkpoints of a thread, newest first
+//	POST   /threads/{thread_id}/history
+//	POST   /threads/{thread_id}/runs               start a background run
+//	GET    /threads/{thread_id}/runs               list the runs of a thread
+//	POST   /threads/{thread_id}/runs/wait          run and wait for the final state
+//	POST   /threads/{thread_id}/runs/stream        run and stream events over Server-Sent Events
+//	GET    /threads/{thread_id}/runs/{run_id}      get a run
+//	GET    /threads/{thread_id}/runs/{run_id}/join wait for a run and return the final state
+//
+// A run resumes an interrupted thread when its request holds a command:
+//
+//	{"command": {"resume": "approved"}}
+//
+// Streams emit a metadata event with the run ID, then values, updates, messages, custom
+// or debug events depending on stream_mode, and an error event if the run fails.
+//
+// # Usage
+//
+//	runnable, _ := g.CompileCheckpointable()
+//	srv := server.New(runnable, server.WithGraphID("agent"))
+//	defer srv.Shutdown(context.Background())
+//	http.ListenAndServe(":8123", srv)
+package server
@@ -0,0 +1,448 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// execution is a run ready to execute: the thread is claimed and the input and config are prepared.
+type execution[S any] struct {
+	threadID string
+	run      *run
+	input    S
+	config   *graph.Config
+}
+
+func (s *Server[S]) handleCreateRun(w http.ResponseWriter, r *http.Request) {
+	exec, _, err := s.startRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		state, err := s.runnable.InvokeWithConfig(s.ctx, exec.input, exec.config)
+		s.finishRun(exec, state, err)
+	}()
+
+	writeJSON(w, http.StatusOK, s.runInfo(exec.run))
+}
+
+func (s *Server[S]) handleWaitRun(w http.ResponseWriter, r *http.Request) {
+	exec, _, err := s.startRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	state, err := s.runnable.InvokeWithConfig(r.Context(), exec.input, exec.config)
+	s.finishRun(exec, state, err)
+	s.writeOutput(w, exec.run)
+}
+
+func (s *Server[S]) handleJoinRun(w http.ResponseWriter, r *http.Request) {
+	rn, err := s.run(r.PathValue("thread_id"), r.PathValue("run_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	select {
+	case <-rn.done:
+		s.writeOutput(w, rn)
+	case <-r.Context().Done():
+	}
+}
+
+func (s *Server[S]) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	rn, err := s.run(r.PathValue("thread_id"), r.PathValue("run_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.runInfo(rn))
+}
+
+func (s *Server[S]) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("thread_id")
+	if _, err := s.thread(r.Context(), threadID, false); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	runs := make([]Run, 0)
+	for _, rn := range s.runs {
+		if rn.info.ThreadID == threadID {
+			runs = append(runs, rn.info)
+		}
+	}
+	s.mu.Unlock()
+
+	// Newest first
+	slices.SortFunc(runs, func(a, b Run) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *Server[S]) handleStreamRun(w http.ResponseWriter, r *http.Request) {
+	sse, ok := newSSEWriter(w)
+	if !ok {
+		writeError(w, errorf(http.StatusInternalServerError, "streaming is not supported by the connection"))
+		return
+	}
+
+	exec, req, err := s.startRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	modes := req.StreamMode
+	if len(modes) == 0 {
+		modes = StreamModes{"values"}
+	}
+
+	result := s.runnable.StreamWithConfig(r.Context(), exec.input, exec.config, graph.StreamConfig{
+		Mode: graph.StreamModeDebug,
+	})
+	defer result.Cancel()
+
+	sse.start()
+	_ = sse.send("metadata", map[string]any{"run_id": exec.run.info.RunID, "attempt": 1})
+	for event := range result.Events {
+		for _, mode := range modes {
+			if name, data, ok := streamEvent(mode, event, req.StreamSubgraphs); ok {
+				_ = sse.send(name, data)
+			}
+		}
+	}
+
+	var state S
+	var runErr error
+	if v, ok := <-result.Result; ok {
+		state = v
+	} else {
+		runErr = <-result.Errors
+	}
+	s.finishRun(exec, state, runErr)
+
+	var gi *graph.GraphInterrupt
+	switch {
+	case errors.As(runErr, &gi):
+		interrupts := newPendingInterrupt[S](gi).interrupts
+		for _, mode := range modes {
+			if mode == "values" || mode == "updates" {
+				_ = sse.send(mode, map[string]any{"__interrupt__": interrupts})
+			}
+		}
+	case runErr != nil:
+		_ = sse.send("error", map[string]string{"error": "RunError", "message": runErr.Error()})
+	}
+}
+
+// startRun decodes the run request of r, claims its thread and prepares the input and config of the run.
+// Only one run of a thread may be active at a time.
+func (s *Server[S]) startRun(r *http.Request) (*execution[S], *RunCreate, error) {
+	var req RunCreate
+	if err := decodeBody(r, &req); err != nil {
+		return nil, nil, err
+	}
+	if req.AssistantID != "" && req.AssistantID != s.graphID {
+		return nil, nil, errorf(http.StatusNotFound, "assistant %s not found", req.AssistantID)
+	}
+	input, hasInput, err := decodeInput[S](req.Input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	threadID := r.PathValue("thread_id")
+	if _, err := s.thread(r.Context(), threadID, req.IfNotExists == "create"); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	rn := &run{
+		info: Run{
+			RunID:       uuid.New().String(),
+			ThreadID:    threadID,
+			AssistantID: s.graphID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Status:      RunPending,
+			Metadata:    req.Metadata,
+		},
+		done: make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	if s.active[threadID] != "" {
+		s.mu.Unlock()
+		return nil, nil, errorf(http.StatusConflict, "thread %s already has an active run", threadID)
+	}
+	s.active[threadID] = rn.info.RunID
+	s.runs[rn.info.RunID] = rn
+	s.mu.Unlock()
+
+	input, config, err := s.runConfig(r.Context(), threadID, &req, input, hasInput)
+	if err != nil {
+		// The run did not start, the thread keeps its state
+		s.mu.Lock()
+		delete(s.active, threadID)
+		rn.info.Status = RunError
+		rn.info.Error = err.Error()
+		s.mu.Unlock()
+		close(rn.done)
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	rn.info.Status = RunRunning
+	s.mu.Unlock()
+	return &execution[S]{threadID: threadID, run: rn, input: input, config: config}, &req, nil
+}
+
+// runConfig returns the input and config of a run.
+//
+// A run with a resume command continues an interrupted thread with the nodes that did not run.
+// A run with input starts from the entry point of the graph, with the input merged into the state
+// of the thread. A run without input continues the thread from its latest checkpoint, rerunning
+// the tasks that failed.
+func (s *Server[S]) runConfig(ctx context.Context, threadID string, req *RunCreate, input S, hasInput bool) (S, *graph.Config, error) {
+	config := &graph.Config{
+		Configurable:    map[string]any{},
+		Metadata:        req.Metadata,
+		InterruptBefore: req.InterruptBefore,
+		InterruptAfter:  req.InterruptAfter,
+	}
+	if req.Config != nil {
+		maps.Copy(config.Configurable, req.Config.Configurable)
+		config.Tags = req.Config.Tags
+		config.RecursionLimit = req.Config.RecursionLimit
+	}
+	config.Configurable["thread_id"] = threadID
+	if req.CheckpointID != "" {
+		config.Configurable["checkpoint_id"] = req.CheckpointID
+	}
+
+	switch {
+	case req.Command != nil && len(req.Command.Resume) > 0:
+		var resume any
+		if err := json.Unmarshal(req.Command.Resume, &resume); err != nil {
+			return input, nil, errorf(http.StatusUnprocessableEntity, "invalid resume value: %v", err)
+		}
+		config.ResumeValue = resume
+		// Without a recorded interrupt, the thread resumes from the given or latest checkpoint
+		if req.CheckpointID != "" {
+			break
+		}
+		checkpoints, err := s.checkpoints(ctx, threadID)
+		if err != nil {
+			return input, nil, err
+		}
+		interrupt, err := latestInterrupt[S](checkpoints)
+		if err != nil {
+			return input, nil, err
+		}
+		if interrupt != nil {
+			config.ResumeFrom = interrupt.next
+			input = interrupt.state
+		}
+
+	case hasInput && req.CheckpointID == "":
+		checkpoints, err := s.checkpoints(ctx, threadID)
+		if err != nil {
+			return input, nil, err
+		}
+		if len(checkpoints) == 0 {
+			break
+		}
+		current, err := decodeState[S](checkpoints[len(checkpoints)-1].State)
+		if err != nil {
+			return input, nil, err
+		}
+		g := s.runnable.GetGraph()
+		if g.Schema != nil {
+			if input, err = g.Schema.Update(current, input); err != nil {
+				return input, nil, errorf(http.StatusUnprocessableEntity, "invalid input: %v", err)
+			}
+		}
+		config.ResumeFrom = []string{g.EntryPoint()}
+	}
+
+	return input, config, nil
+}
+
+// finishRun records the outcome of a run and releases its thread. The interrupt of an
+// interrupted run is saved to the checkpoint store.
+func (s *Server[S]) finishRun(exec *execution[S], state S, err error) {
+	status := RunSuccess
+	var output json.RawMessage
+
+	var gi *graph.GraphInterrupt
+	switch {
+	case errors.As(err, &gi):
+		status = RunInterrupted
+		interrupt := newPendingInterrupt[S](gi)
+		output = withInterrupts(gi.State, interrupt.interrupts)
+		// The run may end because the server shuts down
+		if saveErr := s.saveInterrupt(context.WithoutCancel(s.ctx), exec.threadID, interrupt); saveErr != nil {
+			status, err = RunError, saveErr
+		}
+	case err != nil:
+		status = RunError
+	default:
+		output, _ = json.Marshal(state)
+	}
+
+	s.mu.Lock()
+	delete(s.active, exec.threadID)
+	exec.run.info.Status = status
+	exec.run.info.UpdatedAt = time.Now()
+	if status == RunError {
+		exec.run.info.Error = err.Error()
+	}
+	exec.run.output = output
+	s.mu.Unlock()
+	close(exec.run.done)
+}
+
+// newPendingInterrupt records the interrupt of a run. A resume runs the nodes the run would have run next.
+func newPendingInterrupt[S any](gi *graph.GraphInterrupt) *pendingInterrupt[S] {
+	state, _ := gi.State.(S)
+	next := gi.NextNodes
+	when := "after"
+	switch {
+	case gi.InterruptValue != nil:
+		when = "during"
+	case len(gi.NextNodes) == 0:
+		when = "before"
+	}
+	if len(next) == 0 {
+		next = []string{gi.Node}
+	}
+	return &pendingInterrupt[S]{
+		next:       next,
+		state:      state,
+		interrupts: []Interrupt{{Value: gi.InterruptValue, When: when, Resumable: true}},
+	}
+}
+
+// withInterrupts returns the JSON of state with the interrupts under the "__interrupt__" key.
+func withInterrupts(state any, interrupts []Interrupt) json.RawMessage {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil || values == nil {
+		values = map[string]json.RawMessage{}
+	}
+	values["__interrupt__"], _ = json.Marshal(interrupts)
+	output, _ := json.Marshal(values)
+	return output
+}
+
+// run returns the record of a run of a thread.
+func (s *Server[S]) run(threadID, runID string) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rn, ok := s.runs[runID]
+	if !ok || rn.info.ThreadID != threadID {
+		return nil, errorf(http.StatusNotFound, "run %s not found", runID)
+	}
+	return rn, nil
+}
+
+func (s *Server[S]) runInfo(rn *run) Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return rn.info
+}
+
+// writeOutput responds with the final state of a finished run, or its error.
+func (s *Server[S]) writeOutput(w http.ResponseWriter, rn *run) {
+	s.mu.Lock()
+	info, output := rn.info, rn.output
+	s.mu.Unlock()
+
+	if info.Status == RunError {
+		writeError(w, errors.New(info.Error))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(output)
+}
+
+// streamEvent returns the SSE event of a graph event in a stream mode, if the mode includes it.
+// Events of subgraphs are only streamed with subgraphs, named "<mode>|<namespace>".
+func streamEvent[S any](mode string, event graph.StreamEvent[S], subgraphs bool) (string, any, bool) {
+	if event.Namespace != "" && !subgraphs {
+		return "", nil, false
+	}
+
+	var state any = event.State
+	if v, ok := event.Metadata["state"]; ok {
+		state = v
+	}
+
+	var data any
+	switch mode {
+	case "values":
+		if event.Event != graph.EventStep {
+			return "", nil, false
+		}
+		data = state
+	case "updates":
+		if event.Event != graph.NodeEventComplete {
+			return "", nil, false
+		}
+		data = map[string]any{event.NodeName: state}
+	case "messages":
+		if event.Event != graph.EventToken {
+			return "", nil, false
+		}
+		data = []any{
+			map[string]any{"type": "AIMessageChunk", "id": event.Metadata["message_id"], "content": event.Metadata["chunk"]},
+			map[string]any{"langgraph_node": event.NodeName, "run_id": event.Metadata["run_id"]},
+		}
+	case "custom":
+		if event.Event != graph.EventCustom {
+			return "", nil, false
+		}
+		data = event.Metadata["payload"]
+	case "debug":
+		if event.Event == graph.EventChainStart || event.Event == graph.EventChainEnd {
+			return "", nil, false
+		}
+		debug := map[string]any{
+			"type":      string(event.Event),
+			"node":      event.NodeName,
+			"timestamp": event.Timestamp,
+			"payload":   state,
+		}
+		if event.Error != nil {
+			debug["error"] = event.Error.Error()
+		}
+		data = debug
+	default:
+		return "", nil, false
+	}
+
+	name := mode
+	if event.Namespace != "" {
+		name = strings.Join([]string{mode, event.Namespace}, "|")
+	}
+	return name, data, true
+}
@@ -0,0 +1,625 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// Server serves a compiled graph over HTTP. It implements http.Handler.
+type Server[S any] struct {
+	runnable *graph.CheckpointableRunnable[S]
+	graphID  string
+	mux      *http.ServeMux
+
+	// ctx is the context of background runs, canceled by Shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// active holds the ID of the active run of each thread
+	active map[string]string
+	runs   map[string]*run
+}
+
+// pendingInterrupt records where an interrupted run stopped, so that a resume continues
+// with the nodes that did not run.
+type pendingInterrupt[S any] struct {
+	next       []string
+	state      S
+	interrupts []Interrupt
+}
+
+// run is the server side record of a run.
+type run struct {
+	info Run
+	// output is the JSON of the final state, set when the run ends
+	output json.RawMessage
+	done   chan struct{}
+}
+
+// Option configures a Server.
+type Option func(*options)
+
+type options struct {
+	graphID string
+}
+
+// WithGraphID sets the assistant ID of the graph, "agent" by default.
+// Runs naming another assistant are rejected.
+func WithGraphID(graphID string) Option {
+	return func(o *options) {
+		o.graphID = graphID
+	}
+}
+
+// New creates a Server for runnable.
+func New[S any](runnable *graph.CheckpointableRunnable[S], opts ...Option) *Server[S] {
+	o := options{graphID: "agent"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server[S]{
+		runnable: runnable,
+		graphID:  o.graphID,
+		mux:      http.NewServeMux(),
+		ctx:      ctx,
+		cancel:   cancel,
+		active:   make(map[string]string),
+		runs:     make(map[string]*run),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server[S]) routes() {
+	s.mux.HandleFunc("POST /threads", s.handleCreateThread)
+	s.mux.HandleFunc("GET /threads/{thread_id}", s.handleGetThread)
+	s.mux.HandleFunc("DELETE /threads/{thread_id}", s.handleDeleteThread)
+	s.mux.HandleFunc("GET /threads/{thread_id}/state", s.handleGetState)
+	s.mux.HandleFunc("GET /threads/{thread_id}/state/{checkpoint_id}", s.handleGetState)
+	s.mux.HandleFunc("POST /threads/{thread_id}/state", s.handleUpdateState)
+	s.mux.HandleFunc("GET /threads/{thread_id}/history", s.handleHistory)
+	s.mux.HandleFunc("POST /threads/{thread_id}/history", s.handleHistory)
+	s.mux.HandleFunc("POST /threads/{thread_id}/runs", s.handleCreateRun)
+	s.mux.HandleFunc("GET /threads/{thread_id}/runs", s.handleListRuns)
+	s.mux.HandleFunc("POST /threads/{thread_id}/runs/wait", s.handleWaitRun)
+	s.mux.HandleFunc("POST /threads/{thread_id}/runs/stream", s.handleStreamRun)
+	s.mux.HandleFunc("GET /threads/{thread_id}/runs/{run_id}", s.handleGetRun)
+	s.mux.HandleFunc("GET /threads/{thread_id}/runs/{run_id}/join", s.handleJoinRun)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server[S]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Shutdown cancels the background runs and waits for them to end, or for ctx to be done.
+func (s *Server[S]) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server[S]) handleCreateThread(w http.ResponseWriter, r *http.Request) {
+	var req ThreadCreate
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.ThreadID == "" {
+		req.ThreadID = uuid.New().String()
+	}
+
+	if t, err := s.thread(r.Context(), req.ThreadID, false); err == nil {
+		if req.IfExists != "do_nothing" {
+			writeError(w, errorf(http.StatusConflict, "thread %s already exists", req.ThreadID))
+			return
+		}
+		s.writeThread(r.Context(), w, t)
+		return
+	} else if !isNotFound(err) {
+		writeError(w, err)
+		return
+	}
+
+	threads, err := s.threadStore()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	t, err := threads.UpdateThreadMetadata(r.Context(), req.ThreadID, req.Metadata)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to create thread %s: %w", req.ThreadID, err))
+		return
+	}
+	s.writeThread(r.Context(), w, t)
+}
+
+func (s *Server[S]) handleGetThread(w http.ResponseWriter, r *http.Request) {
+	t, err := s.thread(r.Context(), r.PathValue("thread_id"), false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeThread(r.Context(), w, t)
+}
+
+func (s *Server[S]) handleDeleteThread(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("thread_id")
+	if _, err := s.thread(r.Context(), threadID, false); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	if s.active[threadID] != "" {
+		s.mu.Unlock()
+		writeError(w, errorf(http.StatusConflict, "thread %s has an active run", threadID))
+		return
+	}
+	for id, rn := range s.runs {
+		if rn.info.ThreadID == threadID {
+			delete(s.runs, id)
+		}
+	}
+	s.mu.Unlock()
+
+	threads, err := s.threadStore()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := threads.DeleteThread(r.Context(), threadID); err != nil {
+		writeError(w, fmt.Errorf("failed to delete thread %s: %w", threadID, err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server[S]) handleGetState(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("thread_id")
+	if _, err := s.thread(r.Context(), threadID, false); err != nil {
+		writeError(w, err)
+		return
+	}
+	checkpoints, err := s.checkpoints(r.Context(), threadID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	interrupt, err := latestInterrupt[S](checkpoints)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if checkpointID := r.PathValue("checkpoint_id"); checkpointID != "" {
+		for i, cp := range checkpoints {
+			if cp.ID == checkpointID {
+				writeJSON(w, http.StatusOK, threadState(threadID, cp, latestOnly(interrupt, i == len(checkpoints)-1)))
+				return
+			}
+		}
+		writeError(w, errorf(http.StatusNotFound, "checkpoint %s not found in thread %s", checkpointID, threadID))
+		return
+	}
+
+	var latest *store.Checkpoint
+	if len(checkpoints) > 0 {
+		latest = checkpoints[len(checkpoints)-1]
+	}
+	writeJSON(w, http.StatusOK, threadState(threadID, latest, interrupt))
+}
+
+func (s *Server[S]) handleUpdateState(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("thread_id")
+	var req StateUpdate
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	values, ok, err := decodeInput[S](req.Values)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !ok {
+		writeError(w, errorf(http.StatusUnprocessableEntity, "values are required"))
+		return
+	}
+
+	if _, err := s.thread(r.Context(), threadID, false); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mu.Lock()
+	busy := s.active[threadID] != ""
+	s.mu.Unlock()
+	if busy {
+		writeError(w, errorf(http.StatusConflict, "thread %s has an active run", threadID))
+		return
+	}
+
+	asNode := req.AsNode
+	if asNode == "" {
+		checkpoints, err := s.checkpoints(r.Context(), threadID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if len(checkpoints) > 0 {
+			asNode = checkpoints[len(checkpoints)-1].NodeName
+		} else {
+			asNode = s.runnable.GetGraph().EntryPoint()
+		}
+	}
+
+	// An interrupted thread stays interrupted, and a resume continues from the updated state
+	config := graph.WithThreadID(threadID)
+	if req.CheckpointID != "" {
+		config.Configurable["checkpoint_id"] = req.CheckpointID
+	}
+	updated, err := s.runnable.UpdateState(r.Context(), config, asNode, values)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to update state: %w", err))
+		return
+	}
+	checkpointID, _ := updated.Configurable["checkpoint_id"].(string)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"checkpoint": CheckpointRef{ThreadID: threadID, CheckpointID: checkpointID},
+	})
+}
+
+func (s *Server[S]) handleHistory(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("thread_id")
+	req := HistoryRequest{Limit: 10}
+	if r.Method == http.MethodPost {
+		if err := decodeBody(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+	} else {
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			if _, err := fmt.Sscan(limit, &req.Limit); err != nil {
+				writeError(w, errorf(http.StatusUnprocessableEntity, "invalid limit %q", limit))
+				return
+			}
+		}
+		req.Before = r.URL.Query().Get("before")
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	if _, err := s.thread(r.Context(), threadID, false); err != nil {
+		writeError(w, err)
+		return
+	}
+	checkpoints, err := s.checkpoints(r.Context(), threadID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	interrupt, err := latestInterrupt[S](checkpoints)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Newest first, starting before the given checkpoint
+	end := len(checkpoints)
+	if req.Before != "" {
+		end = slices.IndexFunc(checkpoints, func(cp *store.Checkpoint) bool { return cp.ID == req.Before })
+		if end < 0 {
+			writeError(w, errorf(http.StatusNotFound, "checkpoint %s not found in thread %s", req.Before, threadID))
+			return
+		}
+	}
+	states := make([]ThreadState, 0, min(end, req.Limit))
+	for i := end - 1; i >= 0 && len(states) < req.Limit; i-- {
+		states = append(states, threadState(threadID, checkpoints[i], latestOnly(interrupt, i == len(checkpoints)-1)))
+	}
+	writeJSON(w, http.StatusOK, states)
+}
+
+// threadStore returns the checkpoint store of the runnable, which keeps the threads.
+func (s *Server[S]) threadStore() (store.ThreadStore, error) {
+	cpStore := s.runnable.GetCheckpointStore()
+	threads, ok := cpStore.(store.ThreadStore)
+	if !ok {
+		return nil, errorf(http.StatusNotImplemented, "checkpoint store %T does not manage threads", cpStore)
+	}
+	return threads, nil
+}
+
+// thread returns a thread from the store: threads are created with metadata, or by saving
+// their first checkpoint. If create is true, unknown threads are created.
+func (s *Server[S]) thread(ctx context.Context, threadID string, create bool) (*store.Thread, error) {
+	threads, err := s.threadStore()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := threads.GetThread(ctx, threadID)
+	if errors.Is(err, store.ErrThreadNotFound) {
+		if !create {
+			return nil, errorf(http.StatusNotFound, "thread %s not found", threadID)
+		}
+		t, err = threads.UpdateThreadMetadata(ctx, threadID, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread %s: %w", threadID, err)
+	}
+	return t, nil
+}
+
+// checkpoints returns the checkpoints of a thread, oldest first.
+func (s *Server[S]) checkpoints(ctx context.Context, threadID string) ([]*store.Checkpoint, error) {
+	checkpoints, err := s.runnable.GetCheckpointStore().ListByThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints of thread %s: %w", threadID, err)
+	}
+	slices.SortStableFunc(checkpoints, func(a, b *store.Checkpoint) int { return a.Version - b.Version })
+	return checkpoints, nil
+}
+
+func (s *Server[S]) writeThread(ctx context.Context, w http.ResponseWriter, t *store.Thread) {
+	checkpoints, err := s.checkpoints(ctx, t.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	interrupt, err := latestInterrupt[S](checkpoints)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	info := Thread{
+		ThreadID:   t.ID,
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+		Metadata:   t.Metadata,
+		Status:     ThreadIdle,
+		Interrupts: map[string][]Interrupt{},
+	}
+	if len(checkpoints) > 0 {
+		latest := checkpoints[len(checkpoints)-1]
+		info.Values = latest.State
+		if latest.Metadata["event"] == "step_failed" {
+			info.Status = ThreadError
+		}
+	}
+	if interrupt != nil {
+		info.Status = ThreadInterrupted
+		info.Values = interrupt.state
+		for _, node := range interrupt.next {
+			info.Interrupts[node] = interrupt.interrupts
+		}
+	}
+	s.mu.Lock()
+	if s.active[t.ID] != "" {
+		info.Status = ThreadBusy
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, info)
+}
+
+// saveInterrupt saves a checkpoint of the state an interrupted run stopped at, recording the
+// nodes to resume under the "next" metadata key and the interrupts under "interrupts".
+func (s *Server[S]) saveInterrupt(ctx context.Context, threadID string, interrupt *pendingInterrupt[S]) error {
+	checkpoints, err := s.checkpoints(ctx, threadID)
+	if err != nil {
+		return err
+	}
+	cp := &store.Checkpoint{
+		ID:        uuid.New().String(),
+		NodeName:  interrupt.next[0],
+		State:     interrupt.state,
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata: map[string]any{
+			"execution_id": threadID,
+			"thread_id":    threadID,
+			"event":        "interrupt",
+			"next":         interrupt.next,
+			"interrupts":   interrupt.interrupts,
+		},
+	}
+	if len(checkpoints) > 0 {
+		latest := checkpoints[len(checkpoints)-1]
+		cp.Version = latest.Version + 1
+		cp.ParentID = latest.ID
+	}
+	if err := s.runnable.GetCheckpointStore().Save(ctx, cp); err != nil {
+		return fmt.Errorf("failed to save the interrupt of thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+// latestInterrupt returns the interrupt a thread waits on: the one recorded by its latest
+// checkpoint, or by the checkpoint the following state updates started from. The state to
+// resume from is the latest one.
+func latestInterrupt[S any](checkpoints []*store.Checkpoint) (*pendingInterrupt[S], error) {
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		cp := checkpoints[i]
+		if cp.Metadata["source"] == "update_state" {
+			continue
+		}
+		if cp.Metadata["event"] != "interrupt" {
+			return nil, nil
+		}
+
+		interrupt := &pendingInterrupt[S]{next: metadataNodes(cp, "next")}
+		data, err := json.Marshal(cp.Metadata["interrupts"])
+		if err == nil {
+			err = json.Unmarshal(data, &interrupt.interrupts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the interrupts of checkpoint %s: %w", cp.ID, err)
+		}
+		if interrupt.state, err = decodeState[S](checkpoints[len(checkpoints)-1].State); err != nil {
+			return nil, err
+		}
+		return interrupt, nil
+	}
+	return nil, nil
+}
+
+// latestOnly returns interrupt for the latest checkpoint of a thread, and nil for the others.
+func latestOnly[S any](interrupt *pendingInterrupt[S], latest bool) *pendingInterrupt[S] {
+	if !latest {
+		return nil
+	}
+	return interrupt
+}
+
+// threadState returns the state of a thread at cp, which may be nil for a thread without
+// checkpoints. interrupt is the interrupt the thread waits on at cp, if any.
+func threadState[S any](threadID string, cp *store.Checkpoint, interrupt *pendingInterrupt[S]) ThreadState {
+	state := ThreadState{
+		Next:     []string{},
+		Tasks:    []Task{},
+		Metadata: map[string]any{},
+	}
+	if cp != nil {
+		state.Values = cp.State
+		state.Checkpoint = CheckpointRef{ThreadID: threadID, CheckpointID: cp.ID}
+		state.Metadata = cp.Metadata
+		state.CreatedAt = cp.Timestamp
+		if cp.ParentID != "" {
+			state.ParentCheckpoint = &CheckpointRef{ThreadID: threadID, CheckpointID: cp.ParentID}
+		}
+		state.Next = metadataNodes(cp, "next_nodes")
+	}
+
+	if interrupt != nil {
+		state.Values = interrupt.state
+		state.Next = interrupt.next
+	}
+	for _, node := range state.Next {
+		task := Task{Name: node, Interrupts: []Interrupt{}}
+		if interrupt != nil {
+			task.Interrupts = interrupt.interrupts
+		}
+		state.Tasks = append(state.Tasks, task)
+	}
+	return state
+}
+
+// metadataNodes returns the node names recorded by cp under key: "next_nodes" for the nodes of
+// a failed superstep, "next" for the nodes scheduled after a checkpoint.
+func metadataNodes(cp *store.Checkpoint, key string) []string {
+	nodes := []string{}
+	switch next := cp.Metadata[key].(type) {
+	case []string:
+		nodes = append(nodes, next...)
+	case []any:
+		for _, n := range next {
+			if name, ok := n.(string); ok {
+				nodes = append(nodes, name)
+			}
+		}
+	}
+	return nodes
+}
+
+// apiError is an error with the HTTP status to respond with.
+type apiError struct {
+	status int
+	detail string
+}
+
+func (e *apiError) Error() string {
+	return e.detail
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &apiError{status: status, detail: fmt.Sprintf(format, args...)}
+}
+
+func isNotFound(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.status == http.StatusNotFound
+}
+
+// writeError responds with the status of err, 500 for errors other than apiError,
+// and a {"detail": ...} body.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var ae *apiError
+	if errors.As(err, &ae) {
+		status = ae.status
+	}
+	writeJSON(w, status, map[string]string{"detail": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// decodeBody decodes the JSON body of r into v. An empty body leaves v unchanged.
+func decodeBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return errorf(http.StatusUnprocessableEntity, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// decodeInput decodes a state sent by a client. It returns false if raw is empty or null.
+func decodeInput[S any](raw json.RawMessage) (S, bool, error) {
+	var state S
+	if len(raw) == 0 || string(raw) == "null" {
+		return state, false, nil
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return state, false, errorf(http.StatusUnprocessableEntity, "invalid state: %v", err)
+	}
+	return state, true, nil
+}
+
+// decodeState converts a state loaded from the checkpoint store to S.
+// Stores that serialize states return them as generic JSON values.
+func decodeState[S any](v any) (S, error) {
+	if state, ok := v.(S); ok {
+		return state, nil
+	}
+	var state S
+	data, err := json.Marshal(v)
+	if err != nil {
+		return state, fmt.Errorf("failed to encode checkpoint state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to decode checkpoint state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,390 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/file"
+	"github.com/smallnest/langgraphgo/store/memory"
+)
+
+type mapGraph = graph.CheckpointableStateGraph[map[string]any]
+
+func newTestServer(t *testing.T, build func(g *mapGraph)) *httptest.Server {
+	t.Helper()
+	return newTestServerWithStore(t, memory.NewMemoryCheckpointStore(), build)
+}
+
+// newTestServerWithStore returns a server keeping its threads in cpStore.
+func newTestServerWithStore(t *testing.T, cpStore store.CheckpointStore, build func(g *mapGraph)) *httptest.Server {
+	t.Helper()
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	g.SetCheckpointConfig(graph.CheckpointConfig{Store: cpStore, AutoSave: true})
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("steps", graph.AppendReducer)
+	g.SetSchema(schema)
+	build(g)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	srv := New(runnable)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = srv.Shutdown(context.Background())
+	})
+	return ts
+}
+
+// greetGraph greets the name of the state, then counts the greetings.
+func greetGraph(g *mapGraph) {
+	g.AddNode("greet", "greet", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		graph.GetStreamWriter(ctx)(map[string]any{"progress": "greeting"})
+		return map[string]any{"greeting": "hello " + state["name"].(string), "steps": []any{"greet"}}, nil
+	})
+	g.AddNode("count", "count", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"steps": []any{"count"}}, nil
+	})
+	g.SetEntryPoint("greet")
+	g.AddEdge("greet", "count")
+	g.AddEdge("count", graph.END)
+}
+
+// approvalGraph asks for an approval before finishing.
+func approvalGraph(g *mapGraph) {
+	g.AddNode("draft", "draft", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		return map[string]any{"steps": []any{"draft"}}, nil
+	})
+	g.AddNode("approve", "approve", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+		answer, err := graph.Interrupt(ctx, "approve the draft?")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"steps": []any{"approve"}, "answer": answer}, nil
+	})
+	g.SetEntryPoint("draft")
+	g.AddEdge("draft", "approve")
+	g.AddEdge("approve", graph.END)
+}
+
+func call(t *testing.T, ts *httptest.Server, method, path string, body any) (int, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, ts.URL+path, reader)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, data
+}
+
+func callJSON[T any](t *testing.T, ts *httptest.Server, method, path string, body any) T {
+	t.Helper()
+	status, data := call(t, ts, method, path, body)
+	require.Equal(t, http.StatusOK, status, string(data))
+	var v T
+	require.NoError(t, json.Unmarshal(data, &v))
+	return v
+}
+
+type sseEvent struct {
+	name string
+	data json.RawMessage
+}
+
+func parseSSE(t *testing.T, body []byte) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(string(body)), "\n\n") {
+		var event sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event.name = name
+			} else if data, ok := strings.CutPrefix(line, "data: "); ok {
+				event.data = json.RawMessage(data)
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestThreadLifecycle(t *testing.T) {
+	ts := newTestServer(t, greetGraph)
+
+	thread := callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{Metadata: map[string]any{"user": "ada"}})
+	require.NotEmpty(t, thread.ThreadID)
+	assert.Equal(t, ThreadIdle, thread.Status)
+	assert.Equal(t, "ada", thread.Metadata["user"])
+	base := "/threads/" + thread.ThreadID
+
+	status, _ := call(t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: thread.ThreadID})
+	assert.Equal(t, http.StatusConflict, status)
+
+	values := callJSON[map[string]any](t, ts, http.MethodPost, base+"/runs/wait", RunCreate{
+		AssistantID: "agent",
+		Input:       json.RawMessage(`{"name": "ada"}`),
+	})
+	assert.Equal(t, "hello ada", values["greeting"])
+	assert.Equal(t, []any{"greet", "count"}, values["steps"])
+
+	state := callJSON[ThreadState](t, ts, http.MethodGet, base+"/state", nil)
+	assert.Equal(t, "hello ada", state.Values.(map[string]any)["greeting"])
+	assert.Empty(t, state.Next)
+	assert.Equal(t, thread.ThreadID, state.Checkpoint.ThreadID)
+	require.NotNil(t, state.ParentCheckpoint)
+
+	// A second run with input starts over on top of the thread state
+	values = callJSON[map[string]any](t, ts, http.MethodPost, base+"/runs/wait", RunCreate{
+		Input: json.RawMessage(`{"name": "grace"}`),
+	})
+	assert.Equal(t, "hello grace", values["greeting"])
+	assert.Equal(t, []any{"greet", "count", "greet", "count"}, values["steps"])
+
+	history := callJSON[[]ThreadState](t, ts, http.MethodGet, base+"/history?limit=3", nil)
+	require.Len(t, history, 3)
+	assert.Equal(t, history[0].ParentCheckpoint.CheckpointID, history[1].Checkpoint.CheckpointID)
+
+	older := callJSON[ThreadState](t, ts, http.MethodGet, base+"/state/"+history[2].Checkpoint.CheckpointID, nil)
+	assert.Equal(t, history[2].Values, older.Values)
+
+	update := callJSON[map[string]CheckpointRef](t, ts, http.MethodPost, base+"/state", StateUpdate{
+		Values: json.RawMessage(`{"greeting": "bonjour"}`),
+		AsNode: "count",
+	})
+	state = callJSON[ThreadState](t, ts, http.MethodGet, base+"/state", nil)
+	assert.Equal(t, update["checkpoint"].CheckpointID, state.Checkpoint.CheckpointID)
+	assert.Equal(t, "bonjour", state.Values.(map[string]any)["greeting"])
+
+	runs := callJSON[[]Run](t, ts, http.MethodGet, base+"/runs", nil)
+	require.Len(t, runs, 2)
+	assert.Equal(t, RunSuccess, runs[0].Status)
+
+	status, _ = call(t, ts, http.MethodDelete, base, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+	status, _ = call(t, ts, http.MethodGet, base, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestRunErrors(t *testing.T) {
+	ts := newTestServer(t, greetGraph)
+
+	status, _ := call(t, ts, http.MethodPost, "/threads/missing/runs/wait", RunCreate{Input: json.RawMessage(`{"name": "ada"}`)})
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = call(t, ts, http.MethodPost, "/threads/created/runs/wait", RunCreate{AssistantID: "other", IfNotExists: "create"})
+	assert.Equal(t, http.StatusNotFound, status)
+
+	values := callJSON[map[string]any](t, ts, http.MethodPost, "/threads/created/runs/wait", RunCreate{
+		Input:       json.RawMessage(`{"name": "ada"}`),
+		IfNotExists: "create",
+	})
+	assert.Equal(t, "hello ada", values["greeting"])
+
+	status, data := call(t, ts, http.MethodPost, "/threads/created/runs/wait", RunCreate{Input: json.RawMessage(`[1, 2]`)})
+	assert.Equal(t, http.StatusUnprocessableEntity, status, string(data))
+}
+
+func TestInterruptAndResume(t *testing.T) {
+	ts := newTestServer(t, approvalGraph)
+	thread := callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "review"})
+	base := "/threads/" + thread.ThreadID
+
+	values := callJSON[map[string]any](t, ts, http.MethodPost, base+"/runs/wait", RunCreate{Input: json.RawMessage(`{}`)})
+	interrupts, ok := values["__interrupt__"].([]any)
+	require.True(t, ok, "expected interrupts in %v", values)
+	assert.Equal(t, "approve the draft?", interrupts[0].(map[string]any)["value"])
+
+	thread = callJSON[Thread](t, ts, http.MethodGet, base, nil)
+	assert.Equal(t, ThreadInterrupted, thread.Status)
+	require.Contains(t, thread.Interrupts, "approve")
+
+	state := callJSON[ThreadState](t, ts, http.MethodGet, base+"/state", nil)
+	assert.Equal(t, []string{"approve"}, state.Next)
+	require.Len(t, state.Tasks, 1)
+	assert.Equal(t, "during", state.Tasks[0].Interrupts[0].When)
+
+	values = callJSON[map[string]any](t, ts, http.MethodPost, base+"/runs/wait", RunCreate{
+		Command: &Command{Resume: json.RawMessage(`"yes"`)},
+	})
+	assert.Equal(t, "yes", values["answer"])
+	assert.Equal(t, []any{"draft", "approve"}, values["steps"])
+
+	thread = callJSON[Thread](t, ts, http.MethodGet, base, nil)
+	assert.Equal(t, ThreadIdle, thread.Status)
+	assert.Empty(t, thread.Interrupts)
+}
+
+func TestRestart(t *testing.T) {
+	fileStore, err := file.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	for name, cpStore := range map[string]store.CheckpointStore{"memory": memory.NewMemoryCheckpointStore(), "file": fileStore} {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServerWithStore(t, cpStore, approvalGraph)
+			callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "review", Metadata: map[string]any{"user": "ada"}})
+			callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "empty", Metadata: map[string]any{"user": "grace"}})
+			callJSON[map[string]any](t, ts, http.MethodPost, "/threads/review/runs/wait", RunCreate{Input: json.RawMessage(`{}`)})
+			ts.Close()
+
+			// A new server on the same store knows the threads, their metadata and interrupts
+			ts = newTestServerWithStore(t, cpStore, approvalGraph)
+			empty := callJSON[Thread](t, ts, http.MethodGet, "/threads/empty", nil)
+			assert.Equal(t, ThreadIdle, empty.Status)
+			assert.Equal(t, "grace", empty.Metadata["user"])
+
+			thread := callJSON[Thread](t, ts, http.MethodGet, "/threads/review", nil)
+			assert.Equal(t, ThreadInterrupted, thread.Status)
+			assert.Equal(t, "ada", thread.Metadata["user"])
+			require.Contains(t, thread.Interrupts, "approve")
+			assert.Equal(t, "approve the draft?", thread.Interrupts["approve"][0].Value)
+
+			state := callJSON[ThreadState](t, ts, http.MethodGet, "/threads/review/state", nil)
+			assert.Equal(t, []string{"approve"}, state.Next)
+			require.Len(t, state.Tasks, 1)
+			assert.Equal(t, "during", state.Tasks[0].Interrupts[0].When)
+
+			// Updating the state keeps the thread interrupted, and the resume continues from it
+			callJSON[map[string]CheckpointRef](t, ts, http.MethodPost, "/threads/review/state", StateUpdate{
+				Values: json.RawMessage(`{"note": "urgent"}`),
+			})
+			thread = callJSON[Thread](t, ts, http.MethodGet, "/threads/review", nil)
+			assert.Equal(t, ThreadInterrupted, thread.Status)
+
+			values := callJSON[map[string]any](t, ts, http.MethodPost, "/threads/review/runs/wait", RunCreate{
+				Command: &Command{Resume: json.RawMessage(`"yes"`)},
+			})
+			assert.Equal(t, "yes", values["answer"])
+			assert.Equal(t, "urgent", values["note"])
+			assert.Equal(t, []any{"draft", "approve"}, values["steps"])
+
+			thread = callJSON[Thread](t, ts, http.MethodGet, "/threads/review", nil)
+			assert.Equal(t, ThreadIdle, thread.Status)
+			assert.Empty(t, thread.Interrupts)
+		})
+	}
+}
+
+func TestStaticInterrupt(t *testing.T) {
+	ts := newTestServer(t, greetGraph)
+	callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "static"})
+
+	values := callJSON[map[string]any](t, ts, http.MethodPost, "/threads/static/runs/wait", RunCreate{
+		Input:          json.RawMessage(`{"name": "ada"}`),
+		InterruptAfter: []string{"greet"},
+	})
+	require.Contains(t, values, "__interrupt__")
+	assert.Equal(t, []any{"greet"}, values["steps"])
+
+	state := callJSON[ThreadState](t, ts, http.MethodGet, "/threads/static/state", nil)
+	assert.Equal(t, []string{"count"}, state.Next)
+
+	values = callJSON[map[string]any](t, ts, http.MethodPost, "/threads/static/runs/wait", RunCreate{
+		Command: &Command{Resume: json.RawMessage(`null`)},
+	})
+	assert.Equal(t, []any{"greet", "count"}, values["steps"])
+}
+
+func TestStreamRun(t *testing.T) {
+	ts := newTestServer(t, greetGraph)
+	callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "stream"})
+
+	status, body := call(t, ts, http.MethodPost, "/threads/stream/runs/stream", map[string]any{
+		"input":       map[string]any{"name": "ada"},
+		"stream_mode": []string{"values", "updates", "custom"},
+	})
+	require.Equal(t, http.StatusOK, status)
+
+	events := parseSSE(t, body)
+	require.NotEmpty(t, events)
+	assert.Equal(t, "metadata", events[0].name)
+
+	var names []string
+	for _, event := range events[1:] {
+		names = append(names, event.name)
+	}
+	assert.Equal(t, []string{"custom", "updates", "values", "updates", "values"}, names)
+
+	var update map[string]map[string]any
+	require.NoError(t, json.Unmarshal(events[2].data, &update))
+	assert.Equal(t, "hello ada", update["greet"]["greeting"])
+
+	var last map[string]any
+	require.NoError(t, json.Unmarshal(events[len(events)-1].data, &last))
+	assert.Equal(t, []any{"greet", "count"}, last["steps"])
+
+	// A single mode can be given as a string
+	status, body = call(t, ts, http.MethodPost, "/threads/stream/runs/stream", map[string]any{
+		"input":       map[string]any{"name": "grace"},
+		"stream_mode": "updates",
+	})
+	require.Equal(t, http.StatusOK, status)
+	events = parseSSE(t, body)
+	assert.Len(t, events, 3)
+}
+
+func TestStreamRunInterrupt(t *testing.T) {
+	ts := newTestServer(t, approvalGraph)
+	callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "stream"})
+
+	_, body := call(t, ts, http.MethodPost, "/threads/stream/runs/stream", map[string]any{"input": map[string]any{}})
+	events := parseSSE(t, body)
+	last := events[len(events)-1]
+	assert.Equal(t, "values", last.name)
+	assert.Contains(t, string(last.data), "__interrupt__")
+}
+
+func TestBackgroundRun(t *testing.T) {
+	release := make(chan struct{})
+	ts := newTestServer(t, func(g *mapGraph) {
+		g.AddNode("wait", "wait", func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return map[string]any{"steps": []any{"wait"}}, nil
+		})
+		g.SetEntryPoint("wait")
+		g.AddEdge("wait", graph.END)
+	})
+	callJSON[Thread](t, ts, http.MethodPost, "/threads", ThreadCreate{ThreadID: "background"})
+
+	run := callJSON[Run](t, ts, http.MethodPost, "/threads/background/runs", RunCreate{Input: json.RawMessage(`{}`)})
+	assert.Equal(t, RunRunning, run.Status)
+
+	// Only one run of a thread may be active
+	status, _ := call(t, ts, http.MethodPost, "/threads/background/runs/wait", RunCreate{Input: json.RawMessage(`{}`)})
+	assert.Equal(t, http.StatusConflict, status)
+	thread := callJSON[Thread](t, ts, http.MethodGet, "/threads/background", nil)
+	assert.Equal(t, ThreadBusy, thread.Status)
+
+	close(release)
+	values := callJSON[map[string]any](t, ts, http.MethodGet, "/threads/background/runs/"+run.RunID+"/join", nil)
+	assert.Equal(t, []any{"wait"}, values["steps"])
+
+	require.Eventually(t, func() bool {
+		run = callJSON[Run](t, ts, http.MethodGet, "/threads/background/runs/"+run.RunID, nil)
+		return run.Status == RunSuccess
+	}, time.Second, 10*time.Millisecond)
+}
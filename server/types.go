@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ThreadStatus is the status of a thread.
+type ThreadStatus string
+
+const (
+	// ThreadIdle means the thread has no active run
+	ThreadIdle ThreadStatus = "idle"
+	// ThreadBusy means a run of the thread is in progress
+	ThreadBusy ThreadStatus = "busy"
+	// ThreadInterrupted means the last run was interrupted and waits to be resumed
+	ThreadInterrupted ThreadStatus = "interrupted"
+	// ThreadError means the last run failed
+	ThreadError ThreadStatus = "error"
+)
+
+// RunStatus is the status of a run.
+type RunStatus string
+
+const (
+	// RunPending means the run has not started yet
+	RunPending RunStatus = "pending"
+	// RunRunning means the run is in progress
+	RunRunning RunStatus = "running"
+	// RunSuccess means the run completed
+	RunSuccess RunStatus = "success"
+	// RunError means the run failed
+	RunError RunStatus = "error"
+	// RunInterrupted means the run was interrupted by the graph
+	RunInterrupted RunStatus = "interrupted"
+)
+
+// Thread is a conversation whose state is persisted in the checkpoint store.
+type Thread struct {
+	ThreadID   string                 `json:"thread_id"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	Metadata   map[string]any         `json:"metadata"`
+	Status     ThreadStatus           `json:"status"`
+	Values     any                    `json:"values"`
+	Interrupts map[string][]Interrupt `json:"interrupts"`
+}
+
+// Run is an execution of the graph on a thread.
+type Run struct {
+	RunID       string         `json:"run_id"`
+	ThreadID    string         `json:"thread_id"`
+	AssistantID string         `json:"assistant_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Status      RunStatus      `json:"status"`
+	Metadata    map[string]any `json:"metadata"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// Interrupt describes why a thread is waiting to be resumed.
+type Interrupt struct {
+	// Value is the value passed to graph.Interrupt, nil for static interrupts
+	Value any `json:"value"`
+	// When is "during" for graph.Interrupt calls, "before" or "after" for the static
+	// interrupts of the run config
+	When      string `json:"when"`
+	Resumable bool   `json:"resumable"`
+}
+
+// CheckpointRef identifies a checkpoint of a thread.
+type CheckpointRef struct {
+	ThreadID     string `json:"thread_id"`
+	CheckpointNS string `json:"checkpoint_ns"`
+	CheckpointID string `json:"checkpoint_id"`
+}
+
+// Task is a node scheduled for the next step of a thread.
+type Task struct {
+	Name       string      `json:"name"`
+	Interrupts []Interrupt `json:"interrupts"`
+}
+
+// ThreadState is the state of a thread at a checkpoint.
+type ThreadState struct {
+	Values           any            `json:"values"`
+	Next             []string       `json:"next"`
+	Tasks            []Task         `json:"tasks"`
+	Checkpoint       CheckpointRef  `json:"checkpoint"`
+	Metadata         map[string]any `json:"metadata"`
+	CreatedAt        time.Time      `json:"created_at"`
+	ParentCheckpoint *CheckpointRef `json:"parent_checkpoint"`
+}
+
+// ThreadCreate is the body of POST /threads.
+type ThreadCreate struct {
+	ThreadID string         `json:"thread_id"`
+	Metadata map[string]any `json:"metadata"`
+	// IfExists is "raise" (default) to reject an existing thread ID, or "do_nothing" to return the thread
+	IfExists string `json:"if_exists"`
+}
+
+// RunCreate is the body of the run endpoints.
+type RunCreate struct {
+	AssistantID string          `json:"assistant_id"`
+	Input       json.RawMessage `json:"input"`
+	Command     *Command        `json:"command"`
+	Config      *RunConfig      `json:"config"`
+	Metadata    map[string]any  `json:"metadata"`
+	// StreamMode is a mode or a list of modes: values (default), updates, messages, custom or debug
+	StreamMode StreamModes `json:"stream_mode"`
+	// StreamSubgraphs also streams the events of subgraphs, as "<mode>|<namespace>" events
+	StreamSubgraphs bool     `json:"stream_subgraphs"`
+	InterruptBefore []string `json:"interrupt_before"`
+	InterruptAfter  []string `json:"interrupt_after"`
+	// CheckpointID runs from an older checkpoint of the thread, forking it
+	CheckpointID string `json:"checkpoint_id"`
+	// IfNotExists is "reject" (default) to fail on an unknown thread, or "create" to create it
+	IfNotExists string `json:"if_not_exists"`
+}
+
+// Command resumes an interrupted thread.
+type Command struct {
+	// Resume is returned by the graph.Interrupt call that interrupted the thread
+	Resume json.RawMessage `json:"resume"`
+}
+
+// RunConfig is the config of a run.
+type RunConfig struct {
+	Configurable   map[string]any `json:"configurable"`
+	Tags           []string       `json:"tags"`
+	RecursionLimit int            `json:"recursion_limit"`
+}
+
+// StateUpdate is the body of POST /threads/{thread_id}/state.
+type StateUpdate struct {
+	Values json.RawMessage `json:"values"`
+	// AsNode is the node the update is attributed to, the node of the latest checkpoint by default
+	AsNode       string `json:"as_node"`
+	CheckpointID string `json:"checkpoint_id"`
+}
+
+// HistoryRequest is the body of POST /threads/{thread_id}/history.
+type HistoryRequest struct {
+	Limit int `json:"limit"`
+	// Before only returns the checkpoints older than this checkpoint ID
+	Before string `json:"before"`
+}
+
+// StreamModes are the stream modes of a run. They decode from a string or a list of strings.
+type StreamModes []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *StreamModes) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var mode string
+		if err := json.Unmarshal(data, &mode); err != nil {
+			return err
+		}
+		*m = StreamModes{mode}
+		return nil
+	}
+	var modes []string
+	if err := json.Unmarshal(data, &modes); err != nil {
+		return fmt.Errorf("stream_mode must be a string or a list of strings: %w", err)
+	}
+	*m = modes
+	return nil
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// NodeCache stores cached node results keyed by a string.
+// It backs the CachePolicy of graph nodes; values are opaque encoded results.
+type NodeCache interface {
+	// Get returns the value stored under key. The boolean is false when the key is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key. A zero ttl means the entry does not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the value stored under key.
+	Delete(ctx context.Context, key string) error
+
+	// Clear removes all cached values.
+	Clear(ctx context.Context) error
+}
+
+// ValueCache can be implemented by node caches that keep results in memory. Results stored with
+// SetValue are read back with their Go types, as copies that callers may modify.
+type ValueCache interface {
+	// GetValue returns the value stored under key by SetValue. The boolean is false when the key
+	// is missing or expired.
+	GetValue(ctx context.Context, key string) (any, bool, error)
+
+	// SetValue stores value under key. A zero ttl means the entry does not expire.
+	SetValue(ctx context.Context, key string, value any, ttl time.Duration) error
+}
@@ -2,9 +2,14 @@ package store
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrSchemaOutdated is returned by SQL stores opened without migrating their schema, when the
+// schema lacks the columns or tables of the current version
+var ErrSchemaOutdated = errors.New("checkpoint schema is out of date: run MigrateSchema first")
+
 // Checkpoint represents a saved state at a specific point in execution
 type Checkpoint struct {
 	ID        string         `json:"id"`
@@ -13,6 +18,10 @@ type Checkpoint struct {
 	Metadata  map[string]any `json:"metadata"`
 	Timestamp time.Time      `json:"timestamp"`
 	Version   int            `json:"version"`
+
+	// ParentID is the ID of the checkpoint this one was derived from (empty for the first checkpoint of a thread).
+	// Resuming from an older checkpoint forks the thread: the new checkpoints point back to it.
+	ParentID string `json:"parent_id,omitempty"`
 }
 
 // CheckpointStore defines the interface for checkpoint persistence
@@ -40,3 +49,65 @@ type CheckpointStore interface {
 	// Clear removes all checkpoints for an execution
 	Clear(ctx context.Context, executionID string) error
 }
+
+// ThreadLister is an optional interface for checkpoint stores that can list their threads.
+type ThreadLister interface {
+	// ListThreadIDs returns the IDs of the threads that have checkpoints, in no particular order
+	ListThreadIDs(ctx context.Context) ([]string, error)
+}
+
+// UnthreadedCounter is an optional interface for checkpoint stores that can count their
+// checkpoints without a thread ID, which tools working thread by thread do not see.
+type UnthreadedCounter interface {
+	// CountUnthreaded returns the number of checkpoints without a thread ID
+	CountUnthreaded(ctx context.Context) (int, error)
+}
+
+// PendingWrite is the output of a single task of a superstep that did not complete.
+// When some tasks of a superstep fail, the outputs of the successful tasks are recorded
+// against the checkpoint the superstep started from, so that resuming replays them and
+// only the failed tasks run again.
+type PendingWrite struct {
+	// TaskID identifies the task within its superstep
+	TaskID string `json:"task_id"`
+
+	// Node is the name of the node that produced the write
+	Node string `json:"node"`
+
+	// Value is the output of the node
+	Value any `json:"value"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PendingWriteStore is an optional interface for checkpoint stores that can record pending writes.
+// Deleting a checkpoint also deletes its pending writes.
+type PendingWriteStore interface {
+	// PutWrites records writes made on top of a checkpoint. Writes with an existing TaskID are replaced.
+	PutWrites(ctx context.Context, checkpointID string, writes []PendingWrite) error
+
+	// GetWrites returns the writes recorded on top of a checkpoint, in the order they were recorded
+	GetWrites(ctx context.Context, checkpointID string) ([]PendingWrite, error)
+
+	// DeleteWrites removes the writes recorded on top of a checkpoint
+	DeleteWrites(ctx context.Context, checkpointID string) error
+}
+
+// MergePendingWrites appends writes to existing, replacing entries with the same TaskID in place.
+func MergePendingWrites(existing, writes []PendingWrite) []PendingWrite {
+	merged := append([]PendingWrite(nil), existing...)
+	for _, w := range writes {
+		replaced := false
+		for i := range merged {
+			if merged[i].TaskID == w.TaskID {
+				merged[i] = w
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, w)
+		}
+	}
+	return merged
+}
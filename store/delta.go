@@ -0,0 +1,560 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"time"
+)
+
+// deltaKey is the key of the state of a checkpoint stored as a delta.
+const deltaKey = "$delta"
+
+// DeltaOptions configures a DeltaStore.
+type DeltaOptions struct {
+	// SnapshotInterval is the number of checkpoints of a chain stored for each full snapshot,
+	// default 10. The state of a checkpoint is rebuilt from at most SnapshotInterval records.
+	SnapshotInterval int
+}
+
+// DeltaStore wraps a CheckpointStore to store each checkpoint as the changes to its parent
+// rather than as a full state, with a full snapshot every SnapshotInterval checkpoints.
+// Checkpoints are rebuilt when loaded or listed, so callers always see full states.
+//
+// States of long conversations mostly grow by appending to slices such as messages: only the
+// appended elements are stored, so storage grows with the number of messages instead of with
+// its square.
+//
+// Only map[string]any states are stored as deltas. Checkpoints with other states, without a
+// parent or whose parent belongs to another execution are stored as full snapshots, so that
+// clearing an execution never breaks the chains of another one.
+type DeltaStore struct {
+	inner            CheckpointStore
+	snapshotInterval int
+}
+
+// NewDeltaStore creates a DeltaStore storing checkpoints in inner.
+//
+// Example:
+//
+//	checkpoints := store.NewDeltaStore(sqliteStore, store.DeltaOptions{SnapshotInterval: 20})
+func NewDeltaStore(inner CheckpointStore, opts DeltaOptions) *DeltaStore {
+	interval := opts.SnapshotInterval
+	if interval <= 0 {
+		interval = 10
+	}
+	return &DeltaStore{inner: inner, snapshotInterval: interval}
+}
+
+// stateDelta is the change from the state of a checkpoint to the state of its child.
+type stateDelta struct {
+	base   string
+	depth  int
+	set    map[string]any
+	append map[string]any
+	delete []string
+}
+
+func (d *stateDelta) toState() map[string]any {
+	encoded := map[string]any{
+		"base":  d.base,
+		"depth": d.depth,
+	}
+	if len(d.set) > 0 {
+		encoded["set"] = d.set
+	}
+	if len(d.append) > 0 {
+		encoded["append"] = d.append
+	}
+	if len(d.delete) > 0 {
+		deleted := make([]any, len(d.delete))
+		for i, key := range d.delete {
+			deleted[i] = key
+		}
+		encoded["delete"] = deleted
+	}
+	return map[string]any{deltaKey: encoded}
+}
+
+// parseDelta returns the delta a checkpoint state holds, if it holds one. Deltas are read back
+// both as stored, and as decoded from JSON by the persistent stores.
+func parseDelta(state any) (*stateDelta, bool) {
+	m, ok := state.(map[string]any)
+	if !ok || len(m) != 1 {
+		return nil, false
+	}
+	encoded, ok := m[deltaKey].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	d := &stateDelta{}
+	if d.base, ok = encoded["base"].(string); !ok || d.base == "" {
+		return nil, false
+	}
+	switch depth := encoded["depth"].(type) {
+	case int:
+		d.depth = depth
+	case int64:
+		d.depth = int(depth)
+	case float64:
+		d.depth = int(depth)
+	}
+	d.set, _ = encoded["set"].(map[string]any)
+	d.append, _ = encoded["append"].(map[string]any)
+	switch deleted := encoded["delete"].(type) {
+	case []string:
+		d.delete = deleted
+	case []any:
+		for _, key := range deleted {
+			if s, ok := key.(string); ok {
+				d.delete = append(d.delete, s)
+			}
+		}
+	}
+	return d, true
+}
+
+// diffStates returns the delta turning old into new.
+func diffStates(old, new map[string]any) *stateDelta {
+	d := &stateDelta{set: map[string]any{}, append: map[string]any{}}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			d.delete = append(d.delete, key)
+		}
+	}
+	for key, value := range new {
+		previous, ok := old[key]
+		switch {
+		case !ok:
+			d.set[key] = value
+		case sameValue(previous, value):
+		default:
+			if tail, ok := appendedTail(previous, value); ok {
+				d.append[key] = tail
+			} else {
+				d.set[key] = value
+			}
+		}
+	}
+	return d
+}
+
+// applyDelta returns a copy of state with d applied.
+func applyDelta(state map[string]any, d *stateDelta) map[string]any {
+	result := maps.Clone(state)
+	if result == nil {
+		result = make(map[string]any)
+	}
+	for _, key := range d.delete {
+		delete(result, key)
+	}
+	maps.Copy(result, d.set)
+	for key, tail := range d.append {
+		result[key] = appendValues(result[key], tail)
+	}
+	return result
+}
+
+// sameValue reports whether a and b hold the same value. Values loaded from a persistent store
+// differ in type from the values they were saved from, e.g. float64 instead of int, so values
+// with the same JSON encoding are the same.
+func sameValue(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	return err == nil && bytes.Equal(ja, jb)
+}
+
+// appendedTail returns the elements appended to old to make new, if new extends old.
+func appendedTail(old, new any) (any, bool) {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	if ov.Kind() != reflect.Slice || nv.Kind() != reflect.Slice || nv.Len() <= ov.Len() {
+		return nil, false
+	}
+	if !sameValue(old, nv.Slice(0, ov.Len()).Interface()) {
+		return nil, false
+	}
+	// Copy the tail, so later changes to the state do not change the stored delta
+	tail := nv.Slice(ov.Len(), nv.Len())
+	return reflect.AppendSlice(reflect.MakeSlice(nv.Type(), 0, tail.Len()), tail).Interface(), true
+}
+
+// appendValues returns a new slice holding the elements of prefix followed by those of tail.
+func appendValues(prefix, tail any) any {
+	pv, tv := reflect.ValueOf(prefix), reflect.ValueOf(tail)
+	if pv.Kind() != reflect.Slice {
+		return tail
+	}
+	if tv.Kind() != reflect.Slice {
+		return prefix
+	}
+	if pv.Type() == tv.Type() {
+		result := reflect.MakeSlice(pv.Type(), 0, pv.Len()+tv.Len())
+		return reflect.AppendSlice(reflect.AppendSlice(result, pv), tv).Interface()
+	}
+	result := make([]any, 0, pv.Len()+tv.Len())
+	for i := range pv.Len() {
+		result = append(result, pv.Index(i).Interface())
+	}
+	for i := range tv.Len() {
+		result = append(result, tv.Index(i).Interface())
+	}
+	return result
+}
+
+func executionID(checkpoint *Checkpoint) string {
+	id, _ := checkpoint.Metadata["execution_id"].(string)
+	return id
+}
+
+// Save implements CheckpointStore. The checkpoint is stored as the changes to its parent,
+// unless it starts a new snapshot.
+func (s *DeltaStore) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	stored, err := s.encode(ctx, checkpoint)
+	if err != nil {
+		return err
+	}
+	return s.inner.Save(ctx, stored)
+}
+
+// encode returns checkpoint as it should be stored: as a delta if it can be, else as it is.
+func (s *DeltaStore) encode(ctx context.Context, checkpoint *Checkpoint) (*Checkpoint, error) {
+	state, ok := checkpoint.State.(map[string]any)
+	if !ok || checkpoint.ParentID == "" || executionID(checkpoint) == "" {
+		return checkpoint, nil
+	}
+
+	parent, err := s.inner.Load(ctx, checkpoint.ParentID)
+	if err != nil || executionID(parent) != executionID(checkpoint) {
+		return checkpoint, nil
+	}
+	depth := 0
+	if d, ok := parseDelta(parent.State); ok {
+		depth = d.depth
+	}
+	if depth+1 >= s.snapshotInterval {
+		return checkpoint, nil
+	}
+
+	resolved, err := s.resolve(ctx, parent, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild parent checkpoint %s: %w", parent.ID, err)
+	}
+	parentState, ok := resolved.(map[string]any)
+	if !ok {
+		return checkpoint, nil
+	}
+
+	d := diffStates(parentState, state)
+	d.base = parent.ID
+	d.depth = depth + 1
+
+	stored := *checkpoint
+	stored.State = d.toState()
+	return &stored, nil
+}
+
+// deltaLink is a checkpoint stored as a delta, in the chain leading to a snapshot.
+type deltaLink struct {
+	id    string
+	delta *stateDelta
+}
+
+// resolve returns the full state of a stored checkpoint. Checkpoints in known are used instead
+// of loading them, and rebuilt states are recorded in resolved, when it is not nil.
+func (s *DeltaStore) resolve(ctx context.Context, checkpoint *Checkpoint, known map[string]*Checkpoint, resolved map[string]any) (any, error) {
+	var chain []deltaLink
+	seen := make(map[string]bool)
+	current := checkpoint
+	for {
+		if state, ok := resolved[current.ID]; ok {
+			return applyChain(state, chain, resolved)
+		}
+		d, ok := parseDelta(current.State)
+		if !ok {
+			break
+		}
+		if seen[current.ID] {
+			return nil, fmt.Errorf("delta chain of checkpoint %s has a cycle", checkpoint.ID)
+		}
+		seen[current.ID] = true
+		chain = append(chain, deltaLink{id: current.ID, delta: d})
+
+		base, ok := known[d.base]
+		if !ok {
+			var err error
+			if base, err = s.inner.Load(ctx, d.base); err != nil {
+				return nil, fmt.Errorf("failed to load base checkpoint %s: %w", d.base, err)
+			}
+		}
+		current = base
+	}
+	return applyChain(current.State, chain, resolved)
+}
+
+// applyChain applies the deltas of chain, ordered from the newest, to state.
+func applyChain(state any, chain []deltaLink, resolved map[string]any) (any, error) {
+	for i := len(chain) - 1; i >= 0; i-- {
+		base, ok := state.(map[string]any)
+		if !ok && state != nil {
+			return nil, fmt.Errorf("base of checkpoint %s has a %T state", chain[i].id, state)
+		}
+		state = applyDelta(base, chain[i].delta)
+		if resolved != nil {
+			resolved[chain[i].id] = state
+		}
+	}
+	return state, nil
+}
+
+// decode returns a copy of a stored checkpoint with its full state.
+func (s *DeltaStore) decode(ctx context.Context, checkpoint *Checkpoint, known map[string]*Checkpoint, resolved map[string]any) (*Checkpoint, error) {
+	if _, ok := parseDelta(checkpoint.State); !ok {
+		return checkpoint, nil
+	}
+	state, err := s.resolve(ctx, checkpoint, known, resolved)
+	if err != nil {
+		return nil, err
+	}
+	decoded := *checkpoint
+	decoded.State = state
+	return &decoded, nil
+}
+
+// decodeAll returns copies of stored checkpoints with their full states. Each state is
+// rebuilt once, from the checkpoints of the list where possible.
+func (s *DeltaStore) decodeAll(ctx context.Context, checkpoints []*Checkpoint) ([]*Checkpoint, error) {
+	known := make(map[string]*Checkpoint, len(checkpoints))
+	for _, cp := range checkpoints {
+		known[cp.ID] = cp
+	}
+	resolved := make(map[string]any)
+
+	decoded := make([]*Checkpoint, len(checkpoints))
+	for i, cp := range checkpoints {
+		var err error
+		if decoded[i], err = s.decode(ctx, cp, known, resolved); err != nil {
+			return nil, err
+		}
+	}
+	return decoded, nil
+}
+
+// Load implements CheckpointStore.
+func (s *DeltaStore) Load(ctx context.Context, checkpointID string) (*Checkpoint, error) {
+	checkpoint, err := s.inner.Load(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(ctx, checkpoint, nil, nil)
+}
+
+// List implements CheckpointStore.
+func (s *DeltaStore) List(ctx context.Context, executionID string) ([]*Checkpoint, error) {
+	checkpoints, err := s.inner.List(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.decodeAll(ctx, checkpoints)
+}
+
+// ListByThread implements CheckpointStore.
+func (s *DeltaStore) ListByThread(ctx context.Context, threadID string) ([]*Checkpoint, error) {
+	checkpoints, err := s.inner.ListByThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	return s.decodeAll(ctx, checkpoints)
+}
+
+// GetLatestByThread implements CheckpointStore.
+func (s *DeltaStore) GetLatestByThread(ctx context.Context, threadID string) (*Checkpoint, error) {
+	checkpoint, err := s.inner.GetLatestByThread(ctx, threadID)
+	if err != nil || checkpoint == nil {
+		return checkpoint, err
+	}
+	return s.decode(ctx, checkpoint, nil, nil)
+}
+
+// Delete implements CheckpointStore. Checkpoints stored as changes to the deleted checkpoint
+// are first rewritten as full snapshots.
+func (s *DeltaStore) Delete(ctx context.Context, checkpointID string) error {
+	checkpoint, err := s.inner.Load(ctx, checkpointID)
+	if err == nil && executionID(checkpoint) != "" {
+		// Deltas always share the execution of their base
+		checkpoints, err := s.inner.List(ctx, executionID(checkpoint))
+		if err != nil {
+			return fmt.Errorf("failed to list checkpoints based on %s: %w", checkpointID, err)
+		}
+		var children []*Checkpoint
+		for _, cp := range checkpoints {
+			if d, ok := parseDelta(cp.State); ok && d.base == checkpointID {
+				children = append(children, cp)
+			}
+		}
+		if err := s.snapshot(ctx, children, checkpoints); err != nil {
+			return err
+		}
+	}
+	return s.inner.Delete(ctx, checkpointID)
+}
+
+// snapshot rewrites the stored checkpoints as full snapshots.
+func (s *DeltaStore) snapshot(ctx context.Context, checkpoints, known []*Checkpoint) error {
+	byID := make(map[string]*Checkpoint, len(known))
+	for _, cp := range known {
+		byID[cp.ID] = cp
+	}
+	resolved := make(map[string]any)
+
+	// Rebuild every state before rewriting any, as rewriting changes the stored chains
+	snapshots := make([]*Checkpoint, len(checkpoints))
+	for i, cp := range checkpoints {
+		var err error
+		if snapshots[i], err = s.decode(ctx, cp, byID, resolved); err != nil {
+			return err
+		}
+	}
+	for _, cp := range snapshots {
+		if err := s.inner.Save(ctx, cp); err != nil {
+			return fmt.Errorf("failed to rewrite checkpoint %s as a snapshot: %w", cp.ID, err)
+		}
+	}
+	return nil
+}
+
+// Clear implements CheckpointStore.
+func (s *DeltaStore) Clear(ctx context.Context, executionID string) error {
+	return s.inner.Clear(ctx, executionID)
+}
+
+// Compact rewrites the checkpoints of a thread stored as deltas before the given time as full
+// snapshots, and returns how many were rewritten. A zero time compacts every checkpoint.
+// Compacting old chains keeps their checkpoints readable on their own, e.g. before archiving
+// them or deleting the checkpoints they are based on outside of the store.
+func (s *DeltaStore) Compact(ctx context.Context, threadID string, before time.Time) (int, error) {
+	checkpoints, err := s.inner.ListByThread(ctx, threadID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list checkpoints for thread %s: %w", threadID, err)
+	}
+
+	var deltas []*Checkpoint
+	for _, cp := range checkpoints {
+		if _, ok := parseDelta(cp.State); ok && (before.IsZero() || cp.Timestamp.Before(before)) {
+			deltas = append(deltas, cp)
+		}
+	}
+	if err := s.snapshot(ctx, deltas, checkpoints); err != nil {
+		return 0, err
+	}
+	return len(deltas), nil
+}
+
+// PutWrites implements PendingWriteStore, when the wrapped store does.
+func (s *DeltaStore) PutWrites(ctx context.Context, checkpointID string, writes []PendingWrite) error {
+	pws, ok := s.inner.(PendingWriteStore)
+	if !ok {
+		return fmt.Errorf("%T does not support pending writes", s.inner)
+	}
+	return pws.PutWrites(ctx, checkpointID, writes)
+}
+
+// GetWrites implements PendingWriteStore, when the wrapped store does.
+func (s *DeltaStore) GetWrites(ctx context.Context, checkpointID string) ([]PendingWrite, error) {
+	pws, ok := s.inner.(PendingWriteStore)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support pending writes", s.inner)
+	}
+	return pws.GetWrites(ctx, checkpointID)
+}
+
+// DeleteWrites implements PendingWriteStore, when the wrapped store does.
+func (s *DeltaStore) DeleteWrites(ctx context.Context, checkpointID string) error {
+	pws, ok := s.inner.(PendingWriteStore)
+	if !ok {
+		return fmt.Errorf("%T does not support pending writes", s.inner)
+	}
+	return pws.DeleteWrites(ctx, checkpointID)
+}
+
+// ListThreadIDs implements ThreadLister, when the wrapped store does.
+func (s *DeltaStore) ListThreadIDs(ctx context.Context) ([]string, error) {
+	lister, ok := s.inner.(ThreadLister)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support listing threads", s.inner)
+	}
+	return lister.ListThreadIDs(ctx)
+}
+
+// CountUnthreaded implements UnthreadedCounter, when the wrapped store does.
+func (s *DeltaStore) CountUnthreaded(ctx context.Context) (int, error) {
+	counter, ok := s.inner.(UnthreadedCounter)
+	if !ok {
+		return 0, fmt.Errorf("%T does not support counting checkpoints without a thread", s.inner)
+	}
+	return counter.CountUnthreaded(ctx)
+}
+
+// threadStore returns the wrapped store as a ThreadStore.
+func (s *DeltaStore) threadStore() (ThreadStore, error) {
+	threads, ok := s.inner.(ThreadStore)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support managing threads", s.inner)
+	}
+	return threads, nil
+}
+
+// ListThreads implements ThreadStore, when the wrapped store does.
+func (s *DeltaStore) ListThreads(ctx context.Context, filter ThreadFilter, page Pagination) ([]*Thread, error) {
+	threads, err := s.threadStore()
+	if err != nil {
+		return nil, err
+	}
+	return threads.ListThreads(ctx, filter, page)
+}
+
+// GetThread implements ThreadStore, when the wrapped store does.
+func (s *DeltaStore) GetThread(ctx context.Context, threadID string) (*Thread, error) {
+	threads, err := s.threadStore()
+	if err != nil {
+		return nil, err
+	}
+	return threads.GetThread(ctx, threadID)
+}
+
+// UpdateThreadMetadata implements ThreadStore, when the wrapped store does.
+func (s *DeltaStore) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]any) (*Thread, error) {
+	threads, err := s.threadStore()
+	if err != nil {
+		return nil, err
+	}
+	return threads.UpdateThreadMetadata(ctx, threadID, metadata)
+}
+
+// DeleteThread implements ThreadStore, when the wrapped store does. Deltas never span threads,
+// so the checkpoints are deleted as stored.
+func (s *DeltaStore) DeleteThread(ctx context.Context, threadID string) error {
+	threads, err := s.threadStore()
+	if err != nil {
+		return err
+	}
+	return threads.DeleteThread(ctx, threadID)
+}
+
+// CopyThread implements ThreadStore, when the wrapped store does. The copies are rebuilt and
+// encoded again, as deltas of the copied checkpoints.
+func (s *DeltaStore) CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*Thread, error) {
+	if _, err := s.threadStore(); err != nil {
+		return nil, err
+	}
+	return CopyThread(ctx, s, sourceThreadID, targetThreadID)
+}
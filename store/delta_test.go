@@ -0,0 +1,210 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/file"
+	"github.com/smallnest/langgraphgo/store/memory"
+)
+
+// saveConversation saves a chain of n checkpoints, each adding a message to the state of its
+// parent, and returns the states saved.
+func saveConversation(t *testing.T, s store.CheckpointStore, n int) []map[string]any {
+	t.Helper()
+	var states []map[string]any
+	var messages []any
+	for i := 1; i <= n; i++ {
+		messages = append(messages, fmt.Sprintf("message %d", i))
+		state := map[string]any{
+			"messages": append([]any(nil), messages...),
+			"turn":     i,
+		}
+		if i%4 != 0 {
+			state["draft"] = "pending"
+		}
+		parentID := ""
+		if i > 1 {
+			parentID = fmt.Sprintf("cp-%d", i-1)
+		}
+		require.NoError(t, s.Save(context.Background(), &store.Checkpoint{
+			ID:        fmt.Sprintf("cp-%d", i),
+			ParentID:  parentID,
+			State:     state,
+			Version:   i,
+			Timestamp: time.Now(),
+			Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+		}))
+		states = append(states, state)
+	}
+	return states
+}
+
+// isDelta reports whether a checkpoint is stored as a delta.
+func isDelta(t *testing.T, s store.CheckpointStore, id string) bool {
+	t.Helper()
+	cp, err := s.Load(context.Background(), id)
+	require.NoError(t, err)
+	state, _ := cp.State.(map[string]any)
+	_, ok := state["$delta"]
+	return ok
+}
+
+// assertState checks a checkpoint holds the nth state saved, as seen through JSON for file stores.
+func assertState(t *testing.T, expected map[string]any, n int, cp *store.Checkpoint) {
+	t.Helper()
+	state := cp.State.(map[string]any)
+	require.Len(t, state["messages"], n, cp.ID)
+	assert.Equal(t, fmt.Sprintf("message %d", n), fmt.Sprint(state["messages"].([]any)[n-1]), cp.ID)
+	assert.EqualValues(t, expected["turn"], state["turn"], cp.ID)
+	assert.Equal(t, expected["draft"], state["draft"], cp.ID)
+}
+
+func assertStates(t *testing.T, expected []map[string]any, checkpoints []*store.Checkpoint) {
+	t.Helper()
+	require.Len(t, checkpoints, len(expected))
+	for i, cp := range checkpoints {
+		assertState(t, expected[i], i+1, cp)
+	}
+}
+
+func newDeltaInnerStores(t *testing.T) map[string]store.CheckpointStore {
+	fileStore, err := file.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	return map[string]store.CheckpointStore{
+		"memory": memory.NewMemoryCheckpointStore(),
+		"file":   fileStore,
+	}
+}
+
+func TestDeltaStore_RoundTrip(t *testing.T) {
+	for name, inner := range newDeltaInnerStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewDeltaStore(inner, store.DeltaOptions{SnapshotInterval: 4})
+			expected := saveConversation(t, s, 10)
+
+			// A snapshot every 4 checkpoints of the chain
+			for i := 1; i <= 10; i++ {
+				id := fmt.Sprintf("cp-%d", i)
+				assert.Equal(t, i%4 != 1, isDelta(t, inner, id), id)
+			}
+
+			// Deltas store only the appended messages
+			raw, err := inner.Load(ctx, "cp-3")
+			require.NoError(t, err)
+			delta := raw.State.(map[string]any)["$delta"].(map[string]any)
+			assert.Equal(t, "cp-2", delta["base"])
+			assert.Len(t, delta["append"].(map[string]any)["messages"], 1)
+
+			for i := range 10 {
+				cp, err := s.Load(ctx, fmt.Sprintf("cp-%d", i+1))
+				require.NoError(t, err)
+				assertState(t, expected[i], i+1, cp)
+			}
+
+			byThread, err := s.ListByThread(ctx, "thread-1")
+			require.NoError(t, err)
+			assertStates(t, expected, byThread)
+
+			byExecution, err := s.List(ctx, "exec-1")
+			require.NoError(t, err)
+			assert.Len(t, byExecution, 10)
+
+			latest, err := s.GetLatestByThread(ctx, "thread-1")
+			require.NoError(t, err)
+			assertState(t, expected[9], 10, latest)
+		})
+	}
+}
+
+func TestDeltaStore_Delete(t *testing.T) {
+	for name, inner := range newDeltaInnerStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewDeltaStore(inner, store.DeltaOptions{})
+			expected := saveConversation(t, s, 6)
+
+			// Deleting the oldest checkpoints, as MaxCheckpoints does, keeps the others readable
+			require.NoError(t, s.Delete(ctx, "cp-1"))
+			require.NoError(t, s.Delete(ctx, "cp-2"))
+			assert.False(t, isDelta(t, inner, "cp-3"))
+			assert.True(t, isDelta(t, inner, "cp-4"))
+
+			checkpoints, err := s.ListByThread(ctx, "thread-1")
+			require.NoError(t, err)
+			require.Len(t, checkpoints, 4)
+			for i, cp := range checkpoints {
+				assertState(t, expected[i+2], i+3, cp)
+			}
+		})
+	}
+}
+
+func TestDeltaStore_Compact(t *testing.T) {
+	ctx := context.Background()
+	inner := memory.NewMemoryCheckpointStore()
+	s := store.NewDeltaStore(inner, store.DeltaOptions{})
+	expected := saveConversation(t, s, 5)
+
+	n, err := s.Compact(ctx, "thread-1", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = s.Compact(ctx, "thread-1", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	for i := 1; i <= 5; i++ {
+		assert.False(t, isDelta(t, inner, fmt.Sprintf("cp-%d", i)))
+	}
+
+	// Compacted checkpoints are listed once and hold their full states
+	checkpoints, err := inner.ListByThread(ctx, "thread-1")
+	require.NoError(t, err)
+	assertStates(t, expected, checkpoints)
+}
+
+func TestDeltaStore_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	inner := memory.NewMemoryCheckpointStore()
+	s := store.NewDeltaStore(inner, store.DeltaOptions{})
+
+	save := func(id, parentID, executionID string, state any) {
+		require.NoError(t, s.Save(ctx, &store.Checkpoint{
+			ID:       id,
+			ParentID: parentID,
+			State:    state,
+			Metadata: map[string]any{"execution_id": executionID},
+		}))
+	}
+
+	save("cp-1", "", "exec-1", map[string]any{"count": 1})
+	save("cp-2", "cp-1", "exec-1", map[string]any{"count": 2})
+	assert.True(t, isDelta(t, inner, "cp-2"))
+
+	// A parent in another execution could be cleared with it
+	save("cp-3", "cp-2", "exec-2", map[string]any{"count": 3})
+	assert.False(t, isDelta(t, inner, "cp-3"))
+
+	// Only map states are stored as deltas
+	type counter struct{ Count int }
+	save("cp-4", "", "exec-3", counter{Count: 1})
+	save("cp-5", "cp-4", "exec-3", counter{Count: 2})
+	assert.False(t, isDelta(t, inner, "cp-5"))
+
+	cp, err := s.Load(ctx, "cp-5")
+	require.NoError(t, err)
+	assert.Equal(t, counter{Count: 2}, cp.State)
+
+	// Clearing an execution keeps the checkpoints of the other ones readable
+	require.NoError(t, s.Clear(ctx, "exec-1"))
+	cp, err = s.Load(ctx, "cp-3")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": 3}, cp.State)
+}
@@ -162,6 +162,65 @@
 //	    ShouldCheckpoint func(state any) bool
 //	}
 //
+// ## Node Result Caching
+//
+// NodeCache backs the CachePolicy of graph nodes. Implementations are available in
+// store/memory, store/file, and as adapters over existing Redis and SQLite connections:
+//
+//	rs := redis.NewRedisCheckpointStore(redis.RedisOptions{Addr: "localhost:6379"})
+//	g.SetNodeCache(rs.NodeCache())
+//
+// ## Checkpoint Lineage
+//
+// Every checkpoint records the checkpoint it was derived from in ParentID. Resuming a thread
+// from an older checkpoint forks it instead of overwriting the later checkpoints, and
+// ListHistory returns the resulting tree:
+//
+//	roots, err := store.ListHistory(ctx, s, "thread-1")
+//	for _, root := range roots {
+//	    root.Walk(func(node *store.CheckpointNode, depth int) {
+//	        fmt.Println(strings.Repeat("  ", depth), node.Checkpoint.ID, node.Checkpoint.NodeName)
+//	    })
+//	}
+//
+// ## Pending Writes
+//
+// All bundled stores implement the optional PendingWriteStore interface. When some nodes of a
+// parallel superstep fail, the graph checkpoints the state the superstep started from and records
+// the outputs of the nodes that succeeded as pending writes. Resuming the thread replays those
+// writes and runs only the failed nodes again:
+//
+//	if pws, ok := s.(store.PendingWriteStore); ok {
+//	    writes, err := pws.GetWrites(ctx, checkpointID)
+//	}
+//
+// ## Run Records
+//
+// RunStore keeps the durable records of runs executed in the background by the runqueue
+// package. store/memory provides an in-memory implementation; the SQLite, PostgreSQL and Redis
+// stores provide one next to their checkpoints:
+//
+//	runs, err := sqliteStore.RunStore(ctx)
+//	pending, err := runs.ListRuns(ctx, store.RunFilter{Statuses: []store.RunStatus{store.RunPending}})
+//
+// ## Threads
+//
+// All bundled stores, and DeltaStore over them, implement the optional ThreadStore interface to
+// manage threads as a whole: list them with their metadata and checkpoint counts, attach
+// metadata, delete them, or copy them to try another path without touching the original:
+//
+//	if threads, ok := s.(store.ThreadStore); ok {
+//	    _, err = threads.UpdateThreadMetadata(ctx, "thread-1", map[string]any{"user_id": "u-42"})
+//	    page, err := threads.ListThreads(ctx, store.ThreadFilter{
+//	        Metadata: map[string]any{"user_id": "u-42"},
+//	    }, store.Pagination{Limit: 20})
+//	    fork, err := threads.CopyThread(ctx, "thread-1", "thread-1-retry")
+//	}
+//
+// The SQLite and PostgreSQL stores keep thread metadata in a "<table>_threads" table, and filter,
+// sort and paginate ListThreads in the database. Existing databases get the table, with the
+// indexes of thread queries, from MigrateSchema.
+//
 // # Choosing the Right Store
 //
 // ## Decision Guide
@@ -186,20 +245,24 @@
 //
 // ## Migration Between Stores
 //
-// The package provides utilities to migrate between different store implementations:
+// The store/migrate package copies checkpoints between stores, keeping their IDs, versions,
+// thread IDs and timestamps, and exports and imports them as JSON Lines archives with checksums:
 //
 //	// Migrate from SQLite to PostgreSQL
-//	migrator := store.NewMigrator(sqliteStore, postgresStore)
-//	err := migrator.MigrateAll(ctx)
+//	report, err := migrate.Copy(ctx, sqliteStore, postgresStore)
 //
-//	// Or migrate specific checkpoints
-//	err := migrator.MigrateThread(ctx, "thread-id")
+//	// Or export specific threads, e.g. as test fixtures
+//	report, err := migrate.Export(ctx, sqliteStore, w, migrate.WithThreads("thread-id"))
+//
+// The langgraph-checkpoints command (cmd/langgraph-checkpoints) runs them from the command line.
 //
 // # Performance Considerations
 //
 // ## Serialization
 //
-// All stores use JSON serialization for checkpoint data. For optimal performance:
+// By default stores keep checkpoint state and pending writes as JSON. A Serializer
+// changes how they are encoded; see Checkpoint Compression and Checkpoint Encryption.
+// For optimal performance:
 //   - Keep state objects relatively small
 //   - Avoid storing large binary data in checkpoints
 //   - Consider compression for large state objects
@@ -227,7 +290,7 @@
 //  3. **Manage checkpoint lifecycle**
 //     - Clean up old checkpoints regularly
 //     - Use TTL for automatic cleanup (Redis)
-//     - Implement retention policies
+//     - Sweep old checkpoints with the retention policies of store/retention
 //
 //  4. **Secure checkpoint data**
 //     - Encrypt sensitive data before storage
@@ -277,17 +340,45 @@
 //
 // ## Checkpoint Compression
 //
-// For large state objects, consider compression:
+// For large state objects, compress them with zstd:
+//
+//	serializer, _ := store.NewZstdSerializer(store.JSONSerializer{})
+//	checkpoints, _ := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{
+//	    Path: "./checkpoints.db",
+//	}, store.WithSerializer(serializer))
+//
+// All bundled stores take the serializer as the same option:
 //
-//	compressedStore := store.NewCompressedWrapper(store, gzip.BestCompression)
-//	err := compressedStore.Put(ctx, checkpoint)
+//	checkpoints := memory.NewMemoryCheckpointStore(store.WithSerializer(serializer))
+//
+// Serialized data starts with a header naming its format, so checkpoints written
+// before a serializer was configured stay readable.
+//
+// ## Delta Checkpoints
+//
+// States that grow at every step, such as the messages of a chat agent, make full
+// snapshots grow with the square of the number of turns. DeltaStore wraps any store
+// to save each checkpoint as the changes to its parent, with a full snapshot every
+// SnapshotInterval checkpoints:
+//
+//	checkpoints := store.NewDeltaStore(sqliteStore, store.DeltaOptions{SnapshotInterval: 20})
+//
+// States are rebuilt when checkpoints are loaded or listed. Compact rewrites the
+// older checkpoints of a thread as full snapshots:
+//
+//	n, err := checkpoints.Compact(ctx, threadID, time.Now().Add(-24*time.Hour))
 //
 // ## Checkpoint Encryption
 //
-// Encrypt sensitive checkpoint data:
+// Encrypt sensitive checkpoint data with AES-GCM envelope encryption:
+//
+//	serializer, _ := store.NewAESGCMSerializer(store.GobSerializer{}, map[string][]byte{
+//	    "2024-01": oldKey,
+//	    "2024-06": newKey,
+//	}, "2024-06")
 //
-//	encryptedStore := store.NewEncryptedWrapper(store, encryptionKey)
-//	err := encryptedStore.Put(ctx, checkpoint)
+// New data is encrypted with the active key. Keep retired keys in the map for as long
+// as checkpoints encrypted with them should stay readable.
 //
 // # Extending the Package
 //
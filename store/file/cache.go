@@ -0,0 +1,130 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// FileNodeCache provides file-based node result caching.
+// Each entry is stored in its own file named after the hash of its key.
+type FileNodeCache struct {
+	path  string
+	mutex sync.RWMutex
+}
+
+// fileCacheEntry is the on-disk representation of a cache entry
+type fileCacheEntry struct {
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// NewFileNodeCache creates a new file-based node cache in the given directory
+func NewFileNodeCache(path string) (store.NodeCache, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &FileNodeCache{
+		path: path,
+	}, nil
+}
+
+func (f *FileNodeCache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.path, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements NodeCache interface for file storage
+func (f *FileNodeCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	f.mutex.RLock()
+	data, err := os.ReadFile(f.entryPath(key))
+	f.mutex.RUnlock()
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	}
+
+	// A different key with the same hash is treated as a miss
+	if entry.Key != key {
+		return nil, false, nil
+	}
+
+	if !entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt) {
+		_ = f.Delete(context.Background(), key)
+		return nil, false, nil
+	}
+
+	return entry.Value, true, nil
+}
+
+// Set implements NodeCache interface for file storage
+func (f *FileNodeCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := fileCacheEntry{Key: key, Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := os.WriteFile(f.entryPath(key), data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete implements NodeCache interface for file storage
+func (f *FileNodeCache) Delete(_ context.Context, key string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := os.Remove(f.entryPath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear implements NodeCache interface for file storage
+func (f *FileNodeCache) Clear(_ context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	files, err := os.ReadDir(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(f.path, file.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete cache entry: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNodeCache(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewFileNodeCache(t.TempDir())
+	require.NoError(t, err)
+
+	_, ok, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), 10*time.Millisecond))
+
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Delete(ctx, "a"))
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), 0))
+	require.NoError(t, cache.Clear(ctx))
+	_, ok, _ = cache.Get(ctx, "c")
+	assert.False(t, ok)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"sync"
 
@@ -14,8 +15,9 @@ import (
 
 // FileCheckpointStore provides file-based checkpoint storage
 type FileCheckpointStore struct {
-	path  string
-	mutex sync.RWMutex
+	path       string
+	serializer store.Serializer
+	mutex      sync.RWMutex
 }
 
 // threadIndex represents the in-memory index for thread_id -> checkpoint IDs
@@ -23,8 +25,9 @@ type threadIndex struct {
 	Threads map[string][]string // thread_id -> []checkpoint IDs
 }
 
-// NewFileCheckpointStore creates a new file-based checkpoint store
-func NewFileCheckpointStore(path string) (store.CheckpointStore, error) {
+// NewFileCheckpointStore creates a new file-based checkpoint store.
+// States are written as JSON, unless a serializer is set with store.WithSerializer.
+func NewFileCheckpointStore(path string, opts ...store.Option) (store.CheckpointStore, error) {
 	// Ensure directory exists
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
@@ -37,7 +40,25 @@ func NewFileCheckpointStore(path string) (store.CheckpointStore, error) {
 	}
 
 	return &FileCheckpointStore{
-		path: path,
+		path:       path,
+		serializer: store.NewOptions(opts...).Serializer,
+	}, nil
+}
+
+// OpenFileCheckpointStore opens an existing file checkpoint store without creating its
+// directories, e.g. to read it only.
+func OpenFileCheckpointStore(path string, opts ...store.Option) (store.CheckpointStore, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to open checkpoint directory: %s is not a directory", path)
+	}
+
+	return &FileCheckpointStore{
+		path:       path,
+		serializer: store.NewOptions(opts...).Serializer,
 	}, nil
 }
 
@@ -49,7 +70,12 @@ func (f *FileCheckpointStore) Save(_ context.Context, checkpoint *store.Checkpoi
 	// Create filename from ID
 	filename := filepath.Join(f.path, fmt.Sprintf("%s.json", checkpoint.ID))
 
-	data, err := json.Marshal(checkpoint)
+	encoded, err := store.EncodeCheckpoint(f.serializer, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encoded)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
@@ -90,6 +116,10 @@ func (f *FileCheckpointStore) Load(_ context.Context, checkpointID string) (*sto
 		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
 	}
 
+	if err := store.DecodeCheckpoint(f.serializer, &checkpoint); err != nil {
+		return nil, err
+	}
+
 	return &checkpoint, nil
 }
 
@@ -122,6 +152,9 @@ func (f *FileCheckpointStore) List(_ context.Context, executionID string) ([]*st
 			// Skip invalid files
 			continue
 		}
+		if err := store.DecodeCheckpoint(f.serializer, &checkpoint); err != nil {
+			return nil, err
+		}
 
 		// Filter by executionID, threadID, sessionID, or workflowID
 		execID, _ := checkpoint.Metadata["execution_id"].(string)
@@ -172,6 +205,9 @@ func (f *FileCheckpointStore) ListByThread(_ context.Context, threadID string) (
 			// Skip invalid files
 			continue
 		}
+		if err := store.DecodeCheckpoint(f.serializer, &checkpoint); err != nil {
+			return nil, err
+		}
 
 		checkpoints = append(checkpoints, &checkpoint)
 	}
@@ -233,9 +269,91 @@ func (f *FileCheckpointStore) Delete(_ context.Context, checkpointID string) err
 		}
 	}
 
+	// Remove pending writes
+	if err := os.Remove(f.getWritesPath(checkpointID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete pending writes file: %w", err)
+	}
+
+	return nil
+}
+
+// PutWrites implements PendingWriteStore interface for file storage
+func (f *FileCheckpointStore) PutWrites(_ context.Context, checkpointID string, writes []store.PendingWrite) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	existing, err := f.loadWrites(checkpointID)
+	if err != nil {
+		return err
+	}
+
+	writes, err = store.EncodeWrites(f.serializer, writes)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(store.MergePendingWrites(existing, writes))
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending writes: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(f.path, "writes"), 0755); err != nil {
+		return fmt.Errorf("failed to create pending writes directory: %w", err)
+	}
+
+	if err := os.WriteFile(f.getWritesPath(checkpointID), data, 0600); err != nil {
+		return fmt.Errorf("failed to write pending writes file: %w", err)
+	}
+
+	return nil
+}
+
+// GetWrites implements PendingWriteStore interface for file storage
+func (f *FileCheckpointStore) GetWrites(_ context.Context, checkpointID string) ([]store.PendingWrite, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	writes, err := f.loadWrites(checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DecodeWrites(f.serializer, writes); err != nil {
+		return nil, err
+	}
+	return writes, nil
+}
+
+// DeleteWrites implements PendingWriteStore interface for file storage
+func (f *FileCheckpointStore) DeleteWrites(_ context.Context, checkpointID string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := os.Remove(f.getWritesPath(checkpointID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete pending writes file: %w", err)
+	}
 	return nil
 }
 
+func (f *FileCheckpointStore) getWritesPath(checkpointID string) string {
+	return filepath.Join(f.path, "writes", fmt.Sprintf("%s.json", checkpointID))
+}
+
+func (f *FileCheckpointStore) loadWrites(checkpointID string) ([]store.PendingWrite, error) {
+	data, err := os.ReadFile(f.getWritesPath(checkpointID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read pending writes file: %w", err)
+	}
+
+	var writes []store.PendingWrite
+	if err := json.Unmarshal(data, &writes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending writes: %w", err)
+	}
+	return writes, nil
+}
+
 // Clear implements CheckpointStore interface for file storage
 func (f *FileCheckpointStore) Clear(ctx context.Context, executionID string) error {
 	checkpoints, err := f.List(ctx, executionID)
@@ -259,6 +377,75 @@ func (f *FileCheckpointStore) Clear(ctx context.Context, executionID string) err
 
 // Helper functions for thread index management
 
+// ListThreadIDs implements store.ThreadLister, from the thread indexes.
+func (f *FileCheckpointStore) ListThreadIDs(_ context.Context) ([]string, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	files, err := os.ReadDir(filepath.Join(f.path, "by_thread"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read thread index directory: %w", err)
+	}
+
+	threadIDs := []string{}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(f.path, "by_thread", file.Name()))
+		if err != nil {
+			continue
+		}
+		var index threadIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			continue
+		}
+		for threadID, ids := range index.Threads {
+			if len(ids) > 0 {
+				threadIDs = append(threadIDs, threadID)
+			}
+		}
+	}
+	return threadIDs, nil
+}
+
+// CountUnthreaded implements store.UnthreadedCounter, reading the metadata of every checkpoint.
+func (f *FileCheckpointStore) CountUnthreaded(_ context.Context) (int, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	files, err := os.ReadDir(f.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint directory: %w", err)
+	}
+
+	count := 0
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		// Skip unreadable and invalid files, as List does
+		data, err := os.ReadFile(filepath.Join(f.path, file.Name()))
+		if err != nil {
+			continue
+		}
+		var checkpoint struct {
+			ID       string         `json:"id"`
+			Metadata map[string]any `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &checkpoint); err != nil || checkpoint.ID == "" {
+			continue
+		}
+		if threadID, _ := checkpoint.Metadata["thread_id"].(string); threadID == "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (f *FileCheckpointStore) getThreadIndexPath(threadID string) string {
 	return filepath.Join(f.path, "by_thread", fmt.Sprintf("%s.json", threadID))
 }
@@ -304,7 +491,10 @@ func (f *FileCheckpointStore) addToThreadIndex(threadID, checkpointID string) er
 		index.Threads = make(map[string][]string)
 	}
 
-	// Add checkpoint ID to index
+	// Add checkpoint ID to index, once: saving a checkpoint again rewrites it
+	if slices.Contains(index.Threads[threadID], checkpointID) {
+		return nil
+	}
 	index.Threads[threadID] = append(index.Threads[threadID], checkpointID)
 
 	// Write index back to disk
@@ -388,6 +578,9 @@ func (f *FileCheckpointStore) listByThreadScan(threadID string) ([]*store.Checkp
 		if err := json.Unmarshal(data, &checkpoint); err != nil {
 			continue
 		}
+		if err := store.DecodeCheckpoint(f.serializer, &checkpoint); err != nil {
+			return nil, err
+		}
 
 		// Filter by thread_id
 		if cpThreadID, ok := checkpoint.Metadata["thread_id"].(string); ok && cpThreadID == threadID {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -55,6 +57,49 @@ func TestFileCheckpointStore_New(t *testing.T) {
 	})
 }
 
+func TestOpenFileCheckpointStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tempDir := t.TempDir()
+
+	// A missing directory is not created
+	missing := filepath.Join(tempDir, "missing")
+	if _, err := OpenFileCheckpointStore(missing); err == nil {
+		t.Fatal("Opening a missing directory should fail")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("Directory should not have been created")
+	}
+
+	path := filepath.Join(tempDir, "checkpoints")
+	created, err := NewFileCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	checkpoint := &store.Checkpoint{
+		ID:        "cp-1",
+		State:     map[string]any{"step": 1},
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+	}
+	if err := created.Save(ctx, checkpoint); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	opened, err := OpenFileCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	checkpoints, err := opened.ListByThread(ctx, "thread-1")
+	if err != nil {
+		t.Fatalf("Failed to list checkpoints: %v", err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].ID != "cp-1" {
+		t.Errorf("Expected checkpoint cp-1, got %v", checkpoints)
+	}
+}
+
 func TestFileCheckpointStore_SaveAndLoad(t *testing.T) {
 	t.Parallel()
 
@@ -582,3 +627,201 @@ func TestFileCheckpointStore_Concurrent(t *testing.T) {
 		t.Errorf("Expected %d checkpoint files, got %d", expectedTotal, jsonCount)
 	}
 }
+
+func TestFileCheckpointStore_PendingWrites(t *testing.T) {
+	t.Parallel()
+
+	fs, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	pws, ok := fs.(store.PendingWriteStore)
+	if !ok {
+		t.Fatal("File store should implement PendingWriteStore")
+	}
+
+	cp := &store.Checkpoint{ID: "cp-1", Timestamp: time.Now(), Metadata: map[string]any{"execution_id": "exec-1"}}
+	if err := fs.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	writes := []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: map[string]any{"a": "done"}},
+		{TaskID: "1:b", Node: "b", Value: map[string]any{"b": "done"}},
+	}
+	if err := pws.PutWrites(ctx, cp.ID, writes); err != nil {
+		t.Fatalf("Failed to put writes: %v", err)
+	}
+
+	loaded, err := pws.GetWrites(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get writes: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].TaskID != "0:a" || loaded[1].Value.(map[string]any)["b"] != "done" {
+		t.Errorf("Unexpected writes: %+v", loaded)
+	}
+
+	if err := pws.DeleteWrites(ctx, cp.ID); err != nil {
+		t.Fatalf("Failed to delete writes: %v", err)
+	}
+	loaded, err = pws.GetWrites(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get writes: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("Expected no writes after delete, got %d", len(loaded))
+	}
+}
+
+func TestFileCheckpointStore_ParentID(t *testing.T) {
+	t.Parallel()
+
+	fs, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	cp := &store.Checkpoint{ID: "cp-2", ParentID: "cp-1", Version: 2, Timestamp: time.Now(), Metadata: map[string]any{"thread_id": "thread-1"}}
+	if err := fs.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	latest, err := fs.GetLatestByThread(ctx, "thread-1")
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if latest.ParentID != "cp-1" {
+		t.Errorf("Expected parent cp-1, got %q", latest.ParentID)
+	}
+}
+
+func TestFileCheckpointStore_ListThreadIDs(t *testing.T) {
+	t.Parallel()
+
+	fs, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	threads := fs.(store.ThreadLister)
+	ctx := context.Background()
+
+	threadIDs, err := threads.ListThreadIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list threads: %v", err)
+	}
+	if threadIDs == nil || len(threadIDs) != 0 {
+		t.Errorf("Expected an empty list for an empty store, got %v", threadIDs)
+	}
+
+	checkpoints := []*store.Checkpoint{
+		{ID: "cp-1", Version: 1, Timestamp: time.Now(), Metadata: map[string]any{"thread_id": "thread-b"}},
+		{ID: "cp-2", Version: 2, Timestamp: time.Now(), Metadata: map[string]any{"thread_id": "thread-b"}},
+		{ID: "cp-3", Version: 1, Timestamp: time.Now(), Metadata: map[string]any{"thread_id": "thread-a"}},
+		{ID: "cp-4", Version: 1, Timestamp: time.Now(), Metadata: map[string]any{"execution_id": "exec-1"}},
+		{ID: "cp-5", Version: 1, Timestamp: time.Now(), Metadata: map[string]any{"thread_id": ""}},
+	}
+	for _, cp := range checkpoints {
+		if err := fs.Save(ctx, cp); err != nil {
+			t.Fatalf("Failed to save checkpoint: %v", err)
+		}
+	}
+
+	threadIDs, err = threads.ListThreadIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list threads: %v", err)
+	}
+	sort.Strings(threadIDs)
+	if fmt.Sprint(threadIDs) != "[thread-a thread-b]" {
+		t.Errorf("Expected [thread-a thread-b], got %v", threadIDs)
+	}
+
+	// Threads whose checkpoints were all deleted are not listed
+	if err := fs.Delete(ctx, "cp-3"); err != nil {
+		t.Fatalf("Failed to delete checkpoint: %v", err)
+	}
+	threadIDs, err = threads.ListThreadIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list threads: %v", err)
+	}
+	if fmt.Sprint(threadIDs) != "[thread-b]" {
+		t.Errorf("Expected [thread-b], got %v", threadIDs)
+	}
+}
+
+func TestFileCheckpointStore_Serializer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Checkpoints written without a serializer stay readable
+	plain, err := NewFileCheckpointStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := plain.Save(ctx, &store.Checkpoint{ID: "legacy", State: map[string]any{"count": float64(1)}}); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	serializer, err := store.NewAESGCMSerializer(store.JSONSerializer{}, map[string][]byte{"k1": make([]byte, 32)}, "k1")
+	if err != nil {
+		t.Fatalf("Failed to create serializer: %v", err)
+	}
+	fs, err := NewFileCheckpointStore(dir, store.WithSerializer(serializer))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	cp := &store.Checkpoint{ID: "cp-1", State: map[string]any{"secret": "swordfish"}, Metadata: map[string]any{"thread_id": "thread-1"}}
+	if err := fs.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+	if err := fs.(store.PendingWriteStore).PutWrites(ctx, cp.ID, []store.PendingWrite{{TaskID: "0:a", Node: "a", Value: "swordfish"}}); err != nil {
+		t.Fatalf("Failed to put writes: %v", err)
+	}
+
+	for _, name := range []string{"cp-1.json", filepath.Join("writes", "cp-1.json")} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if strings.Contains(string(data), "swordfish") {
+			t.Errorf("%s should not contain plaintext: %s", name, data)
+		}
+	}
+
+	loaded, err := fs.Load(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if loaded.State.(map[string]any)["secret"] != "swordfish" {
+		t.Errorf("Unexpected state: %v", loaded.State)
+	}
+
+	latest, err := fs.GetLatestByThread(ctx, "thread-1")
+	if err != nil {
+		t.Fatalf("Failed to get latest checkpoint: %v", err)
+	}
+	if latest.State.(map[string]any)["secret"] != "swordfish" {
+		t.Errorf("Unexpected state: %v", latest.State)
+	}
+
+	legacy, err := fs.Load(ctx, "legacy")
+	if err != nil {
+		t.Fatalf("Failed to load legacy checkpoint: %v", err)
+	}
+	if legacy.State.(map[string]any)["count"] != float64(1) {
+		t.Errorf("Unexpected legacy state: %v", legacy.State)
+	}
+
+	writes, err := fs.(store.PendingWriteStore).GetWrites(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get writes: %v", err)
+	}
+	if len(writes) != 1 || writes[0].Value != "swordfish" {
+		t.Errorf("Unexpected writes: %+v", writes)
+	}
+}
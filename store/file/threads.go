@@ -0,0 +1,178 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func (f *FileCheckpointStore) getThreadPath(threadID string) string {
+	return filepath.Join(f.path, "threads", fmt.Sprintf("%s.json", threadID))
+}
+
+func (f *FileCheckpointStore) loadThreadRecord(threadID string) (*store.ThreadRecord, error) {
+	data, err := os.ReadFile(f.getThreadPath(threadID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read thread file: %w", err)
+	}
+
+	var record store.ThreadRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal thread: %w", err)
+	}
+	return &record, nil
+}
+
+// thread summarizes a thread from its record and the timestamps of its checkpoints, without
+// decoding their states; the caller holds the lock
+func (f *FileCheckpointStore) thread(threadID string) (*store.Thread, error) {
+	record, err := f.loadThreadRecord(threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := f.loadThreadIndex(threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load thread index: %w", err)
+	}
+
+	var checkpoints []*store.Checkpoint
+	for _, id := range ids {
+		data, err := os.ReadFile(filepath.Join(f.path, fmt.Sprintf("%s.json", id)))
+		if err != nil {
+			// Skip unreadable files
+			continue
+		}
+
+		var checkpoint struct {
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if err := json.Unmarshal(data, &checkpoint); err != nil {
+			// Skip invalid files
+			continue
+		}
+		checkpoints = append(checkpoints, &store.Checkpoint{ID: id, Timestamp: checkpoint.Timestamp})
+	}
+
+	return store.NewThreadFromCheckpoints(threadID, record, checkpoints), nil
+}
+
+// ListThreads implements store.ThreadStore
+func (f *FileCheckpointStore) ListThreads(ctx context.Context, filter store.ThreadFilter, page store.Pagination) ([]*store.Thread, error) {
+	threadIDs, err := f.ListThreadIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	// Threads with metadata but no checkpoints only have a thread file
+	files, err := os.ReadDir(filepath.Join(f.path, "threads"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read threads directory: %w", err)
+	}
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
+			threadIDs = append(threadIDs, strings.TrimSuffix(file.Name(), ".json"))
+		}
+	}
+
+	seen := make(map[string]bool, len(threadIDs))
+	threads := make([]*store.Thread, 0, len(threadIDs))
+	for _, threadID := range threadIDs {
+		if seen[threadID] {
+			continue
+		}
+		seen[threadID] = true
+
+		thread, err := f.thread(threadID)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, thread)
+	}
+	return store.SelectThreads(threads, filter, page), nil
+}
+
+// GetThread implements store.ThreadStore
+func (f *FileCheckpointStore) GetThread(_ context.Context, threadID string) (*store.Thread, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	thread, err := f.thread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrThreadNotFound, threadID)
+	}
+	return thread, nil
+}
+
+// UpdateThreadMetadata implements store.ThreadStore
+func (f *FileCheckpointStore) UpdateThreadMetadata(_ context.Context, threadID string, metadata map[string]any) (*store.Thread, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	record, err := f.loadThreadRecord(threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(store.MergeThreadMetadata(record, metadata, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal thread: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(f.path, "threads"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create threads directory: %w", err)
+	}
+
+	if err := os.WriteFile(f.getThreadPath(threadID), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write thread file: %w", err)
+	}
+
+	return f.thread(threadID)
+}
+
+// DeleteThread implements store.ThreadStore
+func (f *FileCheckpointStore) DeleteThread(ctx context.Context, threadID string) error {
+	f.mutex.RLock()
+	ids, err := f.loadThreadIndex(threadID)
+	f.mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to load thread index: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := f.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := os.Remove(f.getThreadIndexPath(threadID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete thread index: %w", err)
+	}
+	if err := os.Remove(f.getThreadPath(threadID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete thread file: %w", err)
+	}
+	return nil
+}
+
+// CopyThread implements store.ThreadStore
+func (f *FileCheckpointStore) CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*store.Thread, error) {
+	return store.CopyThread(ctx, f, sourceThreadID, targetThreadID)
+}
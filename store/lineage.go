@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// CheckpointNode is a checkpoint in the history tree of a thread.
+type CheckpointNode struct {
+	Checkpoint *Checkpoint
+
+	// Children are the checkpoints derived from this one, oldest first.
+	// More than one child means the thread was forked at this checkpoint.
+	Children []*CheckpointNode
+}
+
+// IsFork reports whether the thread was forked at this checkpoint.
+func (n *CheckpointNode) IsFork() bool {
+	return len(n.Children) > 1
+}
+
+// Walk calls fn for the node and its descendants in depth-first order, passing the depth of each node.
+func (n *CheckpointNode) Walk(fn func(node *CheckpointNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *CheckpointNode) walk(fn func(node *CheckpointNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// BuildHistory arranges checkpoints into trees following their ParentID.
+// Checkpoints without a parent, or whose parent is not in the list, become roots.
+// Roots and children are ordered by version, then timestamp.
+func BuildHistory(checkpoints []*Checkpoint) []*CheckpointNode {
+	nodes := make(map[string]*CheckpointNode, len(checkpoints))
+	for _, cp := range checkpoints {
+		nodes[cp.ID] = &CheckpointNode{Checkpoint: cp}
+	}
+
+	var roots []*CheckpointNode
+	for _, cp := range checkpoints {
+		node := nodes[cp.ID]
+		if parent, ok := nodes[cp.ParentID]; ok && cp.ParentID != cp.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortCheckpointNodes(roots)
+	for _, node := range nodes {
+		sortCheckpointNodes(node.Children)
+	}
+	return roots
+}
+
+// ListHistory returns the checkpoint tree of a thread.
+func ListHistory(ctx context.Context, s CheckpointStore, threadID string) ([]*CheckpointNode, error) {
+	checkpoints, err := s.ListByThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints for thread %s: %w", threadID, err)
+	}
+	return BuildHistory(checkpoints), nil
+}
+
+// Ancestors returns the checkpoint and its ancestors, starting with the checkpoint itself.
+func Ancestors(ctx context.Context, s CheckpointStore, checkpointID string) ([]*Checkpoint, error) {
+	var lineage []*Checkpoint
+	seen := make(map[string]bool)
+	for id := checkpointID; id != "" && !seen[id]; {
+		seen[id] = true
+		cp, err := s.Load(ctx, id)
+		if err != nil {
+			if len(lineage) > 0 {
+				// The rest of the lineage was deleted
+				break
+			}
+			return nil, err
+		}
+		lineage = append(lineage, cp)
+		id = cp.ParentID
+	}
+	return lineage, nil
+}
+
+func sortCheckpointNodes(nodes []*CheckpointNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i].Checkpoint, nodes[j].Checkpoint
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Timestamp.Before(b.Timestamp)
+	})
+}
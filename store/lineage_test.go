@@ -0,0 +1,87 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/memory"
+)
+
+func saveLineage(t *testing.T, s store.CheckpointStore, id, parentID string, version int) {
+	t.Helper()
+	require.NoError(t, s.Save(context.Background(), &store.Checkpoint{
+		ID:        id,
+		ParentID:  parentID,
+		Version:   version,
+		Timestamp: time.Now(),
+		Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+	}))
+}
+
+func TestListHistory(t *testing.T) {
+	s := memory.NewMemoryCheckpointStore()
+	ctx := context.Background()
+
+	// cp-1 -> cp-2 -> cp-3
+	//            \-> cp-4 (fork)
+	saveLineage(t, s, "cp-1", "", 1)
+	saveLineage(t, s, "cp-2", "cp-1", 2)
+	saveLineage(t, s, "cp-3", "cp-2", 3)
+	saveLineage(t, s, "cp-4", "cp-2", 4)
+
+	roots, err := store.ListHistory(ctx, s, "thread-1")
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	assert.Equal(t, "cp-1", roots[0].Checkpoint.ID)
+
+	fork := roots[0].Children[0]
+	assert.Equal(t, "cp-2", fork.Checkpoint.ID)
+	assert.True(t, fork.IsFork())
+	require.Len(t, fork.Children, 2)
+	assert.Equal(t, "cp-3", fork.Children[0].Checkpoint.ID)
+	assert.Equal(t, "cp-4", fork.Children[1].Checkpoint.ID)
+
+	var visited []string
+	var depths []int
+	roots[0].Walk(func(node *store.CheckpointNode, depth int) {
+		visited = append(visited, node.Checkpoint.ID)
+		depths = append(depths, depth)
+	})
+	assert.Equal(t, []string{"cp-1", "cp-2", "cp-3", "cp-4"}, visited)
+	assert.Equal(t, []int{0, 1, 2, 2}, depths)
+}
+
+func TestBuildHistoryOrphans(t *testing.T) {
+	roots := store.BuildHistory([]*store.Checkpoint{
+		{ID: "b", ParentID: "deleted", Version: 2},
+		{ID: "a", Version: 1},
+	})
+	require.Len(t, roots, 2)
+	assert.Equal(t, "a", roots[0].Checkpoint.ID)
+	assert.Equal(t, "b", roots[1].Checkpoint.ID)
+}
+
+func TestAncestors(t *testing.T) {
+	s := memory.NewMemoryCheckpointStore()
+	ctx := context.Background()
+
+	saveLineage(t, s, "cp-1", "", 1)
+	saveLineage(t, s, "cp-2", "cp-1", 2)
+	saveLineage(t, s, "cp-3", "cp-2", 3)
+
+	lineage, err := store.Ancestors(ctx, s, "cp-3")
+	require.NoError(t, err)
+	ids := make([]string, 0, len(lineage))
+	for _, cp := range lineage {
+		ids = append(ids, cp.ID)
+	}
+	assert.Equal(t, []string{"cp-3", "cp-2", "cp-1"}, ids)
+
+	_, err = store.Ancestors(ctx, s, "missing")
+	assert.Error(t, err)
+}
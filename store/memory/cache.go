@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// MemoryNodeCache provides in-memory node result caching
+type MemoryNodeCache struct {
+	entries map[string]memoryCacheEntry
+	mutex   sync.RWMutex
+}
+
+type memoryCacheEntry struct {
+	value     []byte
+	data      *cachedValue
+	expiresAt time.Time
+}
+
+// cachedValue is a value stored with SetValue, encoded so that it is copied on the way in and
+// out of the cache.
+type cachedValue struct {
+	typ  reflect.Type
+	data []byte
+	json bool
+}
+
+// encodeValue encodes value with encoding/gob, or as JSON when gob cannot encode it, e.g. for
+// maps holding values of types not registered with gob.Register.
+func encodeValue(value any) (*cachedValue, error) {
+	if value == nil {
+		return &cachedValue{}, nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err == nil {
+		return &cachedValue{typ: reflect.TypeOf(value), data: buf.Bytes()}, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedValue{typ: reflect.TypeOf(value), data: data, json: true}, nil
+}
+
+// decode returns a new copy of the value.
+func (v *cachedValue) decode() (any, error) {
+	if v.typ == nil {
+		return nil, nil
+	}
+	ptr := reflect.New(v.typ)
+	var err error
+	if v.json {
+		err = json.Unmarshal(v.data, ptr.Interface())
+	} else {
+		err = gob.NewDecoder(bytes.NewReader(v.data)).Decode(ptr.Interface())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// NewMemoryNodeCache creates a new in-memory node cache
+func NewMemoryNodeCache() store.NodeCache {
+	return &MemoryNodeCache{
+		entries: make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get implements NodeCache interface for memory storage
+func (m *MemoryNodeCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	entry, ok := m.entry(key)
+	if !ok || entry.value == nil {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set implements NodeCache interface for memory storage
+func (m *MemoryNodeCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.set(key, memoryCacheEntry{value: value}, ttl)
+	return nil
+}
+
+// GetValue implements ValueCache interface for memory storage
+func (m *MemoryNodeCache) GetValue(_ context.Context, key string) (any, bool, error) {
+	entry, ok := m.entry(key)
+	if !ok || entry.data == nil {
+		return nil, false, nil
+	}
+	value, err := entry.data.decode()
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// SetValue implements ValueCache interface for memory storage.
+// The value is stored encoded and GetValue decodes a new copy each time, so callers may modify
+// the values they pass and get.
+func (m *MemoryNodeCache) SetValue(_ context.Context, key string, value any, ttl time.Duration) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	m.set(key, memoryCacheEntry{data: data}, ttl)
+	return nil
+}
+
+// entry returns the unexpired entry stored under key.
+func (m *MemoryNodeCache) entry(key string) (memoryCacheEntry, bool) {
+	m.mutex.RLock()
+	entry, ok := m.entries[key]
+	m.mutex.RUnlock()
+
+	if !ok {
+		return memoryCacheEntry{}, false
+	}
+
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		m.mutex.Lock()
+		delete(m.entries, key)
+		m.mutex.Unlock()
+		return memoryCacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// set stores entry under key, expiring after ttl.
+func (m *MemoryNodeCache) set(key string, entry memoryCacheEntry, ttl time.Duration) {
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.entries[key] = entry
+}
+
+// Delete implements NodeCache interface for memory storage
+func (m *MemoryNodeCache) Delete(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+// Clear implements NodeCache interface for memory storage
+func (m *MemoryNodeCache) Clear(_ context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.entries = make(map[string]memoryCacheEntry)
+	return nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNodeCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryNodeCache()
+
+	_, ok, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), 10*time.Millisecond))
+
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Delete(ctx, "a"))
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), 0))
+	require.NoError(t, cache.Clear(ctx))
+	_, ok, _ = cache.Get(ctx, "c")
+	assert.False(t, ok)
+}
+
+func TestMemoryNodeCache_Values(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryNodeCache().(*MemoryNodeCache)
+
+	state := map[string]any{"count": 1}
+	require.NoError(t, cache.SetValue(ctx, "a", state, 0))
+	require.NoError(t, cache.SetValue(ctx, "b", state, 10*time.Millisecond))
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), 0))
+
+	value, ok, err := cache.GetValue(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, state, value)
+
+	// Values and encoded results are not read back as each other
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok)
+	_, ok, _ = cache.GetValue(ctx, "c")
+	assert.False(t, ok)
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok, _ = cache.GetValue(ctx, "b")
+	assert.False(t, ok)
+}
+
+func TestMemoryNodeCache_ValuesCopied(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryNodeCache().(*MemoryNodeCache)
+
+	state := map[string]any{"count": 1, "tags": []string{"a"}}
+	require.NoError(t, cache.SetValue(ctx, "a", state, 0))
+
+	// Modifying the stored value or a value read back leaves the cached value as it was
+	state["count"] = 2
+	state["tags"].([]string)[0] = "b"
+	value, ok, err := cache.GetValue(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"count": 1, "tags": []string{"a"}}, value)
+
+	value.(map[string]any)["tags"].([]string)[0] = "c"
+	value, _, err = cache.GetValue(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": 1, "tags": []string{"a"}}, value)
+
+	type result struct {
+		Tags []string
+	}
+	res := result{Tags: []string{"a"}}
+	require.NoError(t, cache.SetValue(ctx, "b", res, 0))
+	res.Tags[0] = "b"
+	value, _, err = cache.GetValue(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, result{Tags: []string{"a"}}, value)
+}
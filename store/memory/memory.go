@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 
@@ -11,23 +12,59 @@ import (
 
 // MemoryCheckpointStore provides in-memory checkpoint storage
 type MemoryCheckpointStore struct {
-	checkpoints    map[string]*store.Checkpoint // id -> checkpoint
-	threadIndex    map[string][]string          // thread_id -> []checkpoint IDs
-	executionIndex map[string][]string          // execution_id -> []checkpoint IDs
+	checkpoints    map[string]*store.Checkpoint    // id -> checkpoint
+	threadIndex    map[string][]string             // thread_id -> []checkpoint IDs
+	executionIndex map[string][]string             // execution_id -> []checkpoint IDs
+	writes         map[string][]store.PendingWrite // checkpoint_id -> pending writes
+	threads        map[string]*store.ThreadRecord  // thread_id -> thread metadata
+	serializer     store.Serializer
 	mutex          sync.RWMutex
 }
 
-// NewMemoryCheckpointStore creates a new in-memory checkpoint store
-func NewMemoryCheckpointStore() store.CheckpointStore {
+// NewMemoryCheckpointStore creates a new in-memory checkpoint store.
+// States are kept as Go values, unless a serializer is set with store.WithSerializer.
+func NewMemoryCheckpointStore(opts ...store.Option) store.CheckpointStore {
 	return &MemoryCheckpointStore{
 		checkpoints:    make(map[string]*store.Checkpoint),
 		threadIndex:    make(map[string][]string),
 		executionIndex: make(map[string][]string),
+		writes:         make(map[string][]store.PendingWrite),
+		threads:        make(map[string]*store.ThreadRecord),
+		serializer:     store.NewOptions(opts...).Serializer,
 	}
 }
 
+// decode returns a checkpoint with its state deserialized
+func (m *MemoryCheckpointStore) decode(checkpoint *store.Checkpoint) (*store.Checkpoint, error) {
+	if m.serializer == nil {
+		return checkpoint, nil
+	}
+	decoded := *checkpoint
+	if err := store.DecodeCheckpoint(m.serializer, &decoded); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+// decodeAll deserializes the states of checkpoints
+func (m *MemoryCheckpointStore) decodeAll(checkpoints []*store.Checkpoint) ([]*store.Checkpoint, error) {
+	for i, cp := range checkpoints {
+		decoded, err := m.decode(cp)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints[i] = decoded
+	}
+	return checkpoints, nil
+}
+
 // Save implements CheckpointStore interface
 func (m *MemoryCheckpointStore) Save(_ context.Context, checkpoint *store.Checkpoint) error {
+	checkpoint, err := store.EncodeCheckpoint(m.serializer, checkpoint)
+	if err != nil {
+		return err
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -35,12 +72,12 @@ func (m *MemoryCheckpointStore) Save(_ context.Context, checkpoint *store.Checkp
 	m.checkpoints[checkpoint.ID] = checkpoint
 
 	// Update execution_id index
-	if execID, ok := checkpoint.Metadata["execution_id"].(string); ok && execID != "" {
+	if execID, ok := checkpoint.Metadata["execution_id"].(string); ok && execID != "" && !slices.Contains(m.executionIndex[execID], checkpoint.ID) {
 		m.executionIndex[execID] = append(m.executionIndex[execID], checkpoint.ID)
 	}
 
 	// Update thread_id index
-	if threadID, ok := checkpoint.Metadata["thread_id"].(string); ok && threadID != "" {
+	if threadID, ok := checkpoint.Metadata["thread_id"].(string); ok && threadID != "" && !slices.Contains(m.threadIndex[threadID], checkpoint.ID) {
 		m.threadIndex[threadID] = append(m.threadIndex[threadID], checkpoint.ID)
 	}
 
@@ -57,7 +94,7 @@ func (m *MemoryCheckpointStore) Load(_ context.Context, checkpointID string) (*s
 		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
 	}
 
-	return checkpoint, nil
+	return m.decode(checkpoint)
 }
 
 // List implements CheckpointStore interface
@@ -83,7 +120,7 @@ func (m *MemoryCheckpointStore) List(_ context.Context, executionID string) ([]*
 		return checkpoints[i].Version < checkpoints[j].Version
 	})
 
-	return checkpoints, nil
+	return m.decodeAll(checkpoints)
 }
 
 // ListByThread returns all checkpoints for a specific thread_id
@@ -108,7 +145,7 @@ func (m *MemoryCheckpointStore) ListByThread(_ context.Context, threadID string)
 		return checkpoints[i].Version < checkpoints[j].Version
 	})
 
-	return checkpoints, nil
+	return m.decodeAll(checkpoints)
 }
 
 // GetLatestByThread returns the latest checkpoint for a thread_id
@@ -129,7 +166,35 @@ func (m *MemoryCheckpointStore) GetLatestByThread(_ context.Context, threadID st
 		}
 	}
 
-	return latest, nil
+	return m.decode(latest)
+}
+
+// ListThreadIDs implements store.ThreadLister
+func (m *MemoryCheckpointStore) ListThreadIDs(_ context.Context) ([]string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	threadIDs := make([]string, 0, len(m.threadIndex))
+	for threadID, ids := range m.threadIndex {
+		if len(ids) > 0 {
+			threadIDs = append(threadIDs, threadID)
+		}
+	}
+	return threadIDs, nil
+}
+
+// CountUnthreaded implements store.UnthreadedCounter
+func (m *MemoryCheckpointStore) CountUnthreaded(_ context.Context) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	count := 0
+	for _, checkpoint := range m.checkpoints {
+		if threadID, _ := checkpoint.Metadata["thread_id"].(string); threadID == "" {
+			count++
+		}
+	}
+	return count, nil
 }
 
 // Delete implements CheckpointStore interface
@@ -166,6 +231,7 @@ func (m *MemoryCheckpointStore) Delete(_ context.Context, checkpointID string) e
 	}
 
 	delete(m.checkpoints, checkpointID)
+	delete(m.writes, checkpointID)
 	return nil
 }
 
@@ -217,7 +283,43 @@ func (m *MemoryCheckpointStore) Clear(_ context.Context, executionID string) err
 		}
 
 		delete(m.checkpoints, id)
+		delete(m.writes, id)
+	}
+
+	return nil
+}
+
+// PutWrites implements PendingWriteStore interface
+func (m *MemoryCheckpointStore) PutWrites(_ context.Context, checkpointID string, writes []store.PendingWrite) error {
+	writes, err := store.EncodeWrites(m.serializer, writes)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.writes[checkpointID] = store.MergePendingWrites(m.writes[checkpointID], writes)
+	return nil
+}
+
+// GetWrites implements PendingWriteStore interface
+func (m *MemoryCheckpointStore) GetWrites(_ context.Context, checkpointID string) ([]store.PendingWrite, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	writes := append([]store.PendingWrite(nil), m.writes[checkpointID]...)
+	if err := store.DecodeWrites(m.serializer, writes); err != nil {
+		return nil, err
 	}
+	return writes, nil
+}
+
+// DeleteWrites implements PendingWriteStore interface
+func (m *MemoryCheckpointStore) DeleteWrites(_ context.Context, checkpointID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
+	delete(m.writes, checkpointID)
 	return nil
 }
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -495,3 +496,170 @@ func TestMemoryCheckpointStore_ThreadSafety(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryCheckpointStore_PendingWrites(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMemoryCheckpointStore()
+	ctx := context.Background()
+
+	pws, ok := ms.(store.PendingWriteStore)
+	if !ok {
+		t.Fatal("Memory store should implement PendingWriteStore")
+	}
+
+	cp := &store.Checkpoint{ID: "cp-1", Metadata: map[string]any{"execution_id": "exec-1"}}
+	if err := ms.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	writes := []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: "first"},
+		{TaskID: "1:b", Node: "b", Value: "second"},
+	}
+	if err := pws.PutWrites(ctx, cp.ID, writes); err != nil {
+		t.Fatalf("Failed to put writes: %v", err)
+	}
+	if err := pws.PutWrites(ctx, cp.ID, []store.PendingWrite{{TaskID: "0:a", Node: "a", Value: "replaced"}}); err != nil {
+		t.Fatalf("Failed to put writes: %v", err)
+	}
+
+	loaded, err := pws.GetWrites(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get writes: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Value != "replaced" || loaded[1].Node != "b" {
+		t.Errorf("Unexpected writes: %+v", loaded)
+	}
+
+	// Deleting the checkpoint removes its writes
+	if err := ms.Delete(ctx, cp.ID); err != nil {
+		t.Fatalf("Failed to delete checkpoint: %v", err)
+	}
+	loaded, err = pws.GetWrites(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get writes: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("Expected no writes after delete, got %d", len(loaded))
+	}
+}
+
+func TestMemoryCheckpointStore_ParentID(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMemoryCheckpointStore()
+	ctx := context.Background()
+
+	cp := &store.Checkpoint{ID: "cp-2", ParentID: "cp-1", Version: 2, Metadata: map[string]any{"thread_id": "thread-1"}}
+	if err := ms.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	loaded, err := ms.Load(ctx, "cp-2")
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if loaded.ParentID != "cp-1" {
+		t.Errorf("Expected parent cp-1, got %q", loaded.ParentID)
+	}
+}
+
+func TestMemoryCheckpointStore_ListThreadIDs(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMemoryCheckpointStore()
+	threads := ms.(store.ThreadLister)
+	ctx := context.Background()
+
+	threadIDs, err := threads.ListThreadIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list threads: %v", err)
+	}
+	if threadIDs == nil || len(threadIDs) != 0 {
+		t.Errorf("Expected an empty list for an empty store, got %v", threadIDs)
+	}
+
+	checkpoints := []*store.Checkpoint{
+		{ID: "cp-1", Version: 1, Metadata: map[string]any{"thread_id": "thread-b"}},
+		{ID: "cp-2", Version: 2, Metadata: map[string]any{"thread_id": "thread-b"}},
+		{ID: "cp-3", Version: 1, Metadata: map[string]any{"thread_id": "thread-a"}},
+		{ID: "cp-4", Version: 1, Metadata: map[string]any{"execution_id": "exec-1"}},
+		{ID: "cp-5", Version: 1, Metadata: map[string]any{"thread_id": ""}},
+	}
+	for _, cp := range checkpoints {
+		if err := ms.Save(ctx, cp); err != nil {
+			t.Fatalf("Failed to save checkpoint: %v", err)
+		}
+	}
+
+	threadIDs, err = threads.ListThreadIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list threads: %v", err)
+	}
+	sort.Strings(threadIDs)
+	if fmt.Sprint(threadIDs) != "[thread-a thread-b]" {
+		t.Errorf("Expected [thread-a thread-b], got %v", threadIDs)
+	}
+
+	// Threads whose checkpoints were all deleted are not listed
+	if err := ms.Delete(ctx, "cp-3"); err != nil {
+		t.Fatalf("Failed to delete checkpoint: %v", err)
+	}
+	threadIDs, err = threads.ListThreadIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list threads: %v", err)
+	}
+	if fmt.Sprint(threadIDs) != "[thread-b]" {
+		t.Errorf("Expected [thread-b], got %v", threadIDs)
+	}
+}
+
+func TestMemoryCheckpointStore_Serializer(t *testing.T) {
+	t.Parallel()
+
+	serializer, err := store.NewZstdSerializer(store.GobSerializer{})
+	if err != nil {
+		t.Fatalf("Failed to create serializer: %v", err)
+	}
+	ms := NewMemoryCheckpointStore(store.WithSerializer(serializer))
+	ctx := context.Background()
+
+	state := map[string]any{"count": 1, "tags": []any{"a", "b"}}
+	cp := &store.Checkpoint{ID: "cp-1", State: state, Metadata: map[string]any{"thread_id": "thread-1"}}
+	if err := ms.Save(ctx, cp); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+	if cp.State.(map[string]any)["count"] != 1 {
+		t.Errorf("Save should not modify the checkpoint: %v", cp.State)
+	}
+
+	loaded, err := ms.Load(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	// Gob keeps Go types that JSON would turn into float64
+	if loaded.State.(map[string]any)["count"] != 1 {
+		t.Errorf("Unexpected state: %v", loaded.State)
+	}
+
+	list, err := ms.ListByThread(ctx, "thread-1")
+	if err != nil {
+		t.Fatalf("Failed to list checkpoints: %v", err)
+	}
+	if len(list) != 1 || list[0].State.(map[string]any)["count"] != 1 {
+		t.Errorf("Unexpected checkpoints: %+v", list)
+	}
+
+	pws := ms.(store.PendingWriteStore)
+	if err := pws.PutWrites(ctx, cp.ID, []store.PendingWrite{{TaskID: "0:a", Node: "a", Value: 42}}); err != nil {
+		t.Fatalf("Failed to put writes: %v", err)
+	}
+	writes, err := pws.GetWrites(ctx, cp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get writes: %v", err)
+	}
+	if len(writes) != 1 || writes[0].Value != 42 {
+		t.Errorf("Unexpected writes: %+v", writes)
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// MemoryRunStore provides in-memory storage of run records
+type MemoryRunStore struct {
+	runs  map[string]*store.Run
+	mutex sync.RWMutex
+}
+
+// NewMemoryRunStore creates a new in-memory run store
+func NewMemoryRunStore() store.RunStore {
+	return &MemoryRunStore{
+		runs: make(map[string]*store.Run),
+	}
+}
+
+// SaveRun implements RunStore interface for memory storage
+func (m *MemoryRunStore) SaveRun(_ context.Context, run *store.Run) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Keep a copy so that later changes of the caller are not visible until saved
+	saved := *run
+	m.runs[run.ID] = &saved
+	return nil
+}
+
+// GetRun implements RunStore interface for memory storage
+func (m *MemoryRunStore) GetRun(_ context.Context, runID string) (*store.Run, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	run, ok := m.runs[runID]
+	if !ok {
+		return nil, fmt.Errorf("run not found: %s", runID)
+	}
+
+	found := *run
+	return &found, nil
+}
+
+// ListRuns implements RunStore interface for memory storage
+func (m *MemoryRunStore) ListRuns(_ context.Context, filter store.RunFilter) ([]*store.Run, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var runs []*store.Run
+	for _, run := range m.runs {
+		if filter.Match(run) {
+			found := *run
+			runs = append(runs, &found)
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].CreatedAt.Equal(runs[j].CreatedAt) {
+			return runs[i].ID < runs[j].ID
+		}
+		return runs[i].CreatedAt.Before(runs[j].CreatedAt)
+	})
+
+	return runs, nil
+}
+
+// DeleteRun implements RunStore interface for memory storage
+func (m *MemoryRunStore) DeleteRun(_ context.Context, runID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.runs, runID)
+	return nil
+}
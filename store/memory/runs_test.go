@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestMemoryRunStore(t *testing.T) {
+	ctx := context.Background()
+	runs := NewMemoryRunStore()
+
+	now := time.Now()
+	run := &store.Run{ID: "run-1", ThreadID: "thread-1", Status: store.RunPending, Input: map[string]any{"q": "hi"}, CreatedAt: now}
+	require.NoError(t, runs.SaveRun(ctx, run))
+	require.NoError(t, runs.SaveRun(ctx, &store.Run{ID: "run-2", ThreadID: "thread-2", Status: store.RunRunning, CreatedAt: now.Add(time.Second)}))
+	require.NoError(t, runs.SaveRun(ctx, &store.Run{ID: "run-3", ThreadID: "thread-1", Status: store.RunSucceeded, CreatedAt: now.Add(2 * time.Second)}))
+
+	// Saved records are copies
+	run.Status = store.RunFailed
+	loaded, err := runs.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.RunPending, loaded.Status)
+
+	list, err := runs.ListRuns(ctx, store.RunFilter{ThreadID: "thread-1"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "run-1", list[0].ID)
+
+	list, err = runs.ListRuns(ctx, store.RunFilter{Statuses: []store.RunStatus{store.RunPending, store.RunRunning}})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "run-2", list[1].ID)
+
+	require.NoError(t, runs.DeleteRun(ctx, "run-1"))
+	_, err = runs.GetRun(ctx, "run-1")
+	assert.Error(t, err)
+}
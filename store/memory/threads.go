@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// thread summarizes a thread; the caller holds the lock
+func (m *MemoryCheckpointStore) thread(threadID string) *store.Thread {
+	var checkpoints []*store.Checkpoint
+	for _, id := range m.threadIndex[threadID] {
+		if cp, ok := m.checkpoints[id]; ok {
+			checkpoints = append(checkpoints, cp)
+		}
+	}
+	return store.NewThreadFromCheckpoints(threadID, m.threads[threadID], checkpoints)
+}
+
+// ListThreads implements store.ThreadStore
+func (m *MemoryCheckpointStore) ListThreads(_ context.Context, filter store.ThreadFilter, page store.Pagination) ([]*store.Thread, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	threads := make([]*store.Thread, 0, len(m.threadIndex)+len(m.threads))
+	for threadID := range m.threadIndex {
+		if _, ok := m.threads[threadID]; !ok {
+			threads = append(threads, m.thread(threadID))
+		}
+	}
+	for threadID := range m.threads {
+		threads = append(threads, m.thread(threadID))
+	}
+	return store.SelectThreads(threads, filter, page), nil
+}
+
+// GetThread implements store.ThreadStore
+func (m *MemoryCheckpointStore) GetThread(_ context.Context, threadID string) (*store.Thread, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	thread := m.thread(threadID)
+	if thread == nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrThreadNotFound, threadID)
+	}
+	return thread, nil
+}
+
+// UpdateThreadMetadata implements store.ThreadStore
+func (m *MemoryCheckpointStore) UpdateThreadMetadata(_ context.Context, threadID string, metadata map[string]any) (*store.Thread, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.threads[threadID] = store.MergeThreadMetadata(m.threads[threadID], metadata, time.Now())
+	return m.thread(threadID), nil
+}
+
+// DeleteThread implements store.ThreadStore
+func (m *MemoryCheckpointStore) DeleteThread(ctx context.Context, threadID string) error {
+	m.mutex.RLock()
+	ids := append([]string(nil), m.threadIndex[threadID]...)
+	m.mutex.RUnlock()
+
+	for _, id := range ids {
+		if err := m.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.threadIndex, threadID)
+	delete(m.threads, threadID)
+	return nil
+}
+
+// CopyThread implements store.ThreadStore
+func (m *MemoryCheckpointStore) CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*store.Thread, error) {
+	return store.CopyThread(ctx, m, sourceThreadID, targetThreadID)
+}
@@ -0,0 +1,281 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// ErrInvalidArchive is returned when reading something that is not a complete checkpoint archive.
+var ErrInvalidArchive = errors.New("invalid checkpoint archive")
+
+const (
+	// ArchiveFormat identifies checkpoint archives in their header
+	ArchiveFormat = "langgraphgo-checkpoints"
+
+	// ArchiveVersion is the version of the archive format written by Export
+	ArchiveVersion = 1
+)
+
+// Entry kinds of an archive.
+const (
+	kindHeader     = "header"
+	kindThread     = "thread"
+	kindCheckpoint = "checkpoint"
+	kindFooter     = "footer"
+)
+
+// An archive is a JSON Lines stream: a header, then each thread followed by its checkpoints,
+// then a footer with the totals and the checksum of the whole archive.
+
+type headerEntry struct {
+	Kind      string    `json:"kind"`
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type threadEntry struct {
+	Kind        string         `json:"kind"`
+	ThreadID    string         `json:"thread_id"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Checkpoints int            `json:"checkpoints"`
+	Checksum    string         `json:"checksum"`
+}
+
+type checkpointEntry struct {
+	Kind string `json:"kind"`
+	Record
+	Checksum string `json:"checksum"`
+}
+
+type footerEntry struct {
+	Kind        string `json:"kind"`
+	Threads     int    `json:"threads"`
+	Checkpoints int    `json:"checkpoints"`
+	Writes      int    `json:"writes"`
+	Checksum    string `json:"checksum"`
+}
+
+// Export writes the checkpoints, pending writes and thread metadata of src to w as a JSON Lines
+// archive, one thread at a time. States are written as the store returns them, decoded by its
+// serializer.
+//
+// Only checkpoints with a thread ID are exported; the others are counted in Report.Skipped.
+func Export(ctx context.Context, src store.CheckpointStore, w io.Writer, opts ...Option) (*Report, error) {
+	o := newOptions(opts)
+	start := time.Now()
+	report := &Report{}
+	sums := newDigest()
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(headerEntry{Kind: kindHeader, Format: ArchiveFormat, Version: ArchiveVersion, CreatedAt: start}); err != nil {
+		return report, fmt.Errorf("failed to write archive header: %w", err)
+	}
+
+	err := readThreads(ctx, src, o, report, func(thread *sourceThread) error {
+		sum, err := threadChecksum(thread.id, thread.metadata)
+		if err != nil {
+			return err
+		}
+		entry := threadEntry{Kind: kindThread, ThreadID: thread.id, Metadata: thread.metadata, Checkpoints: len(thread.records), Checksum: sum}
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write thread %s: %w", thread.id, err)
+		}
+		sums.add(sum)
+
+		for _, record := range thread.records {
+			sum, err := record.Checksum()
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(checkpointEntry{Kind: kindCheckpoint, Record: *record, Checksum: sum}); err != nil {
+				return fmt.Errorf("failed to write checkpoint %s: %w", record.Checkpoint.ID, err)
+			}
+			sums.add(sum)
+			report.Checkpoints++
+			report.Writes += len(record.Writes)
+		}
+
+		report.Threads++
+		report.Duration = time.Since(start)
+		if o.onProgress != nil {
+			o.onProgress(report)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	report.Checksum = sums.String()
+	footer := footerEntry{Kind: kindFooter, Threads: report.Threads, Checkpoints: report.Checkpoints, Writes: report.Writes, Checksum: report.Checksum}
+	if err := enc.Encode(footer); err != nil {
+		return report, fmt.Errorf("failed to write archive footer: %w", err)
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// Import reads an archive written by Export and saves its checkpoints, pending writes and thread
+// metadata to dst. Each checkpoint is checked against its checksum before it is saved, and the
+// totals of the archive against its footer once it is read; use Verify first to check a whole
+// archive before saving anything.
+func Import(ctx context.Context, r io.Reader, dst store.CheckpointStore, opts ...Option) (*Report, error) {
+	o := newOptions(opts)
+	return readArchive(ctx, r, o, &archiveHandler{
+		thread: func(entry *threadEntry) error {
+			return writeThreadMetadata(ctx, dst, entry.ThreadID, entry.Metadata)
+		},
+		checkpoint: func(entry *checkpointEntry) error {
+			return writeRecord(ctx, dst, &entry.Record, entry.Checksum, o.verify)
+		},
+	})
+}
+
+// Verify reads an archive and checks its checkpoints against their checksums and its totals
+// against its footer, without saving anything.
+func Verify(ctx context.Context, r io.Reader) (*Report, error) {
+	return readArchive(ctx, r, newOptions(nil), &archiveHandler{})
+}
+
+// archiveHandler receives the verified entries of an archive.
+type archiveHandler struct {
+	thread     func(*threadEntry) error
+	checkpoint func(*checkpointEntry) error
+}
+
+// readArchive reads and verifies an archive, passing its entries to h.
+func readArchive(ctx context.Context, r io.Reader, o *options, h *archiveHandler) (*Report, error) {
+	start := time.Now()
+	report := &Report{}
+	sums := newDigest()
+	dec := json.NewDecoder(r)
+
+	var header headerEntry
+	if err := dec.Decode(&header); err != nil {
+		return report, fmt.Errorf("%w: failed to read header: %v", ErrInvalidArchive, err)
+	}
+	if header.Kind != kindHeader || header.Format != ArchiveFormat {
+		return report, fmt.Errorf("%w: missing header", ErrInvalidArchive)
+	}
+	if header.Version > ArchiveVersion {
+		return report, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, header.Version)
+	}
+
+	var thread *threadEntry
+	remaining := 0
+	endThread := func() error {
+		if thread == nil {
+			return nil
+		}
+		if remaining != 0 {
+			return fmt.Errorf("%w: thread %s declares %d checkpoints, found %d", ErrCountMismatch, thread.ThreadID, thread.Checkpoints, thread.Checkpoints-remaining)
+		}
+		report.Threads++
+		report.Duration = time.Since(start)
+		if o.onProgress != nil {
+			o.onProgress(report)
+		}
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return report, fmt.Errorf("%w: missing footer, the archive is truncated", ErrInvalidArchive)
+			}
+			return report, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		var kind struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(raw, &kind); err != nil {
+			return report, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		switch kind.Kind {
+		case kindThread:
+			if err := endThread(); err != nil {
+				return report, err
+			}
+			thread = &threadEntry{}
+			if err := json.Unmarshal(raw, thread); err != nil {
+				return report, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+			sum, err := threadChecksum(thread.ThreadID, thread.Metadata)
+			if err != nil {
+				return report, err
+			}
+			if sum != thread.Checksum {
+				return report, fmt.Errorf("%w: thread %s", ErrChecksumMismatch, thread.ThreadID)
+			}
+			sums.add(sum)
+			remaining = thread.Checkpoints
+			if h.thread != nil {
+				if err := h.thread(thread); err != nil {
+					return report, err
+				}
+			}
+
+		case kindCheckpoint:
+			var entry checkpointEntry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return report, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+			if thread == nil || entry.Checkpoint == nil {
+				return report, fmt.Errorf("%w: checkpoint outside of a thread", ErrInvalidArchive)
+			}
+			sum, err := entry.Record.Checksum()
+			if err != nil {
+				return report, err
+			}
+			if sum != entry.Checksum {
+				return report, fmt.Errorf("%w: checkpoint %s", ErrChecksumMismatch, entry.Checkpoint.ID)
+			}
+			sums.add(sum)
+			remaining--
+			if h.checkpoint != nil {
+				if err := h.checkpoint(&entry); err != nil {
+					return report, err
+				}
+			}
+			report.Checkpoints++
+			report.Writes += len(entry.Writes)
+
+		case kindFooter:
+			if err := endThread(); err != nil {
+				return report, err
+			}
+			var footer footerEntry
+			if err := json.Unmarshal(raw, &footer); err != nil {
+				return report, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+			if footer.Threads != report.Threads || footer.Checkpoints != report.Checkpoints || footer.Writes != report.Writes {
+				return report, fmt.Errorf("%w: the archive declares %d threads, %d checkpoints and %d pending writes, found %d, %d and %d",
+					ErrCountMismatch, footer.Threads, footer.Checkpoints, footer.Writes, report.Threads, report.Checkpoints, report.Writes)
+			}
+			report.Checksum = sums.String()
+			if footer.Checksum != report.Checksum {
+				return report, fmt.Errorf("%w: archive", ErrChecksumMismatch)
+			}
+			report.Verified = true
+			report.Duration = time.Since(start)
+			return report, nil
+
+		default:
+			return report, fmt.Errorf("%w: unknown entry %q", ErrInvalidArchive, kind.Kind)
+		}
+	}
+}
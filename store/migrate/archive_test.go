@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store/memory"
+	"github.com/smallnest/langgraphgo/store/sqlite"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	src := memory.NewMemoryCheckpointStore()
+	seed(t, src)
+
+	var archive bytes.Buffer
+	exported, err := Export(ctx, src, &archive)
+	require.NoError(t, err)
+	assert.Equal(t, 2, exported.Threads)
+	assert.Equal(t, 4, exported.Checkpoints)
+	assert.Equal(t, 2, exported.Writes)
+	assert.False(t, exported.Verified)
+
+	lines := strings.Split(strings.TrimSpace(archive.String()), "\n")
+	require.Len(t, lines, 8) // header, 2 threads, 4 checkpoints, footer
+	assert.Contains(t, lines[0], `"format":"langgraphgo-checkpoints"`)
+	assert.Contains(t, lines[1], `"thread_id":"thread-a"`)
+	assert.Contains(t, lines[7], `"kind":"footer"`)
+
+	verified, err := Verify(ctx, bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.True(t, verified.Verified)
+	assert.Equal(t, exported.Checksum, verified.Checksum)
+
+	dst, err := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{Path: ":memory:"})
+	require.NoError(t, err)
+	defer dst.Close()
+
+	imported, err := Import(ctx, bytes.NewReader(archive.Bytes()), dst)
+	require.NoError(t, err)
+	assert.Equal(t, exported.Checksum, imported.Checksum)
+	assert.Equal(t, 4, imported.Checkpoints)
+	assertSeeded(t, dst)
+
+	// An archive has the checksum of a copy of the same checkpoints
+	copied, err := Copy(ctx, src, memory.NewMemoryCheckpointStore())
+	require.NoError(t, err)
+	assert.Equal(t, exported.Checksum, copied.Checksum)
+}
+
+func TestVerify_Corrupted(t *testing.T) {
+	ctx := context.Background()
+	src := memory.NewMemoryCheckpointStore()
+	seed(t, src)
+
+	var archive bytes.Buffer
+	_, err := Export(ctx, src, &archive)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(archive.String()), "\n")
+
+	corrupt := func(lines []string) *strings.Reader {
+		return strings.NewReader(strings.Join(lines, "\n"))
+	}
+	replace := func(i int, old, new string) []string {
+		changed := append([]string(nil), lines...)
+		require.Contains(t, changed[i], old)
+		changed[i] = strings.Replace(changed[i], old, new, 1)
+		return changed
+	}
+
+	// A changed state
+	_, err = Verify(ctx, corrupt(replace(2, `"count":1`, `"count":7`)))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// Changed thread metadata
+	_, err = Verify(ctx, corrupt(replace(1, `"owner":"alice"`, `"owner":"mallory"`)))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// A missing checkpoint
+	_, err = Verify(ctx, corrupt(append(append([]string(nil), lines[:3]...), lines[4:]...)))
+	assert.ErrorIs(t, err, ErrCountMismatch)
+
+	// A missing thread, with its checkpoint
+	_, err = Verify(ctx, corrupt(append(append([]string(nil), lines[:5]...), lines[7])))
+	assert.ErrorIs(t, err, ErrCountMismatch)
+
+	// A truncated archive
+	_, err = Verify(ctx, corrupt(lines[:7]))
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+
+	_, err = Verify(ctx, strings.NewReader(`{"kind":"footer"}`))
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+
+	// Imports stop at the first corrupted checkpoint
+	dst := memory.NewMemoryCheckpointStore()
+	report, err := Import(ctx, corrupt(replace(3, `"count":2`, `"count":7`)), dst)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Equal(t, 1, report.Checkpoints)
+}
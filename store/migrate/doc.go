@@ -0,0 +1,49 @@
+// Package migrate copies checkpoints between checkpoint stores, and exports and imports them as
+// portable archives, verifying checksums and counts along the way.
+//
+// Copy moves every thread of a store to another, e.g. from the file store used in development
+// to PostgreSQL. Checkpoints keep their IDs, parents, versions, timestamps and metadata, with
+// their pending writes and the thread metadata of stores implementing store.ThreadStore:
+//
+//	report, err := migrate.Copy(ctx, fileStore, postgresStore)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(report)
+//
+// Reading every thread requires the source to implement store.ThreadLister, as all bundled
+// stores do; WithThreads selects threads instead. Checkpoints without a thread ID are not read:
+// when reading every thread, Report.Skipped counts them for stores implementing
+// store.UnthreadedCounter, as all bundled stores do.
+//
+// # Archives
+//
+// Export writes checkpoints to a JSON Lines archive, one thread at a time, and Import saves an
+// archive to a store, e.g. to seed test fixtures from real runs:
+//
+//	f, err := os.Create("fixtures/checkout.jsonl")
+//	...
+//	_, err = migrate.Export(ctx, sqliteStore, f, migrate.WithThreads("thread-42"))
+//
+//	f, err := os.Open("fixtures/checkout.jsonl")
+//	...
+//	_, err = migrate.Import(ctx, f, memory.NewMemoryCheckpointStore())
+//
+// An archive starts with a header and ends with a footer holding its totals and a checksum of
+// all its entries. Each thread and checkpoint entry holds the SHA-256 checksum of its content.
+// States are written as JSON, as the source store returns them after its serializer, so
+// archives of encrypted stores hold plain text.
+//
+// # Verification
+//
+// Import checks each checkpoint against its checksum before saving it, and the archive against
+// its footer; Verify does the same without saving anything. Copy and Import read back every
+// checkpoint they save and compare it with its checksum, unless WithVerify(false) is set.
+//
+// Checksums are computed on JSON encodings, with timestamps in UTC to the microsecond, so that
+// they match across stores. The Report of a copy, an export and an import of the same
+// checkpoints has the same Checksum.
+//
+// The langgraph-checkpoints command (cmd/langgraph-checkpoints) runs these operations from the
+// command line.
+package migrate
@@ -0,0 +1,345 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+var (
+	// ErrThreadsUnsupported is returned when reading all threads of a store that cannot list them.
+	ErrThreadsUnsupported = errors.New("store does not implement store.ThreadLister")
+
+	// ErrChecksumMismatch is returned when a checkpoint differs from its checksum: it was
+	// corrupted in an archive, or not stored as read from the source.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrCountMismatch is returned when an archive holds fewer or more records than it declares.
+	ErrCountMismatch = errors.New("count mismatch")
+)
+
+// Option configures a copy, export or import.
+type Option func(*options)
+
+type options struct {
+	threads    []string
+	verify     bool
+	onProgress func(*Report)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{verify: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithThreads restricts a copy or export to the given threads. By default every thread of the
+// source is read, which requires the source to implement store.ThreadLister.
+func WithThreads(threadIDs ...string) Option {
+	return func(o *options) {
+		o.threads = append(o.threads, threadIDs...)
+	}
+}
+
+// WithVerify sets whether the checkpoints written to the destination store of a copy or import
+// are read back and compared with their checksums. It is enabled by default.
+func WithVerify(verify bool) Option {
+	return func(o *options) {
+		o.verify = verify
+	}
+}
+
+// WithProgress sets a function called with the running totals after each thread.
+func WithProgress(fn func(*Report)) Option {
+	return func(o *options) {
+		o.onProgress = fn
+	}
+}
+
+// Report describes a copy, export, import or verification.
+type Report struct {
+	Threads     int
+	Checkpoints int
+	Writes      int
+
+	// Skipped is the number of checkpoints without a thread ID left out when reading every thread
+	// of a store implementing store.UnthreadedCounter
+	Skipped int
+
+	// Checksum covers every thread and checkpoint, in order. Copying, exporting and importing the
+	// same checkpoints report the same checksum.
+	Checksum string
+
+	// Verified reports whether the checkpoints were checked against their checksums, in the
+	// destination store or in the archive
+	Verified bool
+
+	Duration time.Duration
+}
+
+func (r *Report) String() string {
+	verified := ""
+	if r.Verified {
+		verified = ", verified"
+	}
+	skipped := ""
+	if r.Skipped > 0 {
+		skipped = fmt.Sprintf(", %d checkpoints without a thread ID skipped", r.Skipped)
+	}
+	return fmt.Sprintf("%d threads, %d checkpoints, %d pending writes%s in %s (checksum %s%s)",
+		r.Threads, r.Checkpoints, r.Writes, skipped, r.Duration.Round(time.Millisecond), r.Checksum, verified)
+}
+
+// Record is a checkpoint with its pending writes.
+type Record struct {
+	Checkpoint *store.Checkpoint    `json:"checkpoint"`
+	Writes     []store.PendingWrite `json:"writes,omitempty"`
+}
+
+// Checksum returns the SHA-256 checksum of the record, as a hex string. It does not depend on
+// how a store represents the record: values are compared as JSON, and timestamps in UTC with
+// microsecond precision, which all bundled stores keep.
+func (r *Record) Checksum() (string, error) {
+	cp := *r.Checkpoint
+	cp.Timestamp = normalizeTime(cp.Timestamp)
+
+	writes := make([]store.PendingWrite, len(r.Writes))
+	for i, w := range r.Writes {
+		w.Timestamp = normalizeTime(w.Timestamp)
+		writes[i] = w
+	}
+	sort.Slice(writes, func(i, j int) bool { return writes[i].TaskID < writes[j].TaskID })
+
+	return checksum(Record{Checkpoint: &cp, Writes: writes})
+}
+
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// checksum returns the SHA-256 checksum of the canonical JSON encoding of v, with object keys
+// sorted and numbers formatted alike.
+func checksum(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal record: %w", err)
+	}
+	var canonical any
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return "", fmt.Errorf("failed to unmarshal record: %w", err)
+	}
+	if data, err = json.Marshal(canonical); err != nil {
+		return "", fmt.Errorf("failed to marshal record: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// threadChecksum returns the checksum of the metadata of a thread.
+func threadChecksum(threadID string, metadata map[string]any) (string, error) {
+	return checksum(map[string]any{"thread_id": threadID, "metadata": metadata})
+}
+
+// digest accumulates the checksums of a report.
+type digest struct {
+	hash hash.Hash
+}
+
+func newDigest() *digest {
+	return &digest{hash: sha256.New()}
+}
+
+func (d *digest) add(checksum string) {
+	d.hash.Write([]byte(checksum + "\n"))
+}
+
+func (d *digest) String() string {
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
+
+// sourceThread is a thread read from a store.
+type sourceThread struct {
+	id       string
+	metadata map[string]any
+	records  []*Record
+}
+
+// readThreads calls fn with each thread to read from src, in the order of their IDs, with their
+// checkpoints in version order. When reading every thread, the checkpoints without a thread ID
+// are counted in report.Skipped.
+func readThreads(ctx context.Context, src store.CheckpointStore, o *options, report *Report, fn func(*sourceThread) error) error {
+	threadIDs := slices.Clone(o.threads)
+	if len(threadIDs) == 0 {
+		lister, ok := src.(store.ThreadLister)
+		if !ok {
+			return ErrThreadsUnsupported
+		}
+		var err error
+		if threadIDs, err = lister.ListThreadIDs(ctx); err != nil {
+			return fmt.Errorf("failed to list threads: %w", err)
+		}
+		if counter, ok := src.(store.UnthreadedCounter); ok {
+			if report.Skipped, err = counter.CountUnthreaded(ctx); err != nil {
+				return fmt.Errorf("failed to count checkpoints without a thread ID: %w", err)
+			}
+		}
+	}
+	sort.Strings(threadIDs)
+	threadIDs = slices.Compact(threadIDs)
+
+	threads, _ := src.(store.ThreadStore)
+	pws, _ := src.(store.PendingWriteStore)
+	for _, threadID := range threadIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		thread := &sourceThread{id: threadID}
+		if threads != nil {
+			info, err := threads.GetThread(ctx, threadID)
+			if err != nil && !errors.Is(err, store.ErrThreadNotFound) {
+				return fmt.Errorf("failed to read thread %s: %w", threadID, err)
+			}
+			if info != nil && len(info.Metadata) > 0 {
+				thread.metadata = info.Metadata
+			}
+		}
+
+		checkpoints, err := src.ListByThread(ctx, threadID)
+		if err != nil {
+			return fmt.Errorf("failed to list checkpoints for thread %s: %w", threadID, err)
+		}
+		sort.SliceStable(checkpoints, func(i, j int) bool { return checkpoints[i].Version < checkpoints[j].Version })
+
+		for _, cp := range checkpoints {
+			record := &Record{Checkpoint: cp}
+			if pws != nil {
+				if record.Writes, err = pws.GetWrites(ctx, cp.ID); err != nil {
+					return fmt.Errorf("failed to read pending writes of checkpoint %s: %w", cp.ID, err)
+				}
+			}
+			thread.records = append(thread.records, record)
+		}
+
+		if err := fn(thread); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeThreadMetadata attaches metadata to a thread of dst, when dst manages threads.
+func writeThreadMetadata(ctx context.Context, dst store.CheckpointStore, threadID string, metadata map[string]any) error {
+	threads, ok := dst.(store.ThreadStore)
+	if !ok || len(metadata) == 0 {
+		return nil
+	}
+	if _, err := threads.UpdateThreadMetadata(ctx, threadID, metadata); err != nil {
+		return fmt.Errorf("failed to write metadata of thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+// writeRecord saves a record to dst, and reads it back to compare it with its checksum when
+// verify is set.
+func writeRecord(ctx context.Context, dst store.CheckpointStore, record *Record, sum string, verify bool) error {
+	id := record.Checkpoint.ID
+	if err := dst.Save(ctx, record.Checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint %s: %w", id, err)
+	}
+
+	pws, _ := dst.(store.PendingWriteStore)
+	if len(record.Writes) > 0 {
+		if pws == nil {
+			return fmt.Errorf("failed to save pending writes of checkpoint %s: %T does not implement store.PendingWriteStore", id, dst)
+		}
+		// Replace any writes left by an earlier run
+		if err := pws.DeleteWrites(ctx, id); err != nil {
+			return fmt.Errorf("failed to save pending writes of checkpoint %s: %w", id, err)
+		}
+		if err := pws.PutWrites(ctx, id, record.Writes); err != nil {
+			return fmt.Errorf("failed to save pending writes of checkpoint %s: %w", id, err)
+		}
+	}
+
+	if !verify {
+		return nil
+	}
+
+	saved := &Record{}
+	var err error
+	if saved.Checkpoint, err = dst.Load(ctx, id); err != nil {
+		return fmt.Errorf("failed to verify checkpoint %s: %w", id, err)
+	}
+	if pws != nil {
+		if saved.Writes, err = pws.GetWrites(ctx, id); err != nil {
+			return fmt.Errorf("failed to verify pending writes of checkpoint %s: %w", id, err)
+		}
+	}
+	savedSum, err := saved.Checksum()
+	if err != nil {
+		return err
+	}
+	if savedSum != sum {
+		return fmt.Errorf("%w: checkpoint %s was not stored as read", ErrChecksumMismatch, id)
+	}
+	return nil
+}
+
+// Copy copies the checkpoints, pending writes and thread metadata of src to dst. Checkpoints
+// keep their IDs, parents, versions, timestamps and metadata; saving them again overwrites them,
+// so an interrupted copy can be run again.
+//
+// Only checkpoints with a thread ID are copied; the others are counted in Report.Skipped.
+func Copy(ctx context.Context, src, dst store.CheckpointStore, opts ...Option) (*Report, error) {
+	o := newOptions(opts)
+	start := time.Now()
+	report := &Report{Verified: o.verify}
+	sums := newDigest()
+
+	err := readThreads(ctx, src, o, report, func(thread *sourceThread) error {
+		sum, err := threadChecksum(thread.id, thread.metadata)
+		if err != nil {
+			return err
+		}
+		sums.add(sum)
+
+		for _, record := range thread.records {
+			sum, err := record.Checksum()
+			if err != nil {
+				return err
+			}
+			if err := writeRecord(ctx, dst, record, sum, o.verify); err != nil {
+				return err
+			}
+			sums.add(sum)
+			report.Checkpoints++
+			report.Writes += len(record.Writes)
+		}
+		if err := writeThreadMetadata(ctx, dst, thread.id, thread.metadata); err != nil {
+			return err
+		}
+
+		report.Threads++
+		report.Duration = time.Since(start)
+		if o.onProgress != nil {
+			o.onProgress(report)
+		}
+		return nil
+	})
+
+	report.Checksum = sums.String()
+	report.Duration = time.Since(start)
+	return report, err
+}
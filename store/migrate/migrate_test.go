@@ -0,0 +1,249 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/file"
+	"github.com/smallnest/langgraphgo/store/memory"
+	"github.com/smallnest/langgraphgo/store/redis"
+	"github.com/smallnest/langgraphgo/store/sqlite"
+)
+
+var start = time.Date(2025, 6, 1, 12, 0, 0, 123456789, time.UTC)
+
+// seed saves two threads: thread-a with three checkpoints, metadata and pending writes on its
+// last checkpoint, and thread-b with one checkpoint.
+func seed(t *testing.T, s store.CheckpointStore) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		parentID := ""
+		if i > 1 {
+			parentID = fmt.Sprintf("a-%d", i-1)
+		}
+		require.NoError(t, s.Save(ctx, &store.Checkpoint{
+			ID:        fmt.Sprintf("a-%d", i),
+			ParentID:  parentID,
+			NodeName:  "step",
+			State:     map[string]any{"count": i, "messages": []string{"hi"}},
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Version:   i,
+			Metadata:  map[string]any{"execution_id": "thread-a", "thread_id": "thread-a", "next": []string{"step"}},
+		}))
+	}
+	require.NoError(t, s.Save(ctx, &store.Checkpoint{
+		ID:        "b-1",
+		NodeName:  "step",
+		State:     map[string]any{"count": 1},
+		Timestamp: start,
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "thread-b", "thread_id": "thread-b"},
+	}))
+	require.NoError(t, s.(store.PendingWriteStore).PutWrites(ctx, "a-3", []store.PendingWrite{
+		{TaskID: "task-2", Node: "tool", Value: map[string]any{"result": 2}, Timestamp: start},
+		{TaskID: "task-1", Node: "tool", Value: "done", Timestamp: start},
+	}))
+	_, err := s.(store.ThreadStore).UpdateThreadMetadata(ctx, "thread-a", map[string]any{"owner": "alice"})
+	require.NoError(t, err)
+}
+
+// assertSeeded checks s holds the checkpoints saved by seed.
+func assertSeeded(t *testing.T, s store.CheckpointStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	checkpoints, err := s.ListByThread(ctx, "thread-a")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 3)
+	for i, cp := range checkpoints {
+		assert.Equal(t, fmt.Sprintf("a-%d", i+1), cp.ID)
+		assert.Equal(t, i+1, cp.Version)
+		assert.Equal(t, "step", cp.NodeName)
+		assert.Equal(t, "thread-a", cp.Metadata["thread_id"])
+		assert.True(t, start.Add(time.Duration(i+1)*time.Minute).Truncate(time.Microsecond).Equal(cp.Timestamp.Truncate(time.Microsecond)))
+		assert.EqualValues(t, i+1, cp.State.(map[string]any)["count"])
+	}
+	assert.Equal(t, "a-2", checkpoints[2].ParentID)
+
+	writes, err := s.(store.PendingWriteStore).GetWrites(ctx, "a-3")
+	require.NoError(t, err)
+	assert.Len(t, writes, 2)
+
+	thread, err := s.(store.ThreadStore).GetThread(ctx, "thread-a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"owner": "alice"}, thread.Metadata)
+
+	latest, err := s.GetLatestByThread(ctx, "thread-b")
+	require.NoError(t, err)
+	assert.Equal(t, "b-1", latest.ID)
+}
+
+func newStores(t *testing.T) map[string]store.CheckpointStore {
+	fileStore, err := file.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	sqliteStore, err := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{Path: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteStore.Close() })
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return map[string]store.CheckpointStore{
+		"memory": memory.NewMemoryCheckpointStore(),
+		"file":   fileStore,
+		"sqlite": sqliteStore,
+		"redis":  redis.NewRedisCheckpointStore(redis.RedisOptions{Addr: mr.Addr()}),
+		"delta":  store.NewDeltaStore(memory.NewMemoryCheckpointStore(), store.DeltaOptions{}),
+	}
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	src, err := file.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	seed(t, src)
+
+	for name, dst := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			var progress []int
+			report, err := Copy(ctx, src, dst, WithProgress(func(r *Report) { progress = append(progress, r.Checkpoints) }))
+			require.NoError(t, err)
+			assert.Equal(t, 2, report.Threads)
+			assert.Equal(t, 4, report.Checkpoints)
+			assert.Equal(t, 2, report.Writes)
+			assert.True(t, report.Verified)
+			assert.Len(t, report.Checksum, 64)
+			assert.Equal(t, []int{3, 4}, progress)
+			assertSeeded(t, dst)
+
+			// Copying again overwrites the copies, and copying back finds the same checkpoints
+			again, err := Copy(ctx, src, dst)
+			require.NoError(t, err)
+			assert.Equal(t, report.Checksum, again.Checksum)
+			back, err := Copy(ctx, dst, memory.NewMemoryCheckpointStore())
+			require.NoError(t, err)
+			assert.Equal(t, report.Checksum, back.Checksum)
+		})
+	}
+}
+
+func TestCopy_WithThreads(t *testing.T) {
+	ctx := context.Background()
+	src := memory.NewMemoryCheckpointStore()
+	seed(t, src)
+	dst := memory.NewMemoryCheckpointStore()
+
+	report, err := Copy(ctx, src, dst, WithThreads("thread-b", "thread-b"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Threads)
+	assert.Equal(t, 1, report.Checkpoints)
+
+	checkpoints, err := dst.ListByThread(ctx, "thread-a")
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
+
+func TestCopy_Unthreaded(t *testing.T) {
+	ctx := context.Background()
+	for name, src := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			seed(t, src)
+			require.NoError(t, src.Save(ctx, &store.Checkpoint{
+				ID:        "orphan",
+				State:     map[string]any{},
+				Timestamp: start,
+				Version:   1,
+				Metadata:  map[string]any{"execution_id": "exec-1"},
+			}))
+
+			report, err := Copy(ctx, src, memory.NewMemoryCheckpointStore())
+			require.NoError(t, err)
+			assert.Equal(t, 4, report.Checkpoints)
+			assert.Equal(t, 1, report.Skipped)
+			assert.Contains(t, report.String(), "4 checkpoints, 2 pending writes, 1 checkpoints without a thread ID skipped in ")
+
+			report, err = Export(ctx, src, io.Discard)
+			require.NoError(t, err)
+			assert.Equal(t, 1, report.Skipped)
+
+			// Selected threads leave the other checkpoints out on purpose
+			report, err = Copy(ctx, src, memory.NewMemoryCheckpointStore(), WithThreads("thread-a"))
+			require.NoError(t, err)
+			assert.Zero(t, report.Skipped)
+			assert.NotContains(t, report.String(), "skipped")
+		})
+	}
+}
+
+// unlistedStore is a store that cannot list its threads.
+type unlistedStore struct {
+	store.CheckpointStore
+}
+
+func TestCopy_ThreadsUnsupported(t *testing.T) {
+	_, err := Copy(context.Background(), unlistedStore{memory.NewMemoryCheckpointStore()}, memory.NewMemoryCheckpointStore())
+	assert.ErrorIs(t, err, ErrThreadsUnsupported)
+}
+
+// lossyStore drops the node names of the checkpoints it saves.
+type lossyStore struct {
+	store.CheckpointStore
+}
+
+func (s lossyStore) Save(ctx context.Context, checkpoint *store.Checkpoint) error {
+	saved := *checkpoint
+	saved.NodeName = ""
+	return s.CheckpointStore.Save(ctx, &saved)
+}
+
+func TestCopy_VerifyMismatch(t *testing.T) {
+	ctx := context.Background()
+	src := memory.NewMemoryCheckpointStore()
+	seed(t, src)
+
+	_, err := Copy(ctx, src, lossyStore{memory.NewMemoryCheckpointStore()}, WithThreads("thread-b"))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	_, err = Copy(ctx, src, lossyStore{memory.NewMemoryCheckpointStore()}, WithThreads("thread-b"), WithVerify(false))
+	assert.NoError(t, err)
+}
+
+func TestRecordChecksum(t *testing.T) {
+	record := &Record{
+		Checkpoint: &store.Checkpoint{ID: "cp-1", State: map[string]any{"count": 1, "tags": []string{"a"}}, Timestamp: start},
+		Writes: []store.PendingWrite{
+			{TaskID: "task-2", Value: 2},
+			{TaskID: "task-1", Value: 1},
+		},
+	}
+	sum, err := record.Checksum()
+	require.NoError(t, err)
+
+	// The same record as read from a JSON store in another time zone
+	stored := &Record{
+		Checkpoint: &store.Checkpoint{ID: "cp-1", State: map[string]any{"tags": []any{"a"}, "count": 1.0}, Timestamp: start.In(time.FixedZone("CET", 3600)).Truncate(time.Microsecond)},
+		Writes: []store.PendingWrite{
+			{TaskID: "task-1", Value: 1.0},
+			{TaskID: "task-2", Value: 2.0},
+		},
+	}
+	storedSum, err := stored.Checksum()
+	require.NoError(t, err)
+	assert.Equal(t, sum, storedSum)
+
+	stored.Checkpoint.State = map[string]any{"count": 2}
+	storedSum, err = stored.Checksum()
+	require.NoError(t, err)
+	assert.NotEqual(t, sum, storedSum)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
 )
 
 // DBPool defines the interface for database connection pool
@@ -21,8 +22,9 @@ type DBPool interface {
 
 // PostgresCheckpointStore implements graph.CheckpointStore using PostgreSQL
 type PostgresCheckpointStore struct {
-	pool      DBPool
-	tableName string
+	pool       DBPool
+	tableName  string
+	serializer store.Serializer
 }
 
 // PostgresOptions configuration for Postgres connection
@@ -31,8 +33,9 @@ type PostgresOptions struct {
 	TableName  string // Default "checkpoints"
 }
 
-// NewPostgresCheckpointStore creates a new Postgres checkpoint store
-func NewPostgresCheckpointStore(ctx context.Context, opts PostgresOptions) (*PostgresCheckpointStore, error) {
+// NewPostgresCheckpointStore creates a new Postgres checkpoint store.
+// States and pending write values are stored as plain JSON unless store.WithSerializer is given.
+func NewPostgresCheckpointStore(ctx context.Context, opts PostgresOptions, options ...store.Option) (*PostgresCheckpointStore, error) {
 	pool, err := pgxpool.New(ctx, opts.ConnString)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create connection pool: %w", err)
@@ -44,20 +47,22 @@ func NewPostgresCheckpointStore(ctx context.Context, opts PostgresOptions) (*Pos
 	}
 
 	return &PostgresCheckpointStore{
-		pool:      pool,
-		tableName: tableName,
+		pool:       pool,
+		tableName:  tableName,
+		serializer: store.NewOptions(options...).Serializer,
 	}, nil
 }
 
 // NewPostgresCheckpointStoreWithPool creates a new Postgres checkpoint store with an existing pool
 // Useful for testing with mocks
-func NewPostgresCheckpointStoreWithPool(pool DBPool, tableName string) *PostgresCheckpointStore {
+func NewPostgresCheckpointStoreWithPool(pool DBPool, tableName string, opts ...store.Option) *PostgresCheckpointStore {
 	if tableName == "" {
 		tableName = "checkpoints"
 	}
 	return &PostgresCheckpointStore{
-		pool:      pool,
-		tableName: tableName,
+		pool:       pool,
+		tableName:  tableName,
+		serializer: store.NewOptions(opts...).Serializer,
 	}
 }
 
@@ -72,12 +77,15 @@ func (s *PostgresCheckpointStore) InitSchema(ctx context.Context) error {
 			state JSONB NOT NULL,
 			metadata JSONB,
 			timestamp TIMESTAMPTZ NOT NULL,
-			version INTEGER NOT NULL
+			version INTEGER NOT NULL,
+			parent_id TEXT
 		);
 		CREATE INDEX IF NOT EXISTS idx_%s_execution_id ON %s (execution_id);
 		CREATE INDEX IF NOT EXISTS idx_%s_thread_id ON %s (thread_id);
 		CREATE INDEX IF NOT EXISTS idx_%s_execution_thread ON %s (execution_id, thread_id);
-	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
+		%s
+		%s
+	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.writesSchema(), s.threadsSchema())
 
 	_, err := s.pool.Exec(ctx, query)
 	if err != nil {
@@ -86,7 +94,7 @@ func (s *PostgresCheckpointStore) InitSchema(ctx context.Context) error {
 	return nil
 }
 
-// MigrateSchema adds the thread_id column if it doesn't exist (for existing installations)
+// MigrateSchema adds the thread_id and parent_id columns, the pending writes and thread tables and the thread indexes if they don't exist (for existing installations)
 func (s *PostgresCheckpointStore) MigrateSchema(ctx context.Context) error {
 	// Add thread_id column if it doesn't exist
 	migrationQuery := fmt.Sprintf(`
@@ -99,6 +107,13 @@ func (s *PostgresCheckpointStore) MigrateSchema(ctx context.Context) error {
 				ALTER TABLE %s ADD COLUMN thread_id TEXT;
 			END IF;
 
+			IF NOT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = '%s' AND column_name = 'parent_id'
+			) THEN
+				ALTER TABLE %s ADD COLUMN parent_id TEXT;
+			END IF;
+
 			IF NOT EXISTS (
 				SELECT 1 FROM pg_indexes WHERE indexname = 'idx_%s_thread_id'
 			) THEN
@@ -111,7 +126,9 @@ func (s *PostgresCheckpointStore) MigrateSchema(ctx context.Context) error {
 				CREATE INDEX idx_%s_execution_thread ON %s (execution_id, thread_id);
 			END IF;
 		END $$;
-	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
+		%s
+		%s
+	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.writesSchema(), s.threadsSchema())
 
 	_, err := s.pool.Exec(ctx, migrationQuery)
 	if err != nil {
@@ -120,6 +137,26 @@ func (s *PostgresCheckpointStore) MigrateSchema(ctx context.Context) error {
 	return nil
 }
 
+// CheckSchema returns store.ErrSchemaOutdated if the schema lacks columns or tables added by
+// MigrateSchema, or does not exist. Stores opened without InitSchema and MigrateSchema, e.g. to
+// be read only, can check their schema with it.
+func (s *PostgresCheckpointStore) CheckSchema(ctx context.Context) error {
+	query := `
+		SELECT (
+			SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_name = $1 AND column_name IN ('thread_id', 'parent_id')
+		) = 2 AND to_regclass($2) IS NOT NULL AND to_regclass($3) IS NOT NULL`
+
+	var current bool
+	if err := s.pool.QueryRow(ctx, query, s.tableName, s.writesTable(), s.threadsTable()).Scan(&current); err != nil {
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+	if !current {
+		return fmt.Errorf("%w: table %s", store.ErrSchemaOutdated, s.tableName)
+	}
+	return nil
+}
+
 // Close closes the connection pool
 func (s *PostgresCheckpointStore) Close() {
 	s.pool.Close()
@@ -127,7 +164,12 @@ func (s *PostgresCheckpointStore) Close() {
 
 // Save stores a checkpoint
 func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Checkpoint) error {
-	stateJSON, err := json.Marshal(checkpoint.State)
+	state, err := store.EncodeValue(s.serializer, checkpoint.State)
+	if err != nil {
+		return err
+	}
+
+	stateJSON, err := json.Marshal(state)
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
@@ -148,8 +190,8 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 	}
 
 	query := fmt.Sprintf(`
-		INSERT INTO %s (id, execution_id, thread_id, node_name, state, metadata, timestamp, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO %s (id, execution_id, thread_id, node_name, state, metadata, timestamp, version, parent_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (id) DO UPDATE SET
 			execution_id = EXCLUDED.execution_id,
 			thread_id = EXCLUDED.thread_id,
@@ -157,7 +199,8 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 			state = EXCLUDED.state,
 			metadata = EXCLUDED.metadata,
 			timestamp = EXCLUDED.timestamp,
-			version = EXCLUDED.version
+			version = EXCLUDED.version,
+			parent_id = EXCLUDED.parent_id
 	`, s.tableName)
 
 	_, err = s.pool.Exec(ctx, query,
@@ -169,6 +212,7 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 		metadataJSON,
 		checkpoint.Timestamp,
 		checkpoint.Version,
+		checkpoint.ParentID,
 	)
 
 	if err != nil {
@@ -181,7 +225,7 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 // Load retrieves a checkpoint by ID
 func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string) (*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE id = $1
 	`, s.tableName)
@@ -197,6 +241,7 @@ func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string)
 		&metadataJSON,
 		&cp.Timestamp,
 		&cp.Version,
+		&cp.ParentID,
 	)
 
 	if err != nil {
@@ -210,6 +255,10 @@ func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string)
 		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 	}
 
+	if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+		return nil, err
+	}
+
 	if len(metadataJSON) > 0 {
 		if err := json.Unmarshal(metadataJSON, &cp.Metadata); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
@@ -222,7 +271,7 @@ func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string)
 // List returns all checkpoints for a given execution
 func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string) ([]*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE execution_id = $1
 		ORDER BY timestamp ASC
@@ -247,6 +296,7 @@ func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string)
 			&metadataJSON,
 			&cp.Timestamp,
 			&cp.Version,
+			&cp.ParentID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan checkpoint row: %w", err)
@@ -256,6 +306,10 @@ func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string)
 			return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 		}
 
+		if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+			return nil, err
+		}
+
 		if len(metadataJSON) > 0 {
 			if err := json.Unmarshal(metadataJSON, &cp.Metadata); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
@@ -275,7 +329,7 @@ func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string)
 // ListByThread returns all checkpoints for a specific thread_id
 func (s *PostgresCheckpointStore) ListByThread(ctx context.Context, threadID string) ([]*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE thread_id = $1
 		ORDER BY timestamp ASC
@@ -300,6 +354,7 @@ func (s *PostgresCheckpointStore) ListByThread(ctx context.Context, threadID str
 			&metadataJSON,
 			&cp.Timestamp,
 			&cp.Version,
+			&cp.ParentID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan checkpoint row: %w", err)
@@ -309,6 +364,10 @@ func (s *PostgresCheckpointStore) ListByThread(ctx context.Context, threadID str
 			return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 		}
 
+		if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+			return nil, err
+		}
+
 		if len(metadataJSON) > 0 {
 			if err := json.Unmarshal(metadataJSON, &cp.Metadata); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
@@ -328,7 +387,7 @@ func (s *PostgresCheckpointStore) ListByThread(ctx context.Context, threadID str
 // GetLatestByThread returns the latest checkpoint for a thread_id
 func (s *PostgresCheckpointStore) GetLatestByThread(ctx context.Context, threadID string) (*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE thread_id = $1
 		ORDER BY version DESC
@@ -346,6 +405,7 @@ func (s *PostgresCheckpointStore) GetLatestByThread(ctx context.Context, threadI
 		&metadataJSON,
 		&cp.Timestamp,
 		&cp.Version,
+		&cp.ParentID,
 	)
 
 	if err != nil {
@@ -359,6 +419,10 @@ func (s *PostgresCheckpointStore) GetLatestByThread(ctx context.Context, threadI
 		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 	}
 
+	if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+		return nil, err
+	}
+
 	if len(metadataJSON) > 0 {
 		if err := json.Unmarshal(metadataJSON, &cp.Metadata); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
@@ -368,6 +432,46 @@ func (s *PostgresCheckpointStore) GetLatestByThread(ctx context.Context, threadI
 	return &cp, nil
 }
 
+// ListThreadIDs implements store.ThreadLister
+func (s *PostgresCheckpointStore) ListThreadIDs(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT DISTINCT thread_id
+		FROM %s
+		WHERE thread_id IS NOT NULL AND thread_id <> ''
+	`, s.tableName)
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	defer rows.Close()
+
+	threadIDs := []string{}
+	for rows.Next() {
+		var threadID string
+		if err := rows.Scan(&threadID); err != nil {
+			return nil, fmt.Errorf("failed to scan thread row: %w", err)
+		}
+		threadIDs = append(threadIDs, threadID)
+	}
+	return threadIDs, rows.Err()
+}
+
+// CountUnthreaded implements store.UnthreadedCounter
+func (s *PostgresCheckpointStore) CountUnthreaded(ctx context.Context) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE thread_id IS NULL OR thread_id = ''
+	`, s.tableName)
+
+	var count int
+	if err := s.pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count checkpoints without a thread: %w", err)
+	}
+	return count, nil
+}
+
 // Delete removes a checkpoint
 func (s *PostgresCheckpointStore) Delete(ctx context.Context, checkpointID string) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.tableName)
@@ -387,3 +491,98 @@ func (s *PostgresCheckpointStore) Clear(ctx context.Context, executionID string)
 	}
 	return nil
 }
+
+// writesTable returns the name of the pending writes table
+func (s *PostgresCheckpointStore) writesTable() string {
+	return s.tableName + "_writes"
+}
+
+// writesSchema returns the DDL of the pending writes table.
+// Writes are removed together with their checkpoint through the foreign key.
+func (s *PostgresCheckpointStore) writesSchema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			checkpoint_id TEXT NOT NULL REFERENCES %s (id) ON DELETE CASCADE,
+			task_id TEXT NOT NULL,
+			node_name TEXT NOT NULL,
+			value JSONB NOT NULL,
+			timestamp TIMESTAMPTZ NOT NULL,
+			seq BIGSERIAL,
+			PRIMARY KEY (checkpoint_id, task_id)
+		);`, s.writesTable(), s.tableName)
+}
+
+// PutWrites records pending writes on top of a checkpoint
+func (s *PostgresCheckpointStore) PutWrites(ctx context.Context, checkpointID string, writes []store.PendingWrite) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (checkpoint_id, task_id, node_name, value, timestamp)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (checkpoint_id, task_id) DO UPDATE SET
+			node_name = EXCLUDED.node_name,
+			value = EXCLUDED.value,
+			timestamp = EXCLUDED.timestamp
+	`, s.writesTable())
+
+	for _, w := range writes {
+		value, err := store.EncodeValue(s.serializer, w.Value)
+		if err != nil {
+			return err
+		}
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pending write: %w", err)
+		}
+		if _, err := s.pool.Exec(ctx, query, checkpointID, w.TaskID, w.Node, valueJSON, w.Timestamp); err != nil {
+			return fmt.Errorf("failed to save pending write: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetWrites returns the pending writes recorded on top of a checkpoint
+func (s *PostgresCheckpointStore) GetWrites(ctx context.Context, checkpointID string) ([]store.PendingWrite, error) {
+	query := fmt.Sprintf(`
+		SELECT task_id, node_name, value, timestamp
+		FROM %s
+		WHERE checkpoint_id = $1
+		ORDER BY seq ASC
+	`, s.writesTable())
+
+	rows, err := s.pool.Query(ctx, query, checkpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending writes: %w", err)
+	}
+	defer rows.Close()
+
+	var writes []store.PendingWrite
+	for rows.Next() {
+		var w store.PendingWrite
+		var valueJSON []byte
+		if err := rows.Scan(&w.TaskID, &w.Node, &valueJSON, &w.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan pending write row: %w", err)
+		}
+		if err := json.Unmarshal(valueJSON, &w.Value); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending write: %w", err)
+		}
+		value, err := store.DecodeValue(s.serializer, w.Value)
+		if err != nil {
+			return nil, err
+		}
+		w.Value = value
+		writes = append(writes, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending write rows: %w", err)
+	}
+
+	return writes, nil
+}
+
+// DeleteWrites removes the pending writes recorded on top of a checkpoint
+func (s *PostgresCheckpointStore) DeleteWrites(ctx context.Context, checkpointID string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE checkpoint_id = $1", s.writesTable())
+	if _, err := s.pool.Exec(ctx, query, checkpointID); err != nil {
+		return fmt.Errorf("failed to delete pending writes: %w", err)
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,6 +47,7 @@ func TestPostgresCheckpointStore_Save(t *testing.T) {
 			metadataJSON,
 			cp.Timestamp,
 			cp.Version,
+			"", // parent_id
 		).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
@@ -70,10 +72,10 @@ func TestPostgresCheckpointStore_Load(t *testing.T) {
 	stateJSON, _ := json.Marshal(state)
 	metadataJSON, _ := json.Marshal(metadata)
 
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"}).
-		AddRow(cpID, "node-a", stateJSON, metadataJSON, timestamp, 1)
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow(cpID, "node-a", stateJSON, metadataJSON, timestamp, 1, "")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnRows(rows)
 
@@ -121,6 +123,7 @@ func TestPostgresCheckpointStore_Save_WithoutExecutionID(t *testing.T) {
 			metadataJSON,
 			cp.Timestamp,
 			cp.Version,
+			"", // parent_id
 		).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
@@ -160,7 +163,7 @@ func TestPostgresCheckpoint_Load_NotFound(t *testing.T) {
 
 	cpID := "non-existent"
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnError(pgx.ErrNoRows)
 
@@ -182,7 +185,7 @@ func TestPostgresCheckpoint_Load_DatabaseError(t *testing.T) {
 	cpID := "cp-1"
 	dbError := errors.New("database connection failed")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnError(dbError)
 
@@ -205,10 +208,10 @@ func TestPostgresCheckpoint_Load_InvalidStateJSON(t *testing.T) {
 	timestamp := time.Now()
 
 	// Create row with invalid JSON
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"}).
-		AddRow(cpID, "node-a", []byte("{invalid json"), []byte("{}"), timestamp, 1)
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow(cpID, "node-a", []byte("{invalid json"), []byte("{}"), timestamp, 1, "")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnRows(rows)
 
@@ -233,10 +236,10 @@ func TestPostgresCheckpoint_Load_InvalidMetadataJSON(t *testing.T) {
 	stateJSON, _ := json.Marshal(state)
 
 	// Create row with invalid metadata JSON
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"}).
-		AddRow(cpID, "node-a", stateJSON, []byte("{invalid metadata json"), timestamp, 1)
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow(cpID, "node-a", stateJSON, []byte("{invalid metadata json"), timestamp, 1, "")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnRows(rows)
 
@@ -261,10 +264,10 @@ func TestPostgresCheckpoint_Load_NilMetadata(t *testing.T) {
 	stateJSON, _ := json.Marshal(state)
 
 	// Create row with nil metadata
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"}).
-		AddRow(cpID, "node-a", stateJSON, nil, timestamp, 1)
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow(cpID, "node-a", stateJSON, nil, timestamp, 1, "")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnRows(rows)
 
@@ -314,14 +317,14 @@ func TestPostgresCheckpointStore_List(t *testing.T) {
 		},
 	}
 
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"})
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"})
 	for _, cp := range checkpoints {
 		stateJSON, _ := json.Marshal(cp.state)
 		metadataJSON, _ := json.Marshal(cp.metadata)
-		rows.AddRow(cp.id, cp.nodeName, stateJSON, metadataJSON, timestamp, cp.version)
+		rows.AddRow(cp.id, cp.nodeName, stateJSON, metadataJSON, timestamp, cp.version, "")
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
 		WithArgs(executionID).
 		WillReturnRows(rows)
 
@@ -351,9 +354,9 @@ func TestPostgresCheckpointStore_List_EmptyResult(t *testing.T) {
 
 	executionID := "exec-empty"
 
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"})
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"})
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
 		WithArgs(executionID).
 		WillReturnRows(rows)
 
@@ -374,7 +377,7 @@ func TestPostgresCheckpointStore_List_DatabaseError(t *testing.T) {
 	executionID := "exec-1"
 	dbError := errors.New("database connection failed")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
 		WithArgs(executionID).
 		WillReturnError(dbError)
 
@@ -395,11 +398,11 @@ func TestPostgresCheckpointStore_List_ScanError(t *testing.T) {
 
 	executionID := "exec-1"
 
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"}).
-		AddRow("cp-1", "node-a", []byte("{invalid"), []byte("{}"), time.Now(), 1).
-		AddRow("cp-2", "node-b", []byte("{}"), []byte("{}"), time.Now(), 2)
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow("cp-1", "node-a", []byte("{invalid"), []byte("{}"), time.Now(), 1, "").
+		AddRow("cp-2", "node-b", []byte("{}"), []byte("{}"), time.Now(), 2, "")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE execution_id = $1 ORDER BY timestamp ASC")).
 		WithArgs(executionID).
 		WillReturnRows(rows)
 
@@ -508,7 +511,8 @@ func TestPostgresCheckpointStore_InitSchema(t *testing.T) {
 			state JSONB NOT NULL,
 			metadata JSONB,
 			timestamp TIMESTAMPTZ NOT NULL,
-			version INTEGER NOT NULL
+			version INTEGER NOT NULL,
+			parent_id TEXT
 		);
 		CREATE INDEX IF NOT EXISTS idx_checkpoints_execution_id ON checkpoints (execution_id);
 		CREATE INDEX IF NOT EXISTS idx_checkpoints_thread_id ON checkpoints (thread_id);
@@ -539,7 +543,8 @@ func TestPostgresCheckpointStore_InitSchema_CustomTable(t *testing.T) {
 			state JSONB NOT NULL,
 			metadata JSONB,
 			timestamp TIMESTAMPTZ NOT NULL,
-			version INTEGER NOT NULL
+			version INTEGER NOT NULL,
+			parent_id TEXT
 		);
 		CREATE INDEX IF NOT EXISTS idx_custom_checkpoints_execution_id ON custom_checkpoints (execution_id);
 		CREATE INDEX IF NOT EXISTS idx_custom_checkpoints_thread_id ON custom_checkpoints (thread_id);
@@ -628,6 +633,7 @@ func TestPostgresCheckpointStore_Save_Conflict(t *testing.T) {
 			metadataJSON,
 			cp.Timestamp,
 			cp.Version,
+			"", // parent_id
 		).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
@@ -669,6 +675,7 @@ func TestPostgresCheckpointStore_Save_DatabaseError(t *testing.T) {
 			metadataJSON,
 			cp.Timestamp,
 			cp.Version,
+			"", // parent_id
 		).
 		WillReturnError(dbError)
 
@@ -714,3 +721,206 @@ func TestNewPostgresCheckpointStore_InvalidConnection(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unable to create connection pool")
 }
+
+func TestPostgresCheckpointStore_PendingWrites(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+	ctx := context.Background()
+	timestamp := time.Now()
+
+	valueJSON, _ := json.Marshal(map[string]any{"a": "done"})
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints_writes (checkpoint_id, task_id, node_name, value, timestamp)")).
+		WithArgs("cp-1", "0:a", "a", valueJSON, timestamp).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = s.PutWrites(ctx, "cp-1", []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: map[string]any{"a": "done"}, Timestamp: timestamp},
+	})
+	assert.NoError(t, err)
+
+	rows := pgxmock.NewRows([]string{"task_id", "node_name", "value", "timestamp"}).
+		AddRow("0:a", "a", valueJSON, timestamp)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT task_id, node_name, value, timestamp FROM checkpoints_writes WHERE checkpoint_id = $1 ORDER BY seq ASC")).
+		WithArgs("cp-1").
+		WillReturnRows(rows)
+
+	writes, err := s.GetWrites(ctx, "cp-1")
+	assert.NoError(t, err)
+	assert.Len(t, writes, 1)
+	assert.Equal(t, "a", writes[0].Node)
+	assert.Equal(t, map[string]any{"a": "done"}, writes[0].Value)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM checkpoints_writes WHERE checkpoint_id = $1")).
+		WithArgs("cp-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	assert.NoError(t, s.DeleteWrites(ctx, "cp-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_ParentID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+	timestamp := time.Now()
+
+	cp := &graph.Checkpoint{
+		ID:        "cp-2",
+		ParentID:  "cp-1",
+		NodeName:  "node-b",
+		State:     map[string]any{},
+		Timestamp: timestamp,
+		Version:   2,
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}
+	stateJSON, _ := json.Marshal(cp.State)
+	metadataJSON, _ := json.Marshal(cp.Metadata)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints")).
+		WithArgs(cp.ID, "exec-1", "", cp.NodeName, stateJSON, metadataJSON, timestamp, cp.Version, "cp-1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	assert.NoError(t, s.Save(context.Background(), cp))
+
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow(cp.ID, cp.NodeName, stateJSON, metadataJSON, timestamp, cp.Version, "cp-1")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
+		WithArgs(cp.ID).
+		WillReturnRows(rows)
+
+	loaded, err := s.Load(context.Background(), cp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cp-1", loaded.ParentID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_ListThreadIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT thread_id FROM checkpoints WHERE thread_id IS NOT NULL AND thread_id <> ''")).
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id"}).AddRow("thread-1").AddRow("thread-2"))
+
+	threadIDs, err := s.ListThreadIDs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"thread-1", "thread-2"}, threadIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_CountUnthreaded(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM checkpoints WHERE thread_id IS NULL OR thread_id = ''")).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := s.CountUnthreaded(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_CheckSchema(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+	query := regexp.QuoteMeta("WHERE table_name = $1 AND column_name IN ('thread_id', 'parent_id') ) = 2 AND to_regclass($2) IS NOT NULL AND to_regclass($3) IS NOT NULL")
+
+	mock.ExpectQuery(query).
+		WithArgs("checkpoints", "checkpoints_writes", "checkpoints_threads").
+		WillReturnRows(pgxmock.NewRows([]string{"current"}).AddRow(true))
+	assert.NoError(t, s.CheckSchema(context.Background()))
+
+	// Tables created before MigrateSchema added columns and tables are out of date
+	mock.ExpectQuery(query).
+		WithArgs("checkpoints", "checkpoints_writes", "checkpoints_threads").
+		WillReturnRows(pgxmock.NewRows([]string{"current"}).AddRow(false))
+	assert.ErrorIs(t, s.CheckSchema(context.Background()), store.ErrSchemaOutdated)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// captureArg is a pgxmock argument matching any value and keeping it.
+type captureArg struct {
+	value any
+}
+
+func (c *captureArg) Match(v any) bool {
+	c.value = v
+	return true
+}
+
+func TestPostgresCheckpointStore_Serializer(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	serializer, err := store.NewAESGCMSerializer(store.GobSerializer{}, map[string][]byte{"k1": key}, "k1")
+	assert.NoError(t, err)
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints", store.WithSerializer(serializer))
+	ctx := context.Background()
+	timestamp := time.Now()
+
+	cp := &graph.Checkpoint{
+		ID:        "cp-1",
+		NodeName:  "node-a",
+		State:     map[string]any{"secret": "s3cr3t", "count": 3},
+		Timestamp: timestamp,
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}
+	metadataJSON, _ := json.Marshal(cp.Metadata)
+
+	// The state column holds the encrypted state, not its JSON
+	state := &captureArg{}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints")).
+		WithArgs(cp.ID, "exec-1", "", cp.NodeName, state, metadataJSON, timestamp, cp.Version, "").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	assert.NoError(t, s.Save(ctx, cp))
+	assert.NotContains(t, string(state.value.([]byte)), "s3cr3t")
+
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "parent_id"}).
+		AddRow(cp.ID, cp.NodeName, state.value, metadataJSON, timestamp, cp.Version, "")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '') FROM checkpoints WHERE id = $1")).
+		WithArgs(cp.ID).
+		WillReturnRows(rows)
+
+	// Gob keeps the Go types of the values
+	loaded, err := s.Load(ctx, cp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, cp.State, loaded.State)
+
+	// Pending write values go through the serializer too
+	value := &captureArg{}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints_writes (checkpoint_id, task_id, node_name, value, timestamp)")).
+		WithArgs("cp-1", "0:a", "a", value, timestamp).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	assert.NoError(t, s.PutWrites(ctx, "cp-1", []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: map[string]any{"a": 1}, Timestamp: timestamp},
+	}))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT task_id, node_name, value, timestamp FROM checkpoints_writes WHERE checkpoint_id = $1 ORDER BY seq ASC")).
+		WithArgs("cp-1").
+		WillReturnRows(pgxmock.NewRows([]string{"task_id", "node_name", "value", "timestamp"}).AddRow("0:a", "a", value.value, timestamp))
+	writes, err := s.GetWrites(ctx, "cp-1")
+	assert.NoError(t, err)
+	if assert.Len(t, writes, 1) {
+		assert.Equal(t, map[string]any{"a": 1}, writes[0].Value)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
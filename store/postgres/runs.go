@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// PostgresRunStore implements store.RunStore using PostgreSQL
+type PostgresRunStore struct {
+	pool      DBPool
+	tableName string
+}
+
+// NewPostgresRunStore creates a run store on an existing connection pool.
+// The table (default "runs") is created by InitSchema.
+func NewPostgresRunStore(pool DBPool, tableName string) *PostgresRunStore {
+	if tableName == "" {
+		tableName = "runs"
+	}
+	return &PostgresRunStore{
+		pool:      pool,
+		tableName: tableName,
+	}
+}
+
+// RunStore returns a run store that shares the store's connection pool.
+// Runs are kept in the "<checkpoint table>_runs" table.
+func (s *PostgresCheckpointStore) RunStore() *PostgresRunStore {
+	return NewPostgresRunStore(s.pool, s.tableName+"_runs")
+}
+
+// InitSchema creates the runs table if it doesn't exist
+func (r *PostgresRunStore) InitSchema(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			thread_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			input JSONB,
+			resume_value JSONB,
+			error TEXT,
+			metadata JSONB,
+			attempts INTEGER NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_%s_thread_id ON %s (thread_id);
+		CREATE INDEX IF NOT EXISTS idx_%s_status ON %s (status);
+	`, r.tableName, r.tableName, r.tableName, r.tableName, r.tableName)
+
+	if _, err := r.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create runs schema: %w", err)
+	}
+	return nil
+}
+
+// SaveRun creates or replaces a run record
+func (r *PostgresRunStore) SaveRun(ctx context.Context, run *store.Run) error {
+	inputJSON, err := json.Marshal(run.Input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run input: %w", err)
+	}
+	resumeJSON, err := json.Marshal(run.ResumeValue)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run resume value: %w", err)
+	}
+	metadataJSON, err := json.Marshal(run.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run metadata: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, thread_id, status, input, resume_value, error, metadata, attempts, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			thread_id = EXCLUDED.thread_id,
+			status = EXCLUDED.status,
+			input = EXCLUDED.input,
+			resume_value = EXCLUDED.resume_value,
+			error = EXCLUDED.error,
+			metadata = EXCLUDED.metadata,
+			attempts = EXCLUDED.attempts,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at
+	`, r.tableName)
+
+	_, err = r.pool.Exec(ctx, query,
+		run.ID, run.ThreadID, string(run.Status), inputJSON, resumeJSON, run.Error,
+		metadataJSON, run.Attempts, run.CreatedAt, run.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	return nil
+}
+
+// GetRun retrieves a run record by ID
+func (r *PostgresRunStore) GetRun(ctx context.Context, runID string) (*store.Run, error) {
+	query := fmt.Sprintf(`
+		SELECT id, thread_id, status, input, resume_value, error, metadata, attempts, created_at, updated_at
+		FROM %s
+		WHERE id = $1
+	`, r.tableName)
+
+	run, err := scanRun(r.pool.QueryRow(ctx, query, runID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("run not found: %s", runID)
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListRuns returns the runs selected by the filter, oldest first
+func (r *PostgresRunStore) ListRuns(ctx context.Context, filter store.RunFilter) ([]*store.Run, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.ThreadID != "" {
+		args = append(args, filter.ThreadID)
+		conditions = append(conditions, fmt.Sprintf("thread_id = $%d", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		args = append(args, statuses)
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, thread_id, status, input, resume_value, error, metadata, attempts, created_at, updated_at
+		FROM %s
+		%s
+		ORDER BY created_at ASC, id ASC
+	`, r.tableName, where)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*store.Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating run rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+// DeleteRun removes a run record
+func (r *PostgresRunStore) DeleteRun(ctx context.Context, runID string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.tableName)
+	if _, err := r.pool.Exec(ctx, query, runID); err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+	return nil
+}
+
+// scanRun reads a run record from a row
+func scanRun(row pgx.Row) (*store.Run, error) {
+	var (
+		run                                 store.Run
+		status                              string
+		inputJSON, resumeJSON, metadataJSON []byte
+		errorText                           *string
+	)
+	if err := row.Scan(&run.ID, &run.ThreadID, &status, &inputJSON, &resumeJSON, &errorText, &metadataJSON,
+		&run.Attempts, &run.CreatedAt, &run.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan run row: %w", err)
+	}
+	run.Status = store.RunStatus(status)
+	if errorText != nil {
+		run.Error = *errorText
+	}
+
+	if len(inputJSON) > 0 {
+		if err := json.Unmarshal(inputJSON, &run.Input); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run input: %w", err)
+		}
+	}
+	if len(resumeJSON) > 0 {
+		if err := json.Unmarshal(resumeJSON, &run.ResumeValue); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run resume value: %w", err)
+		}
+	}
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &run.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run metadata: %w", err)
+		}
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestPostgresRunStore_SaveRun(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	runs := NewPostgresCheckpointStoreWithPool(mock, "checkpoints").RunStore()
+
+	now := time.Now()
+	run := &store.Run{
+		ID:        "run-1",
+		ThreadID:  "thread-1",
+		Status:    store.RunPending,
+		Input:     map[string]any{"q": "hi"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	inputJSON, _ := json.Marshal(run.Input)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints_runs")).
+		WithArgs(run.ID, run.ThreadID, "pending", inputJSON, []byte("null"), "", []byte("null"), 0, now, now).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	assert.NoError(t, runs.SaveRun(context.Background(), run))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRunStore_GetRun(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	runs := NewPostgresRunStore(mock, "")
+	now := time.Now()
+
+	rows := pgxmock.NewRows([]string{"id", "thread_id", "status", "input", "resume_value", "error", "metadata", "attempts", "created_at", "updated_at"}).
+		AddRow("run-1", "thread-1", "failed", []byte(`{"q":"hi"}`), []byte("null"), ptr("boom"), []byte(`{"user":"ada"}`), 2, now, now)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM runs")).WithArgs("run-1").WillReturnRows(rows)
+
+	run, err := runs.GetRun(context.Background(), "run-1")
+	assert.NoError(t, err)
+	assert.Equal(t, store.RunFailed, run.Status)
+	assert.Equal(t, "boom", run.Error)
+	assert.Equal(t, 2, run.Attempts)
+	assert.Equal(t, map[string]any{"q": "hi"}, run.Input)
+	assert.Nil(t, run.ResumeValue)
+	assert.Equal(t, "ada", run.Metadata["user"])
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM runs")).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+	_, err = runs.GetRun(context.Background(), "missing")
+	assert.ErrorContains(t, err, "run not found: missing")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRunStore_ListRuns(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	runs := NewPostgresRunStore(mock, "")
+	now := time.Now()
+
+	rows := pgxmock.NewRows([]string{"id", "thread_id", "status", "input", "resume_value", "error", "metadata", "attempts", "created_at", "updated_at"}).
+		AddRow("run-1", "thread-1", "running", []byte("null"), []byte("null"), (*string)(nil), []byte("null"), 1, now, now)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE thread_id = $1 AND status = ANY($2)")).
+		WithArgs("thread-1", []string{"pending", "running"}).
+		WillReturnRows(rows)
+
+	list, err := runs.ListRuns(context.Background(), store.RunFilter{
+		ThreadID: "thread-1",
+		Statuses: []store.RunStatus{store.RunPending, store.RunRunning},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, store.RunRunning, list[0].Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// threadsTable returns the name of the thread metadata table
+func (s *PostgresCheckpointStore) threadsTable() string {
+	return s.tableName + "_threads"
+}
+
+// threadsSchema returns the DDL of the thread metadata table and of the indexes of thread queries.
+// The GIN index serves metadata filters of ListThreads.
+func (s *PostgresCheckpointStore) threadsSchema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			thread_id TEXT PRIMARY KEY,
+			metadata JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_%s_updated_at ON %s (updated_at);
+		CREATE INDEX IF NOT EXISTS idx_%s_metadata ON %s USING GIN (metadata);
+		CREATE INDEX IF NOT EXISTS idx_%s_thread_version ON %s (thread_id, version);`,
+		s.threadsTable(), s.threadsTable(), s.threadsTable(), s.threadsTable(), s.threadsTable(), s.tableName, s.tableName)
+}
+
+// threadStats is the number and time span of the checkpoints of a thread
+type threadStats struct {
+	count       int
+	first, last time.Time
+}
+
+// threadStats returns the checkpoint statistics of a thread
+func (s *PostgresCheckpointStore) threadStats(ctx context.Context, threadID string) (threadStats, error) {
+	query := fmt.Sprintf(`
+		SELECT thread_id, COUNT(*), MIN(timestamp), MAX(timestamp)
+		FROM %s
+		WHERE thread_id = $1
+		GROUP BY thread_id
+	`, s.tableName)
+
+	rows, err := s.pool.Query(ctx, query, threadID)
+	if err != nil {
+		return threadStats{}, fmt.Errorf("failed to query thread checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var st threadStats
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id, &st.count, &st.first, &st.last); err != nil {
+			return threadStats{}, fmt.Errorf("failed to scan thread row: %w", err)
+		}
+	}
+	return st, rows.Err()
+}
+
+// threadRecord returns the metadata of a thread, or nil if it has none
+func (s *PostgresCheckpointStore) threadRecord(ctx context.Context, threadID string) (*store.ThreadRecord, error) {
+	query := fmt.Sprintf(`
+		SELECT thread_id, metadata, created_at, updated_at
+		FROM %s
+		WHERE thread_id = $1
+	`, s.threadsTable())
+
+	rows, err := s.pool.Query(ctx, query, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query threads: %w", err)
+	}
+	defer rows.Close()
+
+	var record *store.ThreadRecord
+	for rows.Next() {
+		var (
+			id           string
+			metadataJSON []byte
+		)
+		record = &store.ThreadRecord{}
+		if err := rows.Scan(&id, &metadataJSON, &record.CreatedAt, &record.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan thread row: %w", err)
+		}
+		if err := json.Unmarshal(metadataJSON, &record.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal thread metadata: %w", err)
+		}
+	}
+	return record, rows.Err()
+}
+
+// ListThreads implements store.ThreadStore. The threads are filtered, sorted and paginated in the
+// database: metadata filters use the GIN index, and match values that are equal as JSON.
+func (s *PostgresCheckpointStore) ListThreads(ctx context.Context, filter store.ThreadFilter, page store.Pagination) ([]*store.Thread, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Threads without metadata only match filters without metadata
+	join := "FULL JOIN"
+	if len(filter.Metadata) > 0 {
+		metadataJSON, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+		}
+		join = "LEFT JOIN"
+		// Containment selects candidates with the index, but also matches arrays and objects
+		// holding more than the filter values, so each value is compared as well
+		conditions = append(conditions, "t.metadata @> "+arg(metadataJSON))
+		for _, key := range slices.Sorted(maps.Keys(filter.Metadata)) {
+			valueJSON, err := json.Marshal(filter.Metadata[key])
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+			}
+			conditions = append(conditions, fmt.Sprintf("t.metadata -> %s = %s::jsonb", arg(key), arg(string(valueJSON))))
+		}
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		conditions = append(conditions, "GREATEST(t.updated_at, c.last_at) > "+arg(filter.UpdatedAfter))
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		conditions = append(conditions, "GREATEST(t.updated_at, c.last_at) < "+arg(filter.UpdatedBefore))
+	}
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	limit := ""
+	if page.Limit > 0 {
+		limit += " LIMIT " + arg(page.Limit)
+	}
+	if page.Offset > 0 {
+		limit += " OFFSET " + arg(page.Offset)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COALESCE(t.thread_id, c.thread_id) AS id,
+			COALESCE(t.metadata, '{}'::jsonb),
+			COALESCE(c.checkpoint_count, 0),
+			LEAST(t.created_at, c.first_at),
+			GREATEST(t.updated_at, c.last_at) AS updated
+		FROM %s t
+		%s (
+			SELECT thread_id, COUNT(*) AS checkpoint_count, MIN(timestamp) AS first_at, MAX(timestamp) AS last_at
+			FROM %s
+			WHERE thread_id IS NOT NULL AND thread_id <> ''
+			GROUP BY thread_id
+		) c ON c.thread_id = t.thread_id
+		WHERE %s
+		ORDER BY updated DESC, id ASC%s
+	`, s.threadsTable(), join, s.tableName, where, limit)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query threads: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []*store.Thread{}
+	for rows.Next() {
+		var (
+			thread       store.Thread
+			metadataJSON []byte
+		)
+		if err := rows.Scan(&thread.ID, &metadataJSON, &thread.CheckpointCount, &thread.CreatedAt, &thread.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan thread row: %w", err)
+		}
+		if err := json.Unmarshal(metadataJSON, &thread.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal thread metadata: %w", err)
+		}
+		threads = append(threads, &thread)
+	}
+	return threads, rows.Err()
+}
+
+// GetThread implements store.ThreadStore
+func (s *PostgresCheckpointStore) GetThread(ctx context.Context, threadID string) (*store.Thread, error) {
+	record, err := s.threadRecord(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.threadStats(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := store.NewThread(threadID, record, st.count, st.first, st.last)
+	if thread == nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrThreadNotFound, threadID)
+	}
+	return thread, nil
+}
+
+// UpdateThreadMetadata implements store.ThreadStore. The metadata is merged in the database, so
+// concurrent updates of different keys are all kept.
+func (s *PostgresCheckpointStore) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]any) (*store.Thread, error) {
+	set := make(map[string]any, len(metadata))
+	removed := []string{}
+	for key, value := range metadata {
+		if value == nil {
+			removed = append(removed, key)
+		} else {
+			set[key] = value
+		}
+	}
+
+	setJSON, err := json.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal thread metadata: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (thread_id, metadata, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (thread_id) DO UPDATE SET
+			metadata = (%s.metadata || EXCLUDED.metadata) - $4::text[],
+			updated_at = EXCLUDED.updated_at
+	`, s.threadsTable(), s.threadsTable())
+
+	if _, err := s.pool.Exec(ctx, query, threadID, setJSON, time.Now(), removed); err != nil {
+		return nil, fmt.Errorf("failed to save thread: %w", err)
+	}
+
+	return s.GetThread(ctx, threadID)
+}
+
+// DeleteThread implements store.ThreadStore. Pending writes are removed with their checkpoints
+// through the foreign key.
+func (s *PostgresCheckpointStore) DeleteThread(ctx context.Context, threadID string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE thread_id = $1", s.tableName)
+	if _, err := s.pool.Exec(ctx, query, threadID); err != nil {
+		return fmt.Errorf("failed to delete thread checkpoints: %w", err)
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE thread_id = $1", s.threadsTable())
+	if _, err := s.pool.Exec(ctx, query, threadID); err != nil {
+		return fmt.Errorf("failed to delete thread: %w", err)
+	}
+	return nil
+}
+
+// CopyThread implements store.ThreadStore
+func (s *PostgresCheckpointStore) CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*store.Thread, error) {
+	return store.CopyThread(ctx, s, sourceThreadID, targetThreadID)
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestPostgresCheckpointStore_MigrateSchema_Threads(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+
+	schema := regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS checkpoints_threads (
+			thread_id TEXT PRIMARY KEY,
+			metadata JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_checkpoints_threads_updated_at ON checkpoints_threads (updated_at);
+		CREATE INDEX IF NOT EXISTS idx_checkpoints_threads_metadata ON checkpoints_threads USING GIN (metadata);
+		CREATE INDEX IF NOT EXISTS idx_checkpoints_thread_version ON checkpoints (thread_id, version);`)
+	mock.ExpectExec(schema).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+	mock.ExpectExec(schema).WillReturnResult(pgxmock.NewResult("DO", 0))
+
+	assert.NoError(t, s.InitSchema(context.Background()))
+	assert.NoError(t, s.MigrateSchema(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_GetThread(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT thread_id, metadata, created_at, updated_at FROM checkpoints_threads WHERE thread_id = $1")).
+		WithArgs("thread-1").
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id", "metadata", "created_at", "updated_at"}).
+			AddRow("thread-1", []byte(`{"owner":"alice"}`), now.Add(-time.Hour), now))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT thread_id, COUNT(*), MIN(timestamp), MAX(timestamp) FROM checkpoints WHERE thread_id = $1 GROUP BY thread_id")).
+		WithArgs("thread-1").
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id", "count", "min", "max"}).
+			AddRow("thread-1", 3, now.Add(-2*time.Hour), now.Add(-time.Minute)))
+
+	thread, err := s.GetThread(context.Background(), "thread-1")
+	assert.NoError(t, err)
+	assert.Equal(t, &store.Thread{
+		ID:              "thread-1",
+		Metadata:        map[string]any{"owner": "alice"},
+		CheckpointCount: 3,
+		CreatedAt:       now.Add(-2 * time.Hour),
+		UpdatedAt:       now,
+	}, thread)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checkpoints_threads WHERE thread_id = $1")).
+		WithArgs("missing").
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id", "metadata", "created_at", "updated_at"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checkpoints WHERE thread_id = $1 GROUP BY thread_id")).
+		WithArgs("missing").
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id", "count", "min", "max"}))
+
+	_, err = s.GetThread(context.Background(), "missing")
+	assert.ErrorIs(t, err, store.ErrThreadNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_ListThreads(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+	now := time.Now()
+
+	// Metadata filters use the GIN index and compare each value, and the threads are sorted and
+	// paginated in the database
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checkpoints_threads t LEFT JOIN ( SELECT thread_id, COUNT(*) AS checkpoint_count, MIN(timestamp) AS first_at, MAX(timestamp) AS last_at FROM checkpoints WHERE thread_id IS NOT NULL AND thread_id <> '' GROUP BY thread_id ) c ON c.thread_id = t.thread_id WHERE t.metadata @> $1 AND t.metadata -> $2 = $3::jsonb AND t.metadata -> $4 = $5::jsonb AND GREATEST(t.updated_at, c.last_at) > $6 ORDER BY updated DESC, id ASC LIMIT $7 OFFSET $8")).
+		WithArgs([]byte(`{"owner":"alice","tags":["a"]}`), "owner", `"alice"`, "tags", `["a"]`, now.Add(-time.Hour), 2, 1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "metadata", "checkpoint_count", "created_at", "updated"}).
+			AddRow("thread-2", []byte(`{"owner":"alice","tags":["a"]}`), 0, now, now.Add(time.Minute)).
+			AddRow("thread-1", []byte(`{"owner":"alice","tags":["a"]}`), 2, now.Add(-time.Hour), now))
+
+	threads, err := s.ListThreads(context.Background(), store.ThreadFilter{
+		Metadata:     map[string]any{"owner": "alice", "tags": []string{"a"}},
+		UpdatedAfter: now.Add(-time.Hour),
+	}, store.Pagination{Limit: 2, Offset: 1})
+	assert.NoError(t, err)
+	metadata := map[string]any{"owner": "alice", "tags": []any{"a"}}
+	assert.Equal(t, []*store.Thread{
+		{ID: "thread-2", Metadata: metadata, CreatedAt: now, UpdatedAt: now.Add(time.Minute)},
+		{ID: "thread-1", Metadata: metadata, CheckpointCount: 2, CreatedAt: now.Add(-time.Hour), UpdatedAt: now},
+	}, threads)
+
+	// Without a metadata filter, threads with checkpoints but no metadata are listed too
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checkpoints_threads t FULL JOIN (")).
+		WithArgs().
+		WillReturnRows(pgxmock.NewRows([]string{"id", "metadata", "checkpoint_count", "created_at", "updated"}).
+			AddRow("thread-3", []byte(`{}`), 1, now, now))
+
+	threads, err = s.ListThreads(context.Background(), store.ThreadFilter{}, store.Pagination{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*store.Thread{{ID: "thread-3", Metadata: map[string]any{}, CheckpointCount: 1, CreatedAt: now, UpdatedAt: now}}, threads)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_UpdateThreadMetadata(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+	now := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints_threads (thread_id, metadata, created_at, updated_at) VALUES ($1, $2, $3, $3) ON CONFLICT (thread_id) DO UPDATE SET metadata = (checkpoints_threads.metadata || EXCLUDED.metadata) - $4::text[]")).
+		WithArgs("thread-1", []byte(`{"owner":"alice"}`), pgxmock.AnyArg(), []string{"draft"}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checkpoints_threads WHERE thread_id = $1")).
+		WithArgs("thread-1").
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id", "metadata", "created_at", "updated_at"}).
+			AddRow("thread-1", []byte(`{"owner":"alice"}`), now, now))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checkpoints WHERE thread_id = $1 GROUP BY thread_id")).
+		WithArgs("thread-1").
+		WillReturnRows(pgxmock.NewRows([]string{"thread_id", "count", "min", "max"}))
+
+	thread, err := s.UpdateThreadMetadata(context.Background(), "thread-1", map[string]any{"owner": "alice", "draft": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"owner": "alice"}, thread.Metadata)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresCheckpointStore_DeleteThread(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewPostgresCheckpointStoreWithPool(mock, "checkpoints")
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM checkpoints WHERE thread_id = $1")).
+		WithArgs("thread-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM checkpoints_threads WHERE thread_id = $1")).
+		WithArgs("thread-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	assert.NoError(t, s.DeleteThread(context.Background(), "thread-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisNodeCache implements graph.NodeCache using Redis
+type RedisNodeCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisNodeCache creates a node cache on an existing Redis client.
+// Keys are stored under prefix + "cache:"; the prefix defaults to "langgraph:".
+func NewRedisNodeCache(client *redis.Client, prefix string) *RedisNodeCache {
+	if prefix == "" {
+		prefix = "langgraph:"
+	}
+
+	return &RedisNodeCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// NodeCache returns a node cache that shares the store's Redis connection and key prefix
+func (s *RedisCheckpointStore) NodeCache() *RedisNodeCache {
+	return NewRedisNodeCache(s.client, s.prefix)
+}
+
+func (c *RedisNodeCache) cacheKey(key string) string {
+	return fmt.Sprintf("%scache:%s", c.prefix, key)
+}
+
+// Get retrieves a cached value
+func (c *RedisNodeCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := c.client.Get(ctx, c.cacheKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to load cache entry from redis: %w", err)
+	}
+	return data, true, nil
+}
+
+// Set stores a cached value; Redis expires it after ttl
+func (c *RedisNodeCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, c.cacheKey(key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save cache entry to redis: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a cached value
+func (c *RedisNodeCache) Delete(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, c.cacheKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache entry from redis: %w", err)
+	}
+	return nil
+}
+
+// Clear removes all cached values under the cache prefix
+func (c *RedisNodeCache) Clear(ctx context.Context) error {
+	iter := c.client.Scan(ctx, 0, c.cacheKey("*"), 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan cache entries: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to clear cache entries from redis: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisNodeCache(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	store := NewRedisCheckpointStore(RedisOptions{Addr: mr.Addr()})
+	cache := store.NodeCache()
+	ctx := context.Background()
+
+	_, ok, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), time.Second))
+	assert.True(t, mr.Exists("langgraph:cache:a"))
+
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	mr.FastForward(2 * time.Second)
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Delete(ctx, "a"))
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok)
+
+	// Clear only removes cache entries
+	require.NoError(t, mr.Set("langgraph:checkpoint:keep", "x"))
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), 0))
+	require.NoError(t, cache.Clear(ctx))
+	_, ok, _ = cache.Get(ctx, "c")
+	assert.False(t, ok)
+	assert.True(t, mr.Exists("langgraph:checkpoint:keep"))
+}
@@ -5,17 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
 )
 
 // RedisCheckpointStore implements graph.CheckpointStore using Redis
 type RedisCheckpointStore struct {
-	client *redis.Client
-	prefix string
-	ttl    time.Duration
+	client     *redis.Client
+	prefix     string
+	ttl        time.Duration
+	serializer store.Serializer
 }
 
 // RedisOptions configuration for Redis connection
@@ -27,8 +31,9 @@ type RedisOptions struct {
 	TTL      time.Duration // Expiration for checkpoints, default 0 (no expiration)
 }
 
-// NewRedisCheckpointStore creates a new Redis checkpoint store
-func NewRedisCheckpointStore(opts RedisOptions) *RedisCheckpointStore {
+// NewRedisCheckpointStore creates a new Redis checkpoint store.
+// States and pending write values are stored as plain JSON unless store.WithSerializer is given.
+func NewRedisCheckpointStore(opts RedisOptions, options ...store.Option) *RedisCheckpointStore {
 	client := redis.NewClient(&redis.Options{
 		Addr:     opts.Addr,
 		Password: opts.Password,
@@ -41,9 +46,10 @@ func NewRedisCheckpointStore(opts RedisOptions) *RedisCheckpointStore {
 	}
 
 	return &RedisCheckpointStore{
-		client: client,
-		prefix: prefix,
-		ttl:    opts.TTL,
+		client:     client,
+		prefix:     prefix,
+		ttl:        opts.TTL,
+		serializer: store.NewOptions(options...).Serializer,
 	}
 }
 
@@ -55,13 +61,22 @@ func (s *RedisCheckpointStore) executionKey(id string) string {
 	return fmt.Sprintf("%sexecution:%s:checkpoints", s.prefix, id)
 }
 
+func (s *RedisCheckpointStore) writesKey(id string) string {
+	return fmt.Sprintf("%scheckpoint:%s:writes", s.prefix, id)
+}
+
 func (s *RedisCheckpointStore) threadKey(id string) string {
 	return fmt.Sprintf("%sthread:%s:checkpoints", s.prefix, id)
 }
 
 // Save stores a checkpoint
 func (s *RedisCheckpointStore) Save(ctx context.Context, checkpoint *graph.Checkpoint) error {
-	data, err := json.Marshal(checkpoint)
+	encoded, err := store.EncodeCheckpoint(s.serializer, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encoded)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
@@ -108,11 +123,20 @@ func (s *RedisCheckpointStore) Load(ctx context.Context, checkpointID string) (*
 		return nil, fmt.Errorf("failed to load checkpoint from redis: %w", err)
 	}
 
+	return s.unmarshalCheckpoint(data)
+}
+
+// unmarshalCheckpoint decodes a stored checkpoint
+func (s *RedisCheckpointStore) unmarshalCheckpoint(data []byte) (*graph.Checkpoint, error) {
 	var checkpoint graph.Checkpoint
 	if err := json.Unmarshal(data, &checkpoint); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
 	}
 
+	if err := store.DecodeCheckpoint(s.serializer, &checkpoint); err != nil {
+		return nil, err
+	}
+
 	return &checkpoint, nil
 }
 
@@ -157,6 +181,9 @@ func (s *RedisCheckpointStore) List(ctx context.Context, executionID string) ([]
 			// Log error or skip? Skipping for now
 			continue
 		}
+		if err := store.DecodeCheckpoint(s.serializer, &checkpoint); err != nil {
+			return nil, err
+		}
 		checkpoints = append(checkpoints, &checkpoint)
 
 		// Sanity check ID - should match if order is preserved
@@ -206,6 +233,9 @@ func (s *RedisCheckpointStore) ListByThread(ctx context.Context, threadID string
 		if err := json.Unmarshal([]byte(strData), &checkpoint); err != nil {
 			continue
 		}
+		if err := store.DecodeCheckpoint(s.serializer, &checkpoint); err != nil {
+			return nil, err
+		}
 		checkpoints = append(checkpoints, &checkpoint)
 	}
 
@@ -236,12 +266,56 @@ func (s *RedisCheckpointStore) GetLatestByThread(ctx context.Context, threadID s
 		return nil, fmt.Errorf("failed to load checkpoint %s: %w", latestCheckpointID, err)
 	}
 
-	var checkpoint graph.Checkpoint
-	if err := json.Unmarshal([]byte(data), &checkpoint); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	return s.unmarshalCheckpoint([]byte(data))
+}
+
+// ListThreadIDs implements store.ThreadLister, scanning the thread index keys
+func (s *RedisCheckpointStore) ListThreadIDs(ctx context.Context) ([]string, error) {
+	prefix, suffix := s.prefix+"thread:", ":checkpoints"
+
+	threadIDs := []string{}
+	iter := s.client.Scan(ctx, 0, s.threadKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		threadIDs = append(threadIDs, strings.TrimSuffix(strings.TrimPrefix(key, prefix), suffix))
 	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	return threadIDs, nil
+}
 
-	return &checkpoint, nil
+// CountUnthreaded implements store.UnthreadedCounter, scanning the checkpoint keys and reading
+// the metadata of every checkpoint.
+func (s *RedisCheckpointStore) CountUnthreaded(ctx context.Context) (int, error) {
+	count := 0
+	iter := s.client.Scan(ctx, 0, s.checkpointKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if strings.HasSuffix(key, ":writes") {
+			continue
+		}
+		data, err := s.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read checkpoint %s: %w", key, err)
+		}
+		var checkpoint struct {
+			Metadata map[string]any `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &checkpoint); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+		}
+		if threadID, _ := checkpoint.Metadata["thread_id"].(string); threadID == "" {
+			count++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return 0, fmt.Errorf("failed to count checkpoints without a thread: %w", err)
+	}
+	return count, nil
 }
 
 // Delete removes a checkpoint
@@ -255,7 +329,7 @@ func (s *RedisCheckpointStore) Delete(ctx context.Context, checkpointID string)
 	key := s.checkpointKey(checkpointID)
 	pipe := s.client.Pipeline()
 
-	pipe.Del(ctx, key)
+	pipe.Del(ctx, key, s.writesKey(checkpointID))
 
 	if execID, ok := checkpoint.Metadata["execution_id"].(string); ok && execID != "" {
 		execKey := s.executionKey(execID)
@@ -291,7 +365,7 @@ func (s *RedisCheckpointStore) Clear(ctx context.Context, executionID string) er
 
 	// Delete all checkpoint keys
 	for _, id := range checkpointIDs {
-		pipe.Del(ctx, s.checkpointKey(id))
+		pipe.Del(ctx, s.checkpointKey(id), s.writesKey(id))
 	}
 
 	// Delete execution index
@@ -304,3 +378,71 @@ func (s *RedisCheckpointStore) Clear(ctx context.Context, executionID string) er
 
 	return nil
 }
+
+// PutWrites records pending writes on top of a checkpoint.
+// Writes are kept in a hash keyed by task ID and share the store TTL.
+func (s *RedisCheckpointStore) PutWrites(ctx context.Context, checkpointID string, writes []store.PendingWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	writes, err := store.EncodeWrites(s.serializer, writes)
+	if err != nil {
+		return err
+	}
+
+	key := s.writesKey(checkpointID)
+	pipe := s.client.Pipeline()
+	for _, w := range writes {
+		data, err := json.Marshal(w)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pending write: %w", err)
+		}
+		pipe.HSet(ctx, key, w.TaskID, data)
+	}
+	if s.ttl > 0 {
+		pipe.Expire(ctx, key, s.ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save pending writes: %w", err)
+	}
+	return nil
+}
+
+// GetWrites returns the pending writes recorded on top of a checkpoint, oldest first
+func (s *RedisCheckpointStore) GetWrites(ctx context.Context, checkpointID string) ([]store.PendingWrite, error) {
+	fields, err := s.client.HGetAll(ctx, s.writesKey(checkpointID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending writes: %w", err)
+	}
+
+	writes := make([]store.PendingWrite, 0, len(fields))
+	for _, data := range fields {
+		var w store.PendingWrite
+		if err := json.Unmarshal([]byte(data), &w); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending write: %w", err)
+		}
+		writes = append(writes, w)
+	}
+	if err := store.DecodeWrites(s.serializer, writes); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(writes, func(i, j int) bool {
+		if writes[i].Timestamp.Equal(writes[j].Timestamp) {
+			return writes[i].TaskID < writes[j].TaskID
+		}
+		return writes[i].Timestamp.Before(writes[j].Timestamp)
+	})
+
+	return writes, nil
+}
+
+// DeleteWrites removes the pending writes recorded on top of a checkpoint
+func (s *RedisCheckpointStore) DeleteWrites(ctx context.Context, checkpointID string) error {
+	if err := s.client.Del(ctx, s.writesKey(checkpointID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete pending writes: %w", err)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,3 +87,122 @@ func TestRedisCheckpointStore(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, list, 0)
 }
+
+func TestRedisCheckpointStore_PendingWrites(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	s := NewRedisCheckpointStore(RedisOptions{
+		Addr: mr.Addr(),
+		TTL:  time.Hour,
+	})
+
+	ctx := context.Background()
+	cp := &graph.Checkpoint{
+		ID:        "cp-1",
+		State:     map[string]any{},
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}
+	assert.NoError(t, s.Save(ctx, cp))
+
+	now := time.Now()
+	assert.NoError(t, s.PutWrites(ctx, cp.ID, []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: "first", Timestamp: now},
+		{TaskID: "1:b", Node: "b", Value: "second", Timestamp: now.Add(time.Millisecond)},
+	}))
+	assert.NoError(t, s.PutWrites(ctx, cp.ID, []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: "replaced", Timestamp: now},
+	}))
+	assert.True(t, mr.TTL("langgraph:checkpoint:cp-1:writes") > 0)
+
+	writes, err := s.GetWrites(ctx, cp.ID)
+	assert.NoError(t, err)
+	assert.Len(t, writes, 2)
+	assert.Equal(t, "replaced", writes[0].Value)
+	assert.Equal(t, "1:b", writes[1].TaskID)
+
+	// Deleting the checkpoint removes its writes
+	assert.NoError(t, s.Delete(ctx, cp.ID))
+	writes, err = s.GetWrites(ctx, cp.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, writes)
+}
+
+func TestRedisCheckpointStore_ParentID(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	s := NewRedisCheckpointStore(RedisOptions{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	assert.NoError(t, s.Save(ctx, &graph.Checkpoint{
+		ID:        "cp-2",
+		ParentID:  "cp-1",
+		State:     map[string]any{},
+		Timestamp: time.Now(),
+		Version:   2,
+		Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+	}))
+
+	history, err := store.ListHistory(ctx, s, "thread-1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, "cp-1", history[0].Checkpoint.ParentID)
+}
+
+func TestRedisCheckpointStore_Serializer(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	// Checkpoints written without a serializer stay readable
+	plain := NewRedisCheckpointStore(RedisOptions{Addr: mr.Addr()})
+	assert.NoError(t, plain.Save(ctx, &graph.Checkpoint{
+		ID:        "legacy",
+		State:     map[string]any{"count": float64(1)},
+		Timestamp: time.Now(),
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}))
+
+	serializer, err := store.NewAESGCMSerializer(store.JSONSerializer{}, map[string][]byte{"k1": make([]byte, 32)}, "k1")
+	assert.NoError(t, err)
+	s := NewRedisCheckpointStore(RedisOptions{Addr: mr.Addr()}, store.WithSerializer(serializer))
+
+	assert.NoError(t, s.Save(ctx, &graph.Checkpoint{
+		ID:        "cp-1",
+		State:     map[string]any{"secret": "swordfish"},
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}))
+
+	raw, err := mr.Get("langgraph:checkpoint:cp-1")
+	assert.NoError(t, err)
+	assert.NotContains(t, raw, "swordfish")
+
+	loaded, err := s.Load(ctx, "cp-1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"secret": "swordfish"}, loaded.State)
+
+	legacy, err := s.Load(ctx, "legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": float64(1)}, legacy.State)
+
+	list, err := s.List(ctx, "exec-1")
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	assert.NoError(t, s.PutWrites(ctx, "cp-1", []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: "swordfish", Timestamp: time.Now()},
+	}))
+	writes, err := s.GetWrites(ctx, "cp-1")
+	assert.NoError(t, err)
+	assert.Len(t, writes, 1)
+	assert.Equal(t, "swordfish", writes[0].Value)
+}
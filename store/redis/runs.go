@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// RedisRunStore implements store.RunStore using Redis
+type RedisRunStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRunStore creates a run store on an existing Redis client.
+// Runs are stored under prefix + "run:"; the prefix defaults to "langgraph:".
+// Run records do not expire.
+func NewRedisRunStore(client *redis.Client, prefix string) *RedisRunStore {
+	if prefix == "" {
+		prefix = "langgraph:"
+	}
+
+	return &RedisRunStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// RunStore returns a run store that shares the store's Redis connection and key prefix
+func (s *RedisCheckpointStore) RunStore() *RedisRunStore {
+	return NewRedisRunStore(s.client, s.prefix)
+}
+
+func (r *RedisRunStore) runKey(id string) string {
+	return fmt.Sprintf("%srun:%s", r.prefix, id)
+}
+
+func (r *RedisRunStore) runsKey() string {
+	return fmt.Sprintf("%sruns", r.prefix)
+}
+
+func (r *RedisRunStore) threadRunsKey(id string) string {
+	return fmt.Sprintf("%sthread:%s:runs", r.prefix, id)
+}
+
+// SaveRun creates or replaces a run record.
+// Runs are indexed by creation time, globally and per thread.
+func (r *RedisRunStore) SaveRun(ctx context.Context, run *store.Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+
+	score := float64(run.CreatedAt.UnixNano())
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.runKey(run.ID), data, 0)
+	pipe.ZAdd(ctx, r.runsKey(), redis.Z{Score: score, Member: run.ID})
+	pipe.ZAdd(ctx, r.threadRunsKey(run.ThreadID), redis.Z{Score: score, Member: run.ID})
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save run to redis: %w", err)
+	}
+	return nil
+}
+
+// GetRun retrieves a run record by ID
+func (r *RedisRunStore) GetRun(ctx context.Context, runID string) (*store.Run, error) {
+	data, err := r.client.Get(ctx, r.runKey(runID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("run not found: %s", runID)
+		}
+		return nil, fmt.Errorf("failed to load run from redis: %w", err)
+	}
+
+	var run store.Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal run: %w", err)
+	}
+	return &run, nil
+}
+
+// ListRuns returns the runs selected by the filter, oldest first
+func (r *RedisRunStore) ListRuns(ctx context.Context, filter store.RunFilter) ([]*store.Run, error) {
+	indexKey := r.runsKey()
+	if filter.ThreadID != "" {
+		indexKey = r.threadRunsKey(filter.ThreadID)
+	}
+
+	runIDs, err := r.client.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	if len(runIDs) == 0 {
+		return []*store.Run{}, nil
+	}
+
+	keys := make([]string, len(runIDs))
+	for i, id := range runIDs {
+		keys[i] = r.runKey(id)
+	}
+
+	results, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch runs: %w", err)
+	}
+
+	runs := make([]*store.Run, 0, len(results))
+	for _, result := range results {
+		data, ok := result.(string)
+		if !ok {
+			continue
+		}
+
+		var run store.Run
+		if err := json.Unmarshal([]byte(data), &run); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run: %w", err)
+		}
+		if filter.Match(&run) {
+			runs = append(runs, &run)
+		}
+	}
+
+	return runs, nil
+}
+
+// DeleteRun removes a run record
+func (r *RedisRunStore) DeleteRun(ctx context.Context, runID string) error {
+	run, err := r.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, r.runKey(runID))
+	pipe.ZRem(ctx, r.runsKey(), runID)
+	pipe.ZRem(ctx, r.threadRunsKey(run.ThreadID), runID)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestRedisRunStore(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	ctx := context.Background()
+	runs := NewRedisCheckpointStore(RedisOptions{Addr: mr.Addr(), TTL: time.Hour}).RunStore()
+
+	now := time.Now()
+	run := &store.Run{ID: "run-1", ThreadID: "thread-1", Status: store.RunPending, Input: map[string]any{"q": "hi"}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, runs.SaveRun(ctx, run))
+	require.NoError(t, runs.SaveRun(ctx, &store.Run{ID: "run-2", ThreadID: "thread-2", Status: store.RunRunning, CreatedAt: now.Add(time.Second)}))
+	require.NoError(t, runs.SaveRun(ctx, &store.Run{ID: "run-3", ThreadID: "thread-1", Status: store.RunCancelled, CreatedAt: now.Add(2 * time.Second)}))
+
+	// Run records do not expire with the checkpoints
+	assert.Zero(t, mr.TTL("langgraph:run:run-1"))
+
+	run.Status = store.RunSucceeded
+	require.NoError(t, runs.SaveRun(ctx, run))
+	loaded, err := runs.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.RunSucceeded, loaded.Status)
+	assert.Equal(t, map[string]any{"q": "hi"}, loaded.Input)
+
+	list, err := runs.ListRuns(ctx, store.RunFilter{ThreadID: "thread-1"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "run-1", list[0].ID)
+	assert.Equal(t, "run-3", list[1].ID)
+
+	list, err = runs.ListRuns(ctx, store.RunFilter{Statuses: []store.RunStatus{store.RunRunning}})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "run-2", list[0].ID)
+
+	require.NoError(t, runs.DeleteRun(ctx, "run-1"))
+	_, err = runs.GetRun(ctx, "run-1")
+	assert.ErrorContains(t, err, "run not found")
+	list, err = runs.ListRuns(ctx, store.RunFilter{ThreadID: "thread-1"})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func (s *RedisCheckpointStore) threadMetadataKey(id string) string {
+	return fmt.Sprintf("%sthread:%s:metadata", s.prefix, id)
+}
+
+func (s *RedisCheckpointStore) loadThreadRecord(ctx context.Context, threadID string) (*store.ThreadRecord, error) {
+	data, err := s.client.Get(ctx, s.threadMetadataKey(threadID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load thread %s: %w", threadID, err)
+	}
+
+	var record store.ThreadRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal thread: %w", err)
+	}
+	return &record, nil
+}
+
+// threadCheckpoints returns the IDs and timestamps of the checkpoints of a thread, without
+// decoding their states. Expired checkpoints are skipped.
+func (s *RedisCheckpointStore) threadCheckpoints(ctx context.Context, threadID string) ([]*store.Checkpoint, error) {
+	checkpointIDs, err := s.client.ZRange(ctx, s.threadKey(threadID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints for thread %s: %w", threadID, err)
+	}
+
+	if len(checkpointIDs) == 0 {
+		return nil, nil
+	}
+
+	var keys []string
+	for _, id := range checkpointIDs {
+		keys = append(keys, s.checkpointKey(id))
+	}
+
+	results, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoints: %w", err)
+	}
+
+	var checkpoints []*store.Checkpoint
+	for i, result := range results {
+		strData, ok := result.(string)
+		if !ok {
+			continue
+		}
+
+		var checkpoint struct {
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if err := json.Unmarshal([]byte(strData), &checkpoint); err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, &store.Checkpoint{ID: checkpointIDs[i], Timestamp: checkpoint.Timestamp})
+	}
+	return checkpoints, nil
+}
+
+func (s *RedisCheckpointStore) thread(ctx context.Context, threadID string) (*store.Thread, error) {
+	record, err := s.loadThreadRecord(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints, err := s.threadCheckpoints(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.NewThreadFromCheckpoints(threadID, record, checkpoints), nil
+}
+
+// ListThreads implements store.ThreadStore, scanning the thread index and metadata keys
+func (s *RedisCheckpointStore) ListThreads(ctx context.Context, filter store.ThreadFilter, page store.Pagination) ([]*store.Thread, error) {
+	threadIDs, err := s.ListThreadIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Threads with metadata but no checkpoints only have a metadata key
+	prefix, suffix := s.prefix+"thread:", ":metadata"
+	iter := s.client.Scan(ctx, 0, s.threadMetadataKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		threadIDs = append(threadIDs, strings.TrimSuffix(strings.TrimPrefix(iter.Val(), prefix), suffix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+
+	seen := make(map[string]bool, len(threadIDs))
+	threads := make([]*store.Thread, 0, len(threadIDs))
+	for _, threadID := range threadIDs {
+		if seen[threadID] {
+			continue
+		}
+		seen[threadID] = true
+
+		thread, err := s.thread(ctx, threadID)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, thread)
+	}
+	return store.SelectThreads(threads, filter, page), nil
+}
+
+// GetThread implements store.ThreadStore
+func (s *RedisCheckpointStore) GetThread(ctx context.Context, threadID string) (*store.Thread, error) {
+	thread, err := s.thread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrThreadNotFound, threadID)
+	}
+	return thread, nil
+}
+
+// UpdateThreadMetadata implements store.ThreadStore. The metadata expires with the TTL of the
+// store, like the checkpoints.
+func (s *RedisCheckpointStore) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]any) (*store.Thread, error) {
+	record, err := s.loadThreadRecord(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(store.MergeThreadMetadata(record, metadata, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal thread: %w", err)
+	}
+
+	if err := s.client.Set(ctx, s.threadMetadataKey(threadID), data, s.ttl).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save thread to redis: %w", err)
+	}
+
+	return s.GetThread(ctx, threadID)
+}
+
+// DeleteThread implements store.ThreadStore
+func (s *RedisCheckpointStore) DeleteThread(ctx context.Context, threadID string) error {
+	checkpoints, err := s.threadCheckpoints(ctx, threadID)
+	if err != nil {
+		return err
+	}
+
+	for _, cp := range checkpoints {
+		if err := s.Delete(ctx, cp.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.client.Del(ctx, s.threadKey(threadID), s.threadMetadataKey(threadID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete thread: %w", err)
+	}
+	return nil
+}
+
+// CopyThread implements store.ThreadStore
+func (s *RedisCheckpointStore) CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*store.Thread, error) {
+	return store.CopyThread(ctx, s, sourceThreadID, targetThreadID)
+}
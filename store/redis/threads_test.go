@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestRedisCheckpointStore_Threads(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	ctx := context.Background()
+	s := NewRedisCheckpointStore(RedisOptions{Addr: mr.Addr(), TTL: time.Hour})
+
+	created := time.Now().Add(-time.Hour)
+	for i, id := range []string{"cp-1", "cp-2"} {
+		require.NoError(t, s.Save(ctx, &store.Checkpoint{
+			ID:        id,
+			State:     map[string]any{"step": i + 1},
+			Timestamp: created.Add(time.Duration(i) * time.Minute),
+			Version:   i + 1,
+			Metadata:  map[string]any{"execution_id": "thread-1", "thread_id": "thread-1"},
+		}))
+	}
+	require.NoError(t, s.PutWrites(ctx, "cp-2", []store.PendingWrite{{TaskID: "task-1", Node: "b", Value: "pending"}}))
+
+	thread, err := s.UpdateThreadMetadata(ctx, "thread-1", map[string]any{"owner": "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, thread.CheckpointCount)
+	assert.WithinDuration(t, created, thread.CreatedAt, time.Millisecond)
+
+	// Thread metadata expires with the checkpoints
+	assert.Equal(t, time.Hour, mr.TTL("langgraph:thread:thread-1:metadata"))
+
+	_, err = s.UpdateThreadMetadata(ctx, "draft", map[string]any{"owner": "bob"})
+	require.NoError(t, err)
+
+	threads, err := s.ListThreads(ctx, store.ThreadFilter{}, store.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	assert.Equal(t, "draft", threads[0].ID)
+
+	threads, err = s.ListThreads(ctx, store.ThreadFilter{Metadata: map[string]any{"owner": "alice"}}, store.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, threads, 1)
+	assert.Equal(t, "thread-1", threads[0].ID)
+
+	copied, err := s.CopyThread(ctx, "thread-1", "thread-2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied.CheckpointCount)
+	assert.Equal(t, map[string]any{"owner": "alice"}, copied.Metadata)
+
+	require.NoError(t, s.DeleteThread(ctx, "thread-1"))
+	_, err = s.GetThread(ctx, "thread-1")
+	assert.ErrorIs(t, err, store.ErrThreadNotFound)
+	assert.False(t, mr.Exists("langgraph:checkpoint:cp-2:writes"))
+	assert.False(t, mr.Exists("langgraph:thread:thread-1:metadata"))
+
+	latest, err := s.GetLatestByThread(ctx, "thread-2")
+	require.NoError(t, err)
+	writes, err := s.GetWrites(ctx, latest.ID)
+	require.NoError(t, err)
+	assert.Len(t, writes, 1)
+}
@@ -0,0 +1,41 @@
+// Package retention deletes old checkpoints according to retention policies, for any checkpoint
+// store that can list its threads: the memory, file, SQLite, PostgreSQL and Redis stores.
+//
+// CheckpointConfig.MaxCheckpoints only prunes the checkpoints of the running execution, and
+// RedisOptions.TTL only applies to Redis. A Sweeper applies policies to every thread of a store
+// instead, on demand or in the background:
+//
+//	sweeper := retention.NewSweeper(checkpoints, []retention.Policy{
+//		retention.KeepLast(50),
+//		retention.KeepTerminal(),
+//		retention.DeleteInactiveThreads(30 * 24 * time.Hour),
+//	}, retention.WithInterval(time.Hour))
+//	if err := sweeper.Start(); err != nil {
+//		return err
+//	}
+//	defer sweeper.Stop()
+//
+// # Policies
+//
+// Each policy selects checkpoints to delete, and a checkpoint is deleted when any policy selects
+// it:
+//   - KeepLast keeps the latest checkpoints of each thread
+//   - KeepNewerThan keeps the checkpoints saved recently, and the latest one of each thread
+//   - KeepTerminal keeps only the checkpoints ending a run, once the last run of a thread completed
+//   - DeleteInactiveThreads deletes threads without recent checkpoints
+//
+// Custom policies implement the Policy interface.
+//
+// # Dry Runs
+//
+// Plan reports what a sweep would delete without deleting anything, and WithDryRun makes every
+// sweep of a sweeper a dry run, to try policies on a production store:
+//
+//	report, err := sweeper.Plan(ctx)
+//	fmt.Print(report)
+//
+// # Metrics
+//
+// Metrics returns the totals of the sweeps of a sweeper, and WithReportHandler receives the
+// report of each sweep, to log it or export it to a monitoring system.
+package retention
@@ -0,0 +1,157 @@
+package retention
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// Thread is a thread and its checkpoints, as seen by the policies.
+type Thread struct {
+	ID string
+
+	// Checkpoints are sorted by version, oldest first
+	Checkpoints []*store.Checkpoint
+}
+
+// Latest returns the latest checkpoint of the thread, or nil if it has none.
+func (t *Thread) Latest() *store.Checkpoint {
+	if len(t.Checkpoints) == 0 {
+		return nil
+	}
+	return t.Checkpoints[len(t.Checkpoints)-1]
+}
+
+// Finished reports whether the last run of the thread completed, i.e. its latest checkpoint
+// is terminal.
+func (t *Thread) Finished() bool {
+	latest := t.Latest()
+	return latest != nil && IsTerminal(latest)
+}
+
+// IsTerminal reports whether a checkpoint was saved at the end of a run: no nodes other than
+// END were scheduled after its step.
+func IsTerminal(cp *store.Checkpoint) bool {
+	next, ok := cp.Metadata["next"]
+	if !ok || next == nil {
+		return false
+	}
+	v := reflect.ValueOf(next)
+	if v.Kind() != reflect.Slice {
+		return false
+	}
+	for i := range v.Len() {
+		if node, ok := v.Index(i).Interface().(string); !ok || node != graph.END {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy selects checkpoints to delete. Policies are limits: a checkpoint is deleted when any
+// policy of a sweeper selects it, and a thread is deleted when all its checkpoints are.
+type Policy interface {
+	// Name identifies the policy in reports
+	Name() string
+
+	// Select returns the checkpoints of the thread to delete
+	Select(thread *Thread, now time.Time) []*store.Checkpoint
+}
+
+type keepLast struct {
+	n int
+}
+
+// KeepLast keeps the n latest checkpoints of each thread.
+func KeepLast(n int) Policy {
+	return keepLast{n: max(n, 1)}
+}
+
+func (p keepLast) Name() string {
+	return fmt.Sprintf("keep_last(%d)", p.n)
+}
+
+func (p keepLast) Select(thread *Thread, _ time.Time) []*store.Checkpoint {
+	if len(thread.Checkpoints) <= p.n {
+		return nil
+	}
+	return thread.Checkpoints[:len(thread.Checkpoints)-p.n]
+}
+
+type keepNewerThan struct {
+	age time.Duration
+}
+
+// KeepNewerThan keeps the checkpoints saved within age, and the latest checkpoint of each
+// thread so that idle threads can still be resumed. Use DeleteInactiveThreads to delete
+// idle threads.
+func KeepNewerThan(age time.Duration) Policy {
+	return keepNewerThan{age: age}
+}
+
+func (p keepNewerThan) Name() string {
+	return fmt.Sprintf("keep_newer_than(%s)", p.age)
+}
+
+func (p keepNewerThan) Select(thread *Thread, now time.Time) []*store.Checkpoint {
+	cutoff := now.Add(-p.age)
+	var selected []*store.Checkpoint
+	for _, cp := range thread.Checkpoints[:max(len(thread.Checkpoints)-1, 0)] {
+		if cp.Timestamp.Before(cutoff) {
+			selected = append(selected, cp)
+		}
+	}
+	return selected
+}
+
+type keepTerminal struct{}
+
+// KeepTerminal keeps only the terminal checkpoints of finished threads: the checkpoints saved
+// at the end of each run. Threads whose last run did not complete are left as they are, so
+// that they can be resumed.
+func KeepTerminal() Policy {
+	return keepTerminal{}
+}
+
+func (keepTerminal) Name() string {
+	return "keep_terminal"
+}
+
+func (keepTerminal) Select(thread *Thread, _ time.Time) []*store.Checkpoint {
+	if !thread.Finished() {
+		return nil
+	}
+	var selected []*store.Checkpoint
+	for _, cp := range thread.Checkpoints {
+		if !IsTerminal(cp) {
+			selected = append(selected, cp)
+		}
+	}
+	return selected
+}
+
+type deleteInactiveThreads struct {
+	after time.Duration
+}
+
+// DeleteInactiveThreads deletes whole threads without checkpoints saved within after.
+func DeleteInactiveThreads(after time.Duration) Policy {
+	return deleteInactiveThreads{after: after}
+}
+
+func (p deleteInactiveThreads) Name() string {
+	return fmt.Sprintf("delete_inactive_threads(%s)", p.after)
+}
+
+func (p deleteInactiveThreads) Select(thread *Thread, now time.Time) []*store.Checkpoint {
+	cutoff := now.Add(-p.after)
+	for _, cp := range thread.Checkpoints {
+		if !cp.Timestamp.Before(cutoff) {
+			return nil
+		}
+	}
+	return thread.Checkpoints
+}
@@ -0,0 +1,86 @@
+package retention
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newThread returns a thread with a checkpoint per age, oldest first. Checkpoints with a
+// negative age are terminal, the others scheduled more nodes.
+func newThread(ages ...time.Duration) *Thread {
+	thread := &Thread{ID: "thread-1"}
+	for i, age := range ages {
+		next := []any{"node"}
+		if age < 0 {
+			age, next = -age, []any{}
+		}
+		thread.Checkpoints = append(thread.Checkpoints, &store.Checkpoint{
+			ID:        fmt.Sprintf("cp-%d", i+1),
+			Version:   i + 1,
+			Timestamp: now.Add(-age),
+			Metadata:  map[string]any{"next": next},
+		})
+	}
+	return thread
+}
+
+func ids(checkpoints []*store.Checkpoint) []string {
+	result := []string{}
+	for _, cp := range checkpoints {
+		result = append(result, cp.ID)
+	}
+	return result
+}
+
+func TestKeepLast(t *testing.T) {
+	thread := newThread(4*time.Hour, 3*time.Hour, 2*time.Hour, time.Hour)
+	assert.Equal(t, []string{"cp-1", "cp-2"}, ids(KeepLast(2).Select(thread, now)))
+	assert.Empty(t, ids(KeepLast(4).Select(thread, now)))
+	// The latest checkpoint is always kept
+	assert.Equal(t, []string{"cp-1", "cp-2", "cp-3"}, ids(KeepLast(0).Select(thread, now)))
+	assert.Equal(t, "keep_last(1)", KeepLast(0).Name())
+}
+
+func TestKeepNewerThan(t *testing.T) {
+	thread := newThread(4*time.Hour, 3*time.Hour, 2*time.Hour, time.Hour)
+	assert.Equal(t, []string{"cp-1", "cp-2"}, ids(KeepNewerThan(150*time.Minute).Select(thread, now)))
+
+	// The latest checkpoint of an idle thread is kept
+	assert.Equal(t, []string{"cp-1", "cp-2", "cp-3"}, ids(KeepNewerThan(time.Minute).Select(thread, now)))
+	assert.Empty(t, ids(KeepNewerThan(time.Minute).Select(&Thread{}, now)))
+}
+
+func TestKeepTerminal(t *testing.T) {
+	// Two runs: the first ended at cp-2, the second at cp-4
+	finished := newThread(4*time.Hour, -3*time.Hour, 2*time.Hour, -time.Hour)
+	assert.True(t, finished.Finished())
+	assert.Equal(t, []string{"cp-1", "cp-3"}, ids(KeepTerminal().Select(finished, now)))
+
+	// Threads that are not finished can be resumed from any checkpoint
+	running := newThread(4*time.Hour, -3*time.Hour, 2*time.Hour)
+	assert.False(t, running.Finished())
+	assert.Empty(t, ids(KeepTerminal().Select(running, now)))
+
+	// Checkpoints without next nodes recorded, e.g. saved by UpdateState, are not terminal
+	assert.False(t, IsTerminal(&store.Checkpoint{}))
+	assert.True(t, IsTerminal(&store.Checkpoint{Metadata: map[string]any{"next": []string{}}}))
+
+	// Completed runs schedule END, which reads back as []any from JSON stores
+	assert.True(t, IsTerminal(&store.Checkpoint{Metadata: map[string]any{"next": []string{graph.END}}}))
+	assert.True(t, IsTerminal(&store.Checkpoint{Metadata: map[string]any{"next": []any{graph.END}}}))
+	assert.False(t, IsTerminal(&store.Checkpoint{Metadata: map[string]any{"next": []any{graph.END, "node"}}}))
+}
+
+func TestDeleteInactiveThreads(t *testing.T) {
+	thread := newThread(48*time.Hour, 25*time.Hour)
+	assert.Equal(t, []string{"cp-1", "cp-2"}, ids(DeleteInactiveThreads(24*time.Hour).Select(thread, now)))
+	assert.Empty(t, ids(DeleteInactiveThreads(30*time.Hour).Select(thread, now)))
+}
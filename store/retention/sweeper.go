@@ -0,0 +1,322 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/log"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// ErrThreadsUnsupported is returned when sweeping a store that cannot list its threads.
+var ErrThreadsUnsupported = errors.New("store does not implement store.ThreadLister")
+
+// Option configures a Sweeper.
+type Option func(*options)
+
+type options struct {
+	interval time.Duration
+	dryRun   bool
+	onSweep  func(*Report)
+}
+
+// WithInterval sets the time between background sweeps. The default is an hour.
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		if interval > 0 {
+			o.interval = interval
+		}
+	}
+}
+
+// WithDryRun makes sweeps report the checkpoints they would delete without deleting them.
+func WithDryRun(dryRun bool) Option {
+	return func(o *options) {
+		o.dryRun = dryRun
+	}
+}
+
+// WithReportHandler sets a function called with the report of each sweep, e.g. to log it or
+// to export it as metrics.
+func WithReportHandler(fn func(*Report)) Option {
+	return func(o *options) {
+		o.onSweep = fn
+	}
+}
+
+// Deletion is a checkpoint deleted by a sweep, or to delete for a dry run.
+type Deletion struct {
+	ThreadID     string
+	CheckpointID string
+	Version      int
+	Timestamp    time.Time
+
+	// Policies are the names of the policies that selected the checkpoint
+	Policies []string
+}
+
+// Report describes a sweep.
+type Report struct {
+	DryRun    bool
+	StartedAt time.Time
+	Duration  time.Duration
+
+	// ThreadsScanned and CheckpointsScanned count the threads and checkpoints examined
+	ThreadsScanned     int
+	CheckpointsScanned int
+
+	// Deletions are the checkpoints deleted, or to delete for a dry run, newest first per thread
+	Deletions []Deletion
+
+	// DeletedThreads are the threads all of whose checkpoints were deleted. Stores implementing
+	// store.ThreadStore delete them with DeleteThread, with their metadata.
+	DeletedThreads []string
+
+	// Errors are the failures to list or delete the checkpoints of a thread
+	Errors []error
+}
+
+// String formats the report for people, listing the deletions.
+func (r *Report) String() string {
+	var b strings.Builder
+	verb := "deleted"
+	if r.DryRun {
+		verb = "would delete"
+	}
+	fmt.Fprintf(&b, "retention sweep at %s (%s): scanned %d checkpoints in %d threads, %s %d checkpoints and %d threads\n",
+		r.StartedAt.Format(time.RFC3339), r.Duration.Round(time.Millisecond),
+		r.CheckpointsScanned, r.ThreadsScanned, verb, len(r.Deletions), len(r.DeletedThreads))
+	for _, d := range r.Deletions {
+		fmt.Fprintf(&b, "  %s %s (thread %s, version %d, %s): %s\n",
+			verb, d.CheckpointID, d.ThreadID, d.Version, d.Timestamp.Format(time.RFC3339), strings.Join(d.Policies, ", "))
+	}
+	for _, err := range r.Errors {
+		fmt.Fprintf(&b, "  error: %v\n", err)
+	}
+	return b.String()
+}
+
+// Metrics are the totals of the sweeps of a Sweeper. Dry runs count as sweeps but delete nothing.
+type Metrics struct {
+	Sweeps             int64
+	Errors             int64
+	CheckpointsScanned int64
+	CheckpointsDeleted int64
+	ThreadsDeleted     int64
+
+	LastSweep    time.Time
+	LastDuration time.Duration
+}
+
+// Sweeper deletes the checkpoints selected by retention policies, across all the threads of
+// a store. Sweeps run on demand with Sweep, or in the background between Start and Stop.
+type Sweeper struct {
+	store    store.CheckpointStore
+	policies []Policy
+	opts     options
+
+	mu      sync.Mutex
+	metrics Metrics
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewSweeper creates a sweeper applying policies to the threads of s, which must implement
+// store.ThreadLister. All the bundled stores do.
+func NewSweeper(s store.CheckpointStore, policies []Policy, opts ...Option) *Sweeper {
+	o := options{interval: time.Hour}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Sweeper{store: s, policies: policies, opts: o}
+}
+
+// Plan returns the report of a dry run, whether or not the sweeper was created with one.
+func (s *Sweeper) Plan(ctx context.Context) (*Report, error) {
+	return s.sweep(ctx, true)
+}
+
+// Sweep deletes the checkpoints selected by the policies, or only reports them for a dry run.
+// Failures on single threads are recorded in the report and joined in the returned error.
+func (s *Sweeper) Sweep(ctx context.Context) (*Report, error) {
+	return s.sweep(ctx, s.opts.dryRun)
+}
+
+func (s *Sweeper) sweep(ctx context.Context, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun, StartedAt: time.Now()}
+	err := s.run(ctx, report)
+	report.Duration = time.Since(report.StartedAt)
+	if err == nil {
+		err = errors.Join(report.Errors...)
+	}
+
+	s.record(report, err)
+	if s.opts.onSweep != nil {
+		s.opts.onSweep(report)
+	}
+	return report, err
+}
+
+func (s *Sweeper) run(ctx context.Context, report *Report) error {
+	lister, ok := s.store.(store.ThreadLister)
+	if !ok {
+		return ErrThreadsUnsupported
+	}
+	threadIDs, err := lister.ListThreadIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list threads: %w", err)
+	}
+	sort.Strings(threadIDs)
+
+	for _, threadID := range threadIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		checkpoints, err := s.store.ListByThread(ctx, threadID)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("failed to list checkpoints of thread %s: %w", threadID, err))
+			continue
+		}
+		report.ThreadsScanned++
+		report.CheckpointsScanned += len(checkpoints)
+
+		deletions := s.selectDeletions(threadID, checkpoints, report.StartedAt)
+		if len(deletions) == 0 {
+			continue
+		}
+		wholeThread := len(deletions) == len(checkpoints)
+		failed := false
+		if threads, ok := s.store.(store.ThreadStore); ok && wholeThread && !report.DryRun {
+			// Also drop the thread metadata, so that the thread is gone from the store
+			if err := threads.DeleteThread(ctx, threadID); err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("failed to delete thread %s: %w", threadID, err))
+				continue
+			}
+			report.Deletions = append(report.Deletions, deletions...)
+		} else if !report.DryRun {
+			for _, d := range deletions {
+				if err := s.store.Delete(ctx, d.CheckpointID); err != nil {
+					report.Errors = append(report.Errors, fmt.Errorf("failed to delete checkpoint %s of thread %s: %w", d.CheckpointID, threadID, err))
+					failed = true
+					continue
+				}
+				report.Deletions = append(report.Deletions, d)
+			}
+		} else {
+			report.Deletions = append(report.Deletions, deletions...)
+		}
+		if wholeThread && !failed {
+			report.DeletedThreads = append(report.DeletedThreads, threadID)
+		}
+	}
+	return nil
+}
+
+// selectDeletions applies the policies to a thread, and returns its checkpoints to delete,
+// newest first. Deleting the newest first spares stores that keep checkpoints as changes to
+// their parent, such as store.DeltaStore, from rewriting the children of deleted checkpoints.
+func (s *Sweeper) selectDeletions(threadID string, checkpoints []*store.Checkpoint, now time.Time) []Deletion {
+	sorted := append([]*store.Checkpoint(nil), checkpoints...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Version != sorted[j].Version {
+			return sorted[i].Version < sorted[j].Version
+		}
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	thread := &Thread{ID: threadID, Checkpoints: sorted}
+
+	selectedBy := make(map[string][]string)
+	for _, policy := range s.policies {
+		for _, cp := range policy.Select(thread, now) {
+			selectedBy[cp.ID] = append(selectedBy[cp.ID], policy.Name())
+		}
+	}
+
+	var deletions []Deletion
+	for i := len(sorted) - 1; i >= 0; i-- {
+		cp := sorted[i]
+		if policies, ok := selectedBy[cp.ID]; ok {
+			deletions = append(deletions, Deletion{
+				ThreadID:     threadID,
+				CheckpointID: cp.ID,
+				Version:      cp.Version,
+				Timestamp:    cp.Timestamp,
+				Policies:     policies,
+			})
+		}
+	}
+	return deletions
+}
+
+func (s *Sweeper) record(report *Report, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.Sweeps++
+	if err != nil {
+		s.metrics.Errors++
+	}
+	s.metrics.CheckpointsScanned += int64(report.CheckpointsScanned)
+	if !report.DryRun {
+		s.metrics.CheckpointsDeleted += int64(len(report.Deletions))
+		s.metrics.ThreadsDeleted += int64(len(report.DeletedThreads))
+	}
+	s.metrics.LastSweep = report.StartedAt
+	s.metrics.LastDuration = report.Duration
+}
+
+// Metrics returns the totals of the sweeps run so far.
+func (s *Sweeper) Metrics() Metrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.metrics
+}
+
+// Start sweeps in the background: once right away, then at every interval until Stop is called.
+func (s *Sweeper) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return errors.New("retention sweeper is already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.loop(ctx, s.done)
+	return nil
+}
+
+func (s *Sweeper) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.opts.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			log.Error("retention sweep failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the background sweeps and waits for a sweep in progress to return.
+func (s *Sweeper) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
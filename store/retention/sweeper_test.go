@@ -0,0 +1,294 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/file"
+	"github.com/smallnest/langgraphgo/store/memory"
+	"github.com/smallnest/langgraphgo/store/redis"
+	"github.com/smallnest/langgraphgo/store/sqlite"
+)
+
+func newStores(t *testing.T) map[string]store.CheckpointStore {
+	fileStore, err := file.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	sqliteStore, err := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{Path: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteStore.Close() })
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return map[string]store.CheckpointStore{
+		"memory": memory.NewMemoryCheckpointStore(),
+		"file":   fileStore,
+		"sqlite": sqliteStore,
+		"redis":  redis.NewRedisCheckpointStore(redis.RedisOptions{Addr: mr.Addr()}),
+	}
+}
+
+// saveThread saves checkpoints of a thread with the given ages, oldest first. The last one is
+// terminal.
+func saveThread(t *testing.T, s store.CheckpointStore, threadID string, ages ...time.Duration) {
+	t.Helper()
+	for i, age := range ages {
+		next := []string{"node"}
+		if i == len(ages)-1 {
+			next = []string{}
+		}
+		require.NoError(t, s.Save(context.Background(), &store.Checkpoint{
+			ID:        fmt.Sprintf("%s-cp-%d", threadID, i+1),
+			State:     map[string]any{"step": i + 1},
+			Timestamp: time.Now().Add(-age),
+			Version:   i + 1,
+			Metadata: map[string]any{
+				"execution_id": threadID,
+				"thread_id":    threadID,
+				"next":         next,
+			},
+		}))
+	}
+}
+
+func TestSweeper(t *testing.T) {
+	for name, s := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			saveThread(t, s, "active", 5*time.Hour, 4*time.Hour, 3*time.Hour, 2*time.Hour)
+			saveThread(t, s, "idle", 72*time.Hour, 71*time.Hour)
+
+			threadIDs, err := s.(store.ThreadLister).ListThreadIDs(ctx)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"active", "idle"}, threadIDs)
+
+			var reports []*Report
+			sweeper := NewSweeper(s, []Policy{
+				KeepLast(3),
+				KeepNewerThan(210 * time.Minute),
+				DeleteInactiveThreads(48 * time.Hour),
+			}, WithReportHandler(func(r *Report) { reports = append(reports, r) }))
+
+			// Dry runs delete nothing
+			plan, err := sweeper.Plan(ctx)
+			require.NoError(t, err)
+			assert.True(t, plan.DryRun)
+			assert.Equal(t, 2, plan.ThreadsScanned)
+			assert.Equal(t, 6, plan.CheckpointsScanned)
+			assert.Equal(t, []Deletion{
+				{ThreadID: "active", CheckpointID: "active-cp-2", Version: 2, Timestamp: plan.Deletions[0].Timestamp, Policies: []string{"keep_newer_than(3h30m0s)"}},
+				{ThreadID: "active", CheckpointID: "active-cp-1", Version: 1, Timestamp: plan.Deletions[1].Timestamp, Policies: []string{"keep_last(3)", "keep_newer_than(3h30m0s)"}},
+				{ThreadID: "idle", CheckpointID: "idle-cp-2", Version: 2, Timestamp: plan.Deletions[2].Timestamp, Policies: []string{"delete_inactive_threads(48h0m0s)"}},
+				{ThreadID: "idle", CheckpointID: "idle-cp-1", Version: 1, Timestamp: plan.Deletions[3].Timestamp, Policies: []string{"keep_newer_than(3h30m0s)", "delete_inactive_threads(48h0m0s)"}},
+			}, plan.Deletions)
+			assert.Equal(t, []string{"idle"}, plan.DeletedThreads)
+			assert.Contains(t, plan.String(), "would delete active-cp-2 (thread active, version 2")
+
+			checkpoints, err := s.ListByThread(ctx, "active")
+			require.NoError(t, err)
+			assert.Len(t, checkpoints, 4)
+
+			report, err := sweeper.Sweep(ctx)
+			require.NoError(t, err)
+			assert.False(t, report.DryRun)
+			assert.Len(t, report.Deletions, 4)
+
+			checkpoints, err = s.ListByThread(ctx, "active")
+			require.NoError(t, err)
+			assert.Len(t, checkpoints, 2)
+			checkpoints, err = s.ListByThread(ctx, "idle")
+			require.NoError(t, err)
+			assert.Empty(t, checkpoints)
+
+			threadIDs, err = s.(store.ThreadLister).ListThreadIDs(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"active"}, threadIDs)
+
+			// Sweeping again finds nothing to delete
+			report, err = sweeper.Sweep(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, report.Deletions)
+
+			metrics := sweeper.Metrics()
+			assert.Equal(t, int64(3), metrics.Sweeps)
+			assert.Equal(t, int64(4), metrics.CheckpointsDeleted)
+			assert.Equal(t, int64(1), metrics.ThreadsDeleted)
+			assert.Equal(t, int64(14), metrics.CheckpointsScanned)
+			assert.Zero(t, metrics.Errors)
+			assert.Len(t, reports, 3)
+		})
+	}
+}
+
+func TestSweeper_KeepTerminal(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewMemoryCheckpointStore()
+	saveThread(t, s, "finished", 3*time.Hour, 2*time.Hour, time.Hour)
+
+	sweeper := NewSweeper(s, []Policy{KeepTerminal()})
+	report, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Len(t, report.Deletions, 2)
+
+	checkpoints, err := s.ListByThread(ctx, "finished")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, "finished-cp-3", checkpoints[0].ID)
+}
+
+func TestSweeper_KeepTerminalGraphRun(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewMemoryCheckpointStore()
+
+	g := graph.NewCheckpointableStateGraph[map[string]any]()
+	for _, name := range []string{"a", "b"} {
+		g.AddNode(name, name, func(ctx context.Context, state map[string]any) (map[string]any, error) {
+			return map[string]any{name: true}, nil
+		})
+	}
+	g.AddEdge("a", "b")
+	g.AddEdge("b", graph.END)
+	g.SetEntryPoint("a")
+	g.SetSchema(graph.NewMapSchema())
+	g.SetCheckpointConfig(graph.CheckpointConfig{Store: s, AutoSave: true})
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	_, err = runnable.InvokeWithConfig(ctx, map[string]any{}, graph.WithThreadID("finished"))
+	require.NoError(t, err)
+
+	checkpoints, err := s.ListByThread(ctx, "finished")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	last := checkpoints[len(checkpoints)-1]
+
+	// The completed run scheduled only END after its last step
+	report, err := NewSweeper(s, []Policy{KeepTerminal()}).Sweep(ctx)
+	require.NoError(t, err)
+	assert.Len(t, report.Deletions, 1)
+
+	checkpoints, err = s.ListByThread(ctx, "finished")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, last.ID, checkpoints[0].ID)
+}
+
+func TestSweeper_DeleteThread(t *testing.T) {
+	for name, s := range newStores(t) {
+		if _, ok := s.(store.ThreadStore); !ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			threads := s.(store.ThreadStore)
+			saveThread(t, s, "idle", 72*time.Hour, 71*time.Hour)
+			_, err := threads.UpdateThreadMetadata(ctx, "idle", map[string]any{"owner": "alice"})
+			require.NoError(t, err)
+
+			report, err := NewSweeper(s, []Policy{DeleteInactiveThreads(48 * time.Hour)}).Sweep(ctx)
+			require.NoError(t, err)
+			assert.Len(t, report.Deletions, 2)
+			assert.Equal(t, []string{"idle"}, report.DeletedThreads)
+
+			// The metadata of the thread is deleted with its checkpoints
+			_, err = threads.GetThread(ctx, "idle")
+			assert.ErrorIs(t, err, store.ErrThreadNotFound)
+			checkpoints, err := s.ListByThread(ctx, "idle")
+			require.NoError(t, err)
+			assert.Empty(t, checkpoints)
+		})
+	}
+}
+
+func TestSweeper_DeltaStore(t *testing.T) {
+	ctx := context.Background()
+	inner := memory.NewMemoryCheckpointStore()
+	s := store.NewDeltaStore(inner, store.DeltaOptions{})
+	for i := 1; i <= 5; i++ {
+		parentID := ""
+		if i > 1 {
+			parentID = fmt.Sprintf("cp-%d", i-1)
+		}
+		require.NoError(t, s.Save(ctx, &store.Checkpoint{
+			ID:       fmt.Sprintf("cp-%d", i),
+			ParentID: parentID,
+			State:    map[string]any{"count": i},
+			Version:  i,
+			Metadata: map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+		}))
+	}
+
+	_, err := NewSweeper(s, []Policy{KeepLast(2)}).Sweep(ctx)
+	require.NoError(t, err)
+
+	// The remaining checkpoints are rebuilt without their deleted ancestors
+	checkpoints, err := s.ListByThread(ctx, "thread-1")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	assert.Equal(t, map[string]any{"count": 4}, checkpoints[0].State)
+	assert.Equal(t, map[string]any{"count": 5}, checkpoints[1].State)
+}
+
+func TestSweeper_DryRunOption(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewMemoryCheckpointStore()
+	saveThread(t, s, "thread-1", 2*time.Hour, time.Hour)
+
+	sweeper := NewSweeper(s, []Policy{KeepLast(1)}, WithDryRun(true))
+	report, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Deletions, 1)
+	assert.Zero(t, sweeper.Metrics().CheckpointsDeleted)
+
+	checkpoints, err := s.ListByThread(ctx, "thread-1")
+	require.NoError(t, err)
+	assert.Len(t, checkpoints, 2)
+}
+
+// unlistedStore is a store that cannot list its threads.
+type unlistedStore struct {
+	store.CheckpointStore
+}
+
+func TestSweeper_ThreadsUnsupported(t *testing.T) {
+	sweeper := NewSweeper(unlistedStore{memory.NewMemoryCheckpointStore()}, []Policy{KeepLast(1)})
+	_, err := sweeper.Sweep(context.Background())
+	assert.ErrorIs(t, err, ErrThreadsUnsupported)
+	assert.Equal(t, int64(1), sweeper.Metrics().Errors)
+}
+
+func TestSweeper_Background(t *testing.T) {
+	s := memory.NewMemoryCheckpointStore()
+	saveThread(t, s, "thread-1", 2*time.Hour, time.Hour)
+
+	swept := make(chan *Report, 10)
+	sweeper := NewSweeper(s, []Policy{KeepLast(1)},
+		WithInterval(10*time.Millisecond),
+		WithReportHandler(func(r *Report) {
+			select {
+			case swept <- r:
+			default:
+			}
+		}))
+	require.NoError(t, sweeper.Start())
+	assert.Error(t, sweeper.Start())
+
+	// The first sweep runs right away
+	report := <-swept
+	assert.Len(t, report.Deletions, 1)
+	<-swept
+
+	sweeper.Stop()
+	sweeper.Stop()
+	assert.GreaterOrEqual(t, sweeper.Metrics().Sweeps, int64(2))
+}
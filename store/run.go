@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// RunStatus is the status of a queued graph run.
+type RunStatus string
+
+const (
+	// RunPending means the run is queued and has not started yet
+	RunPending RunStatus = "pending"
+	// RunRunning means a worker is executing the run
+	RunRunning RunStatus = "running"
+	// RunInterrupted means the graph interrupted the run; the thread waits to be resumed
+	RunInterrupted RunStatus = "interrupted"
+	// RunSucceeded means the run completed
+	RunSucceeded RunStatus = "succeeded"
+	// RunFailed means the run returned an error
+	RunFailed RunStatus = "failed"
+	// RunCancelled means the run was cancelled before it completed
+	RunCancelled RunStatus = "cancelled"
+)
+
+// Done reports whether the status is final: the run will not be executed again.
+func (s RunStatus) Done() bool {
+	switch s {
+	case RunInterrupted, RunSucceeded, RunFailed, RunCancelled:
+		return true
+	}
+	return false
+}
+
+// Run is the durable record of a graph run executed by a run queue.
+type Run struct {
+	ID       string    `json:"id"`
+	ThreadID string    `json:"thread_id"`
+	Status   RunStatus `json:"status"`
+
+	// Input is the input state of the run
+	Input any `json:"input,omitempty"`
+
+	// ResumeValue is returned by the graph.Interrupt call that interrupted the thread
+	ResumeValue any `json:"resume_value,omitempty"`
+
+	// Error is the error message of a failed run
+	Error string `json:"error,omitempty"`
+
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// Attempts counts the executions of the run, including resumptions after a restart
+	Attempts int `json:"attempts"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RunFilter selects runs in RunStore.ListRuns. Zero fields match every run.
+type RunFilter struct {
+	ThreadID string
+	Statuses []RunStatus
+}
+
+// Match reports whether the run is selected by the filter.
+func (f RunFilter) Match(run *Run) bool {
+	if f.ThreadID != "" && run.ThreadID != f.ThreadID {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if run.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RunStore persists the records of queued graph runs, usually next to the checkpoints of their threads.
+type RunStore interface {
+	// SaveRun creates or replaces a run record
+	SaveRun(ctx context.Context, run *Run) error
+
+	// GetRun retrieves a run record by ID
+	GetRun(ctx context.Context, runID string) (*Run, error)
+
+	// ListRuns returns the runs selected by the filter, oldest first
+	ListRuns(ctx context.Context, filter RunFilter) ([]*Run, error)
+
+	// DeleteRun removes a run record
+	DeleteRun(ctx context.Context, runID string) error
+}
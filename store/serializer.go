@@ -0,0 +1,263 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Serializer encodes checkpoint states and pending write values for storage.
+//
+// The output of Marshal starts with a format header (see AddFormatHeader), so that data written
+// by another serializer, or before serializers existed, can still be recognized. Unmarshal
+// decodes the formats the serializer knows, and plain JSON without a header.
+type Serializer interface {
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte) (any, error)
+}
+
+// Formats of the serializers of this package.
+const (
+	FormatJSON   = "json"
+	FormatGob    = "gob"
+	FormatZstd   = "zstd"
+	FormatAESGCM = "aes-gcm"
+)
+
+// ErrUnsupportedFormat is returned when serialized data has a format the serializer cannot decode.
+var ErrUnsupportedFormat = errors.New("unsupported serialization format")
+
+// formatMagic starts every format header. Legacy data is JSON, which never starts with a NUL byte.
+var formatMagic = []byte{0, 'L', 'G', 'S'}
+
+// formatVersion is the version of the header layout.
+const formatVersion = 1
+
+// AddFormatHeader prefixes a payload with the header of a format: a magic number, the header
+// version and the format name.
+func AddFormatHeader(format string, payload []byte) []byte {
+	data := make([]byte, 0, len(formatMagic)+2+len(format)+len(payload))
+	data = append(data, formatMagic...)
+	data = append(data, formatVersion, byte(len(format)))
+	data = append(data, format...)
+	return append(data, payload...)
+}
+
+// ParseFormatHeader splits serialized data into its format and payload. It returns false for
+// data without a header, such as checkpoints written as plain JSON.
+func ParseFormatHeader(data []byte) (format string, payload []byte, ok bool) {
+	if !bytes.HasPrefix(data, formatMagic) || len(data) < len(formatMagic)+2 {
+		return "", nil, false
+	}
+	rest := data[len(formatMagic):]
+	if rest[0] != formatVersion {
+		return "", nil, false
+	}
+	n := int(rest[1])
+	if len(rest) < 2+n {
+		return "", nil, false
+	}
+	return string(rest[2 : 2+n]), rest[2+n:], true
+}
+
+// JSONSerializer encodes values as JSON. Decoded values are generic: objects become
+// map[string]any and numbers float64, as with checkpoints stored without a serializer.
+type JSONSerializer struct{}
+
+// Marshal implements Serializer.
+func (JSONSerializer) Marshal(value any) ([]byte, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return AddFormatHeader(FormatJSON, payload), nil
+}
+
+// Unmarshal implements Serializer. It also decodes gob data.
+func (JSONSerializer) Unmarshal(data []byte) (any, error) {
+	return unmarshalBase(data)
+}
+
+func init() {
+	// Generic values nested in states, as decoded from JSON
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+// GobSerializer encodes values with encoding/gob, which keeps their Go types. Types stored in
+// interfaces, such as the values of a map[string]any state, must be registered with gob.Register.
+type GobSerializer struct{}
+
+// gobEnvelope lets gob encode values of any type.
+type gobEnvelope struct {
+	Value any
+}
+
+// Marshal implements Serializer.
+func (GobSerializer) Marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobEnvelope{Value: value}); err != nil {
+		return nil, err
+	}
+	return AddFormatHeader(FormatGob, buf.Bytes()), nil
+}
+
+// Unmarshal implements Serializer. It also decodes JSON data.
+func (GobSerializer) Unmarshal(data []byte) (any, error) {
+	return unmarshalBase(data)
+}
+
+// unmarshalBase decodes JSON and gob data, with or without a header.
+func unmarshalBase(data []byte) (any, error) {
+	format, payload, ok := ParseFormatHeader(data)
+	if !ok {
+		format, payload = FormatJSON, data
+	}
+
+	switch format {
+	case FormatJSON:
+		var value any
+		if err := json.Unmarshal(payload, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case FormatGob:
+		var envelope gobEnvelope
+		if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&envelope); err != nil {
+			return nil, err
+		}
+		return envelope.Value, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// serializedKey is the key of the JSON object holding serialized data in stores that keep
+// values in JSON documents or columns.
+const serializedKey = "$serialized"
+
+// EncodeValue converts a state or pending write value to the form a store keeps in JSON: the
+// value itself without a serializer, or an object holding its serialized bytes.
+func EncodeValue(serializer Serializer, value any) (any, error) {
+	if serializer == nil {
+		return value, nil
+	}
+	data, err := serializer.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize value: %w", err)
+	}
+	return map[string]any{serializedKey: data}, nil
+}
+
+// DecodeValue reverses EncodeValue on a value decoded from JSON. Values stored without a
+// serializer are returned as is. Without a serializer, JSON and gob data is still decoded.
+func DecodeValue(serializer Serializer, value any) (any, error) {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) != 1 {
+		return value, nil
+	}
+	var data []byte
+	switch encoded := m[serializedKey].(type) {
+	case []byte:
+		data = encoded
+	case string:
+		// JSON encodes bytes as base64
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("failed to decode serialized value: %w", err)
+		}
+	default:
+		return value, nil
+	}
+	if serializer == nil {
+		serializer = JSONSerializer{}
+	}
+	decoded, err := serializer.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize value: %w", err)
+	}
+	return decoded, nil
+}
+
+// EncodeCheckpoint returns a copy of a checkpoint whose state is encoded with EncodeValue.
+func EncodeCheckpoint(serializer Serializer, checkpoint *Checkpoint) (*Checkpoint, error) {
+	if serializer == nil {
+		return checkpoint, nil
+	}
+	state, err := EncodeValue(serializer, checkpoint.State)
+	if err != nil {
+		return nil, err
+	}
+	encoded := *checkpoint
+	encoded.State = state
+	return &encoded, nil
+}
+
+// DecodeCheckpoint decodes the state of a checkpoint loaded from JSON in place.
+func DecodeCheckpoint(serializer Serializer, checkpoint *Checkpoint) error {
+	state, err := DecodeValue(serializer, checkpoint.State)
+	if err != nil {
+		return fmt.Errorf("checkpoint %s: %w", checkpoint.ID, err)
+	}
+	checkpoint.State = state
+	return nil
+}
+
+// EncodeWrites returns copies of pending writes whose values are encoded with EncodeValue.
+func EncodeWrites(serializer Serializer, writes []PendingWrite) ([]PendingWrite, error) {
+	if serializer == nil {
+		return writes, nil
+	}
+	encoded := make([]PendingWrite, len(writes))
+	for i, w := range writes {
+		value, err := EncodeValue(serializer, w.Value)
+		if err != nil {
+			return nil, err
+		}
+		w.Value = value
+		encoded[i] = w
+	}
+	return encoded, nil
+}
+
+// DecodeWrites decodes the values of pending writes loaded from JSON in place.
+func DecodeWrites(serializer Serializer, writes []PendingWrite) error {
+	for i := range writes {
+		value, err := DecodeValue(serializer, writes[i].Value)
+		if err != nil {
+			return fmt.Errorf("pending write %s: %w", writes[i].TaskID, err)
+		}
+		writes[i].Value = value
+	}
+	return nil
+}
+
+// Options holds the settings shared by checkpoint stores.
+type Options struct {
+	// Serializer encodes states and pending write values. Nil stores them as plain JSON.
+	Serializer Serializer
+}
+
+// Option configures a checkpoint store.
+type Option func(*Options)
+
+// WithSerializer sets the serializer of a checkpoint store.
+func WithSerializer(serializer Serializer) Option {
+	return func(o *Options) {
+		o.Serializer = serializer
+	}
+}
+
+// NewOptions applies opts to zero Options.
+func NewOptions(opts ...Option) Options {
+	var options Options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKey is returned when encrypted data names a key the serializer does not have.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// AESGCMSerializer encrypts the output of another serializer with AES-GCM envelope encryption.
+// Each value is encrypted with a fresh data key, which is itself encrypted with a key encryption
+// key identified by an ID stored next to the data.
+//
+// Keys are rotated by adding a key and making it active: new values are encrypted with the
+// active key, and values encrypted with the other keys stay readable as long as they are kept.
+type AESGCMSerializer struct {
+	inner       Serializer
+	keys        map[string]cipher.AEAD
+	activeKeyID string
+}
+
+// NewAESGCMSerializer creates a serializer encrypting the output of inner. Keys map key IDs to
+// AES-128, AES-192 or AES-256 keys, and activeKeyID names the key used to encrypt.
+//
+// Example:
+//
+//	serializer, _ := store.NewAESGCMSerializer(store.JSONSerializer{}, map[string][]byte{
+//	    "2024-01": oldKey,
+//	    "2024-06": newKey,
+//	}, "2024-06")
+func NewAESGCMSerializer(inner Serializer, keys map[string][]byte, activeKeyID string) (*AESGCMSerializer, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, activeKeyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if len(id) > 255 {
+			return nil, fmt.Errorf("key ID %q is longer than 255 bytes", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aeads[id] = aead
+	}
+	return &AESGCMSerializer{inner: inner, keys: aeads, activeKeyID: activeKeyID}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Marshal implements Serializer. The payload is the key ID, the encrypted data key and the
+// encrypted data, each ciphertext prefixed with its nonce:
+//
+//	len(keyID) keyID len(wrappedKey) wrappedKey ciphertext
+func (s *AESGCMSerializer) Marshal(value any) ([]byte, error) {
+	plaintext, err := s.inner.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key ID is authenticated with the data key, so it cannot be swapped
+	keyID := []byte(s.activeKeyID)
+	wrappedKey, err := seal(s.keys[s.activeKeyID], dataKey, keyID)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dataAEAD, plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, 0, 1+len(keyID)+2+len(wrappedKey)+len(ciphertext))
+	payload = append(payload, byte(len(keyID)))
+	payload = append(payload, keyID...)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(wrappedKey)))
+	payload = append(payload, wrappedKey...)
+	payload = append(payload, ciphertext...)
+	return AddFormatHeader(FormatAESGCM, payload), nil
+}
+
+// Unmarshal implements Serializer. Unencrypted data is passed to the inner serializer, so stores
+// written before encryption was enabled stay readable.
+func (s *AESGCMSerializer) Unmarshal(data []byte) (any, error) {
+	format, payload, ok := ParseFormatHeader(data)
+	if !ok || format != FormatAESGCM {
+		return s.inner.Unmarshal(data)
+	}
+
+	errInvalid := errors.New("invalid encrypted data")
+	if len(payload) < 1 {
+		return nil, errInvalid
+	}
+	n := int(payload[0])
+	if len(payload) < 1+n+2 {
+		return nil, errInvalid
+	}
+	keyID := payload[1 : 1+n]
+	payload = payload[1+n:]
+	m := int(binary.BigEndian.Uint16(payload))
+	if len(payload) < 2+m {
+		return nil, errInvalid
+	}
+	wrappedKey, ciphertext := payload[2:2+m], payload[2+m:]
+
+	keyAEAD, ok := s.keys[string(keyID)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	dataKey, err := open(keyAEAD, wrappedKey, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataAEAD, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return s.inner.Unmarshal(plaintext)
+}
+
+// seal encrypts plaintext with a random nonce, which prefixes the result.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the output of seal.
+func open(aead cipher.AEAD, data, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
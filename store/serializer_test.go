@@ -0,0 +1,184 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestFormatHeader(t *testing.T) {
+	data := AddFormatHeader("custom", []byte("payload"))
+	format, payload, ok := ParseFormatHeader(data)
+	assert.True(t, ok)
+	assert.Equal(t, "custom", format)
+	assert.Equal(t, []byte("payload"), payload)
+
+	_, _, ok = ParseFormatHeader([]byte(`{"count": 1}`))
+	assert.False(t, ok)
+	_, _, ok = ParseFormatHeader(formatMagic)
+	assert.False(t, ok)
+}
+
+func TestSerializers_RoundTrip(t *testing.T) {
+	zstdJSON, err := NewZstdSerializer(JSONSerializer{})
+	require.NoError(t, err)
+	aesGob, err := NewAESGCMSerializer(GobSerializer{}, map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+	aesZstd, err := NewAESGCMSerializer(zstdJSON, map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+
+	state := map[string]any{
+		"name":     "ada",
+		"messages": []any{"hello", "world"},
+		"nested":   map[string]any{"ok": true},
+	}
+
+	tests := []struct {
+		name       string
+		serializer Serializer
+		format     string
+	}{
+		{"json", JSONSerializer{}, FormatJSON},
+		{"gob", GobSerializer{}, FormatGob},
+		{"zstd", zstdJSON, FormatZstd},
+		{"aes-gcm", aesGob, FormatAESGCM},
+		{"aes-gcm over zstd", aesZstd, FormatAESGCM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.serializer.Marshal(state)
+			require.NoError(t, err)
+			format, _, ok := ParseFormatHeader(data)
+			require.True(t, ok)
+			assert.Equal(t, tt.format, format)
+
+			decoded, err := tt.serializer.Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, state, decoded)
+
+			// Plain JSON written before serializers existed stays readable
+			legacy, err := tt.serializer.Unmarshal([]byte(`{"name": "ada"}`))
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"name": "ada"}, legacy)
+		})
+	}
+}
+
+func TestZstdSerializer_Compresses(t *testing.T) {
+	serializer, err := NewZstdSerializer(JSONSerializer{})
+	require.NoError(t, err)
+
+	state := map[string]any{"history": strings.Repeat("the same message ", 1000)}
+	plain, err := JSONSerializer{}.Marshal(state)
+	require.NoError(t, err)
+	compressed, err := serializer.Marshal(state)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed)*10, len(plain))
+
+	// A plain serializer cannot read compressed data
+	_, err = JSONSerializer{}.Unmarshal(compressed)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestAESGCMSerializer_KeyRotation(t *testing.T) {
+	_, err := NewAESGCMSerializer(JSONSerializer{}, map[string][]byte{"k1": testKey(1)}, "k2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	_, err = NewAESGCMSerializer(JSONSerializer{}, map[string][]byte{"k1": []byte("short")}, "k1")
+	assert.Error(t, err)
+
+	before, err := NewAESGCMSerializer(JSONSerializer{}, map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+	old, err := before.Marshal(map[string]any{"ssn": "123-45-6789"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(old), "123-45-6789")
+
+	// After rotation new data uses the new key and old data stays readable
+	after, err := NewAESGCMSerializer(JSONSerializer{}, map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, "k2")
+	require.NoError(t, err)
+	decoded, err := after.Unmarshal(old)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"ssn": "123-45-6789"}, decoded)
+
+	rotated, err := after.Marshal(decoded)
+	require.NoError(t, err)
+	_, err = before.Unmarshal(rotated)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Retired keys cannot read old data
+	retired, err := NewAESGCMSerializer(JSONSerializer{}, map[string][]byte{"k2": testKey(2)}, "k2")
+	require.NoError(t, err)
+	_, err = retired.Unmarshal(old)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Tampering is detected
+	tampered := bytes.Clone(rotated)
+	tampered[len(tampered)-1] ^= 1
+	_, err = after.Unmarshal(tampered)
+	assert.Error(t, err)
+
+	// A wrong key under the right ID is detected
+	wrong, err := NewAESGCMSerializer(JSONSerializer{}, map[string][]byte{"k2": testKey(3)}, "k2")
+	require.NoError(t, err)
+	_, err = wrong.Unmarshal(rotated)
+	assert.Error(t, err)
+}
+
+func TestEncodeValue(t *testing.T) {
+	value := map[string]any{"count": float64(3)}
+
+	// Without a serializer values are stored as they are
+	encoded, err := EncodeValue(nil, value)
+	require.NoError(t, err)
+	assert.Equal(t, value, encoded)
+
+	serializer, err := NewZstdSerializer(GobSerializer{})
+	require.NoError(t, err)
+	encoded, err = EncodeValue(serializer, value)
+	require.NoError(t, err)
+
+	// Stores keep encoded values in JSON
+	data, err := json.Marshal(encoded)
+	require.NoError(t, err)
+	var stored any
+	require.NoError(t, json.Unmarshal(data, &stored))
+
+	decoded, err := DecodeValue(serializer, stored)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	// Values that are not encoded are returned as they are
+	decoded, err = DecodeValue(serializer, value)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	// Without a serializer, compressed values cannot be read
+	_, err = DecodeValue(nil, stored)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestEncodeCheckpoint(t *testing.T) {
+	serializer := GobSerializer{}
+	cp := &Checkpoint{ID: "cp-1", State: map[string]any{"count": 1}}
+
+	encoded, err := EncodeCheckpoint(serializer, cp)
+	require.NoError(t, err)
+	assert.NotSame(t, cp, encoded)
+	assert.Equal(t, map[string]any{"count": 1}, cp.State)
+
+	require.NoError(t, DecodeCheckpoint(serializer, encoded))
+	assert.Equal(t, cp.State, encoded.State)
+
+	writes, err := EncodeWrites(serializer, []PendingWrite{{TaskID: "t1", Value: "v"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, "v", writes[0].Value)
+	require.NoError(t, DecodeWrites(serializer, writes))
+	assert.Equal(t, "v", writes[0].Value)
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ZstdSerializer compresses the output of another serializer with zstd. Large states, such as
+// long message histories, usually shrink several times.
+type ZstdSerializer struct {
+	inner   Serializer
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// NewZstdSerializer creates a serializer compressing the output of inner.
+//
+// Example:
+//
+//	serializer, _ := store.NewZstdSerializer(store.JSONSerializer{})
+func NewZstdSerializer(inner Serializer) (*ZstdSerializer, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	return &ZstdSerializer{inner: inner, encoder: encoder, decoder: decoder}, nil
+}
+
+// Marshal implements Serializer.
+func (s *ZstdSerializer) Marshal(value any) ([]byte, error) {
+	data, err := s.inner.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return AddFormatHeader(FormatZstd, s.encoder.EncodeAll(data, nil)), nil
+}
+
+// Unmarshal implements Serializer. Uncompressed data is passed to the inner serializer.
+func (s *ZstdSerializer) Unmarshal(data []byte) (any, error) {
+	format, payload, ok := ParseFormatHeader(data)
+	if !ok || format != FormatZstd {
+		return s.inner.Unmarshal(data)
+	}
+	decompressed, err := s.decoder.DecodeAll(payload, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+	return s.inner.Unmarshal(decompressed)
+}
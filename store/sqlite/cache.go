@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SqliteNodeCache implements graph.NodeCache using SQLite
+type SqliteNodeCache struct {
+	db        *sql.DB
+	tableName string
+}
+
+// NewSqliteNodeCache creates a node cache on an existing SQLite connection.
+// The table (default "node_cache") is created if it doesn't exist.
+func NewSqliteNodeCache(ctx context.Context, db *sql.DB, tableName string) (*SqliteNodeCache, error) {
+	if tableName == "" {
+		tableName = "node_cache"
+	}
+
+	cache := &SqliteNodeCache{
+		db:        db,
+		tableName: tableName,
+	}
+
+	if err := cache.InitSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// NodeCache returns a node cache that shares the store's database connection.
+// Entries are kept in the "<checkpoint table>_node_cache" table.
+func (s *SqliteCheckpointStore) NodeCache(ctx context.Context) (*SqliteNodeCache, error) {
+	return NewSqliteNodeCache(ctx, s.db, s.tableName+"_node_cache")
+}
+
+// InitSchema creates the cache table if it doesn't exist
+func (c *SqliteNodeCache) InitSchema(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key TEXT PRIMARY KEY,
+			value BLOB NOT NULL,
+			expires_at INTEGER
+		);
+	`, c.tableName)
+
+	if _, err := c.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create cache schema: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a cached value
+func (c *SqliteNodeCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`SELECT value, expires_at FROM %s WHERE key = ?`, c.tableName)
+
+	var value []byte
+	var expiresAt sql.NullInt64
+	err := c.db.QueryRowContext(ctx, query, key).Scan(&value, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to load cache entry: %w", err)
+	}
+
+	if expiresAt.Valid && time.Now().UnixNano() > expiresAt.Int64 {
+		if err := c.Delete(ctx, key); err != nil {
+			return nil, false, err
+		}
+		return nil, false, nil
+	}
+
+	return value, true, nil
+}
+
+// Set stores a cached value
+func (c *SqliteNodeCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt sql.NullInt64
+	if ttl > 0 {
+		expiresAt = sql.NullInt64{Int64: time.Now().Add(ttl).UnixNano(), Valid: true}
+	}
+
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		INSERT INTO %s (key, value, expires_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value,
+			expires_at = excluded.expires_at
+	`, c.tableName)
+
+	if _, err := c.db.ExecContext(ctx, query, key, value, expiresAt); err != nil {
+		return fmt.Errorf("failed to save cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a cached value
+func (c *SqliteNodeCache) Delete(ctx context.Context, key string) error {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`DELETE FROM %s WHERE key = ?`, c.tableName)
+	if _, err := c.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes all cached values
+func (c *SqliteNodeCache) Clear(ctx context.Context) error {
+	// nolint:gosec // G201: Table name cannot be parameterized
+	query := fmt.Sprintf(`DELETE FROM %s`, c.tableName)
+	if _, err := c.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to clear cache entries: %w", err)
+	}
+	return nil
+}
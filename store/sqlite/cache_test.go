@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteNodeCache(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewSqliteCheckpointStore(SqliteOptions{
+		Path: filepath.Join(t.TempDir(), "cache.db"),
+	})
+	require.NoError(t, err)
+	defer store.Close()
+
+	cache, err := store.NodeCache(ctx)
+	require.NoError(t, err)
+
+	_, ok, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), 10*time.Millisecond))
+
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	// Overwrite
+	require.NoError(t, cache.Set(ctx, "a", []byte("11"), 0))
+	value, _, _ = cache.Get(ctx, "a")
+	assert.Equal(t, []byte("11"), value)
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Delete(ctx, "a"))
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), 0))
+	require.NoError(t, cache.Clear(ctx))
+	_, ok, _ = cache.Get(ctx, "c")
+	assert.False(t, ok)
+}
@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// SqliteRunStore implements store.RunStore using SQLite
+type SqliteRunStore struct {
+	db        *sql.DB
+	tableName string
+}
+
+// NewSqliteRunStore creates a run store on an existing SQLite connection.
+// The table (default "runs") is created if it doesn't exist.
+func NewSqliteRunStore(ctx context.Context, db *sql.DB, tableName string) (*SqliteRunStore, error) {
+	if tableName == "" {
+		tableName = "runs"
+	}
+
+	runs := &SqliteRunStore{
+		db:        db,
+		tableName: tableName,
+	}
+
+	if err := runs.InitSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// RunStore returns a run store that shares the store's database connection.
+// Runs are kept in the "<checkpoint table>_runs" table.
+func (s *SqliteCheckpointStore) RunStore(ctx context.Context) (*SqliteRunStore, error) {
+	return NewSqliteRunStore(ctx, s.db, s.tableName+"_runs")
+}
+
+// InitSchema creates the runs table if it doesn't exist
+func (r *SqliteRunStore) InitSchema(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			thread_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			input TEXT,
+			resume_value TEXT,
+			error TEXT,
+			metadata TEXT,
+			attempts INTEGER NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_%s_thread_id ON %s (thread_id);
+		CREATE INDEX IF NOT EXISTS idx_%s_status ON %s (status);
+	`, r.tableName, r.tableName, r.tableName, r.tableName, r.tableName)
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create runs schema: %w", err)
+	}
+	return nil
+}
+
+// SaveRun creates or replaces a run record
+func (r *SqliteRunStore) SaveRun(ctx context.Context, run *store.Run) error {
+	inputJSON, err := json.Marshal(run.Input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run input: %w", err)
+	}
+	resumeJSON, err := json.Marshal(run.ResumeValue)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run resume value: %w", err)
+	}
+	metadataJSON, err := json.Marshal(run.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run metadata: %w", err)
+	}
+
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, thread_id, status, input, resume_value, error, metadata, attempts, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			thread_id = excluded.thread_id,
+			status = excluded.status,
+			input = excluded.input,
+			resume_value = excluded.resume_value,
+			error = excluded.error,
+			metadata = excluded.metadata,
+			attempts = excluded.attempts,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+	`, r.tableName)
+
+	_, err = r.db.ExecContext(ctx, query,
+		run.ID, run.ThreadID, string(run.Status), string(inputJSON), string(resumeJSON), run.Error,
+		string(metadataJSON), run.Attempts, run.CreatedAt, run.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	return nil
+}
+
+// GetRun retrieves a run record by ID
+func (r *SqliteRunStore) GetRun(ctx context.Context, runID string) (*store.Run, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		SELECT id, thread_id, status, input, resume_value, error, metadata, attempts, created_at, updated_at
+		FROM %s
+		WHERE id = ?
+	`, r.tableName)
+
+	run, err := scanRun(r.db.QueryRowContext(ctx, query, runID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("run not found: %s", runID)
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListRuns returns the runs selected by the filter, oldest first
+func (r *SqliteRunStore) ListRuns(ctx context.Context, filter store.RunFilter) ([]*store.Run, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.ThreadID != "" {
+		conditions = append(conditions, "thread_id = ?")
+		args = append(args, filter.ThreadID)
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			placeholders[i] = "?"
+			args = append(args, string(status))
+		}
+		conditions = append(conditions, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		SELECT id, thread_id, status, input, resume_value, error, metadata, attempts, created_at, updated_at
+		FROM %s
+		%s
+		ORDER BY created_at ASC, id ASC
+	`, r.tableName, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*store.Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating run rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+// DeleteRun removes a run record
+func (r *SqliteRunStore) DeleteRun(ctx context.Context, runID string) error {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = ?", r.tableName)
+	if _, err := r.db.ExecContext(ctx, query, runID); err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+	return nil
+}
+
+// scanRun reads a run record from a row
+func scanRun(row interface{ Scan(dest ...any) error }) (*store.Run, error) {
+	var (
+		run                                store.Run
+		status                             string
+		inputJSON, resumeJSON, metadataSQL sql.NullString
+		errorText                          sql.NullString
+	)
+	if err := row.Scan(&run.ID, &run.ThreadID, &status, &inputJSON, &resumeJSON, &errorText, &metadataSQL,
+		&run.Attempts, &run.CreatedAt, &run.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan run row: %w", err)
+	}
+	run.Status = store.RunStatus(status)
+	run.Error = errorText.String
+
+	if inputJSON.Valid {
+		if err := json.Unmarshal([]byte(inputJSON.String), &run.Input); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run input: %w", err)
+		}
+	}
+	if resumeJSON.Valid {
+		if err := json.Unmarshal([]byte(resumeJSON.String), &run.ResumeValue); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run resume value: %w", err)
+		}
+	}
+	if metadataSQL.Valid {
+		if err := json.Unmarshal([]byte(metadataSQL.String), &run.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run metadata: %w", err)
+		}
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestSqliteRunStore(t *testing.T) {
+	ctx := context.Background()
+	checkpoints, err := NewSqliteCheckpointStore(SqliteOptions{
+		Path: filepath.Join(t.TempDir(), "runs.db"),
+	})
+	require.NoError(t, err)
+	defer checkpoints.Close()
+
+	runs, err := checkpoints.RunStore(ctx)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	run := &store.Run{
+		ID:          "run-1",
+		ThreadID:    "thread-1",
+		Status:      store.RunPending,
+		Input:       map[string]any{"q": "hi"},
+		ResumeValue: "yes",
+		Metadata:    map[string]any{"user": "ada"},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, runs.SaveRun(ctx, run))
+	require.NoError(t, runs.SaveRun(ctx, &store.Run{ID: "run-2", ThreadID: "thread-2", Status: store.RunRunning, CreatedAt: now.Add(time.Second), UpdatedAt: now}))
+
+	run.Status = store.RunFailed
+	run.Error = "boom"
+	run.Attempts = 1
+	require.NoError(t, runs.SaveRun(ctx, run))
+
+	loaded, err := runs.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, store.RunFailed, loaded.Status)
+	assert.Equal(t, "boom", loaded.Error)
+	assert.Equal(t, 1, loaded.Attempts)
+	assert.Equal(t, map[string]any{"q": "hi"}, loaded.Input)
+	assert.Equal(t, "yes", loaded.ResumeValue)
+	assert.Equal(t, "ada", loaded.Metadata["user"])
+	assert.True(t, now.Equal(loaded.CreatedAt))
+
+	list, err := runs.ListRuns(ctx, store.RunFilter{Statuses: []store.RunStatus{store.RunPending, store.RunRunning}})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "run-2", list[0].ID)
+
+	list, err = runs.ListRuns(ctx, store.RunFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	require.NoError(t, runs.DeleteRun(ctx, "run-1"))
+	_, err = runs.GetRun(ctx, "run-1")
+	assert.ErrorContains(t, err, "run not found")
+}
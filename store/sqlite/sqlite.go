@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -14,19 +15,32 @@ import (
 
 // SqliteCheckpointStore implements graph.CheckpointStore using SQLite
 type SqliteCheckpointStore struct {
-	db        *sql.DB
-	tableName string
+	db         *sql.DB
+	tableName  string
+	serializer store.Serializer
 }
 
 // SqliteOptions configuration for SQLite connection
 type SqliteOptions struct {
 	Path      string
 	TableName string // Default "checkpoints"
+
+	// ReadOnly opens an existing database read-only, without creating or migrating its schema.
+	// Opening fails with store.ErrSchemaOutdated when the schema needs migrating.
+	ReadOnly bool
 }
 
-// NewSqliteCheckpointStore creates a new SQLite checkpoint store
-func NewSqliteCheckpointStore(opts SqliteOptions) (*SqliteCheckpointStore, error) {
-	db, err := sql.Open("sqlite3", opts.Path)
+// NewSqliteCheckpointStore creates a new SQLite checkpoint store.
+// States and pending write values are stored as plain JSON unless store.WithSerializer is given.
+func NewSqliteCheckpointStore(opts SqliteOptions, options ...store.Option) (*SqliteCheckpointStore, error) {
+	dsn := opts.Path
+	if opts.ReadOnly {
+		if _, err := os.Stat(opts.Path); err != nil {
+			return nil, fmt.Errorf("unable to open database: %w", err)
+		}
+		dsn = "file:" + opts.Path + "?mode=ro"
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open database: %w", err)
 	}
@@ -37,11 +51,16 @@ func NewSqliteCheckpointStore(opts SqliteOptions) (*SqliteCheckpointStore, error
 	}
 
 	store := &SqliteCheckpointStore{
-		db:        db,
-		tableName: tableName,
+		db:         db,
+		tableName:  tableName,
+		serializer: store.NewOptions(options...).Serializer,
 	}
 
-	if err := store.InitSchema(context.Background()); err != nil {
+	setup := store.InitSchema
+	if opts.ReadOnly {
+		setup = store.CheckSchema
+	}
+	if err := setup(context.Background()); err != nil {
 		db.Close()
 		return nil, err
 	}
@@ -60,19 +79,96 @@ func (s *SqliteCheckpointStore) InitSchema(ctx context.Context) error {
 			state TEXT NOT NULL,
 			metadata TEXT,
 			timestamp DATETIME NOT NULL,
-			version INTEGER NOT NULL
+			version INTEGER NOT NULL,
+			parent_id TEXT
 		);
 		CREATE INDEX IF NOT EXISTS idx_%s_execution_id ON %s (execution_id);
 		CREATE INDEX IF NOT EXISTS idx_%s_thread_id ON %s (thread_id);
-	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
+		CREATE TABLE IF NOT EXISTS %s (
+			checkpoint_id TEXT NOT NULL,
+			task_id TEXT NOT NULL,
+			node_name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			timestamp DATETIME NOT NULL,
+			PRIMARY KEY (checkpoint_id, task_id)
+		);
+	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.writesTable())
 
 	_, err := s.db.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
+	return s.MigrateSchema(ctx)
+}
+
+// MigrateSchema adds the columns, tables and indexes introduced after the table was created (for existing installations)
+func (s *SqliteCheckpointStore) MigrateSchema(ctx context.Context) error {
+	columns, err := s.columns(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	if !columns["parent_id"] {
+		// nolint:gosec // G201: Table name cannot be parameterized
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN parent_id TEXT", s.tableName)
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to migrate schema: %w", err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, s.threadsSchema()); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
 	return nil
 }
 
+// CheckSchema returns store.ErrSchemaOutdated if the schema lacks columns or tables added by
+// MigrateSchema, or does not exist.
+func (s *SqliteCheckpointStore) CheckSchema(ctx context.Context) error {
+	columns, err := s.columns(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+
+	var tables int
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN (?, ?)",
+		s.writesTable(), s.threadsTable()).Scan(&tables)
+	if err != nil {
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+
+	if !columns["thread_id"] || !columns["parent_id"] || tables != 2 {
+		return fmt.Errorf("%w: table %s", store.ErrSchemaOutdated, s.tableName)
+	}
+	return nil
+}
+
+// columns returns the column names of the checkpoints table
+func (s *SqliteCheckpointStore) columns(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", s.tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
 // Close closes the database connection
 func (s *SqliteCheckpointStore) Close() error {
 	return s.db.Close()
@@ -80,7 +176,12 @@ func (s *SqliteCheckpointStore) Close() error {
 
 // Save stores a checkpoint
 func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Checkpoint) error {
-	stateJSON, err := json.Marshal(checkpoint.State)
+	state, err := store.EncodeValue(s.serializer, checkpoint.State)
+	if err != nil {
+		return err
+	}
+
+	stateJSON, err := json.Marshal(state)
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
@@ -102,8 +203,8 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 
 	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
 	query := fmt.Sprintf(`
-		INSERT INTO %s (id, execution_id, thread_id, node_name, state, metadata, timestamp, version)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO %s (id, execution_id, thread_id, node_name, state, metadata, timestamp, version, parent_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			execution_id = excluded.execution_id,
 			thread_id = excluded.thread_id,
@@ -111,7 +212,8 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 			state = excluded.state,
 			metadata = excluded.metadata,
 			timestamp = excluded.timestamp,
-			version = excluded.version
+			version = excluded.version,
+			parent_id = excluded.parent_id
 	`, s.tableName)
 
 	_, err = s.db.ExecContext(ctx, query,
@@ -123,6 +225,7 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 		string(metadataJSON),
 		checkpoint.Timestamp,
 		checkpoint.Version,
+		checkpoint.ParentID,
 	)
 
 	if err != nil {
@@ -136,7 +239,7 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (*graph.Checkpoint, error) {
 	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE id = ?
 	`, s.tableName)
@@ -152,6 +255,7 @@ func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (
 		&metadataJSON,
 		&cp.Timestamp,
 		&cp.Version,
+		&cp.ParentID,
 	)
 
 	if err != nil {
@@ -165,6 +269,10 @@ func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (
 		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 	}
 
+	if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+		return nil, err
+	}
+
 	if len(metadataJSON) > 0 {
 		if err := json.Unmarshal([]byte(metadataJSON), &cp.Metadata); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
@@ -178,7 +286,7 @@ func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (
 func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([]*graph.Checkpoint, error) {
 	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE execution_id = ?
 		ORDER BY timestamp ASC
@@ -203,6 +311,7 @@ func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([
 			&metadataJSON,
 			&cp.Timestamp,
 			&cp.Version,
+			&cp.ParentID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan checkpoint row: %w", err)
@@ -212,6 +321,10 @@ func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([
 			return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 		}
 
+		if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+			return nil, err
+		}
+
 		if len(metadataJSON) > 0 {
 			if err := json.Unmarshal([]byte(metadataJSON), &cp.Metadata); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
@@ -228,6 +341,48 @@ func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([
 	return checkpoints, nil
 }
 
+// ListThreadIDs implements store.ThreadLister
+func (s *SqliteCheckpointStore) ListThreadIDs(ctx context.Context) ([]string, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized
+	query := fmt.Sprintf(`
+		SELECT DISTINCT thread_id
+		FROM %s
+		WHERE thread_id IS NOT NULL AND thread_id != ''
+	`, s.tableName)
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	defer rows.Close()
+
+	threadIDs := []string{}
+	for rows.Next() {
+		var threadID string
+		if err := rows.Scan(&threadID); err != nil {
+			return nil, fmt.Errorf("failed to scan thread row: %w", err)
+		}
+		threadIDs = append(threadIDs, threadID)
+	}
+	return threadIDs, rows.Err()
+}
+
+// CountUnthreaded implements store.UnthreadedCounter
+func (s *SqliteCheckpointStore) CountUnthreaded(ctx context.Context) (int, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE thread_id IS NULL OR thread_id = ''
+	`, s.tableName)
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count checkpoints without a thread: %w", err)
+	}
+	return count, nil
+}
+
 // Delete removes a checkpoint
 func (s *SqliteCheckpointStore) Delete(ctx context.Context, checkpointID string) error {
 	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
@@ -236,11 +391,17 @@ func (s *SqliteCheckpointStore) Delete(ctx context.Context, checkpointID string)
 	if err != nil {
 		return fmt.Errorf("failed to delete checkpoint: %w", err)
 	}
-	return nil
+	return s.DeleteWrites(ctx, checkpointID)
 }
 
 // Clear removes all checkpoints for an execution
 func (s *SqliteCheckpointStore) Clear(ctx context.Context, executionID string) error {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	writesQuery := fmt.Sprintf("DELETE FROM %s WHERE checkpoint_id IN (SELECT id FROM %s WHERE execution_id = ?)", s.writesTable(), s.tableName)
+	if _, err := s.db.ExecContext(ctx, writesQuery, executionID); err != nil {
+		return fmt.Errorf("failed to clear pending writes: %w", err)
+	}
+
 	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
 	query := fmt.Sprintf("DELETE FROM %s WHERE execution_id = ?", s.tableName)
 	_, err := s.db.ExecContext(ctx, query, executionID)
@@ -250,11 +411,105 @@ func (s *SqliteCheckpointStore) Clear(ctx context.Context, executionID string) e
 	return nil
 }
 
+// writesTable returns the name of the pending writes table
+func (s *SqliteCheckpointStore) writesTable() string {
+	return s.tableName + "_writes"
+}
+
+// PutWrites records pending writes on top of a checkpoint
+func (s *SqliteCheckpointStore) PutWrites(ctx context.Context, checkpointID string, writes []store.PendingWrite) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		INSERT INTO %s (checkpoint_id, task_id, node_name, value, timestamp)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(checkpoint_id, task_id) DO UPDATE SET
+			node_name = excluded.node_name,
+			value = excluded.value,
+			timestamp = excluded.timestamp
+	`, s.writesTable())
+
+	for _, w := range writes {
+		value, err := store.EncodeValue(s.serializer, w.Value)
+		if err != nil {
+			return err
+		}
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pending write: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, query, checkpointID, w.TaskID, w.Node, string(valueJSON), w.Timestamp); err != nil {
+			return fmt.Errorf("failed to save pending write: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pending writes: %w", err)
+	}
+	return nil
+}
+
+// GetWrites returns the pending writes recorded on top of a checkpoint
+func (s *SqliteCheckpointStore) GetWrites(ctx context.Context, checkpointID string) ([]store.PendingWrite, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		SELECT task_id, node_name, value, timestamp
+		FROM %s
+		WHERE checkpoint_id = ?
+		ORDER BY rowid ASC
+	`, s.writesTable())
+
+	rows, err := s.db.QueryContext(ctx, query, checkpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending writes: %w", err)
+	}
+	defer rows.Close()
+
+	var writes []store.PendingWrite
+	for rows.Next() {
+		var w store.PendingWrite
+		var valueJSON string
+		if err := rows.Scan(&w.TaskID, &w.Node, &valueJSON, &w.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan pending write row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(valueJSON), &w.Value); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending write: %w", err)
+		}
+		value, err := store.DecodeValue(s.serializer, w.Value)
+		if err != nil {
+			return nil, err
+		}
+		w.Value = value
+		writes = append(writes, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending write rows: %w", err)
+	}
+
+	return writes, nil
+}
+
+// DeleteWrites removes the pending writes recorded on top of a checkpoint
+func (s *SqliteCheckpointStore) DeleteWrites(ctx context.Context, checkpointID string) error {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf("DELETE FROM %s WHERE checkpoint_id = ?", s.writesTable())
+	if _, err := s.db.ExecContext(ctx, query, checkpointID); err != nil {
+		return fmt.Errorf("failed to delete pending writes: %w", err)
+	}
+	return nil
+}
+
 // ListByThread returns all checkpoints for a specific thread_id
 func (s *SqliteCheckpointStore) ListByThread(ctx context.Context, threadID string) ([]*store.Checkpoint, error) {
 	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, COALESCE(parent_id, '')
 		FROM %s
 		WHERE thread_id = ?
 		ORDER BY timestamp ASC
@@ -279,6 +534,7 @@ func (s *SqliteCheckpointStore) ListByThread(ctx context.Context, threadID strin
 			&metadataJSON,
 			&cp.Timestamp,
 			&cp.Version,
+			&cp.ParentID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan checkpoint row: %w", err)
@@ -288,6 +544,10 @@ func (s *SqliteCheckpointStore) ListByThread(ctx context.Context, threadID strin
 			return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 		}
 
+		if err := store.DecodeCheckpoint(s.serializer, &cp); err != nil {
+			return nil, err
+		}
+
 		if len(metadataJSON) > 0 {
 			if err := json.Unmarshal([]byte(metadataJSON), &cp.Metadata); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
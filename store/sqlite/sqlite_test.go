@@ -2,10 +2,13 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/smallnest/langgraphgo/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -80,3 +83,259 @@ func TestSqliteCheckpointStore(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, list, 0)
 }
+
+func TestSqliteCheckpointStore_PendingWrites(t *testing.T) {
+	s, err := NewSqliteCheckpointStore(SqliteOptions{
+		Path: ":memory:",
+	})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	cp := &graph.Checkpoint{
+		ID:        "cp-1",
+		State:     map[string]any{},
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}
+	assert.NoError(t, s.Save(ctx, cp))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, s.PutWrites(ctx, cp.ID, []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: map[string]any{"a": "first"}, Timestamp: now},
+		{TaskID: "1:b", Node: "b", Value: map[string]any{"b": "done"}, Timestamp: now},
+	}))
+	assert.NoError(t, s.PutWrites(ctx, cp.ID, []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: map[string]any{"a": "replaced"}, Timestamp: now},
+	}))
+
+	writes, err := s.GetWrites(ctx, cp.ID)
+	assert.NoError(t, err)
+	assert.Len(t, writes, 2)
+	assert.Equal(t, "0:a", writes[0].TaskID)
+	assert.Equal(t, map[string]any{"a": "replaced"}, writes[0].Value)
+	assert.Equal(t, "b", writes[1].Node)
+
+	// Clearing the execution removes the writes of its checkpoints
+	assert.NoError(t, s.Clear(ctx, "exec-1"))
+	writes, err = s.GetWrites(ctx, cp.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, writes)
+}
+
+func TestSqliteCheckpointStore_ParentID(t *testing.T) {
+	s, err := NewSqliteCheckpointStore(SqliteOptions{
+		Path: ":memory:",
+	})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, s.Save(ctx, &graph.Checkpoint{
+		ID:        "cp-2",
+		ParentID:  "cp-1",
+		State:     map[string]any{},
+		Timestamp: time.Now(),
+		Version:   2,
+		Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+	}))
+
+	loaded, err := s.Load(ctx, "cp-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "cp-1", loaded.ParentID)
+
+	latest, err := s.GetLatestByThread(ctx, "thread-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "cp-1", latest.ParentID)
+}
+
+func TestSqliteCheckpointStore_ListThreadIDs(t *testing.T) {
+	s, err := NewSqliteCheckpointStore(SqliteOptions{
+		Path: ":memory:",
+	})
+	assert.NoError(t, err)
+	defer s.Close()
+	ctx := context.Background()
+
+	threadIDs, err := s.ListThreadIDs(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, threadIDs)
+	assert.Empty(t, threadIDs)
+
+	for _, cp := range []*graph.Checkpoint{
+		{ID: "cp-1", Version: 1, Metadata: map[string]any{"execution_id": "exec-1", "thread_id": "thread-b"}},
+		{ID: "cp-2", Version: 2, Metadata: map[string]any{"execution_id": "exec-1", "thread_id": "thread-b"}},
+		{ID: "cp-3", Version: 1, Metadata: map[string]any{"execution_id": "exec-2", "thread_id": "thread-a"}},
+		{ID: "cp-4", Version: 1, Metadata: map[string]any{"execution_id": "exec-3"}},
+		{ID: "cp-5", Version: 1, Metadata: map[string]any{"execution_id": "exec-4", "thread_id": ""}},
+	} {
+		cp.State = map[string]any{}
+		cp.Timestamp = time.Now()
+		assert.NoError(t, s.Save(ctx, cp))
+	}
+
+	threadIDs, err = s.ListThreadIDs(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"thread-a", "thread-b"}, threadIDs)
+
+	unthreaded, err := s.CountUnthreaded(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, unthreaded)
+
+	// Threads whose checkpoints were all deleted are not listed
+	assert.NoError(t, s.Delete(ctx, "cp-3"))
+	threadIDs, err = s.ListThreadIDs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"thread-b"}, threadIDs)
+}
+
+func TestSqliteCheckpointStore_MigrateParentID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Create a table from before parent pointers were recorded
+	db, err := sql.Open("sqlite3", path)
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE checkpoints (
+		id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		thread_id TEXT,
+		node_name TEXT NOT NULL,
+		state TEXT NOT NULL,
+		metadata TEXT,
+		timestamp DATETIME NOT NULL,
+		version INTEGER NOT NULL
+	)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO checkpoints VALUES ('old', 'exec-1', 'thread-1', 'a', '{}', '{}', ?, 1)`, time.Now())
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	s, err := NewSqliteCheckpointStore(SqliteOptions{Path: path})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	old, err := s.Load(ctx, "old")
+	assert.NoError(t, err)
+	assert.Empty(t, old.ParentID)
+
+	assert.NoError(t, s.Save(ctx, &graph.Checkpoint{
+		ID:        "new",
+		ParentID:  "old",
+		State:     map[string]any{},
+		Timestamp: time.Now(),
+		Version:   2,
+		Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+	}))
+
+	history, err := store.ListHistory(ctx, s, "thread-1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, "new", history[0].Children[0].Checkpoint.ID)
+}
+
+func TestSqliteCheckpointStore_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// A missing database is not created
+	missing := filepath.Join(dir, "missing.db")
+	_, err := NewSqliteCheckpointStore(SqliteOptions{Path: missing, ReadOnly: true})
+	assert.Error(t, err)
+	assert.NoFileExists(t, missing)
+
+	path := filepath.Join(dir, "checkpoints.db")
+	s, err := NewSqliteCheckpointStore(SqliteOptions{Path: path})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Save(ctx, &graph.Checkpoint{
+		ID:        "cp-1",
+		State:     map[string]any{"step": 1},
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1", "thread_id": "thread-1"},
+	}))
+	assert.NoError(t, s.Close())
+
+	readOnly, err := NewSqliteCheckpointStore(SqliteOptions{Path: path, ReadOnly: true})
+	assert.NoError(t, err)
+	defer readOnly.Close()
+	checkpoints, err := readOnly.ListByThread(ctx, "thread-1")
+	assert.NoError(t, err)
+	assert.Len(t, checkpoints, 1)
+	assert.Error(t, readOnly.Save(ctx, &graph.Checkpoint{ID: "cp-2", State: map[string]any{}, Timestamp: time.Now()}))
+
+	// Databases from before parent pointers were recorded must be migrated first
+	legacy := filepath.Join(dir, "legacy.db")
+	db, err := sql.Open("sqlite3", legacy)
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE checkpoints (
+		id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		thread_id TEXT,
+		node_name TEXT NOT NULL,
+		state TEXT NOT NULL,
+		metadata TEXT,
+		timestamp DATETIME NOT NULL,
+		version INTEGER NOT NULL
+	)`)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	_, err = NewSqliteCheckpointStore(SqliteOptions{Path: legacy, ReadOnly: true})
+	assert.ErrorIs(t, err, store.ErrSchemaOutdated)
+}
+
+func TestSqliteCheckpointStore_Serializer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.db")
+	ctx := context.Background()
+
+	// Checkpoints written without a serializer stay readable
+	plain, err := NewSqliteCheckpointStore(SqliteOptions{Path: path})
+	assert.NoError(t, err)
+	assert.NoError(t, plain.Save(ctx, &graph.Checkpoint{
+		ID:        "legacy",
+		State:     map[string]any{"count": float64(1)},
+		Timestamp: time.Now(),
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}))
+	assert.NoError(t, plain.Close())
+
+	serializer, err := store.NewAESGCMSerializer(store.JSONSerializer{}, map[string][]byte{"k1": make([]byte, 32)}, "k1")
+	assert.NoError(t, err)
+	s, err := NewSqliteCheckpointStore(SqliteOptions{Path: path}, store.WithSerializer(serializer))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	assert.NoError(t, s.Save(ctx, &graph.Checkpoint{
+		ID:        "cp-1",
+		State:     map[string]any{"secret": "swordfish"},
+		Timestamp: time.Now(),
+		Version:   1,
+		Metadata:  map[string]any{"execution_id": "exec-1"},
+	}))
+	assert.NoError(t, s.PutWrites(ctx, "cp-1", []store.PendingWrite{
+		{TaskID: "0:a", Node: "a", Value: "swordfish", Timestamp: time.Now()},
+	}))
+
+	var state string
+	assert.NoError(t, s.db.QueryRowContext(ctx, "SELECT state FROM checkpoints WHERE id = 'cp-1'").Scan(&state))
+	assert.NotContains(t, state, "swordfish")
+
+	loaded, err := s.Load(ctx, "cp-1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"secret": "swordfish"}, loaded.State)
+
+	legacy, err := s.Load(ctx, "legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": float64(1)}, legacy.State)
+
+	list, err := s.List(ctx, "exec-1")
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	writes, err := s.GetWrites(ctx, "cp-1")
+	assert.NoError(t, err)
+	assert.Len(t, writes, 1)
+	assert.Equal(t, "swordfish", writes[0].Value)
+}
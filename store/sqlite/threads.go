@@ -0,0 +1,285 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/smallnest/langgraphgo/store"
+)
+
+// threadsTable returns the name of the thread metadata table
+func (s *SqliteCheckpointStore) threadsTable() string {
+	return s.tableName + "_threads"
+}
+
+// threadsSchema returns the DDL of the thread metadata table and of the indexes of thread queries
+func (s *SqliteCheckpointStore) threadsSchema() string {
+	return fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			thread_id TEXT PRIMARY KEY,
+			metadata TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_%s_updated_at ON %s (updated_at);
+		CREATE INDEX IF NOT EXISTS idx_%s_thread_version ON %s (thread_id, version);
+	`, s.threadsTable(), s.threadsTable(), s.threadsTable(), s.tableName, s.tableName)
+}
+
+// threadStats is the number and time span of the checkpoints of a thread
+type threadStats struct {
+	count       int
+	first, last time.Time
+}
+
+// parseTimestamp parses a timestamp as written by the driver. Aggregates of DATETIME columns are
+// returned as text, and DATETIME columns scanned into strings are formatted as RFC 3339.
+func parseTimestamp(value string) (time.Time, error) {
+	for _, format := range append(sqlite3.SQLiteTimestampFormats, time.RFC3339Nano) {
+		if t, err := time.ParseInLocation(format, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp: %q", value)
+}
+
+// threadStats returns the checkpoint statistics of a thread
+func (s *SqliteCheckpointStore) threadStats(ctx context.Context, threadID string) (threadStats, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		SELECT COUNT(*), COALESCE(MIN(timestamp), ''), COALESCE(MAX(timestamp), '')
+		FROM %s
+		WHERE thread_id = ?
+	`, s.tableName)
+
+	var (
+		st          threadStats
+		first, last string
+	)
+	if err := s.db.QueryRowContext(ctx, query, threadID).Scan(&st.count, &first, &last); err != nil {
+		return threadStats{}, fmt.Errorf("failed to query thread checkpoints: %w", err)
+	}
+	if st.count == 0 {
+		return st, nil
+	}
+
+	var err error
+	if st.first, err = parseTimestamp(first); err != nil {
+		return threadStats{}, err
+	}
+	if st.last, err = parseTimestamp(last); err != nil {
+		return threadStats{}, err
+	}
+	return st, nil
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// threadRecord returns the metadata of a thread, or nil if it has none
+func (s *SqliteCheckpointStore) threadRecord(ctx context.Context, q queryer, threadID string) (*store.ThreadRecord, error) {
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		SELECT metadata, created_at, updated_at
+		FROM %s
+		WHERE thread_id = ?
+	`, s.threadsTable())
+
+	var (
+		metadataJSON string
+		record       store.ThreadRecord
+	)
+	err := q.QueryRowContext(ctx, query, threadID).Scan(&metadataJSON, &record.CreatedAt, &record.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query threads: %w", err)
+	}
+	if err := json.Unmarshal([]byte(metadataJSON), &record.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal thread metadata: %w", err)
+	}
+	return &record, nil
+}
+
+// ListThreads implements store.ThreadStore. The threads are filtered, sorted and paginated in the
+// database.
+func (s *SqliteCheckpointStore) ListThreads(ctx context.Context, filter store.ThreadFilter, page store.Pagination) ([]*store.Thread, error) {
+	conditions, args := []string{"1 = 1"}, []any{}
+	for key, value := range filter.Metadata {
+		path, err := json.Marshal(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+		}
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+		}
+		// Values match when their JSON encodings are equal, as for store.ThreadFilter.Match
+		conditions = append(conditions, "metadata -> ? = json(?)")
+		args = append(args, "$."+string(path), string(valueJSON))
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		conditions = append(conditions, "updated > ?")
+		args = append(args, filter.UpdatedAfter)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		conditions = append(conditions, "updated < ?")
+		args = append(args, filter.UpdatedBefore)
+	}
+
+	limit := -1
+	if page.Limit > 0 {
+		limit = page.Limit
+	}
+	args = append(args, limit, page.Offset)
+
+	// Threads have checkpoints, metadata or both
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		WITH stats AS (
+			SELECT thread_id, COUNT(*) AS checkpoint_count, MIN(timestamp) AS first_at, MAX(timestamp) AS last_at
+			FROM %s
+			WHERE thread_id IS NOT NULL AND thread_id != ''
+			GROUP BY thread_id
+		), threads AS (
+			SELECT c.thread_id AS id, t.metadata, c.checkpoint_count,
+				MIN(c.first_at, COALESCE(t.created_at, c.first_at)) AS created,
+				MAX(c.last_at, COALESCE(t.updated_at, c.last_at)) AS updated
+			FROM stats c
+			LEFT JOIN %s t ON t.thread_id = c.thread_id
+			UNION ALL
+			SELECT t.thread_id, t.metadata, 0, t.created_at, t.updated_at
+			FROM %s t
+			WHERE t.thread_id NOT IN (SELECT thread_id FROM stats)
+		)
+		SELECT id, COALESCE(metadata, '{}'), checkpoint_count, created, updated
+		FROM threads
+		WHERE %s
+		ORDER BY updated DESC, id ASC
+		LIMIT ? OFFSET ?
+	`, s.tableName, s.threadsTable(), s.threadsTable(), strings.Join(conditions, " AND "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query threads: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []*store.Thread{}
+	for rows.Next() {
+		var (
+			thread               store.Thread
+			metadataJSON         string
+			createdAt, updatedAt string
+		)
+		if err := rows.Scan(&thread.ID, &metadataJSON, &thread.CheckpointCount, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan thread row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(metadataJSON), &thread.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal thread metadata: %w", err)
+		}
+		if thread.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, err
+		}
+		if thread.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+			return nil, err
+		}
+		threads = append(threads, &thread)
+	}
+	return threads, rows.Err()
+}
+
+// GetThread implements store.ThreadStore
+func (s *SqliteCheckpointStore) GetThread(ctx context.Context, threadID string) (*store.Thread, error) {
+	record, err := s.threadRecord(ctx, s.db, threadID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.threadStats(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := store.NewThread(threadID, record, st.count, st.first, st.last)
+	if thread == nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrThreadNotFound, threadID)
+	}
+	return thread, nil
+}
+
+// UpdateThreadMetadata implements store.ThreadStore
+func (s *SqliteCheckpointStore) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]any) (*store.Thread, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := s.threadRecord(ctx, tx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := store.MergeThreadMetadata(current, metadata, time.Now())
+	metadataJSON, err := json.Marshal(record.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal thread metadata: %w", err)
+	}
+
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	query := fmt.Sprintf(`
+		INSERT INTO %s (thread_id, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(thread_id) DO UPDATE SET
+			metadata = excluded.metadata,
+			updated_at = excluded.updated_at
+	`, s.threadsTable())
+	if _, err := tx.ExecContext(ctx, query, threadID, string(metadataJSON), record.CreatedAt, record.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save thread: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit thread: %w", err)
+	}
+
+	return s.GetThread(ctx, threadID)
+}
+
+// DeleteThread implements store.ThreadStore
+func (s *SqliteCheckpointStore) DeleteThread(ctx context.Context, threadID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// nolint:gosec // G201: Table name cannot be parameterized, but all values use parameterized queries
+	queries := []string{
+		fmt.Sprintf("DELETE FROM %s WHERE checkpoint_id IN (SELECT id FROM %s WHERE thread_id = ?)", s.writesTable(), s.tableName),
+		fmt.Sprintf("DELETE FROM %s WHERE thread_id = ?", s.tableName),
+		fmt.Sprintf("DELETE FROM %s WHERE thread_id = ?", s.threadsTable()),
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, threadID); err != nil {
+			return fmt.Errorf("failed to delete thread: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit thread deletion: %w", err)
+	}
+	return nil
+}
+
+// CopyThread implements store.ThreadStore
+func (s *SqliteCheckpointStore) CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*store.Thread, error) {
+	return store.CopyThread(ctx, s, sourceThreadID, targetThreadID)
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+)
+
+func TestSqliteCheckpointStore_Threads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Create a table from before threads were managed
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE checkpoints (
+		id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		thread_id TEXT,
+		node_name TEXT NOT NULL,
+		state TEXT NOT NULL,
+		metadata TEXT,
+		timestamp DATETIME NOT NULL,
+		version INTEGER NOT NULL
+	)`)
+	require.NoError(t, err)
+	created := time.Now().Add(-time.Hour)
+	_, err = db.Exec(`INSERT INTO checkpoints VALUES ('old', 'thread-1', 'thread-1', 'a', '{"step":1}', '{"execution_id":"thread-1","thread_id":"thread-1"}', ?, 1)`, created)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s, err := NewSqliteCheckpointStore(SqliteOptions{Path: path})
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	var indexes int
+	require.NoError(t, s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name IN ('idx_checkpoints_threads_updated_at', 'idx_checkpoints_thread_version')`).Scan(&indexes))
+	assert.Equal(t, 2, indexes)
+
+	thread, err := s.GetThread(ctx, "thread-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, thread.CheckpointCount)
+	assert.WithinDuration(t, created, thread.CreatedAt, time.Millisecond)
+	assert.Empty(t, thread.Metadata)
+
+	require.NoError(t, s.Save(ctx, &store.Checkpoint{
+		ID:        "new",
+		ParentID:  "old",
+		State:     map[string]any{"step": 2},
+		Timestamp: time.Now(),
+		Version:   2,
+		Metadata:  map[string]any{"execution_id": "thread-1", "thread_id": "thread-1"},
+	}))
+	require.NoError(t, s.PutWrites(ctx, "new", []store.PendingWrite{{TaskID: "task-1", Node: "b", Value: "pending", Timestamp: time.Now()}}))
+
+	thread, err = s.UpdateThreadMetadata(ctx, "thread-1", map[string]any{"owner": "alice", "tier": 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, thread.CheckpointCount)
+	thread, err = s.UpdateThreadMetadata(ctx, "thread-1", map[string]any{"tier": nil})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"owner": "alice"}, thread.Metadata)
+	assert.WithinDuration(t, created, thread.CreatedAt, time.Millisecond)
+
+	_, err = s.UpdateThreadMetadata(ctx, "draft", map[string]any{"owner": "bob"})
+	require.NoError(t, err)
+
+	copied, err := s.CopyThread(ctx, "thread-1", "thread-2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied.CheckpointCount)
+
+	threads, err := s.ListThreads(ctx, store.ThreadFilter{Metadata: map[string]any{"owner": "alice"}}, store.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	assert.Equal(t, "thread-2", threads[0].ID)
+
+	threads, err = s.ListThreads(ctx, store.ThreadFilter{}, store.Pagination{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, threads, 2)
+
+	require.NoError(t, s.DeleteThread(ctx, "thread-1"))
+	_, err = s.GetThread(ctx, "thread-1")
+	assert.ErrorIs(t, err, store.ErrThreadNotFound)
+	writes, err := s.GetWrites(ctx, "new")
+	require.NoError(t, err)
+	assert.Empty(t, writes)
+
+	checkpoints, err := s.ListByThread(ctx, "thread-2")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	writes, err = s.GetWrites(ctx, checkpoints[1].ID)
+	require.NoError(t, err)
+	assert.Len(t, writes, 1)
+}
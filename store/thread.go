@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrThreadNotFound is returned for threads without checkpoints or metadata
+	ErrThreadNotFound = errors.New("thread not found")
+
+	// ErrThreadExists is returned when copying a thread to a thread that already exists
+	ErrThreadExists = errors.New("thread already exists")
+)
+
+// Thread summarizes a thread: its metadata and checkpoints.
+type Thread struct {
+	ID string `json:"thread_id"`
+
+	// Metadata is attached to the thread with ThreadStore.UpdateThreadMetadata
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// CheckpointCount is the number of checkpoints of the thread
+	CheckpointCount int `json:"checkpoint_count"`
+
+	// CreatedAt is the time of the first checkpoint or metadata update of the thread, and
+	// UpdatedAt the time of the last one
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ThreadFilter selects threads in ThreadStore.ListThreads. Zero fields match every thread.
+type ThreadFilter struct {
+	// Metadata selects threads whose metadata holds all these values
+	Metadata map[string]any
+
+	// UpdatedAfter and UpdatedBefore select threads by their last update
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+// Match reports whether the thread is selected by the filter. Metadata values match when they
+// are equal once encoded to JSON, as persistent stores decode numbers as float64.
+func (f ThreadFilter) Match(thread *Thread) bool {
+	for key, value := range f.Metadata {
+		actual, ok := thread.Metadata[key]
+		if !ok || !sameValue(actual, value) {
+			return false
+		}
+	}
+	if !f.UpdatedAfter.IsZero() && !thread.UpdatedAt.After(f.UpdatedAfter) {
+		return false
+	}
+	if !f.UpdatedBefore.IsZero() && !thread.UpdatedAt.Before(f.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+// Pagination selects a page of results. A zero Limit returns all the results after Offset.
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// ThreadStore is an optional interface for checkpoint stores that manage threads as a whole.
+// All bundled stores implement it.
+type ThreadStore interface {
+	ThreadLister
+
+	// ListThreads returns the threads selected by the filter, most recently updated first
+	ListThreads(ctx context.Context, filter ThreadFilter, page Pagination) ([]*Thread, error)
+
+	// GetThread returns a thread, or ErrThreadNotFound
+	GetThread(ctx context.Context, threadID string) (*Thread, error)
+
+	// UpdateThreadMetadata merges metadata into the metadata of a thread, creating the thread if
+	// it does not exist yet. Keys with a nil value are removed.
+	UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]any) (*Thread, error)
+
+	// DeleteThread removes the checkpoints, pending writes and metadata of a thread
+	DeleteThread(ctx context.Context, threadID string) error
+
+	// CopyThread copies the checkpoints, pending writes and metadata of a thread to a new thread.
+	// It returns ErrThreadExists if the target thread already exists.
+	CopyThread(ctx context.Context, sourceThreadID, targetThreadID string) (*Thread, error)
+}
+
+// ThreadRecord is the metadata of a thread as kept by a store.
+type ThreadRecord struct {
+	Metadata  map[string]any `json:"metadata"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// MergeThreadMetadata returns the record with metadata merged into it at now: keys with a nil
+// value are removed, the others set. A nil record starts a new one.
+func MergeThreadMetadata(record *ThreadRecord, metadata map[string]any, now time.Time) *ThreadRecord {
+	merged := &ThreadRecord{Metadata: map[string]any{}, CreatedAt: now, UpdatedAt: now}
+	if record != nil {
+		maps.Copy(merged.Metadata, record.Metadata)
+		merged.CreatedAt = record.CreatedAt
+	}
+	for key, value := range metadata {
+		if value == nil {
+			delete(merged.Metadata, key)
+		} else {
+			merged.Metadata[key] = value
+		}
+	}
+	return merged
+}
+
+// NewThread summarizes a thread from its metadata record and the number and time span of its
+// checkpoints. It returns nil if the thread has neither.
+func NewThread(threadID string, record *ThreadRecord, count int, first, last time.Time) *Thread {
+	if record == nil && count == 0 {
+		return nil
+	}
+	thread := &Thread{ID: threadID, Metadata: map[string]any{}, CheckpointCount: count}
+	if count > 0 {
+		thread.CreatedAt, thread.UpdatedAt = first, last
+	}
+	if record != nil {
+		maps.Copy(thread.Metadata, record.Metadata)
+		if thread.CreatedAt.IsZero() || record.CreatedAt.Before(thread.CreatedAt) {
+			thread.CreatedAt = record.CreatedAt
+		}
+		if record.UpdatedAt.After(thread.UpdatedAt) {
+			thread.UpdatedAt = record.UpdatedAt
+		}
+	}
+	return thread
+}
+
+// NewThreadFromCheckpoints summarizes a thread from its metadata record and checkpoints.
+func NewThreadFromCheckpoints(threadID string, record *ThreadRecord, checkpoints []*Checkpoint) *Thread {
+	var first, last time.Time
+	for i, cp := range checkpoints {
+		if i == 0 || cp.Timestamp.Before(first) {
+			first = cp.Timestamp
+		}
+		if cp.Timestamp.After(last) {
+			last = cp.Timestamp
+		}
+	}
+	return NewThread(threadID, record, len(checkpoints), first, last)
+}
+
+// SelectThreads returns the page of the threads selected by the filter, most recently updated
+// first.
+func SelectThreads(threads []*Thread, filter ThreadFilter, page Pagination) []*Thread {
+	selected := []*Thread{}
+	for _, thread := range threads {
+		if thread != nil && filter.Match(thread) {
+			selected = append(selected, thread)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if !selected[i].UpdatedAt.Equal(selected[j].UpdatedAt) {
+			return selected[i].UpdatedAt.After(selected[j].UpdatedAt)
+		}
+		return selected[i].ID < selected[j].ID
+	})
+
+	if page.Offset > 0 {
+		selected = selected[min(page.Offset, len(selected)):]
+	}
+	if page.Limit > 0 && len(selected) > page.Limit {
+		selected = selected[:page.Limit]
+	}
+	return selected
+}
+
+// CopyThread copies a thread with the methods of s, for stores implementing
+// ThreadStore.CopyThread. The copies of the checkpoints get new IDs, keep their lineage and
+// belong to the target thread and to an execution of the same ID.
+func CopyThread(ctx context.Context, s interface {
+	CheckpointStore
+	ThreadStore
+}, sourceThreadID, targetThreadID string) (*Thread, error) {
+	source, err := s.GetThread(ctx, sourceThreadID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetThread(ctx, targetThreadID); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrThreadExists, targetThreadID)
+	} else if !errors.Is(err, ErrThreadNotFound) {
+		return nil, err
+	}
+
+	checkpoints, err := s.ListByThread(ctx, sourceThreadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints for thread %s: %w", sourceThreadID, err)
+	}
+	ids := make(map[string]string, len(checkpoints))
+	for _, cp := range checkpoints {
+		ids[cp.ID] = uuid.NewString()
+	}
+
+	pws, _ := s.(PendingWriteStore)
+	for _, cp := range checkpoints {
+		copied := *cp
+		copied.ID = ids[cp.ID]
+		if parentID, ok := ids[cp.ParentID]; ok {
+			copied.ParentID = parentID
+		}
+		copied.Metadata = maps.Clone(cp.Metadata)
+		if copied.Metadata == nil {
+			copied.Metadata = map[string]any{}
+		}
+		copied.Metadata["thread_id"] = targetThreadID
+		copied.Metadata["execution_id"] = targetThreadID
+		if err := s.Save(ctx, &copied); err != nil {
+			return nil, fmt.Errorf("failed to copy checkpoint %s: %w", cp.ID, err)
+		}
+
+		if pws == nil {
+			continue
+		}
+		writes, err := pws.GetWrites(ctx, cp.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy pending writes of checkpoint %s: %w", cp.ID, err)
+		}
+		if len(writes) > 0 {
+			if err := pws.PutWrites(ctx, copied.ID, writes); err != nil {
+				return nil, fmt.Errorf("failed to copy pending writes of checkpoint %s: %w", cp.ID, err)
+			}
+		}
+	}
+
+	if len(source.Metadata) > 0 || source.CheckpointCount == 0 {
+		if _, err := s.UpdateThreadMetadata(ctx, targetThreadID, source.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetThread(ctx, targetThreadID)
+}
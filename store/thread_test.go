@@ -0,0 +1,158 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smallnest/langgraphgo/store"
+	"github.com/smallnest/langgraphgo/store/file"
+	"github.com/smallnest/langgraphgo/store/memory"
+	"github.com/smallnest/langgraphgo/store/sqlite"
+)
+
+type threadStore interface {
+	store.CheckpointStore
+	store.ThreadStore
+}
+
+func threadIDs(threads []*store.Thread) []string {
+	ids := []string{}
+	for _, thread := range threads {
+		ids = append(ids, thread.ID)
+	}
+	return ids
+}
+
+func TestThreadStore(t *testing.T) {
+	fileStore, err := file.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	sqliteStore, err := sqlite.NewSqliteCheckpointStore(sqlite.SqliteOptions{Path: ":memory:"})
+	require.NoError(t, err)
+	defer sqliteStore.Close()
+
+	stores := map[string]threadStore{
+		"memory": memory.NewMemoryCheckpointStore().(threadStore),
+		"file":   fileStore.(threadStore),
+		"sqlite": sqliteStore,
+		"delta":  store.NewDeltaStore(memory.NewMemoryCheckpointStore(), store.DeltaOptions{}),
+	}
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			start := time.Now()
+			for i, cp := range []*store.Checkpoint{
+				{ID: "a-1", State: map[string]any{"step": 1}, Version: 1, Timestamp: start.Add(-2 * time.Hour)},
+				{ID: "a-2", ParentID: "a-1", State: map[string]any{"step": 2}, Version: 2, Timestamp: start.Add(-time.Hour)},
+				{ID: "b-1", State: map[string]any{"step": 1}, Version: 1, Timestamp: start.Add(-time.Hour)},
+			} {
+				threadID := "thread-a"
+				if i == 2 {
+					threadID = "thread-b"
+				}
+				cp.Metadata = map[string]any{"execution_id": threadID, "thread_id": threadID}
+				require.NoError(t, s.Save(ctx, cp))
+			}
+			pws := s.(store.PendingWriteStore)
+			require.NoError(t, pws.PutWrites(ctx, "a-2", []store.PendingWrite{{TaskID: "task-1", Node: "tool", Value: "result"}}))
+
+			thread, err := s.UpdateThreadMetadata(ctx, "thread-a", map[string]any{"owner": "alice", "tier": 1})
+			require.NoError(t, err)
+			assert.Equal(t, 2, thread.CheckpointCount)
+			assert.Equal(t, "alice", thread.Metadata["owner"])
+			assert.WithinDuration(t, start.Add(-2*time.Hour), thread.CreatedAt, time.Second)
+			assert.False(t, thread.UpdatedAt.Before(start))
+
+			// Metadata can be attached to threads without checkpoints
+			draft, err := s.UpdateThreadMetadata(ctx, "draft", map[string]any{"owner": "bob", "tags": []string{"a", "b"}})
+			require.NoError(t, err)
+			assert.Zero(t, draft.CheckpointCount)
+
+			threads, err := s.ListThreads(ctx, store.ThreadFilter{}, store.Pagination{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"draft", "thread-a", "thread-b"}, threadIDs(threads))
+
+			threads, err = s.ListThreads(ctx, store.ThreadFilter{Metadata: map[string]any{"tier": 1}}, store.Pagination{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"thread-a"}, threadIDs(threads))
+
+			// Values match when they are equal, not when they are contained
+			threads, err = s.ListThreads(ctx, store.ThreadFilter{Metadata: map[string]any{"tags": []string{"a"}}}, store.Pagination{})
+			require.NoError(t, err)
+			assert.Empty(t, threads)
+			threads, err = s.ListThreads(ctx, store.ThreadFilter{Metadata: map[string]any{"tags": []string{"a", "b"}}}, store.Pagination{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"draft"}, threadIDs(threads))
+
+			threads, err = s.ListThreads(ctx, store.ThreadFilter{UpdatedBefore: start}, store.Pagination{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"thread-b"}, threadIDs(threads))
+
+			threads, err = s.ListThreads(ctx, store.ThreadFilter{}, store.Pagination{Limit: 1, Offset: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"thread-a"}, threadIDs(threads))
+
+			// Nil values remove keys
+			thread, err = s.UpdateThreadMetadata(ctx, "thread-a", map[string]any{"tier": nil})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"owner": "alice"}, thread.Metadata)
+
+			copied, err := s.CopyThread(ctx, "thread-a", "thread-c")
+			require.NoError(t, err)
+			assert.Equal(t, 2, copied.CheckpointCount)
+			assert.Equal(t, map[string]any{"owner": "alice"}, copied.Metadata)
+
+			checkpoints, err := s.ListByThread(ctx, "thread-c")
+			require.NoError(t, err)
+			require.Len(t, checkpoints, 2)
+			assert.NotEqual(t, "a-1", checkpoints[0].ID)
+			assert.Equal(t, checkpoints[0].ID, checkpoints[1].ParentID)
+			assert.Equal(t, "thread-c", checkpoints[1].Metadata["execution_id"])
+			assert.EqualValues(t, 2, checkpoints[1].State.(map[string]any)["step"])
+			writes, err := pws.GetWrites(ctx, checkpoints[1].ID)
+			require.NoError(t, err)
+			require.Len(t, writes, 1)
+			assert.Equal(t, "result", writes[0].Value)
+
+			_, err = s.CopyThread(ctx, "thread-a", "thread-b")
+			assert.ErrorIs(t, err, store.ErrThreadExists)
+			_, err = s.CopyThread(ctx, "missing", "thread-d")
+			assert.ErrorIs(t, err, store.ErrThreadNotFound)
+
+			require.NoError(t, s.DeleteThread(ctx, "thread-a"))
+			_, err = s.GetThread(ctx, "thread-a")
+			assert.ErrorIs(t, err, store.ErrThreadNotFound)
+			checkpoints, err = s.ListByThread(ctx, "thread-a")
+			require.NoError(t, err)
+			assert.Empty(t, checkpoints)
+			writes, err = pws.GetWrites(ctx, "a-2")
+			require.NoError(t, err)
+			assert.Empty(t, writes)
+
+			// The copy is independent of its source
+			copied, err = s.GetThread(ctx, "thread-c")
+			require.NoError(t, err)
+			assert.Equal(t, 2, copied.CheckpointCount)
+		})
+	}
+}
+
+func TestSelectThreads(t *testing.T) {
+	now := time.Now()
+	threads := []*store.Thread{
+		{ID: "b", UpdatedAt: now},
+		{ID: "a", UpdatedAt: now},
+		{ID: "c", UpdatedAt: now.Add(time.Minute), Metadata: map[string]any{"tags": []any{"x"}}},
+		nil,
+	}
+
+	assert.Equal(t, []string{"c", "a", "b"}, threadIDs(store.SelectThreads(threads, store.ThreadFilter{}, store.Pagination{})))
+	assert.Empty(t, store.SelectThreads(threads, store.ThreadFilter{}, store.Pagination{Offset: 5}))
+	assert.Equal(t, []string{"c"}, threadIDs(store.SelectThreads(threads, store.ThreadFilter{
+		Metadata: map[string]any{"tags": []string{"x"}},
+	}, store.Pagination{})))
+	assert.Equal(t, []string{"a", "b"}, threadIDs(store.SelectThreads(threads, store.ThreadFilter{UpdatedBefore: now.Add(time.Second)}, store.Pagination{})))
+}